@@ -0,0 +1,275 @@
+package libveritas
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default poll intervals of the anchor sources.
+const (
+	DefaultFileAnchorInterval   = 5 * time.Second
+	DefaultSpacedAnchorInterval = 30 * time.Second
+)
+
+// FileAnchorSource watches an anchors JSON file, as accepted by
+// AnchorsFromJson. Next returns the parsed file on the first call and then
+// each time its content changes. The file is polled; a change of
+// modification time or size triggers a read, and the content hash decides
+// whether it changed.
+type FileAnchorSource struct {
+	Path string
+	// Interval between polls. Zero selects DefaultFileAnchorInterval.
+	Interval time.Duration
+
+	mu      sync.Mutex
+	started bool
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+// NewFileAnchorSource returns a source watching path.
+func NewFileAnchorSource(path string) *FileAnchorSource {
+	return &FileAnchorSource{Path: path}
+}
+
+func (s *FileAnchorSource) Next(ctx context.Context) (AnchorSet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	interval := s.Interval
+	if interval <= 0 {
+		interval = DefaultFileAnchorInterval
+	}
+	for {
+		set, changed, err := s.poll()
+		if err != nil || changed {
+			return set, err
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// poll reads the file if its stamp changed. Content that fails to parse is
+// reported once and then treated as seen.
+func (s *FileAnchorSource) poll() (AnchorSet, bool, error) {
+	info, err := os.Stat(s.Path)
+	if err != nil {
+		return nil, false, err
+	}
+	if s.started && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return nil, false, nil
+	}
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, false, err
+	}
+	hash := sha256.Sum256(data)
+	s.modTime, s.size = info.ModTime(), info.Size()
+	if s.started && hash == s.hash {
+		return nil, false, nil
+	}
+	s.started, s.hash = true, hash
+	set, err := ParseAnchorSet(string(data))
+	if err != nil {
+		return nil, false, fmt.Errorf("libveritas: %s: %w", s.Path, err)
+	}
+	return set, true, nil
+}
+
+// SpacedAnchorSource polls a spaced node for its root anchors with the
+// getrootanchors JSON-RPC method. Next returns the anchors on the first call
+// and then each time they change. Requests that fail in transport, or with
+// an HTTP status of 429 or 5xx, are retried with exponential backoff; Next
+// returns the last error once MaxRetries retries failed. Errors returned by
+// the RPC method and malformed responses, including replies without anchors
+// or to another request id, are returned without retrying.
+type SpacedAnchorSource struct {
+	// URL of the RPC endpoint, e.g. "http://127.0.0.1:7225".
+	URL string
+	// Client sends the requests. Nil selects http.DefaultClient.
+	Client *http.Client
+	// User and Password are sent with basic authentication when User is set.
+	User     string
+	Password string
+	// Interval between polls. Zero selects DefaultSpacedAnchorInterval.
+	Interval time.Duration
+	// Backoff is the delay before the first retry, doubled for each
+	// further retry up to MaxBackoff. Zero selects 1s and 30s.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// MaxRetries bounds the retries of a failed request. Zero selects 4,
+	// a negative value disables retries.
+	MaxRetries int
+
+	mu      sync.Mutex
+	started bool
+	last    string
+	id      uint64
+}
+
+// NewSpacedAnchorSource returns a source polling the spaced RPC endpoint at
+// url.
+func NewSpacedAnchorSource(url string) *SpacedAnchorSource {
+	return &SpacedAnchorSource{URL: url}
+}
+
+// spacedMaxResponse bounds the size of an RPC response.
+const spacedMaxResponse = 64 << 20
+
+// SpacedRPCError is an error returned by the spaced RPC server.
+type SpacedRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *SpacedRPCError) Error() string {
+	return fmt.Sprintf("libveritas: spaced rpc error %d: %s", e.Code, e.Message)
+}
+
+func (s *SpacedAnchorSource) Next(ctx context.Context) (AnchorSet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	interval := s.Interval
+	if interval <= 0 {
+		interval = DefaultSpacedAnchorInterval
+	}
+	for {
+		if s.started {
+			if err := sleepContext(ctx, interval); err != nil {
+				return nil, err
+			}
+		}
+		set, err := s.fetchWithRetry(ctx)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := set.Json()
+		if err != nil {
+			return nil, err
+		}
+		if !s.started || encoded != s.last {
+			s.started, s.last = true, encoded
+			return set, nil
+		}
+	}
+}
+
+func (s *SpacedAnchorSource) fetchWithRetry(ctx context.Context) (AnchorSet, error) {
+	retries := s.MaxRetries
+	switch {
+	case retries == 0:
+		retries = 4
+	case retries < 0:
+		retries = 0
+	}
+	backoff, maxBackoff := s.Backoff, s.MaxBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+	for attempt := 0; ; attempt++ {
+		set, retry, err := s.fetch(ctx)
+		if err == nil || !retry || attempt == retries || ctx.Err() != nil {
+			return set, err
+		}
+		if err := sleepContext(ctx, backoff); err != nil {
+			return nil, err
+		}
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+// fetch sends one request. It reports whether a failed request may succeed
+// when retried.
+func (s *SpacedAnchorSource) fetch(ctx context.Context) (AnchorSet, bool, error) {
+	s.id++
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      s.id,
+		"method":  "getrootanchors",
+		"params":  []any{},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.User != "" {
+		req.SetBasicAuth(s.User, s.Password)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, spacedMaxResponse))
+	if err != nil {
+		return nil, true, err
+	}
+	var reply struct {
+		ID     json.RawMessage `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *SpacedRPCError `json:"error"`
+	}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, retryableStatus(resp.StatusCode), fmt.Errorf("libveritas: spaced rpc: %s", resp.Status)
+		}
+		return nil, false, fmt.Errorf("libveritas: spaced rpc: %w", err)
+	}
+	if reply.Error != nil {
+		return nil, false, reply.Error
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, retryableStatus(resp.StatusCode), fmt.Errorf("libveritas: spaced rpc: %s", resp.Status)
+	}
+	if id := strconv.FormatUint(s.id, 10); string(reply.ID) != id {
+		return nil, false, fmt.Errorf("libveritas: spaced rpc: reply id %s, want %s", reply.ID, id)
+	}
+	if len(reply.Result) == 0 || string(reply.Result) == "null" {
+		return nil, false, fmt.Errorf("libveritas: spaced rpc: no result")
+	}
+	set, err := ParseAnchorSet(string(reply.Result))
+	if err == nil && len(set) == 0 {
+		err = fmt.Errorf("libveritas: spaced rpc: no anchors")
+	}
+	return set, false, err
+}
+
+// retryableStatus reports whether a request that failed with HTTP status
+// code may succeed later.
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
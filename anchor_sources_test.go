@@ -0,0 +1,259 @@
+package libveritas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testAnchorsJSON      = `[{"spaces_root":"aa","block":{"hash":"01","height":1}}]`
+	testAnchorsJSONNewer = `[{"spaces_root":"aa","block":{"hash":"01","height":1}},{"spaces_root":"bb","block":{"hash":"02","height":2}}]`
+)
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestFileAnchorSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "anchors.json")
+	if err := os.WriteFile(path, []byte(testAnchorsJSON), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	source := NewFileAnchorSource(path)
+	source.Interval = 10 * time.Millisecond
+	ctx := testContext(t)
+
+	set, err := source.Next(ctx)
+	if err != nil || len(set) != 1 {
+		t.Fatalf("first Next = %+v, %v", set, err)
+	}
+
+	// Next waits while the file is unchanged.
+	short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := source.Next(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Next of unchanged file = %v, want context.DeadlineExceeded", err)
+	}
+
+	if err := os.WriteFile(path, []byte(testAnchorsJSONNewer), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	set, err = source.Next(ctx)
+	if err != nil || len(set) != 2 {
+		t.Fatalf("Next after change = %+v, %v", set, err)
+	}
+}
+
+func TestFileAnchorSourceErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "anchors.json")
+	source := NewFileAnchorSource(path)
+	source.Interval = 10 * time.Millisecond
+	ctx := testContext(t)
+
+	if _, err := source.Next(ctx); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Next of missing file = %v, want os.ErrNotExist", err)
+	}
+
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.Next(ctx); !errors.Is(err, ErrVeritasErrorInvalidInput) {
+		t.Errorf("Next of malformed file = %v, want ErrVeritasErrorInvalidInput", err)
+	}
+	// Malformed content is reported once; fixing the file recovers.
+	if err := os.WriteFile(path, []byte(testAnchorsJSON), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if set, err := source.Next(ctx); err != nil || len(set) != 1 {
+		t.Errorf("Next after fix = %+v, %v", set, err)
+	}
+}
+
+// spacedStub is a stand-in for the spaced RPC server. Each request gets the
+// next of responses; the last one repeats.
+type spacedStub struct {
+	t         *testing.T
+	responses []spacedResponse
+	requests  atomic.Int32
+}
+
+func (s *spacedStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	body, _ := io.ReadAll(r.Body)
+	if err := json.Unmarshal(body, &req); err != nil || req.Method != "getrootanchors" || r.Method != http.MethodPost {
+		s.t.Errorf("unexpected request %s %s", r.Method, body)
+	}
+	n := int(s.requests.Add(1)) - 1
+	s.responses[min(n, len(s.responses)-1)](w, req.ID)
+}
+
+// spacedResponse answers the request with the given id.
+type spacedResponse func(w http.ResponseWriter, id json.RawMessage)
+
+func rpcResult(result string) spacedResponse {
+	return func(w http.ResponseWriter, id json.RawMessage) {
+		io.WriteString(w, `{"jsonrpc":"2.0","id":`+string(id)+`,"result":`+result+`}`)
+	}
+}
+
+func rpcError(code int, message string) spacedResponse {
+	return func(w http.ResponseWriter, id json.RawMessage) {
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":%d,"message":%q}}`, id, code, message)
+	}
+}
+
+func rpcStatus(code int) spacedResponse {
+	return func(w http.ResponseWriter, _ json.RawMessage) {
+		w.WriteHeader(code)
+	}
+}
+
+func newSpacedStub(t *testing.T, responses ...spacedResponse) (*spacedStub, *SpacedAnchorSource) {
+	stub := &spacedStub{t: t, responses: responses}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+	source := NewSpacedAnchorSource(server.URL)
+	source.Interval = 10 * time.Millisecond
+	source.Backoff = time.Millisecond
+	return stub, source
+}
+
+func TestSpacedAnchorSource(t *testing.T) {
+	stub, source := newSpacedStub(t,
+		rpcResult(testAnchorsJSON),
+		rpcResult(testAnchorsJSON),
+		rpcResult(testAnchorsJSONNewer),
+	)
+	ctx := testContext(t)
+	set, err := source.Next(ctx)
+	if err != nil || len(set) != 1 {
+		t.Fatalf("first Next = %+v, %v", set, err)
+	}
+	// The unchanged second answer is skipped.
+	set, err = source.Next(ctx)
+	if err != nil || len(set) != 2 {
+		t.Fatalf("second Next = %+v, %v", set, err)
+	}
+	if n := stub.requests.Load(); n != 3 {
+		t.Errorf("%d requests, want 3", n)
+	}
+}
+
+func TestSpacedAnchorSourceBasicAuth(t *testing.T) {
+	var user, password string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ = r.BasicAuth()
+		rpcResult(testAnchorsJSON)(w, json.RawMessage(`1`))
+	}))
+	defer server.Close()
+	source := NewSpacedAnchorSource(server.URL)
+	source.User, source.Password = "alice", "secret"
+	if _, err := source.Next(testContext(t)); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if user != "alice" || password != "secret" {
+		t.Errorf("basic auth = %q, %q", user, password)
+	}
+}
+
+func TestSpacedAnchorSourceRetriesTransportErrors(t *testing.T) {
+	stub, source := newSpacedStub(t,
+		rpcStatus(http.StatusServiceUnavailable),
+		rpcStatus(http.StatusTooManyRequests),
+		rpcResult(testAnchorsJSON),
+	)
+	set, err := source.Next(testContext(t))
+	if err != nil || len(set) != 1 {
+		t.Fatalf("Next = %+v, %v", set, err)
+	}
+	if n := stub.requests.Load(); n != 3 {
+		t.Errorf("%d requests, want 3", n)
+	}
+}
+
+func TestSpacedAnchorSourceGivesUp(t *testing.T) {
+	stub, source := newSpacedStub(t, rpcStatus(http.StatusBadGateway))
+	source.MaxRetries = 2
+	if _, err := source.Next(testContext(t)); err == nil {
+		t.Fatal("Next succeeded against a failing server")
+	}
+	if n := stub.requests.Load(); n != 3 {
+		t.Errorf("%d requests, want 3", n)
+	}
+}
+
+func TestSpacedAnchorSourceConnectionRefused(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+	source := NewSpacedAnchorSource(url)
+	source.Backoff = time.Millisecond
+	source.MaxRetries = -1
+	if _, err := source.Next(testContext(t)); err == nil {
+		t.Error("Next succeeded without a server")
+	}
+}
+
+func TestSpacedAnchorSourceDoesNotRetryApplicationErrors(t *testing.T) {
+	tests := map[string]spacedResponse{
+		"rpc error": rpcError(-32601, "method not found"),
+		"rpc error with status": func(w http.ResponseWriter, id json.RawMessage) {
+			w.WriteHeader(http.StatusInternalServerError)
+			rpcError(-1, "not synced")(w, id)
+		},
+		"unauthorized":      rpcStatus(http.StatusUnauthorized),
+		"malformed reply":   func(w http.ResponseWriter, _ json.RawMessage) { io.WriteString(w, "not json") },
+		"malformed anchors": rpcResult(`{"not":"a list"}`),
+		"missing result": func(w http.ResponseWriter, id json.RawMessage) {
+			io.WriteString(w, `{"jsonrpc":"2.0","id":`+string(id)+`}`)
+		},
+		"null result":  rpcResult(`null`),
+		"empty result": rpcResult(`[]`),
+		"other id": func(w http.ResponseWriter, _ json.RawMessage) {
+			rpcResult(testAnchorsJSON)(w, json.RawMessage(`999`))
+		},
+		"missing id": func(w http.ResponseWriter, _ json.RawMessage) {
+			io.WriteString(w, `{"jsonrpc":"2.0","result":`+testAnchorsJSON+`}`)
+		},
+	}
+	for name, response := range tests {
+		stub, source := newSpacedStub(t, response, rpcResult(testAnchorsJSON))
+		_, err := source.Next(testContext(t))
+		if err == nil {
+			t.Errorf("%s: Next succeeded", name)
+		}
+		if n := stub.requests.Load(); n != 1 {
+			t.Errorf("%s: %d requests, want 1", name, n)
+		}
+	}
+	_, source := newSpacedStub(t, tests["rpc error"])
+	var rpcErr *SpacedRPCError
+	if _, err := source.Next(testContext(t)); !errors.As(err, &rpcErr) || rpcErr.Code != -32601 {
+		t.Errorf("error = %v, want *SpacedRPCError with code -32601", err)
+	}
+}
+
+func TestSpacedAnchorSourceCancel(t *testing.T) {
+	_, source := newSpacedStub(t, rpcStatus(http.StatusServiceUnavailable))
+	source.Backoff = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := source.Next(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Next = %v, want context.DeadlineExceeded", err)
+	}
+}
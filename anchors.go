@@ -0,0 +1,276 @@
+package libveritas
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// AnchorEntry is one trust anchor in the JSON format of AnchorsFromJson, as
+// returned by the getrootanchors RPC of spaced:
+//
+//	{"spaces_root": "<hex>", "nums_root": "<hex>", "block": {"hash": "<hex>", "height": 1}}
+//
+// Fields not modelled here are kept and written back unchanged.
+type AnchorEntry struct {
+	SpacesRoot []byte
+	// NumsRoot is nil when the entry has none.
+	NumsRoot  []byte
+	BlockHash []byte
+	Height    uint32
+
+	extra map[string]json.RawMessage
+}
+
+type anchorEntryJSON struct {
+	SpacesRoot hexBytes        `json:"spaces_root"`
+	NumsRoot   *hexBytes       `json:"nums_root,omitempty"`
+	Block      anchorBlockJSON `json:"block"`
+}
+
+type anchorBlockJSON struct {
+	Hash   hexBytes `json:"hash"`
+	Height uint32   `json:"height"`
+}
+
+var anchorEntryKeys = []string{"spaces_root", "nums_root", "block"}
+
+func (e AnchorEntry) MarshalJSON() ([]byte, error) {
+	v := anchorEntryJSON{
+		SpacesRoot: e.SpacesRoot,
+		Block:      anchorBlockJSON{Hash: e.BlockHash, Height: e.Height},
+	}
+	if e.NumsRoot != nil {
+		numsRoot := hexBytes(e.NumsRoot)
+		v.NumsRoot = &numsRoot
+	}
+	data, err := json.Marshal(v)
+	if err != nil || len(e.extra) == 0 {
+		return data, err
+	}
+	fields := make(map[string]json.RawMessage, len(e.extra)+len(anchorEntryKeys))
+	for key, value := range e.extra {
+		fields[key] = value
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+func (e *AnchorEntry) UnmarshalJSON(data []byte) error {
+	var v anchorEntryJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return newInvalidInputError(CodeInvalidInput, fmt.Sprintf("anchor entry: %s", err))
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return newInvalidInputError(CodeInvalidInput, fmt.Sprintf("anchor entry: %s", err))
+	}
+	for _, key := range anchorEntryKeys {
+		delete(fields, key)
+	}
+	if len(fields) == 0 {
+		fields = nil
+	}
+	var numsRoot []byte
+	if v.NumsRoot != nil {
+		numsRoot = *v.NumsRoot
+	}
+	*e = AnchorEntry{
+		SpacesRoot: v.SpacesRoot,
+		NumsRoot:   numsRoot,
+		BlockHash:  v.Block.Hash,
+		Height:     v.Block.Height,
+		extra:      fields,
+	}
+	return nil
+}
+
+// Equal reports whether e and other describe the same anchor.
+func (e AnchorEntry) Equal(other AnchorEntry) bool {
+	return e.Height == other.Height &&
+		bytes.Equal(e.BlockHash, other.BlockHash) &&
+		bytes.Equal(e.SpacesRoot, other.SpacesRoot) &&
+		bytes.Equal(e.NumsRoot, other.NumsRoot)
+}
+
+// AnchorSet is a list of anchor entries. The functions returning an
+// AnchorSet sort it by ascending height.
+type AnchorSet []AnchorEntry
+
+// ParseAnchorSet parses anchors JSON as accepted by AnchorsFromJson.
+func ParseAnchorSet(anchorsJson string) (AnchorSet, error) {
+	var set AnchorSet
+	if err := json.Unmarshal([]byte(anchorsJson), &set); err != nil {
+		if _, ok := err.(*VeritasError); ok {
+			return nil, err
+		}
+		return nil, newInvalidInputError(CodeInvalidInput, fmt.Sprintf("anchors: %s", err))
+	}
+	return set, nil
+}
+
+// NewAnchors creates Anchors from entries.
+func NewAnchors(set AnchorSet) (*Anchors, error) {
+	anchorsJson, err := set.Json()
+	if err != nil {
+		return nil, err
+	}
+	return AnchorsFromJson(anchorsJson)
+}
+
+// Entries parses the JSON the anchors were created from.
+func (_self *Anchors) Entries() (AnchorSet, error) {
+	return ParseAnchorSet(_self.source)
+}
+
+// AnchorsToJson returns the entries of anchors as JSON accepted by
+// AnchorsFromJson.
+func AnchorsToJson(anchors *Anchors) (string, error) {
+	set, err := anchors.Entries()
+	if err != nil {
+		return "", err
+	}
+	return set.Json()
+}
+
+// Json encodes the set as accepted by AnchorsFromJson.
+func (s AnchorSet) Json() (string, error) {
+	if s == nil {
+		s = AnchorSet{}
+	}
+	data, err := json.Marshal([]AnchorEntry(s))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Sorted returns a copy of s sorted by ascending height.
+func (s AnchorSet) Sorted() AnchorSet {
+	out := append(AnchorSet(nil), s...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Height < out[j].Height })
+	return out
+}
+
+// Newest returns the entry with the greatest height.
+func (s AnchorSet) Newest() (AnchorEntry, bool) {
+	if len(s) == 0 {
+		return AnchorEntry{}, false
+	}
+	newest := s[0]
+	for _, entry := range s[1:] {
+		if entry.Height > newest.Height {
+			newest = entry
+		}
+	}
+	return newest, true
+}
+
+// Oldest returns the entry with the smallest height.
+func (s AnchorSet) Oldest() (AnchorEntry, bool) {
+	if len(s) == 0 {
+		return AnchorEntry{}, false
+	}
+	oldest := s[0]
+	for _, entry := range s[1:] {
+		if entry.Height < oldest.Height {
+			oldest = entry
+		}
+	}
+	return oldest, true
+}
+
+// At returns the entry at height.
+func (s AnchorSet) At(height uint32) (AnchorEntry, bool) {
+	for _, entry := range s {
+		if entry.Height == height {
+			return entry, true
+		}
+	}
+	return AnchorEntry{}, false
+}
+
+// Range returns the entries with from <= height <= to.
+func (s AnchorSet) Range(from, to uint32) AnchorSet {
+	var out AnchorSet
+	for _, entry := range s {
+		if from <= entry.Height && entry.Height <= to {
+			out = append(out, entry)
+		}
+	}
+	return out.Sorted()
+}
+
+// Prune returns the entries at or above minHeight.
+func (s AnchorSet) Prune(minHeight uint32) AnchorSet {
+	return s.Range(minHeight, ^uint32(0))
+}
+
+// KeepNewest returns the n entries with the greatest heights.
+func (s AnchorSet) KeepNewest(n int) AnchorSet {
+	sorted := s.Sorted()
+	if n < 0 {
+		n = 0
+	}
+	if len(sorted) > n {
+		sorted = sorted[len(sorted)-n:]
+	}
+	return sorted
+}
+
+// ErrAnchorConflict is matched by *AnchorConflictError.
+var ErrAnchorConflict = fmt.Errorf("libveritas: conflicting anchors")
+
+// AnchorConflictError reports two different entries for the same height, or
+// the same block at two heights.
+type AnchorConflictError struct {
+	Existing AnchorEntry
+	Other    AnchorEntry
+}
+
+func (e *AnchorConflictError) Error() string {
+	if e.Existing.Height == e.Other.Height {
+		return fmt.Sprintf("libveritas: conflicting anchors at height %d", e.Existing.Height)
+	}
+	return fmt.Sprintf("libveritas: block %x anchored at heights %d and %d", e.Existing.BlockHash, e.Existing.Height, e.Other.Height)
+}
+
+func (e *AnchorConflictError) Is(target error) bool {
+	return target == ErrAnchorConflict
+}
+
+// MergeAnchorSets combines sets into one, sorted by height. Entries present
+// in several sets are kept once; entries that disagree about a height or a
+// block fail with *AnchorConflictError.
+func MergeAnchorSets(sets ...AnchorSet) (AnchorSet, error) {
+	byHeight := make(map[uint32]AnchorEntry)
+	byBlock := make(map[string]AnchorEntry)
+	var merged AnchorSet
+	for _, set := range sets {
+		for _, entry := range set {
+			if existing, ok := byHeight[entry.Height]; ok {
+				if !existing.Equal(entry) {
+					return nil, &AnchorConflictError{Existing: existing, Other: entry}
+				}
+				continue
+			}
+			if len(entry.BlockHash) > 0 {
+				if existing, ok := byBlock[string(entry.BlockHash)]; ok {
+					return nil, &AnchorConflictError{Existing: existing, Other: entry}
+				}
+				byBlock[string(entry.BlockHash)] = entry
+			}
+			byHeight[entry.Height] = entry
+			merged = append(merged, entry)
+		}
+	}
+	return merged.Sorted(), nil
+}
+
+// Merge is MergeAnchorSets(s, others...).
+func (s AnchorSet) Merge(others ...AnchorSet) (AnchorSet, error) {
+	return MergeAnchorSets(append([]AnchorSet{s}, others...)...)
+}
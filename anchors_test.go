@@ -0,0 +1,147 @@
+package libveritas
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func testAnchor(height uint32, hash byte) AnchorEntry {
+	return AnchorEntry{
+		SpacesRoot: []byte{hash, 0x01},
+		BlockHash:  []byte{hash},
+		Height:     height,
+	}
+}
+
+func TestAnchorSetJSON(t *testing.T) {
+	const input = `[{"spaces_root":"aa01","nums_root":"bb","block":{"hash":"cc","height":7},"version":2},` +
+		`{"spaces_root":"dd","block":{"hash":"ee","height":3}}]`
+	set, err := ParseAnchorSet(input)
+	if err != nil {
+		t.Fatalf("ParseAnchorSet: %v", err)
+	}
+	want := AnchorSet{
+		{SpacesRoot: []byte{0xaa, 0x01}, NumsRoot: []byte{0xbb}, BlockHash: []byte{0xcc}, Height: 7},
+		{SpacesRoot: []byte{0xdd}, BlockHash: []byte{0xee}, Height: 3},
+	}
+	if len(set) != len(want) || !set[0].Equal(want[0]) || !set[1].Equal(want[1]) || set[1].NumsRoot != nil {
+		t.Fatalf("ParseAnchorSet = %+v, want %+v", set, want)
+	}
+
+	// Unmodelled fields are written back, and absent nums roots stay absent.
+	out, err := set.Json()
+	if err != nil {
+		t.Fatalf("Json: %v", err)
+	}
+	var got, expected any
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(input), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Json = %s, want %s", out, input)
+	}
+}
+
+func TestAnchorSetJSONEmpty(t *testing.T) {
+	if out, err := AnchorSet(nil).Json(); err != nil || out != "[]" {
+		t.Errorf("Json of nil set = %q, %v, want []", out, err)
+	}
+}
+
+func TestParseAnchorSetErrors(t *testing.T) {
+	for _, input := range []string{
+		``,
+		`{}`,
+		`[{"spaces_root":"zz","block":{"hash":"cc","height":1}}]`,
+		`[{"spaces_root":"aa","block":{"hash":"cc","height":-1}}]`,
+		`[1]`,
+	} {
+		if _, err := ParseAnchorSet(input); !errors.Is(err, ErrVeritasErrorInvalidInput) {
+			t.Errorf("ParseAnchorSet(%q) = %v, want ErrVeritasErrorInvalidInput", input, err)
+		}
+	}
+}
+
+func TestAnchorSetQueries(t *testing.T) {
+	set := AnchorSet{testAnchor(20, 2), testAnchor(10, 1), testAnchor(40, 4), testAnchor(30, 3)}
+	heights := func(s AnchorSet) []uint32 {
+		out := []uint32{}
+		for _, entry := range s {
+			out = append(out, entry.Height)
+		}
+		return out
+	}
+	if got := heights(set.Sorted()); !reflect.DeepEqual(got, []uint32{10, 20, 30, 40}) {
+		t.Errorf("Sorted = %v", got)
+	}
+	if set[0].Height != 20 {
+		t.Error("Sorted modified the set")
+	}
+	if newest, ok := set.Newest(); !ok || newest.Height != 40 {
+		t.Errorf("Newest = %d, %v", newest.Height, ok)
+	}
+	if oldest, ok := set.Oldest(); !ok || oldest.Height != 10 {
+		t.Errorf("Oldest = %d, %v", oldest.Height, ok)
+	}
+	if entry, ok := set.At(30); !ok || !entry.Equal(testAnchor(30, 3)) {
+		t.Errorf("At(30) = %+v, %v", entry, ok)
+	}
+	if _, ok := set.At(25); ok {
+		t.Error("At(25) found an entry")
+	}
+	if got := heights(set.Range(15, 30)); !reflect.DeepEqual(got, []uint32{20, 30}) {
+		t.Errorf("Range(15, 30) = %v", got)
+	}
+	if got := heights(set.Prune(30)); !reflect.DeepEqual(got, []uint32{30, 40}) {
+		t.Errorf("Prune(30) = %v", got)
+	}
+	for n, want := range map[int][]uint32{-1: {}, 0: {}, 2: {30, 40}, 9: {10, 20, 30, 40}} {
+		if got := heights(set.KeepNewest(n)); !reflect.DeepEqual(got, want) {
+			t.Errorf("KeepNewest(%d) = %v, want %v", n, got, want)
+		}
+	}
+
+	var empty AnchorSet
+	if _, ok := empty.Newest(); ok {
+		t.Error("Newest of empty set found an entry")
+	}
+	if _, ok := empty.Oldest(); ok {
+		t.Error("Oldest of empty set found an entry")
+	}
+}
+
+func TestMergeAnchorSets(t *testing.T) {
+	a := AnchorSet{testAnchor(30, 3), testAnchor(10, 1)}
+	b := AnchorSet{testAnchor(20, 2), testAnchor(10, 1)}
+	merged, err := a.Merge(b)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	want := AnchorSet{testAnchor(10, 1), testAnchor(20, 2), testAnchor(30, 3)}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("Merge = %+v, want %+v", merged, want)
+	}
+}
+
+func TestMergeAnchorSetsConflicts(t *testing.T) {
+	tests := map[string]AnchorSet{
+		"same height": {testAnchor(10, 9)},
+		"same block":  {testAnchor(11, 1)},
+	}
+	for name, other := range tests {
+		_, err := MergeAnchorSets(AnchorSet{testAnchor(10, 1)}, other)
+		var conflict *AnchorConflictError
+		if !errors.As(err, &conflict) || !errors.Is(err, ErrAnchorConflict) {
+			t.Errorf("%s: error = %v, want *AnchorConflictError", name, err)
+			continue
+		}
+		if conflict.Existing.Height != 10 || !conflict.Other.Equal(other[0]) {
+			t.Errorf("%s: conflict = %+v", name, conflict)
+		}
+	}
+}
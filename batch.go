@@ -0,0 +1,55 @@
+package libveritas
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BatchError reports the items of a batch call that failed. The results of
+// the other items are still returned; failed items hold zero values.
+type BatchError struct {
+	// Failed items in input order.
+	Items []BatchItemError
+}
+
+// BatchItemError is the failure of one item of a batch call.
+type BatchItemError struct {
+	// Index of the item in the input.
+	Index int
+	Err   error
+}
+
+func (err *BatchError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "libveritas: %d batch item(s) failed", len(err.Items))
+	for i, item := range err.Items {
+		if i == 3 {
+			fmt.Fprintf(&b, "; ...")
+			break
+		}
+		fmt.Fprintf(&b, "; item %d: %s", item.Index, item.Err)
+	}
+	return b.String()
+}
+
+// Unwrap returns the item errors, so errors.Is and errors.As match if any
+// item failed with the target.
+func (err *BatchError) Unwrap() []error {
+	errs := make([]error, len(err.Items))
+	for i, item := range err.Items {
+		errs[i] = item.Err
+	}
+	return errs
+}
+
+func (err *BatchError) add(index int, itemErr error) {
+	err.Items = append(err.Items, BatchItemError{Index: index, Err: itemErr})
+}
+
+// AsError returns nil if no item failed.
+func (err *BatchError) AsError() error {
+	if err == nil || len(err.Items) == 0 {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+//go:build cgo && !libveritas_nocgo && (libveritas_dynamic || libveritas_pkgconfig || (linux && amd64) || (darwin && arm64) || (windows && amd64))
+
+package libveritas
+
+// #include <libveritas_uniffi_shims.h>
+import "C"
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"runtime"
+	"unsafe"
+)
+
+// Decode stored zone bytes like DecodeZone, crossing into the native
+// library once for the whole batch. Items that fail are reported in a
+// *BatchError.
+func DecodeZones(blobs [][]byte) ([]Zone, error) {
+	if err := Init(); err != nil {
+		return nil, err
+	}
+	return runBatch(C.LIBVERITAS_BATCH_DECODE_ZONE, blobs, FfiConverterZoneINSTANCE.Read)
+}
+
+// Serialize zones like ZoneToBytes, crossing into the native library once
+// for the whole batch. Items that fail are reported in a *BatchError.
+func ZonesToBytes(zones []Zone) ([][]byte, error) {
+	if err := Init(); err != nil {
+		return nil, err
+	}
+	inputs := make([][]byte, len(zones))
+	for i, zone := range zones {
+		var buffer bytes.Buffer
+		FfiConverterZoneINSTANCE.Write(&buffer, zone)
+		inputs[i] = buffer.Bytes()
+	}
+	return runBatch(C.LIBVERITAS_BATCH_ZONE_TO_BYTES, inputs, FfiConverterBytesINSTANCE.Read)
+}
+
+// Parse record sets like NewRecordSet(data).Unpack(), crossing into the
+// native library once for the whole batch. Items that fail are reported in
+// a *BatchError.
+func UnpackRecordSets(sets [][]byte) ([][]ParsedRecord, error) {
+	if err := Init(); err != nil {
+		return nil, err
+	}
+	return runBatch(C.LIBVERITAS_BATCH_UNPACK_RECORD_SET, sets, FfiConverterSequenceParsedRecordINSTANCE.Read)
+}
+
+// runBatch passes all inputs to one libveritas_batch call and reads each
+// successful result with read. The result buffers are freed together in one
+// more call.
+func runBatch[T any](op C.int, inputs [][]byte, read func(io.Reader) T) ([]T, error) {
+	results := make([]T, len(inputs))
+	if len(inputs) == 0 {
+		return results, nil
+	}
+	if len(inputs) > math.MaxInt32 {
+		return nil, newInvalidInputError(CodeInvalidInput, "batch has too many items")
+	}
+
+	var pinner runtime.Pinner
+	defer pinner.Unpin()
+	foreign := make([]C.ForeignBytes, len(inputs))
+	skip := make([]bool, len(inputs))
+	for i, input := range inputs {
+		if len(input) > math.MaxInt32-4 {
+			// Passed as empty; the result is discarded below.
+			skip[i] = true
+			continue
+		}
+		if len(input) > 0 {
+			pinner.Pin(&input[0])
+			foreign[i] = C.ForeignBytes{
+				len:  C.int32_t(len(input)),
+				data: (*C.uint8_t)(unsafe.Pointer(&input[0])),
+			}
+		}
+	}
+
+	items := make([]C.LibveritasBatchItem, len(inputs))
+	C.libveritas_batch(op, &foreign[0], C.int32_t(len(inputs)), &items[0])
+
+	// Every item owns a buffer: its value, or its error until that is read.
+	// The values are freed together once the results are read. Reading an
+	// error frees it, even when it panics because panic isolation is off;
+	// the errors the loop did not reach are freed with the values, so that a
+	// panic does not leak them.
+	var batchErr BatchError
+	next := 0
+	defer func() {
+		owned := make([]C.RustBuffer, 0, len(items))
+		for i := range items {
+			switch {
+			case items[i].status.code == 0:
+				owned = append(owned, items[i].value)
+			case i >= next:
+				owned = append(owned, items[i].status.errorBuf)
+			}
+		}
+		if len(owned) > 0 {
+			C.libveritas_free_buffers(&owned[0], C.int32_t(len(owned)))
+		}
+	}()
+	for i := range items {
+		next = i + 1
+		switch {
+		case skip[i]:
+			if items[i].status.code != 0 {
+				GoRustBuffer{inner: items[i].status.errorBuf}.Free()
+			}
+			batchErr.add(i, newInvalidInputError(CodeInvalidInput, "batch item is too large"))
+		case items[i].status.code == 0:
+			results[i] = read(GoRustBuffer{inner: items[i].value}.AsReader())
+		default:
+			if err := checkCallStatus[VeritasError](FfiConverterVeritasError{}, items[i].status); err != nil {
+				batchErr.add(i, err)
+			}
+		}
+	}
+	return results, batchErr.AsError()
+}
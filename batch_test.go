@@ -0,0 +1,177 @@
+package libveritas
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBatchError(t *testing.T) {
+	var batchErr BatchError
+	if batchErr.AsError() != nil {
+		t.Fatal("AsError of an empty batch is not nil")
+	}
+	for i := 0; i < 5; i++ {
+		batchErr.add(i*2, newInvalidInputError(CodeInvalidHandle, "bad handle"))
+	}
+	err := batchErr.AsError()
+	if !errors.Is(err, ErrInvalidHandle) {
+		t.Errorf("%v does not match the item errors", err)
+	}
+	msg := err.Error()
+	if !strings.HasPrefix(msg, "libveritas: 5 batch item(s) failed; item 0:") || !strings.HasSuffix(msg, "; ...") {
+		t.Errorf("Error = %q", msg)
+	}
+	if strings.Contains(msg, "item 6:") {
+		t.Errorf("Error lists more than three items: %q", msg)
+	}
+}
+
+// batchRecordSets returns record sets for the batch tests, followed by one
+// that does not decode.
+func batchRecordSets(t testing.TB) [][]byte {
+	t.Helper()
+	var sets [][]byte
+	for i := 0; i < 8; i++ {
+		set, err := PackRecords([]Record{
+			RecordSeq{Version: uint64(i)},
+			RecordTxt{Key: "name", Value: []string{fmt.Sprintf("user%d", i)}},
+			RecordBlob{Key: "avatar", Value: make([]byte, 64*i+1)},
+		})
+		if err != nil {
+			t.Fatalf("PackRecords: %v", err)
+		}
+		sets = append(sets, set)
+	}
+	return append(sets, []byte{0xff, 0xff, 0xff})
+}
+
+// batchZones returns zones for the batch tests.
+func batchZones(t testing.TB) []Zone {
+	t.Helper()
+	sets := batchRecordSets(t)
+	zones := make([]Zone, len(sets)-1)
+	for i := range zones {
+		handle := fmt.Sprintf("user%d@bitcoin", i)
+		zones[i] = Zone{
+			Anchor:          uint32(100 + i),
+			AnchorHash:      bytes.Repeat([]byte{byte(i)}, 32),
+			Sovereignty:     "sovereign",
+			Handle:          handle,
+			Canonical:       handle,
+			ScriptPubkey:    bytes.Repeat([]byte{0x51}, 34),
+			Records:         sets[i],
+			FallbackRecords: []byte{},
+			Delegate:        DelegateStateEmpty{},
+			Commitment:      CommitmentStateEmpty{},
+		}
+	}
+	return zones
+}
+
+// checkBatchItem compares item i of a batch call with the result of the
+// single call.
+func checkBatchItem[T any](t *testing.T, name string, i int, batchErr error, got T, want T, wantErr error) {
+	t.Helper()
+	var itemErr error
+	var errs *BatchError
+	if errors.As(batchErr, &errs) {
+		for _, item := range errs.Items {
+			if item.Index == i {
+				itemErr = item.Err
+			}
+		}
+	}
+	switch {
+	case (itemErr == nil) != (wantErr == nil):
+		t.Errorf("%s item %d: error %v, single call %v", name, i, itemErr, wantErr)
+	case itemErr != nil && ErrorCodeOf(itemErr) != ErrorCodeOf(wantErr):
+		t.Errorf("%s item %d: code %s, single call %s", name, i, ErrorCodeOf(itemErr), ErrorCodeOf(wantErr))
+	case wantErr == nil && !reflect.DeepEqual(got, want):
+		t.Errorf("%s item %d = %+v, single call %+v", name, i, got, want)
+	}
+}
+
+// UnpackRecordSets uses the Go codec without the native library, so this
+// test runs in both builds.
+func TestUnpackRecordSetsParity(t *testing.T) {
+	sets := batchRecordSets(t)
+	got, err := UnpackRecordSets(sets)
+	if len(got) != len(sets) {
+		t.Fatalf("UnpackRecordSets returned %d results for %d sets", len(got), len(sets))
+	}
+	for i, set := range sets {
+		want, wantErr := NewRecordSet(set).Unpack()
+		checkBatchItem(t, "UnpackRecordSets", i, err, got[i], want, wantErr)
+	}
+}
+
+func TestNativeZoneBatchParity(t *testing.T) {
+	if !NativeAvailable() {
+		t.Skip("native library not available")
+	}
+	zones := batchZones(t)
+	blobs, err := ZonesToBytes(zones)
+	if err != nil || len(blobs) != len(zones) {
+		t.Fatalf("ZonesToBytes = %d blobs, %v", len(blobs), err)
+	}
+	for i, zone := range zones {
+		want, wantErr := ZoneToBytes(zone)
+		checkBatchItem(t, "ZonesToBytes", i, err, blobs[i], want, wantErr)
+	}
+
+	blobs = append(blobs, []byte{0xff})
+	decoded, err := DecodeZones(blobs)
+	if len(decoded) != len(blobs) {
+		t.Fatalf("DecodeZones returned %d zones for %d blobs", len(decoded), len(blobs))
+	}
+	for i, blob := range blobs {
+		want, wantErr := DecodeZone(blob)
+		checkBatchItem(t, "DecodeZones", i, err, decoded[i], want, wantErr)
+	}
+	if errs := new(BatchError); !errors.As(err, &errs) || len(errs.Items) != 1 || errs.Items[0].Index != len(blobs)-1 {
+		t.Errorf("DecodeZones error = %v, want only the last item to fail", err)
+	}
+
+	for name, call := range map[string]func() (int, error){
+		"DecodeZones":      func() (int, error) { r, err := DecodeZones(nil); return len(r), err },
+		"ZonesToBytes":     func() (int, error) { r, err := ZonesToBytes(nil); return len(r), err },
+		"UnpackRecordSets": func() (int, error) { r, err := UnpackRecordSets(nil); return len(r), err },
+	} {
+		if n, err := call(); n != 0 || err != nil {
+			t.Errorf("%s of an empty batch = %d results, %v", name, n, err)
+		}
+	}
+}
+
+// BenchmarkDecodeZones compares one batch call with a call per zone.
+func BenchmarkDecodeZones(b *testing.B) {
+	if !NativeAvailable() {
+		b.Skip("native library not available")
+	}
+	blobs, err := ZonesToBytes(batchZones(b))
+	if err != nil {
+		b.Fatalf("ZonesToBytes: %v", err)
+	}
+	b.Run("batch", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := DecodeZones(blobs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("single", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, blob := range blobs {
+				if _, err := DecodeZone(blob); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package libveritas
+
+import "sync"
+
+// BytesView is a read-only view of bytes owned by the native library, used
+// for large outputs to avoid copying them into Go memory. The bytes stay
+// valid until Release; copy them to keep them longer.
+//
+// The methods of a view may be called concurrently. Release does not wait
+// for users of a slice returned by Bytes, so it must still not be called
+// before they are done.
+//
+// Unlike the native objects, a view has no finalizer: slices returned by
+// Bytes do not keep the view reachable, so a finalizer could free the bytes
+// while they are still in use. A view that is never released leaks its
+// buffer.
+type BytesView struct {
+	mu      sync.RWMutex
+	data    []byte
+	release func()
+}
+
+func newBytesView(data []byte, release func()) *BytesView {
+	return &BytesView{data: data, release: release}
+}
+
+// Bytes returns the viewed bytes, or nil after Release. The slice must not
+// be modified, and must not be used after Release.
+func (v *BytesView) Bytes() []byte {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.data
+}
+
+// Len returns the number of viewed bytes, or 0 after Release.
+func (v *BytesView) Len() int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return len(v.data)
+}
+
+// Release frees the native buffer. Calling it again has no effect.
+func (v *BytesView) Release() {
+	v.mu.Lock()
+	release := v.release
+	v.data, v.release = nil, nil
+	v.mu.Unlock()
+	if release != nil {
+		release()
+	}
+}
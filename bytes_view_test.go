@@ -0,0 +1,36 @@
+package libveritas
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBytesViewRelease(t *testing.T) {
+	var released atomic.Int32
+	v := newBytesView([]byte{1, 2, 3}, func() { released.Add(1) })
+	if v.Len() != 3 || string(v.Bytes()) != "\x01\x02\x03" {
+		t.Fatalf("view = %x", v.Bytes())
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if n := v.Len(); n != 0 && n != 3 {
+				t.Errorf("Len = %d", n)
+			}
+			v.Bytes()
+			v.Release()
+		}()
+	}
+	wg.Wait()
+
+	if n := released.Load(); n != 1 {
+		t.Errorf("buffer released %d times", n)
+	}
+	if v.Len() != 0 || v.Bytes() != nil {
+		t.Errorf("view after Release = %x", v.Bytes())
+	}
+}
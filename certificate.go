@@ -0,0 +1,261 @@
+package libveritas
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// CertificateKind tells whether a certificate is issued for a space or for
+// a handle within it.
+type CertificateKind int
+
+const (
+	CertificateKindUnknown CertificateKind = iota
+	// Certificate of a space itself, such as @bitcoin.
+	CertificateKindRoot
+	// Certificate of a handle in a space, such as alice@bitcoin.
+	CertificateKindLeaf
+)
+
+func (k CertificateKind) String() string {
+	switch k {
+	case CertificateKindRoot:
+		return "root"
+	case CertificateKindLeaf:
+		return "leaf"
+	default:
+		return "unknown"
+	}
+}
+
+// Certificate is the typed form of a certificate as decoded by
+// DecodeCertificate. JSON keeps the full decoded form.
+type Certificate struct {
+	// Subject is the handle the certificate is for, e.g. "alice@bitcoin".
+	Subject string
+	// Issuer is the space the subject belongs to, e.g. "@bitcoin".
+	Issuer       string
+	AnchorHeight uint32
+	// ScriptPubkey is empty if the certificate does not carry one.
+	ScriptPubkey []byte
+	Kind         CertificateKind
+	// Raw is the certificate as stored.
+	Raw []byte
+	// JSON is the output of DecodeCertificate.
+	JSON string
+}
+
+// certificateJSON is the object returned by DecodeCertificate:
+//
+//	{
+//	  "subject": "alice@bitcoin",
+//	  "issuer": "@bitcoin",
+//	  "kind": "leaf",
+//	  "anchor_height": 871000,
+//	  "script_pubkey": "5120..."
+//	}
+//
+// subject, kind and anchor_height are required. issuer defaults to the
+// space of the subject, and script_pubkey may be absent.
+//
+// The native library does not document this object, and it has not been
+// checked against a real certificate in this repository, which has no
+// fixture for one. TestNativeCertificateFixture checks it when
+// LIBVERITAS_TEST_CERTIFICATE names a certificate file. A decoded
+// certificate that lacks the required fields, or has values for them other
+// than those above, fails ParseCertificate with ErrMalformedCertificate
+// rather than being misread.
+type certificateJSON struct {
+	Subject      *string `json:"subject"`
+	Issuer       *string `json:"issuer"`
+	Kind         *string `json:"kind"`
+	AnchorHeight *uint32 `json:"anchor_height"`
+	ScriptPubkey *string `json:"script_pubkey"`
+}
+
+// ParseCertificate decodes certificate bytes into a Certificate.
+func ParseCertificate(data []byte) (Certificate, error) {
+	decoded, err := DecodeCertificate(data)
+	if err != nil {
+		return Certificate{}, err
+	}
+	return parseDecodedCertificate(data, decoded)
+}
+
+// parseDecodedCertificate builds a Certificate from data and its
+// DecodeCertificate output.
+func parseDecodedCertificate(data []byte, decoded string) (Certificate, error) {
+	var fields certificateJSON
+	if err := json.Unmarshal([]byte(decoded), &fields); err != nil {
+		return Certificate{}, malformedCertificate("decoded certificate: %s", err)
+	}
+	switch {
+	case fields.Subject == nil:
+		return Certificate{}, malformedCertificate("decoded certificate has no subject")
+	case fields.Kind == nil:
+		return Certificate{}, malformedCertificate("decoded certificate has no kind")
+	case fields.AnchorHeight == nil:
+		return Certificate{}, malformedCertificate("decoded certificate has no anchor_height")
+	}
+	subject, err := ParseHandle(*fields.Subject)
+	if err != nil {
+		return Certificate{}, malformedCertificate("certificate subject %q is not a valid handle", *fields.Subject)
+	}
+	cert := Certificate{
+		Subject:      subject.String(),
+		Issuer:       subject.SpaceHandle().String(),
+		AnchorHeight: *fields.AnchorHeight,
+		Raw:          bytes.Clone(data),
+		JSON:         decoded,
+	}
+	switch *fields.Kind {
+	case "root":
+		cert.Kind = CertificateKindRoot
+	case "leaf":
+		cert.Kind = CertificateKindLeaf
+	default:
+		return Certificate{}, malformedCertificate("certificate kind %q is neither root nor leaf", *fields.Kind)
+	}
+	if (cert.Kind == CertificateKindRoot) != subject.IsSpace() {
+		return Certificate{}, malformedCertificate("%s certificate for %s", cert.Kind, subject)
+	}
+	if fields.Issuer != nil && *fields.Issuer != cert.Issuer {
+		return Certificate{}, malformedCertificate("certificate issuer %q is not the space of %s", *fields.Issuer, subject)
+	}
+	if fields.ScriptPubkey != nil {
+		if cert.ScriptPubkey, err = hex.DecodeString(*fields.ScriptPubkey); err != nil {
+			return Certificate{}, malformedCertificate("certificate script_pubkey: %s", err)
+		}
+	}
+	return cert, nil
+}
+
+func malformedCertificate(format string, args ...any) error {
+	return newInvalidInputError(CodeMalformedCertificate, fmt.Sprintf(format, args...))
+}
+
+// ParseCertificates parses a list of certificates, such as
+// VerifiedMessage.Certificates().
+func ParseCertificates(certs [][]byte) ([]Certificate, error) {
+	out := make([]Certificate, len(certs))
+	for i, data := range certs {
+		cert, err := ParseCertificate(data)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = cert
+	}
+	return out, nil
+}
+
+// SubjectHandle parses the subject.
+func (c Certificate) SubjectHandle() (Handle, error) {
+	return ParseHandle(c.Subject)
+}
+
+// CertificateChain is a parsed .spacecert: a subject and the certificates
+// proving it, as written by CreateCertificateChain.
+type CertificateChain struct {
+	Subject      string
+	Certificates []Certificate
+	// Raw is the .spacecert encoding.
+	Raw []byte
+}
+
+// NewCertificateChain creates a chain with CreateCertificateChain and parses
+// its certificates.
+func NewCertificateChain(subject string, certs [][]byte) (*CertificateChain, error) {
+	raw, err := CreateCertificateChain(subject, certs)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := ParseCertificates(certs)
+	if err != nil {
+		return nil, err
+	}
+	return &CertificateChain{Subject: subject, Certificates: parsed, Raw: raw}, nil
+}
+
+// ParseCertificateChain parses a .spacecert. The result is checked by
+// encoding it again with CreateCertificateChain, so the native library is
+// required.
+func ParseCertificateChain(data []byte) (*CertificateChain, error) {
+	subject, certs, err := splitSpacecert(data)
+	if err != nil {
+		return nil, newInvalidInputError(CodeMalformedCertificate, fmt.Sprintf("malformed .spacecert: %s", err))
+	}
+	encoded, err := CreateCertificateChain(subject, certs)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(encoded, data) {
+		return nil, newInvalidInputError(CodeMalformedCertificate, "malformed .spacecert: unrecognized encoding")
+	}
+	parsed, err := ParseCertificates(certs)
+	if err != nil {
+		return nil, err
+	}
+	return &CertificateChain{Subject: subject, Certificates: parsed, Raw: bytes.Clone(data)}, nil
+}
+
+// CertificateBytes returns the raw certificates of the chain.
+func (c *CertificateChain) CertificateBytes() [][]byte {
+	out := make([][]byte, len(c.Certificates))
+	for i, cert := range c.Certificates {
+		out[i] = cert.Raw
+	}
+	return out
+}
+
+// splitSpacecert reads the subject and certificates of a .spacecert in the
+// layout
+//
+//	subject:str32 | count:u32 | (cert:bytes32)*
+//
+// with u32 little-endian length prefixes. This layout is not documented by
+// the native library: TestNativeSpacecertLayout holds it to the output of
+// CreateCertificateChain, and ParseCertificateChain re-encodes every result
+// natively, so that a chain in another encoding is rejected rather than
+// misread.
+func splitSpacecert(data []byte) (string, [][]byte, error) {
+	subject, rest, err := readSpacecertField(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("subject: %w", err)
+	}
+	if len(rest) < 4 {
+		return "", nil, fmt.Errorf("certificate count truncated")
+	}
+	count := binary.LittleEndian.Uint32(rest)
+	rest = rest[4:]
+	// Every certificate takes at least its length prefix.
+	if uint64(count)*4 > uint64(len(rest)) {
+		return "", nil, fmt.Errorf("certificate count %d exceeds data", count)
+	}
+	certs := make([][]byte, count)
+	for i := range certs {
+		var cert []byte
+		cert, rest, err = readSpacecertField(rest)
+		if err != nil {
+			return "", nil, fmt.Errorf("certificate %d: %w", i, err)
+		}
+		certs[i] = bytes.Clone(cert)
+	}
+	if len(rest) != 0 {
+		return "", nil, fmt.Errorf("%d trailing bytes", len(rest))
+	}
+	return string(subject), certs, nil
+}
+
+func readSpacecertField(data []byte) ([]byte, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, fmt.Errorf("length truncated")
+	}
+	length := binary.LittleEndian.Uint32(data)
+	if uint64(length) > uint64(len(data)-4) {
+		return nil, nil, fmt.Errorf("truncated")
+	}
+	return data[4 : 4+length], data[4+length:], nil
+}
@@ -0,0 +1,189 @@
+package libveritas
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseDecodedCertificate(t *testing.T) {
+	raw := []byte{0xc0, 0xde}
+	tests := []struct {
+		name    string
+		decoded string
+		want    Certificate
+	}{
+		{
+			name:    "root",
+			decoded: `{"subject":"@bitcoin","kind":"root","anchor_height":871000}`,
+			want:    Certificate{Subject: "@bitcoin", Issuer: "@bitcoin", AnchorHeight: 871000, Kind: CertificateKindRoot},
+		},
+		{
+			name:    "leaf",
+			decoded: `{"subject":"alice@bitcoin","issuer":"@bitcoin","kind":"leaf","anchor_height":5,"script_pubkey":"5120ab"}`,
+			want: Certificate{Subject: "alice@bitcoin", Issuer: "@bitcoin", AnchorHeight: 5, Kind: CertificateKindLeaf,
+				ScriptPubkey: []byte{0x51, 0x20, 0xab}},
+		},
+		{
+			name:    "nested",
+			decoded: `{"subject":"bob.alice@bitcoin","kind":"leaf","anchor_height":0,"extra":{"subject":"@other"}}`,
+			want:    Certificate{Subject: "bob.alice@bitcoin", Issuer: "@bitcoin", Kind: CertificateKindLeaf},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert, err := parseDecodedCertificate(raw, tt.decoded)
+			if err != nil {
+				t.Fatalf("parseDecodedCertificate: %v", err)
+			}
+			tt.want.Raw, tt.want.JSON = raw, tt.decoded
+			if !reflect.DeepEqual(cert, tt.want) {
+				t.Errorf("got %+v, want %+v", cert, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDecodedCertificateCopiesRaw(t *testing.T) {
+	raw := []byte{1}
+	cert, err := parseDecodedCertificate(raw, `{"subject":"@bitcoin","kind":"root","anchor_height":1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw[0] = 2
+	if cert.Raw[0] != 1 {
+		t.Error("Certificate.Raw aliases the input")
+	}
+}
+
+func TestParseDecodedCertificateErrors(t *testing.T) {
+	tests := map[string]string{
+		"not json":         `[`,
+		"not an object":    `[]`,
+		"no subject":       `{"kind":"root","anchor_height":1}`,
+		"no kind":          `{"subject":"@bitcoin","anchor_height":1}`,
+		"no anchor height": `{"subject":"@bitcoin","kind":"root"}`,
+		"null subject":     `{"subject":null,"kind":"root","anchor_height":1}`,
+		"bad subject":      `{"subject":"bitcoin","kind":"root","anchor_height":1}`,
+		"bad kind":         `{"subject":"@bitcoin","kind":"trunk","anchor_height":1}`,
+		"root for handle":  `{"subject":"alice@bitcoin","kind":"root","anchor_height":1}`,
+		"leaf for space":   `{"subject":"@bitcoin","kind":"leaf","anchor_height":1}`,
+		"wrong issuer":     `{"subject":"alice@bitcoin","issuer":"@other","kind":"leaf","anchor_height":1}`,
+		"height as string": `{"subject":"@bitcoin","kind":"root","anchor_height":"1"}`,
+		"negative height":  `{"subject":"@bitcoin","kind":"root","anchor_height":-1}`,
+		"bad spk":          `{"subject":"@bitcoin","kind":"root","anchor_height":1,"script_pubkey":"zz"}`,
+	}
+	for name, decoded := range tests {
+		if _, err := parseDecodedCertificate(nil, decoded); !errors.Is(err, ErrMalformedCertificate) {
+			t.Errorf("%s: error = %v, want ErrMalformedCertificate", name, err)
+		}
+	}
+}
+
+// encodeSpacecert writes the layout splitSpacecert reads.
+func encodeSpacecert(subject string, certs [][]byte) []byte {
+	out := binary.LittleEndian.AppendUint32(nil, uint32(len(subject)))
+	out = append(out, subject...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(certs)))
+	for _, cert := range certs {
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(cert)))
+		out = append(out, cert...)
+	}
+	return out
+}
+
+func TestSplitSpacecert(t *testing.T) {
+	certs := [][]byte{{1, 2, 3}, {}, {4}}
+	subject, got, err := splitSpacecert(encodeSpacecert("alice@bitcoin", certs))
+	if err != nil {
+		t.Fatalf("splitSpacecert: %v", err)
+	}
+	if subject != "alice@bitcoin" || len(got) != len(certs) {
+		t.Fatalf("splitSpacecert = %q, %x", subject, got)
+	}
+	for i := range certs {
+		if !bytes.Equal(got[i], certs[i]) {
+			t.Errorf("certificate %d = %x, want %x", i, got[i], certs[i])
+		}
+	}
+}
+
+func TestSplitSpacecertErrors(t *testing.T) {
+	good := encodeSpacecert("alice@bitcoin", [][]byte{{1, 2, 3}})
+	tests := map[string][]byte{
+		"empty":           nil,
+		"subject length":  good[:3],
+		"subject":         good[:10],
+		"count":           good[:4+13+2],
+		"certificate":     good[:len(good)-1],
+		"trailing":        append(bytes.Clone(good), 0),
+		"count past data": binary.LittleEndian.AppendUint32(encodeSpacecert("@bitcoin", nil)[:12], 1),
+		"oversized count": binary.LittleEndian.AppendUint32(encodeSpacecert("@bitcoin", nil)[:12], 1<<31),
+	}
+	for name, data := range tests {
+		if _, _, err := splitSpacecert(data); err == nil {
+			t.Errorf("%s: splitSpacecert accepted %x", name, data)
+		}
+	}
+}
+
+// TestNativeSpacecertLayout holds splitSpacecert to the encoding of
+// CreateCertificateChain.
+func TestNativeSpacecertLayout(t *testing.T) {
+	if !NativeAvailable() {
+		t.Skip("native library not available")
+	}
+	certs := [][]byte{{1, 2, 3}, {4, 5}}
+	data, err := CreateCertificateChain("alice@bitcoin", certs)
+	if err != nil {
+		t.Skipf("CreateCertificateChain: %v", err)
+	}
+	if want := encodeSpacecert("alice@bitcoin", certs); !bytes.Equal(data, want) {
+		t.Fatalf("CreateCertificateChain = %x, splitSpacecert expects %x", data, want)
+	}
+}
+
+// certificateFixture returns a real certificate, which this repository has
+// no fixture for. It is read from the file named by
+// LIBVERITAS_TEST_CERTIFICATE, and the calling test skips without it or
+// without the native library.
+func certificateFixture(t *testing.T) []byte {
+	t.Helper()
+	path := os.Getenv("LIBVERITAS_TEST_CERTIFICATE")
+	if path == "" {
+		t.Skip("LIBVERITAS_TEST_CERTIFICATE not set")
+	}
+	if !NativeAvailable() {
+		t.Skip("native library not available")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestNativeCertificateFixture(t *testing.T) {
+	data := certificateFixture(t)
+	cert, err := ParseCertificate(data)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	if cert.Subject == "" || cert.Kind == CertificateKindUnknown || !bytes.Equal(cert.Raw, data) {
+		t.Errorf("ParseCertificate = %+v", cert)
+	}
+	chain, err := NewCertificateChain(cert.Subject, [][]byte{data})
+	if err != nil {
+		t.Fatalf("NewCertificateChain: %v", err)
+	}
+	parsed, err := ParseCertificateChain(chain.Raw)
+	if err != nil {
+		t.Fatalf("ParseCertificateChain: %v", err)
+	}
+	if parsed.Subject != cert.Subject || !reflect.DeepEqual(parsed.CertificateBytes(), [][]byte{data}) {
+		t.Errorf("ParseCertificateChain = %+v", parsed)
+	}
+}
@@ -0,0 +1,235 @@
+package libveritas
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+//go:generate go run gen_confusables.go
+
+// Homograph checks for handles shown to users. Handle strings from the
+// network are not trusted to be well-formed, so the checks work on any
+// string and not only on values accepted by ParseHandle.
+
+// ConfusableReason tells why a handle was flagged.
+type ConfusableReason int
+
+const (
+	// The handle mixes letters of more than one script, such as Latin
+	// and Cyrillic.
+	ConfusableMixedScript ConfusableReason = iota + 1
+	// The handle renders like a known handle: both reduce to the same
+	// skeleton.
+	ConfusableSkeleton
+	// The handle is within a small edit distance of a known handle.
+	ConfusableSimilar
+)
+
+func (r ConfusableReason) String() string {
+	switch r {
+	case ConfusableMixedScript:
+		return "mixed-script"
+	case ConfusableSkeleton:
+		return "skeleton"
+	case ConfusableSimilar:
+		return "similar"
+	default:
+		return "unknown"
+	}
+}
+
+// ConfusableWarning is a single finding of a check. Contact is the known
+// handle the checked one resembles, empty for ConfusableMixedScript.
+// Distance is the edit distance between the skeletons.
+type ConfusableWarning struct {
+	Reason   ConfusableReason
+	Contact  string
+	Distance int
+	// Scripts lists the scripts found, for ConfusableMixedScript.
+	Scripts []string
+}
+
+// DefaultConfusableDistance is the edit distance up to which handles are
+// reported as similar.
+const DefaultConfusableDistance = 1
+
+// ConfusableChecker compares handles against a fixed list of known handles,
+// typically the user's contacts.
+type ConfusableChecker struct {
+	// MaxDistance is the largest edit distance reported as
+	// ConfusableSimilar. Zero disables the edit distance check.
+	MaxDistance int
+
+	contacts []confusableContact
+}
+
+type confusableContact struct {
+	handle   string
+	skeleton string
+}
+
+// NewConfusableChecker prepares a checker for contacts, with MaxDistance set
+// to DefaultConfusableDistance.
+func NewConfusableChecker(contacts []string) *ConfusableChecker {
+	c := &ConfusableChecker{MaxDistance: DefaultConfusableDistance}
+	for _, contact := range contacts {
+		c.contacts = append(c.contacts, confusableContact{
+			handle:   asciiLower(contact),
+			skeleton: Skeleton(contact),
+		})
+	}
+	return c
+}
+
+// CheckConfusable is NewConfusableChecker(contacts).Check(handle).
+func CheckConfusable(handle string, contacts []string) []ConfusableWarning {
+	return NewConfusableChecker(contacts).Check(handle)
+}
+
+// Check returns the warnings for handle, or nil if it looks safe. A handle
+// equal to a contact is that contact and is not reported against it.
+func (c *ConfusableChecker) Check(handle string) []ConfusableWarning {
+	var warnings []ConfusableWarning
+	if scripts := handleScripts(handle); len(scripts) > 1 {
+		warnings = append(warnings, ConfusableWarning{Reason: ConfusableMixedScript, Scripts: scripts})
+	}
+	name := asciiLower(handle)
+	skeleton := Skeleton(handle)
+	for _, contact := range c.contacts {
+		if name == contact.handle {
+			continue
+		}
+		if skeleton == contact.skeleton {
+			warnings = append(warnings, ConfusableWarning{Reason: ConfusableSkeleton, Contact: contact.handle})
+			continue
+		}
+		if c.MaxDistance <= 0 {
+			continue
+		}
+		if d := editDistance(skeleton, contact.skeleton, c.MaxDistance); d <= c.MaxDistance {
+			warnings = append(warnings, ConfusableWarning{Reason: ConfusableSimilar, Contact: contact.handle, Distance: d})
+		}
+	}
+	return warnings
+}
+
+// CheckConfusable checks the handle of the zone against contacts.
+func (r Zone) CheckConfusable(contacts []string) []ConfusableWarning {
+	return CheckConfusable(r.Handle, contacts)
+}
+
+// Skeleton reduces s to a form in which visually confusable strings are
+// equal, after the skeleton of Unicode Technical Standard #39: s is
+// decomposed with NFKD, which also maps full-width and other compatibility
+// forms, lower-cased, and each character replaced by its prototype in
+// confusableTable; the result is decomposed with NFD again. Unlike the
+// standard skeleton, combining marks are dropped and the result is
+// lower-cased, since handles are compared without regard to either.
+func Skeleton(s string) string {
+	var mapped strings.Builder
+	for _, r := range norm.NFKD.String(s) {
+		r = unicode.ToLower(r)
+		if prototype, ok := confusableTable[r]; ok {
+			mapped.WriteString(prototype)
+		} else {
+			mapped.WriteRune(r)
+		}
+	}
+	var b strings.Builder
+	for _, r := range norm.NFD.String(mapped.String()) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// asciiLower folds only A-Z, so that non-ASCII look-alikes such as the
+// Kelvin sign are not mapped to letters.
+func asciiLower(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}, s)
+}
+
+// handleScripts returns the scripts of the letters in s, ignoring digits,
+// punctuation and marks, in order of first appearance.
+func handleScripts(s string) []string {
+	var scripts []string
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		name := runeScript(r)
+		found := false
+		for _, script := range scripts {
+			if script == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			scripts = append(scripts, name)
+		}
+	}
+	return scripts
+}
+
+var confusableScripts = []string{"Latin", "Cyrillic", "Greek", "Armenian", "Cherokee", "Georgian", "Han", "Arabic", "Hebrew"}
+
+func runeScript(r rune) string {
+	if r < 0x80 {
+		return "Latin"
+	}
+	for _, name := range confusableScripts {
+		if unicode.Is(unicode.Scripts[name], r) {
+			return name
+		}
+	}
+	for name, table := range unicode.Scripts {
+		if name != "Common" && name != "Inherited" && unicode.Is(table, r) {
+			return name
+		}
+	}
+	return "Unknown"
+}
+
+// editDistance returns the Damerau-Levenshtein distance (optimal string
+// alignment) between a and b, or limit+1 once it is known to exceed limit.
+func editDistance(a, b string, limit int) int {
+	s, t := []rune(a), []rune(b)
+	if d := len(s) - len(t); d > limit || -d > limit {
+		return limit + 1
+	}
+	prev2 := make([]int, len(t)+1)
+	prev := make([]int, len(t)+1)
+	cur := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return min(prev[len(t)], limit+1)
+}
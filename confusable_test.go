@@ -0,0 +1,113 @@
+package libveritas
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSkeleton(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"alice@bitcoin", "ALICE@bitcoin"},
+		{"alice@bitcoin", "аlice@bitcoin"},    // Cyrillic а
+		{"alice@bitcoin", "ａｌｉｃｅ@bitcoin"},    // full-width
+		{"alice@bitcoin", "alicé@bitcoin"},    // combining mark
+		{"alice@bitcoin", "a1ice@bitcoin"},    // digit one
+		{"modern@bitcoin", "rnodern@bitcoin"}, // rn for m
+		{"bob@bitcoin", "b0b@bitcoin"},
+		{"vault@x", "νault@x"}, // Greek nu
+	}
+	for _, tt := range tests {
+		if a, b := Skeleton(tt.a), Skeleton(tt.b); a != b {
+			t.Errorf("Skeleton(%q) = %q, Skeleton(%q) = %q, want equal", tt.a, a, tt.b, b)
+		}
+	}
+	if Skeleton("alice@bitcoin") == Skeleton("alina@bitcoin") {
+		t.Error("distinct handles have the same skeleton")
+	}
+}
+
+func TestCheckConfusable(t *testing.T) {
+	contacts := []string{"alice@bitcoin", "bob@bitcoin"}
+	tests := []struct {
+		handle  string
+		reasons []ConfusableReason
+		contact string
+	}{
+		{handle: "alice@bitcoin"},
+		{handle: "carol@bitcoin"},
+		{handle: "аlice@bitcoin", reasons: []ConfusableReason{ConfusableMixedScript, ConfusableSkeleton}, contact: "alice@bitcoin"},
+		{handle: "b0b@bitcoin", reasons: []ConfusableReason{ConfusableSkeleton}, contact: "bob@bitcoin"},
+		{handle: "alicee@bitcoin", reasons: []ConfusableReason{ConfusableSimilar}, contact: "alice@bitcoin"},
+		{handle: "ailce@bitcoin", reasons: []ConfusableReason{ConfusableSimilar}, contact: "alice@bitcoin"},
+	}
+	for _, tt := range tests {
+		warnings := CheckConfusable(tt.handle, contacts)
+		var reasons []ConfusableReason
+		for _, warning := range warnings {
+			reasons = append(reasons, warning.Reason)
+			if warning.Reason != ConfusableMixedScript && warning.Contact != tt.contact {
+				t.Errorf("%q: %s warning for %q, want %q", tt.handle, warning.Reason, warning.Contact, tt.contact)
+			}
+		}
+		if !slices.Equal(reasons, tt.reasons) {
+			t.Errorf("CheckConfusable(%q) reasons = %v, want %v", tt.handle, reasons, tt.reasons)
+		}
+	}
+}
+
+func TestCheckConfusableMixedScript(t *testing.T) {
+	warnings := CheckConfusable("pаypal@bitcoin", nil)
+	if len(warnings) != 1 || !slices.Equal(warnings[0].Scripts, []string{"Latin", "Cyrillic"}) {
+		t.Errorf("CheckConfusable = %+v", warnings)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"abc", "abc", 2, 0},
+		{"abc", "abd", 2, 1},
+		{"abc", "acb", 2, 1},
+		{"abc", "", 2, 3},
+		{"kitten", "sitting", 5, 3},
+		{"kitten", "sitting", 1, 2},
+		{"ab", "ba", 0, 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b, tt.limit); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
+
+// TestConfusableTableGenerated checks that confusables_table.go is the
+// output of gen_confusables.go for testdata/confusables.txt, the
+// confusables.txt data of the version named in the table's header.
+func TestConfusableTableGenerated(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the generator")
+	}
+	out := filepath.Join(t.TempDir(), "confusables_table.go")
+	cmd := exec.Command("go", "run", "gen_confusables.go", "-in", filepath.Join("testdata", "confusables.txt"), "-out", out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("gen_confusables.go: %v\n%s", err, output)
+	}
+	generated, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	committed, err := os.ReadFile("confusables_table.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(generated, committed) {
+		t.Error("confusables_table.go differs from the generator output; run go generate")
+	}
+}
@@ -0,0 +1,6328 @@
+// Code generated by gen_confusables.go; DO NOT EDIT.
+
+package libveritas
+
+// confusableTable maps each code point to the prototype it is confusable
+// with. Source:
+//
+//	confusables.txt
+//	Date: 2020-02-13, 01:38:49 GMT
+//	© 2020 Unicode®, Inc.
+//	Unicode and the Unicode Logo are registered trademarks of Unicode, Inc. in the U.S. and other countries.
+//	For terms of use, see http://www.unicode.org/terms_of_use.html
+//	Unicode Security Mechanisms for UTS #39
+//	Version: 13.0.0
+//	For documentation and usage, see http://www.unicode.org/reports/tr39
+var confusableTable = map[rune]string{
+	0x0022:  "''",
+	0x0025:  "\u00ba/\u2080",
+	0x0030:  "O",
+	0x0031:  "l",
+	0x0049:  "l",
+	0x0060:  "'",
+	0x006d:  "rn",
+	0x007c:  "l",
+	0x00a0:  " ",
+	0x00a2:  "c\u0338",
+	0x00a5:  "Y\u0335",
+	0x00af:  "\u02c9",
+	0x00b4:  "'",
+	0x00b5:  "\u03bc",
+	0x00b8:  ",",
+	0x00c6:  "AE",
+	0x00c7:  "C\u0326",
+	0x00d0:  "D\u0335",
+	0x00d7:  "x",
+	0x00d8:  "O\u0338",
+	0x00e6:  "ae",
+	0x00e7:  "c\u0326",
+	0x00f0:  "\u2202\u0335",
+	0x00f6:  "\u0629",
+	0x00f8:  "o\u0338",
+	0x0110:  "D\u0335",
+	0x0111:  "d\u0335",
+	0x011a:  "\u0114",
+	0x011b:  "\u0115",
+	0x0126:  "H\u0335",
+	0x0127:  "h\u0335",
+	0x0131:  "i",
+	0x0132:  "lJ",
+	0x0133:  "ij",
+	0x013f:  "l\u00b7",
+	0x0140:  "l\u00b7",
+	0x0141:  "L\u0338",
+	0x0142:  "l\u0338",
+	0x0146:  "\u0272",
+	0x0149:  "'n",
+	0x0150:  "\u00d6",
+	0x0152:  "OE",
+	0x0153:  "oe",
+	0x0163:  "\u01ab",
+	0x0166:  "T\u0335",
+	0x0167:  "t\u0335",
+	0x017f:  "f",
+	0x0180:  "b\u0335",
+	0x0181:  "'B",
+	0x0182:  "b\u0304",
+	0x0183:  "b\u0304",
+	0x0184:  "b",
+	0x0187:  "C'",
+	0x0189:  "D\u0335",
+	0x018a:  "'D",
+	0x018c:  "d\u0304",
+	0x018d:  "g",
+	0x0191:  "F\u0326",
+	0x0192:  "f\u0326",
+	0x0193:  "G'",
+	0x0196:  "l",
+	0x0197:  "l\u0335",
+	0x0198:  "K'",
+	0x0199:  "k\u0314",
+	0x019a:  "l\u0335",
+	0x019d:  "N\u0326",
+	0x019e:  "n\u0329",
+	0x019f:  "O\u0335",
+	0x01a0:  "O'",
+	0x01a1:  "o'",
+	0x01a4:  "'P",
+	0x01a5:  "p\u0314",
+	0x01a6:  "R",
+	0x01a7:  "2",
+	0x01ac:  "'T",
+	0x01ad:  "t\u0314",
+	0x01ae:  "T\u0328",
+	0x01b3:  "'Y",
+	0x01b4:  "y\u0314",
+	0x01b5:  "Z\u0335",
+	0x01b6:  "z\u0335",
+	0x01b7:  "3",
+	0x01bb:  "2\u0335",
+	0x01bc:  "5",
+	0x01bd:  "s",
+	0x01bf:  "\u00fe",
+	0x01c0:  "l",
+	0x01c1:  "ll",
+	0x01c3:  "!",
+	0x01c4:  "D\u017d",
+	0x01c5:  "D\u017e",
+	0x01c6:  "d\u017e",
+	0x01c7:  "LJ",
+	0x01c8:  "Lj",
+	0x01c9:  "lj",
+	0x01ca:  "NJ",
+	0x01cb:  "Nj",
+	0x01cc:  "nj",
+	0x01cd:  "\u0102",
+	0x01ce:  "\u0103",
+	0x01cf:  "\u012c",
+	0x01d0:  "\u012d",
+	0x01d1:  "\u014e",
+	0x01d2:  "\u014f",
+	0x01d3:  "\u016c",
+	0x01d4:  "\u016d",
+	0x01e4:  "G\u0335",
+	0x01e5:  "g\u0335",
+	0x01e6:  "\u011e",
+	0x01e7:  "\u011f",
+	0x01f1:  "DZ",
+	0x01f2:  "Dz",
+	0x01f3:  "dz",
+	0x01f5:  "\u0123",
+	0x01fe:  "O\u0338\u0301",
+	0x021a:  "\u0162",
+	0x021b:  "\u01ab",
+	0x021c:  "3",
+	0x0222:  "8",
+	0x0223:  "8",
+	0x0224:  "Z\u0326",
+	0x0225:  "z\u0326",
+	0x0226:  "\u00c5",
+	0x0227:  "\u00e5",
+	0x023c:  "c\u0338",
+	0x023e:  "T\u0338",
+	0x0241:  "?",
+	0x0244:  "U\u0335",
+	0x0246:  "E\u0338",
+	0x0247:  "e\u0338",
+	0x0248:  "J\u0335",
+	0x0249:  "j\u0335",
+	0x024d:  "r\u0335",
+	0x024e:  "Y\u0335",
+	0x024f:  "y\u0335",
+	0x0251:  "a",
+	0x0253:  "b\u0314",
+	0x0256:  "d\u0328",
+	0x0257:  "d\u0314",
+	0x0259:  "\u01dd",
+	0x025a:  "\u01dd\u02de",
+	0x025b:  "\ua793",
+	0x0260:  "g\u0314",
+	0x0261:  "g",
+	0x0263:  "y",
+	0x0266:  "h\u0314",
+	0x0268:  "i\u0335",
+	0x0269:  "i",
+	0x026a:  "i",
+	0x026b:  "l\u0334",
+	0x026d:  "l\u0328",
+	0x026e:  "l\u021d",
+	0x026f:  "w",
+	0x0271:  "rn\u0326",
+	0x0273:  "n\u0328",
+	0x0275:  "o\u0335",
+	0x0276:  "o\u1d07",
+	0x027c:  "r\u0329",
+	0x027d:  "r\u0328",
+	0x0282:  "s\u0328",
+	0x028b:  "u",
+	0x028f:  "y",
+	0x0290:  "z\u0328",
+	0x0292:  "\u021d",
+	0x0294:  "?",
+	0x02a0:  "q\u0314",
+	0x02a3:  "dz",
+	0x02a4:  "d\u021d",
+	0x02a5:  "d\u0291",
+	0x02a6:  "ts",
+	0x02a7:  "t\u0283",
+	0x02a8:  "t\u0255",
+	0x02a9:  "f\u014b",
+	0x02aa:  "ls",
+	0x02ab:  "lz",
+	0x02b3:  "\u18f4",
+	0x02b9:  "'",
+	0x02ba:  "''",
+	0x02bb:  "'",
+	0x02bc:  "'",
+	0x02bd:  "'",
+	0x02be:  "'",
+	0x02bf:  "\u0559",
+	0x02c2:  "<",
+	0x02c3:  ">",
+	0x02c4:  "^",
+	0x02c6:  "^",
+	0x02c8:  "'",
+	0x02ca:  "'",
+	0x02cb:  "'",
+	0x02d0:  ":",
+	0x02d3:  "\u0559",
+	0x02d7:  "-",
+	0x02d8:  "\u02c7",
+	0x02d9:  "\u0971",
+	0x02da:  "\u00b0",
+	0x02db:  "i",
+	0x02dc:  "~",
+	0x02dd:  "''",
+	0x02e1:  "\u18f3",
+	0x02e2:  "\u18f5",
+	0x02e4:  "\u02c1",
+	0x02ee:  "''",
+	0x02f4:  "'",
+	0x02f6:  "''",
+	0x02f8:  ":",
+	0x02fb:  "\u02ea",
+	0x0305:  "\u0304",
+	0x030c:  "\u0306",
+	0x030d:  "\u0670",
+	0x0310:  "\u0306\u0307",
+	0x0311:  "\u0302",
+	0x0315:  "\u0313",
+	0x0317:  "\u0650",
+	0x0320:  "\u0331",
+	0x0321:  "\u0326",
+	0x0322:  "\u0328",
+	0x0327:  "\u0326",
+	0x0336:  "\u0335",
+	0x0337:  "\u0338",
+	0x0339:  "\u0326",
+	0x0340:  "\u0300",
+	0x0341:  "\u0301",
+	0x0342:  "\u0303",
+	0x0343:  "\u0313",
+	0x0345:  "\u0328",
+	0x0347:  "\u0333",
+	0x0357:  "\u0350",
+	0x0358:  "\u0307",
+	0x0366:  "\u030a",
+	0x036e:  "\u0306",
+	0x0370:  "\u2c75",
+	0x0374:  "'",
+	0x0375:  "\u02cf",
+	0x0376:  "\u0418",
+	0x0377:  "\u1d0e",
+	0x037a:  "i",
+	0x037b:  "\u0254",
+	0x037d:  "\ua73f",
+	0x037e:  ";",
+	0x037f:  "J",
+	0x0384:  "'",
+	0x0387:  "\u00b7",
+	0x0391:  "A",
+	0x0392:  "B",
+	0x0395:  "E",
+	0x0396:  "Z",
+	0x0397:  "H",
+	0x0398:  "O\u0335",
+	0x0399:  "l",
+	0x039a:  "K",
+	0x039b:  "\u0245",
+	0x039c:  "M",
+	0x039d:  "N",
+	0x039f:  "O",
+	0x03a1:  "P",
+	0x03a3:  "\u01a9",
+	0x03a4:  "T",
+	0x03a5:  "Y",
+	0x03a7:  "X",
+	0x03b1:  "a",
+	0x03b2:  "\u00df",
+	0x03b3:  "y",
+	0x03b4:  "\u1e9f",
+	0x03b5:  "\ua793",
+	0x03b7:  "n\u0329",
+	0x03b8:  "O\u0335",
+	0x03b9:  "i",
+	0x03ba:  "\u0138",
+	0x03bd:  "v",
+	0x03bf:  "o",
+	0x03c1:  "p",
+	0x03c3:  "o",
+	0x03c4:  "\u1d1b",
+	0x03c5:  "u",
+	0x03c6:  "\u0278",
+	0x03d0:  "\u00df",
+	0x03d1:  "O\u0335",
+	0x03d2:  "Y",
+	0x03d5:  "\u0278",
+	0x03d6:  "\u03c0",
+	0x03db:  "\u03c2",
+	0x03dc:  "F",
+	0x03e8:  "2",
+	0x03e9:  "\u01a8",
+	0x03f0:  "\u0138",
+	0x03f1:  "p",
+	0x03f2:  "c",
+	0x03f3:  "j",
+	0x03f4:  "O\u0335",
+	0x03f5:  "\ua793",
+	0x03f7:  "\u00de",
+	0x03f8:  "\u00fe",
+	0x03f9:  "C",
+	0x03fa:  "M",
+	0x03fd:  "\u0186",
+	0x03ff:  "\ua73e",
+	0x0404:  "\ua792",
+	0x0405:  "S",
+	0x0406:  "l",
+	0x0408:  "J",
+	0x0410:  "A",
+	0x0411:  "b\u0304",
+	0x0412:  "B",
+	0x0413:  "\u0393",
+	0x0415:  "E",
+	0x0417:  "3",
+	0x0419:  "\u040d",
+	0x041a:  "K",
+	0x041b:  "\u0245",
+	0x041c:  "M",
+	0x041d:  "H",
+	0x041e:  "O",
+	0x041f:  "\u03a0",
+	0x0420:  "P",
+	0x0421:  "C",
+	0x0422:  "T",
+	0x0423:  "Y",
+	0x0424:  "\u03a6",
+	0x0425:  "X",
+	0x042b:  "bl",
+	0x042c:  "b",
+	0x042e:  "lO",
+	0x0430:  "a",
+	0x0431:  "6",
+	0x0432:  "\u0299",
+	0x0433:  "r",
+	0x0435:  "e",
+	0x0437:  "\u025c",
+	0x0438:  "\u1d0e",
+	0x043a:  "\u0138",
+	0x043c:  "\u028d",
+	0x043d:  "\u029c",
+	0x043e:  "o",
+	0x043f:  "\u03c0",
+	0x0440:  "p",
+	0x0441:  "c",
+	0x0442:  "\u1d1b",
+	0x0443:  "y",
+	0x0444:  "\u0278",
+	0x0445:  "x",
+	0x044a:  "\u02c9b",
+	0x044b:  "\u0185i",
+	0x044c:  "\u0185",
+	0x044f:  "\u1d19",
+	0x0454:  "\ua793",
+	0x0455:  "s",
+	0x0456:  "i",
+	0x0458:  "j",
+	0x045b:  "h\u0335",
+	0x045d:  "\u0439",
+	0x0461:  "w",
+	0x0462:  "b\u0335",
+	0x0463:  "b\u0335",
+	0x0470:  "\u03a8",
+	0x0471:  "\u03c8",
+	0x0472:  "O\u0335",
+	0x0473:  "o\u0335",
+	0x0474:  "V",
+	0x0475:  "v",
+	0x047c:  "\u0460\u0486\u0487",
+	0x047d:  "w\u0486\u0487",
+	0x048a:  "\u040d\u0326",
+	0x048b:  "\u0439\u0326",
+	0x048c:  "b\u0335",
+	0x048d:  "b\u0335",
+	0x0490:  "\u0393'",
+	0x0491:  "r'",
+	0x0492:  "\u0393\u0335",
+	0x0493:  "r\u0335",
+	0x0496:  "\u0416\u0329",
+	0x0497:  "\u0436\u0329",
+	0x0498:  "3\u0326",
+	0x0499:  "\u025c\u0326",
+	0x049a:  "K\u0329",
+	0x049b:  "\u0138\u0329",
+	0x049e:  "K\u0335",
+	0x049f:  "\u0138\u0335",
+	0x04a2:  "H\u0329",
+	0x04a3:  "\u029c\u0329",
+	0x04aa:  "C\u0326",
+	0x04ab:  "c\u0326",
+	0x04ac:  "T\u0329",
+	0x04ad:  "\u1d1b\u0329",
+	0x04ae:  "Y",
+	0x04af:  "y",
+	0x04b0:  "Y\u0335",
+	0x04b1:  "y\u0335",
+	0x04b2:  "X\u0329",
+	0x04bb:  "h",
+	0x04bd:  "e",
+	0x04be:  "\u04bc\u0328",
+	0x04bf:  "e\u0328",
+	0x04c0:  "l",
+	0x04c5:  "\u0245\u0326",
+	0x04c6:  "\u043b\u0326",
+	0x04c7:  "H\u0326",
+	0x04c8:  "\u029c\u0326",
+	0x04c9:  "H\u0326",
+	0x04ca:  "\u029c\u0326",
+	0x04cb:  "\u04b6",
+	0x04cc:  "\u04b7",
+	0x04cd:  "M\u0326",
+	0x04ce:  "\u028d\u0326",
+	0x04cf:  "i",
+	0x04d4:  "AE",
+	0x04d5:  "ae",
+	0x04d8:  "\u018f",
+	0x04d9:  "\u01dd",
+	0x04e0:  "3",
+	0x04e1:  "\u021d",
+	0x04e8:  "O\u0335",
+	0x04e9:  "o\u0335",
+	0x0501:  "d",
+	0x050a:  "\u01f6",
+	0x050c:  "G",
+	0x050d:  "\u0262",
+	0x0510:  "\u0190",
+	0x0511:  "\ua793",
+	0x051b:  "q",
+	0x051c:  "W",
+	0x051d:  "w",
+	0x053b:  "\u12ae",
+	0x0544:  "\u1206",
+	0x054a:  "\u1323",
+	0x054c:  "\u1261",
+	0x054d:  "U",
+	0x054f:  "S",
+	0x0553:  "\u03a6",
+	0x0555:  "O",
+	0x055a:  "'",
+	0x055d:  "'",
+	0x0561:  "w",
+	0x0563:  "q",
+	0x0566:  "q",
+	0x056e:  "\u1e9f",
+	0x0570:  "h",
+	0x0575:  "\u0237",
+	0x0578:  "n",
+	0x057a:  "\u0270",
+	0x057c:  "n",
+	0x057d:  "u",
+	0x0581:  "g",
+	0x0584:  "f",
+	0x0585:  "o",
+	0x0587:  "\u0565\u0582",
+	0x0589:  ":",
+	0x059c:  "\u0301",
+	0x059d:  "\u0301",
+	0x05a4:  "\u059a",
+	0x05a8:  "\u0599",
+	0x05ad:  "\u0596",
+	0x05ae:  "\u0598",
+	0x05af:  "\u030a",
+	0x05b4:  "\u0323",
+	0x05b9:  "\u0307",
+	0x05ba:  "\u0307",
+	0x05c0:  "l",
+	0x05c1:  "\u0307",
+	0x05c2:  "\u0307",
+	0x05c3:  ":",
+	0x05c4:  "\u0307",
+	0x05c5:  "\u0323",
+	0x05d5:  "l",
+	0x05d8:  "v",
+	0x05d9:  "'",
+	0x05df:  "l",
+	0x05e1:  "o",
+	0x05f0:  "ll",
+	0x05f1:  "l'",
+	0x05f2:  "''",
+	0x05f3:  "'",
+	0x05f4:  "''",
+	0x0609:  "\u00ba/\u2080\u2080",
+	0x060a:  "\u00ba/\u2080\u2080\u2080",
+	0x060d:  ",",
+	0x060f:  "\u0639",
+	0x0618:  "\u0301",
+	0x0619:  "\u0313",
+	0x061a:  "\u0650",
+	0x0623:  "l\u0674",
+	0x0624:  "\u0648\u0674",
+	0x0625:  "l\u0655",
+	0x0626:  "\u0649\u0674",
+	0x0627:  "l",
+	0x062b:  "\u0649\u06db",
+	0x0634:  "\u0633\u06db",
+	0x063d:  "\u0649\u0302",
+	0x063f:  "\u0649\u06db",
+	0x0647:  "o",
+	0x064a:  "\u0649",
+	0x064b:  "\u030b",
+	0x064e:  "\u0301",
+	0x064f:  "\u0313",
+	0x0652:  "\u030a",
+	0x0653:  "\u0303",
+	0x0656:  "\u0329",
+	0x0657:  "\u0312",
+	0x0658:  "\u0306",
+	0x0659:  "\u0304",
+	0x065a:  "\u0306",
+	0x065b:  "\u0302",
+	0x065c:  "\u0323",
+	0x065d:  "\u0314",
+	0x065f:  "\u0655",
+	0x0660:  ".",
+	0x0661:  "l",
+	0x0665:  "o",
+	0x0667:  "V",
+	0x0668:  "\u0245",
+	0x066a:  "\u00ba/\u2080",
+	0x066b:  ",",
+	0x066c:  "\u060c",
+	0x066d:  "*",
+	0x066e:  "\u0649",
+	0x066f:  "\u06a1",
+	0x0672:  "l\u0674",
+	0x0673:  "l\u0655",
+	0x0675:  "l\u0674",
+	0x0676:  "\u0648\u0674",
+	0x0677:  "\u0648\u0313\u0674",
+	0x0678:  "\u0649\u0674",
+	0x0679:  "\u0649\u0615",
+	0x067e:  "\u0649\u06db",
+	0x0681:  "\u062d\u0654",
+	0x0685:  "\u062d\u06db",
+	0x0688:  "\u062f\u0615",
+	0x068b:  "\u068a\u0615",
+	0x068e:  "\u062f\u06db",
+	0x0691:  "\u0631\u0615",
+	0x0692:  "\u0631\u0306",
+	0x0698:  "\u0631\u06db",
+	0x069e:  "\u0635\u06db",
+	0x069f:  "\u0637\u06db",
+	0x06a4:  "\u06a1\u06db",
+	0x06a7:  "\u0641",
+	0x06a8:  "\u06a1\u06db",
+	0x06a9:  "\u0643",
+	0x06aa:  "\u0643",
+	0x06ad:  "\u0643\u06db",
+	0x06b4:  "\u06af\u06db",
+	0x06b5:  "\u0644\u0306",
+	0x06b7:  "\u0644\u06db",
+	0x06ba:  "\u0649",
+	0x06bb:  "\u0649\u0615",
+	0x06bd:  "\u0649\u06db",
+	0x06be:  "o",
+	0x06c1:  "o",
+	0x06c2:  "\u06c0",
+	0x06c3:  "\u0629",
+	0x06c6:  "\u0648\u0306",
+	0x06c7:  "\u0648\u0313",
+	0x06c8:  "\u0648\u0670",
+	0x06c9:  "\u0648\u0302",
+	0x06cb:  "\u0648\u06db",
+	0x06cc:  "\u0649",
+	0x06ce:  "\u0649\u0306",
+	0x06d0:  "\u067b",
+	0x06d1:  "\u0649\u06db",
+	0x06d2:  "\u0649",
+	0x06d4:  "-",
+	0x06d5:  "o",
+	0x06df:  "\u030a",
+	0x06e8:  "\u0306\u0307",
+	0x06ec:  "\u0307",
+	0x06ee:  "\u062f\u0302",
+	0x06ef:  "\u0631\u0302",
+	0x06f0:  ".",
+	0x06f1:  "l",
+	0x06f2:  "\u0662",
+	0x06f3:  "\u0663",
+	0x06f4:  "\u0664",
+	0x06f5:  "o",
+	0x06f6:  "\u0666",
+	0x06f7:  "V",
+	0x06f8:  "\u0245",
+	0x06f9:  "\u0669",
+	0x06fd:  "\u0621\u0348",
+	0x06fe:  "\u0645\u0348",
+	0x06ff:  "o\u0302",
+	0x0701:  ".",
+	0x0702:  ".",
+	0x0703:  ":",
+	0x0704:  ":",
+	0x0740:  "\u0307",
+	0x0741:  "\u0307",
+	0x0742:  "\u073c",
+	0x0747:  "\u0301",
+	0x0751:  "\u0628\u06db",
+	0x0756:  "\u0649\u0306",
+	0x0762:  "\u06ac",
+	0x0763:  "\u0643\u06db",
+	0x0767:  "\u0754",
+	0x0768:  "\u0646\u0615",
+	0x0769:  "\u0646\u0306",
+	0x076c:  "\u0631\u0654",
+	0x0771:  "\u0697\u0615",
+	0x0772:  "\u062d\u0654",
+	0x077e:  "\u0633\u0302",
+	0x07c0:  "O",
+	0x07ca:  "l",
+	0x07eb:  "\u0304",
+	0x07ed:  "\u0307",
+	0x07ee:  "\u0302",
+	0x07f3:  "\u0308",
+	0x07f4:  "'",
+	0x07f5:  "'",
+	0x07fa:  "_",
+	0x08a1:  "\u0628\u0654",
+	0x08a4:  "\u06a2\u06db",
+	0x08a7:  "\u0645\u06db",
+	0x08a8:  "\u0649\u0654",
+	0x08a9:  "\u0754",
+	0x08ae:  "\u062f\u0324\u0323",
+	0x08af:  "\u0635\u0324\u0323",
+	0x08b0:  "\u06af",
+	0x08b1:  "\u0648",
+	0x08b2:  "\u0632\u0302",
+	0x08b6:  "\u0628\u06e2",
+	0x08b7:  "\u0649\u06db\u06e2",
+	0x08b9:  "\u0631\u0306\u0307",
+	0x08ba:  "\u0649\u0306\u0307",
+	0x08bb:  "\u06a1",
+	0x08bc:  "\u06a1",
+	0x08bd:  "\u0649",
+	0x08e5:  "\u064c",
+	0x08e8:  "\u064c",
+	0x08ea:  "\u0307",
+	0x08eb:  "\u0308",
+	0x08ed:  "\u0323",
+	0x08ee:  "\u0324",
+	0x08f0:  "\u030b",
+	0x08f1:  "\u064c",
+	0x08f2:  "\u064d",
+	0x08f3:  "\u0313",
+	0x08f8:  "\u0350",
+	0x08f9:  "\u0354",
+	0x08fa:  "\u0355",
+	0x08ff:  "\u0350",
+	0x0900:  "\u0352",
+	0x0901:  "\u0306\u0307",
+	0x0902:  "\u0307",
+	0x0903:  ":",
+	0x0904:  "\u0905\u0946",
+	0x0906:  "\u0905\u093e",
+	0x0908:  "\u0930\u094d\u0907",
+	0x090d:  "\u090f\u0945",
+	0x090e:  "\u090f\u0946",
+	0x0910:  "\u090f\u0947",
+	0x0911:  "\u0905\u0949",
+	0x0912:  "\u0905\u093e\u0946",
+	0x0913:  "\u0905\u093e\u0947",
+	0x0914:  "\u0905\u093e\u0948",
+	0x093c:  "\u0323",
+	0x0952:  "\u0331",
+	0x0953:  "\u0300",
+	0x0954:  "\u0301",
+	0x0965:  "\u0964\u0964",
+	0x0966:  "o",
+	0x0967:  "\u0669",
+	0x097d:  "?",
+	0x0981:  "\u0306\u0307",
+	0x0986:  "\u0985\u09be",
+	0x09bc:  "\u0323",
+	0x09e0:  "\u098b\u09c3",
+	0x09e1:  "\u098b\u09c3",
+	0x09e6:  "O",
+	0x09ea:  "8",
+	0x09ed:  "9",
+	0x0a02:  "\u0307",
+	0x0a03:  "\u0983",
+	0x0a06:  "\u0a05\u0a3e",
+	0x0a07:  "\u0a72\u0a3f",
+	0x0a08:  "\u0a72\u0a40",
+	0x0a09:  "\u0a73\u0a41",
+	0x0a0a:  "\u0a73\u0a42",
+	0x0a0f:  "\u0a72\u0a47",
+	0x0a10:  "\u0a05\u0a48",
+	0x0a14:  "\u0a05\u0a4c",
+	0x0a3c:  "\u0323",
+	0x0a4b:  "\u0946",
+	0x0a4d:  "\u094d",
+	0x0a66:  "o",
+	0x0a67:  "9",
+	0x0a6a:  "8",
+	0x0a81:  "\u0306\u0307",
+	0x0a82:  "\u0307",
+	0x0a83:  ":",
+	0x0a86:  "\u0a85\u0abe",
+	0x0a8d:  "\u0a85\u0ac5",
+	0x0a8f:  "\u0a85\u0ac7",
+	0x0a90:  "\u0a85\u0ac8",
+	0x0a91:  "\u0a85\u0abe\u0ac5",
+	0x0a93:  "\u0a85\u0abe\u0ac7",
+	0x0a94:  "\u0a85\u0abe\u0ac8",
+	0x0abc:  "\u0323",
+	0x0abd:  "\u093d",
+	0x0ac1:  "\u0941",
+	0x0ac2:  "\u0942",
+	0x0acd:  "\u094d",
+	0x0ae6:  "o",
+	0x0ae8:  "\u0968",
+	0x0ae9:  "\u0969",
+	0x0aea:  "\u096a",
+	0x0aee:  "\u096e",
+	0x0af0:  "\u0970",
+	0x0b01:  "\u0306\u0307",
+	0x0b03:  "8",
+	0x0b06:  "\u0b05\u0b3e",
+	0x0b20:  "O",
+	0x0b3c:  "\u0323",
+	0x0b66:  "O",
+	0x0b68:  "9",
+	0x0b82:  "\u030a",
+	0x0b8a:  "\u0b89\u0bb3",
+	0x0b9c:  "\u0b90",
+	0x0bb0:  "\u0b88",
+	0x0bbe:  "\u0b88",
+	0x0bc8:  "\u0ba9",
+	0x0bca:  "\u0bc6\u0b88",
+	0x0bcb:  "\u0bc7\u0b88",
+	0x0bcc:  "\u0bc6\u0bb3",
+	0x0bcd:  "\u0307",
+	0x0bd7:  "\u0bb3",
+	0x0be6:  "o",
+	0x0be7:  "\u0b95",
+	0x0be8:  "\u0b89",
+	0x0bea:  "\u0b9a",
+	0x0beb:  "\u0b88\u0bc1",
+	0x0bec:  "\u0b9a\u0bc1",
+	0x0bed:  "\u0b8e",
+	0x0bee:  "\u0b85",
+	0x0bf0:  "\u0baf",
+	0x0bf2:  "\u0b9a\u0bc2",
+	0x0bf4:  "\u0bae\u0bc0",
+	0x0bf5:  "\u0bf3",
+	0x0bf7:  "\u0b8e\u0bb5",
+	0x0bf8:  "\u0bb7",
+	0x0bfa:  "\u0ba8\u0bc0",
+	0x0c00:  "\u0306\u0307",
+	0x0c02:  "o",
+	0x0c03:  "\u0983",
+	0x0c13:  "\u0c12\u0c55",
+	0x0c14:  "\u0c12\u0c4c",
+	0x0c20:  "\u0c30\u05bc",
+	0x0c22:  "\u0c21\u0323",
+	0x0c25:  "\u0c27\u05bc",
+	0x0c2d:  "\u0c2c\u0323",
+	0x0c2e:  "\u0c35\u0c41",
+	0x0c37:  "\u0c35\u0323",
+	0x0c39:  "\u0c35\u0c3e",
+	0x0c42:  "\u0c41\u0c3e",
+	0x0c44:  "\u0c43\u0c3e",
+	0x0c60:  "\u0c0b\u0c3e",
+	0x0c61:  "\u0c0c\u0c3e",
+	0x0c66:  "o",
+	0x0c81:  "\u0306\u0307",
+	0x0c82:  "o",
+	0x0c83:  "\u0983",
+	0x0c85:  "\u0c05",
+	0x0c86:  "\u0c06",
+	0x0c87:  "\u0c07",
+	0x0c92:  "\u0c12",
+	0x0c93:  "\u0c12\u0c55",
+	0x0c94:  "\u0c12\u0c4c",
+	0x0c9c:  "\u0c1c",
+	0x0c9e:  "\u0c1e",
+	0x0ca3:  "\u0c23",
+	0x0caf:  "\u0c2f",
+	0x0cb1:  "\u0c31",
+	0x0cb2:  "\u0c32",
+	0x0ce1:  "\u0c8c\u0cbe",
+	0x0ce6:  "o",
+	0x0ce7:  "\u0c67",
+	0x0ce8:  "\u0c68",
+	0x0cef:  "\u0c6f",
+	0x0d01:  "\u0306\u0307",
+	0x0d02:  "o",
+	0x0d03:  "\u0983",
+	0x0d08:  "\u0d07\u0d57",
+	0x0d09:  "\u0b89",
+	0x0d0a:  "\u0b89\u0d57",
+	0x0d0c:  "\u0d28\u0d41",
+	0x0d10:  "\u0d0e\u0d46",
+	0x0d13:  "\u0d12\u0d3e",
+	0x0d14:  "\u0d12\u0d57",
+	0x0d19:  "\u0d28\u0d41",
+	0x0d1c:  "\u0b90",
+	0x0d20:  "o",
+	0x0d23:  "\u0ba3",
+	0x0d31:  "\u0d30",
+	0x0d34:  "\u0bb4",
+	0x0d36:  "\u0bb6",
+	0x0d3a:  "\u0b9f\u0bbf",
+	0x0d3f:  "\u0bbf",
+	0x0d40:  "\u0bbf",
+	0x0d42:  "\u0d41",
+	0x0d43:  "\u0d41",
+	0x0d48:  "\u0d46\u0d46",
+	0x0d4e:  "\u0971",
+	0x0d5a:  "\u0d28\u0d4d\u0d2e",
+	0x0d5f:  "o\u0d30o",
+	0x0d61:  "\u0d1e",
+	0x0d66:  "o",
+	0x0d6a:  "\u0d30\u0d4d",
+	0x0d6b:  "\u0d26\u0d4d\u0d30",
+	0x0d6c:  "\u0d28\u0d4d\u0d28",
+	0x0d6d:  "9",
+	0x0d6e:  "\u0d35\u0d4d\u0d30",
+	0x0d6f:  "\u0d28\u0d4d",
+	0x0d76:  "\u0d39\u0d4d\u0d2e",
+	0x0d79:  "\u0d28\u0d41",
+	0x0d7b:  "\u0d28\u0d4d",
+	0x0d7c:  "\u0d30\u0d4d",
+	0x0d82:  "o",
+	0x0d83:  "\u0983",
+	0x0de9:  "\u0de8\u0dcf",
+	0x0dea:  "\u0da2",
+	0x0deb:  "\u0daf",
+	0x0def:  "\u0de8\u0dd3",
+	0x0e03:  "\u0e02",
+	0x0e0b:  "\u0e0a",
+	0x0e0f:  "\u0e0e",
+	0x0e14:  "\u0e04",
+	0x0e15:  "\u0e04",
+	0x0e17:  "\u0e11",
+	0x0e21:  "\u0e06",
+	0x0e26:  "\u0e20",
+	0x0e33:  "\u030a\u0e32",
+	0x0e41:  "\u0e40\u0e40",
+	0x0e45:  "\u0e32",
+	0x0e4d:  "\u030a",
+	0x0e50:  "o",
+	0x0e88:  "\u0e08",
+	0x0e8d:  "\u0e22",
+	0x0e9a:  "\u0e1a",
+	0x0e9b:  "\u0e1b",
+	0x0e9d:  "\u0e1d",
+	0x0e9e:  "\u0e1e",
+	0x0e9f:  "\u0e1f",
+	0x0eb3:  "\u030a\u0eb2",
+	0x0eb8:  "\u0e38",
+	0x0eb9:  "\u0e39",
+	0x0ec8:  "\u0e48",
+	0x0ec9:  "\u0e49",
+	0x0eca:  "\u0e4a",
+	0x0ecb:  "\u0e4b",
+	0x0ecd:  "\u030a",
+	0x0ed0:  "o",
+	0x0edc:  "\u0eab\u0e99",
+	0x0edd:  "\u0eab\u0ea1",
+	0x0f00:  "\u0f68\u0f7c\u0f7e",
+	0x0f02:  "\u0f60\u0f74\u0f82\u0f7f",
+	0x0f03:  "\u0f60\u0f74\u0f82\u0f14",
+	0x0f0c:  "\u0f0b",
+	0x0f0e:  "\u0f0d\u0f0d",
+	0x0f1b:  "\u0f1a\u0f1a",
+	0x0f1e:  "\u0f1d\u0f1d",
+	0x0f1f:  "\u0f1a\u0f1d",
+	0x0f37:  "\u0325",
+	0x0f6a:  "\u0f62",
+	0x0f77:  "\u0fb2\u0f71\u0f80",
+	0x0f79:  "\u0fb3\u0f71\u0f80",
+	0x0fce:  "\u0f1d\u0f1a",
+	0x0fd5:  "\u5350",
+	0x0fd6:  "\u534d",
+	0x1000:  "\u1002\u102c",
+	0x1010:  "o\u102c",
+	0x101d:  "o",
+	0x101f:  "\u1015\u102c",
+	0x1029:  "\u101e\u103c",
+	0x102a:  "\u101e\u103c\u1031\u102c\u103a",
+	0x1036:  "\u030a",
+	0x1038:  "\u0983",
+	0x1040:  "o",
+	0x104b:  "\u104a\u104a",
+	0x1065:  "\u1041",
+	0x1066:  "\u1015\u103e",
+	0x106f:  "\u1015\u102c\u103e",
+	0x1070:  "\u1003\u103e",
+	0x107e:  "\u107d\u103e",
+	0x1081:  "\u1002\u103e",
+	0x109e:  "\u1083\u030a",
+	0x10a0:  "\ua786",
+	0x10e7:  "y",
+	0x10f3:  "\u021d",
+	0x10ff:  "o",
+	0x1101:  "\u1100\u1100",
+	0x1104:  "\u1103\u1103",
+	0x1108:  "\u1107\u1107",
+	0x110a:  "\u1109\u1109",
+	0x110d:  "\u110c\u110c",
+	0x1113:  "\u1102\u1100",
+	0x1114:  "\u1102\u1102",
+	0x1115:  "\u1102\u1103",
+	0x1116:  "\u1102\u1107",
+	0x1117:  "\u1103\u1100",
+	0x1118:  "\u1105\u1102",
+	0x1119:  "\u1105\u1105",
+	0x111a:  "\u1105\u1112",
+	0x111b:  "\u1105\u110b",
+	0x111c:  "\u1106\u1107",
+	0x111d:  "\u1106\u110b",
+	0x111e:  "\u1107\u1100",
+	0x111f:  "\u1107\u1102",
+	0x1120:  "\u1107\u1103",
+	0x1121:  "\u1107\u1109",
+	0x1122:  "\u1107\u1109\u1100",
+	0x1123:  "\u1107\u1109\u1103",
+	0x1124:  "\u1107\u1109\u1107",
+	0x1125:  "\u1107\u1109\u1109",
+	0x1126:  "\u1107\u1109\u110c",
+	0x1127:  "\u1107\u110c",
+	0x1128:  "\u1107\u110e",
+	0x1129:  "\u1107\u1110",
+	0x112a:  "\u1107\u1111",
+	0x112b:  "\u1107\u110b",
+	0x112c:  "\u1107\u1107\u110b",
+	0x112d:  "\u1109\u1100",
+	0x112e:  "\u1109\u1102",
+	0x112f:  "\u1109\u1103",
+	0x1130:  "\u1109\u1105",
+	0x1131:  "\u1109\u1106",
+	0x1132:  "\u1109\u1107",
+	0x1133:  "\u1109\u1107\u1100",
+	0x1134:  "\u1109\u1109\u1109",
+	0x1135:  "\u1109\u110b",
+	0x1136:  "\u1109\u110c",
+	0x1137:  "\u1109\u110e",
+	0x1138:  "\u1109\u110f",
+	0x1139:  "\u1109\u1110",
+	0x113a:  "\u1109\u1111",
+	0x113b:  "\u1105\u1112",
+	0x113d:  "\u113c\u113c",
+	0x113f:  "\u113e\u113e",
+	0x1141:  "\u110b\u1100",
+	0x1142:  "\u110b\u1103",
+	0x1143:  "\u110b\u1106",
+	0x1144:  "\u110b\u1107",
+	0x1145:  "\u110b\u1109",
+	0x1146:  "\u110b\u1140",
+	0x1147:  "\u110b\u110b",
+	0x1148:  "\u110b\u110c",
+	0x1149:  "\u110b\u110e",
+	0x114a:  "\u110b\u1110",
+	0x114b:  "\u110b\u1111",
+	0x114d:  "\u110c\u110b",
+	0x114f:  "\u114e\u114e",
+	0x1151:  "\u1150\u1150",
+	0x1152:  "\u110e\u110f",
+	0x1153:  "\u110e\u1112",
+	0x1156:  "\u1111\u1107",
+	0x1157:  "\u1111\u110b",
+	0x1158:  "\u1112\u1112",
+	0x115a:  "\u1100\u1103",
+	0x115b:  "\u1102\u1109",
+	0x115c:  "\u1102\u110c",
+	0x115d:  "\u1102\u1112",
+	0x115e:  "\u1103\u1105",
+	0x1162:  "\u1161\u4e28",
+	0x1164:  "\u1163\u4e28",
+	0x1166:  "\u1165\u4e28",
+	0x1168:  "\u1167\u4e28",
+	0x116a:  "\u1169\u1161",
+	0x116b:  "\u1169\u1161\u4e28",
+	0x116c:  "\u1169\u4e28",
+	0x116f:  "\u116e\u1165",
+	0x1170:  "\u116e\u1165\u4e28",
+	0x1171:  "\u116e\u4e28",
+	0x1173:  "\u30fc",
+	0x1174:  "\u30fc\u4e28",
+	0x1175:  "\u4e28",
+	0x1176:  "\u1161\u1169",
+	0x1177:  "\u1161\u116e",
+	0x1178:  "\u1163\u1169",
+	0x1179:  "\u1163\u116d",
+	0x117a:  "\u1165\u1169",
+	0x117b:  "\u1165\u116e",
+	0x117c:  "\u1165\u30fc",
+	0x117d:  "\u1167\u1169",
+	0x117e:  "\u1167\u116e",
+	0x117f:  "\u1169\u1165",
+	0x1180:  "\u1169\u1165\u4e28",
+	0x1181:  "\u1169\u1167\u4e28",
+	0x1182:  "\u1169\u1169",
+	0x1183:  "\u1169\u116e",
+	0x1184:  "\u116d\u1163",
+	0x1185:  "\u116d\u1163\u4e28",
+	0x1186:  "\u116d\u1163",
+	0x1187:  "\u116d\u1169",
+	0x1188:  "\u116d\u4e28",
+	0x1189:  "\u116e\u1161",
+	0x118a:  "\u116e\u1161\u4e28",
+	0x118b:  "\u116e\u1165\u30fc",
+	0x118c:  "\u116e\u1167\u4e28",
+	0x118d:  "\u116e\u116e",
+	0x118e:  "\u1172\u1161",
+	0x118f:  "\u1172\u1165",
+	0x1190:  "\u1172\u1165\u4e28",
+	0x1191:  "\u1172\u1167",
+	0x1192:  "\u1172\u1167\u4e28",
+	0x1193:  "\u1172\u116e",
+	0x1194:  "\u1172\u4e28",
+	0x1195:  "\u30fc\u116e",
+	0x1196:  "\u30fc\u30fc",
+	0x1197:  "\u30fc\u4e28\u116e",
+	0x1198:  "\u4e28\u1161",
+	0x1199:  "\u4e28\u1163",
+	0x119a:  "\u4e28\u1169",
+	0x119b:  "\u4e28\u116e",
+	0x119c:  "\u4e28\u30fc",
+	0x119d:  "\u4e28\u119e",
+	0x119f:  "\u119e\u1165",
+	0x11a0:  "\u119e\u116e",
+	0x11a1:  "\u119e\u4e28",
+	0x11a2:  "\u119e\u119e",
+	0x11a3:  "\u1161\u30fc",
+	0x11a4:  "\u1163\u116e",
+	0x11a5:  "\u1167\u1163",
+	0x11a6:  "\u1169\u1163",
+	0x11a7:  "\u1169\u1163\u4e28",
+	0x11a8:  "\u1100",
+	0x11a9:  "\u1100\u1100",
+	0x11aa:  "\u1100\u1109",
+	0x11ab:  "\u1102",
+	0x11ac:  "\u1102\u110c",
+	0x11ad:  "\u1102\u1112",
+	0x11ae:  "\u1103",
+	0x11af:  "\u1105",
+	0x11b0:  "\u1105\u1100",
+	0x11b1:  "\u1105\u1106",
+	0x11b2:  "\u1105\u1107",
+	0x11b3:  "\u1105\u1109",
+	0x11b4:  "\u1105\u1110",
+	0x11b5:  "\u1105\u1111",
+	0x11b6:  "\u1105\u1112",
+	0x11b7:  "\u1106",
+	0x11b8:  "\u1107",
+	0x11b9:  "\u1107\u1109",
+	0x11ba:  "\u1109",
+	0x11bb:  "\u1109\u1109",
+	0x11bc:  "\u110b",
+	0x11bd:  "\u110c",
+	0x11be:  "\u110e",
+	0x11bf:  "\u110f",
+	0x11c0:  "\u1110",
+	0x11c1:  "\u1111",
+	0x11c2:  "\u1112",
+	0x11c3:  "\u1100\u1105",
+	0x11c4:  "\u1100\u1109\u1100",
+	0x11c5:  "\u1102\u1100",
+	0x11c6:  "\u1102\u1103",
+	0x11c7:  "\u1102\u1109",
+	0x11c8:  "\u1102\u1140",
+	0x11c9:  "\u1102\u1110",
+	0x11ca:  "\u1103\u1100",
+	0x11cb:  "\u1103\u1105",
+	0x11cc:  "\u1105\u1100\u1109",
+	0x11cd:  "\u1105\u1102",
+	0x11ce:  "\u1105\u1103",
+	0x11cf:  "\u1105\u1103\u1112",
+	0x11d0:  "\u1105\u1105",
+	0x11d1:  "\u1105\u1106\u1100",
+	0x11d2:  "\u1105\u1106\u1109",
+	0x11d3:  "\u1105\u1107\u1109",
+	0x11d4:  "\u1105\u1107\u1112",
+	0x11d5:  "\u1105\u1107\u110b",
+	0x11d6:  "\u1105\u1109\u1109",
+	0x11d7:  "\u1105\u1140",
+	0x11d8:  "\u1105\u110f",
+	0x11d9:  "\u1105\u1159",
+	0x11da:  "\u1106\u1100",
+	0x11db:  "\u1106\u1105",
+	0x11dc:  "\u1106\u1107",
+	0x11dd:  "\u1106\u1109",
+	0x11de:  "\u1106\u1109\u1109",
+	0x11df:  "\u1106\u1140",
+	0x11e0:  "\u1106\u110e",
+	0x11e1:  "\u1106\u1112",
+	0x11e2:  "\u1106\u110b",
+	0x11e3:  "\u1107\u1105",
+	0x11e4:  "\u1107\u1111",
+	0x11e5:  "\u1107\u1112",
+	0x11e6:  "\u1107\u110b",
+	0x11e7:  "\u1109\u1100",
+	0x11e8:  "\u1109\u1103",
+	0x11e9:  "\u1109\u1105",
+	0x11ea:  "\u1109\u1107",
+	0x11eb:  "\u1140",
+	0x11ec:  "\u110b\u1100",
+	0x11ed:  "\u110b\u1100\u1100",
+	0x11ee:  "\u110b\u110b",
+	0x11ef:  "\u110b\u110f",
+	0x11f0:  "\u114c",
+	0x11f1:  "\u110b\u1109",
+	0x11f2:  "\u110b\u1140",
+	0x11f3:  "\u1111\u1107",
+	0x11f4:  "\u1111\u110b",
+	0x11f5:  "\u1112\u1102",
+	0x11f6:  "\u1112\u1105",
+	0x11f7:  "\u1112\u1106",
+	0x11f8:  "\u1112\u1107",
+	0x11f9:  "\u1159",
+	0x11fa:  "\u1100\u1102",
+	0x11fb:  "\u1100\u1107",
+	0x11fc:  "\u1100\u110e",
+	0x11fd:  "\u1100\u110f",
+	0x11fe:  "\u1100\u1112",
+	0x11ff:  "\u1102\u1102",
+	0x1200:  "U",
+	0x1223:  "\u0270",
+	0x1240:  "\u03a6",
+	0x1260:  "\u0548",
+	0x1294:  "\u0571",
+	0x12d0:  "O",
+	0x13a0:  "D",
+	0x13a1:  "R",
+	0x13a2:  "T",
+	0x13a4:  "O'",
+	0x13a5:  "i",
+	0x13a8:  "\u2c75",
+	0x13a9:  "Y",
+	0x13aa:  "A",
+	0x13ab:  "J",
+	0x13ac:  "E",
+	0x13ae:  "?",
+	0x13b0:  "\u2c75",
+	0x13b1:  "\u0393",
+	0x13b3:  "W",
+	0x13b7:  "M",
+	0x13bb:  "H",
+	0x13bd:  "Y",
+	0x13be:  "O\u0335",
+	0x13bf:  "\u01ab",
+	0x13c0:  "G",
+	0x13c2:  "h",
+	0x13c3:  "Z",
+	0x13c7:  "\u0460",
+	0x13cb:  "\u0190",
+	0x13cc:  "U\u0335",
+	0x13ce:  "4",
+	0x13cf:  "b",
+	0x13d2:  "R",
+	0x13d4:  "W",
+	0x13d5:  "S",
+	0x13d9:  "V",
+	0x13da:  "S",
+	0x13de:  "L",
+	0x13df:  "C",
+	0x13e2:  "P",
+	0x13e6:  "K",
+	0x13e7:  "d",
+	0x13eb:  "O\u0335",
+	0x13ee:  "6",
+	0x13f0:  "\u00df",
+	0x13f2:  "h\u0314",
+	0x13f3:  "G",
+	0x13f4:  "B",
+	0x13fb:  "\u0262",
+	0x13fc:  "\u0299",
+	0x1400:  "=",
+	0x1403:  "\u0394",
+	0x140c:  "\u00b7\u1401",
+	0x140d:  "\u1401\u00b7",
+	0x140e:  "\u00b7\u0394",
+	0x140f:  "\u0394\u00b7",
+	0x1410:  "\u00b7\u1404",
+	0x1411:  "\u1404\u00b7",
+	0x1412:  "\u00b7\u1405",
+	0x1413:  "\u1405\u00b7",
+	0x1414:  "\u00b7\u1406",
+	0x1415:  "\u1406\u00b7",
+	0x1417:  "\u00b7\u140a",
+	0x1418:  "\u140a\u00b7",
+	0x1419:  "\u00b7\u140b",
+	0x141a:  "\u140b\u00b7",
+	0x1427:  "\u00b7",
+	0x142b:  "\u1401\u1420",
+	0x142c:  "\u0394\u1420",
+	0x142d:  "\u1405\u1420",
+	0x142e:  "\u140a\u1420",
+	0x142f:  "V",
+	0x1431:  "\u0245",
+	0x1433:  ">",
+	0x1437:  "\u00b7>",
+	0x1438:  "<",
+	0x143a:  "\u00b7V",
+	0x143b:  "V\u00b7",
+	0x143c:  "\u00b7\u0245",
+	0x143d:  "\u0245\u00b7",
+	0x143e:  "\u00b7\u1432",
+	0x143f:  "\u1432\u00b7",
+	0x1440:  "\u00b7>",
+	0x1441:  ">\u00b7",
+	0x1442:  "\u00b7\u1434",
+	0x1443:  "\u1434\u00b7",
+	0x1444:  "\u00b7<",
+	0x1445:  "<\u00b7",
+	0x1446:  "\u00b7\u1439",
+	0x1447:  "\u1439\u00b7",
+	0x144a:  "'",
+	0x144c:  "U",
+	0x144e:  "\u0548",
+	0x1454:  "\u00b7\u1450",
+	0x1457:  "\u00b7U",
+	0x1458:  "U\u00b7",
+	0x1459:  "\u00b7\u0548",
+	0x145a:  "\u0548\u00b7",
+	0x145b:  "\u00b7\u144f",
+	0x145c:  "\u144f\u00b7",
+	0x145d:  "\u00b7\u1450",
+	0x145e:  "\u1450\u00b7",
+	0x145f:  "\u00b7\u1451",
+	0x1460:  "\u1451\u00b7",
+	0x1461:  "\u00b7\u1455",
+	0x1462:  "\u1455\u00b7",
+	0x1463:  "\u00b7\u1456",
+	0x1464:  "\u1456\u00b7",
+	0x1467:  "U'",
+	0x1468:  "\u0548'",
+	0x1469:  "\u1450'",
+	0x146a:  "\u1455'",
+	0x146d:  "P",
+	0x146f:  "d",
+	0x1472:  "b",
+	0x1473:  "b\u0307",
+	0x1474:  "\u00b7\u146b",
+	0x1475:  "\u146b\u00b7",
+	0x1476:  "\u00b7P",
+	0x1477:  "p\u00b7",
+	0x1478:  "\u00b7\u146e",
+	0x1479:  "\u146e\u00b7",
+	0x147a:  "\u00b7d",
+	0x147b:  "d\u00b7",
+	0x147c:  "\u00b7\u1470",
+	0x147d:  "\u1470\u00b7",
+	0x147e:  "\u00b7b",
+	0x147f:  "b\u00b7",
+	0x1480:  "\u00b7b\u0307",
+	0x1481:  "b\u0307\u00b7",
+	0x1485:  "\u146b'",
+	0x1486:  "P'",
+	0x1487:  "d'",
+	0x1488:  "b'",
+	0x148d:  "J",
+	0x1492:  "\u00b7\u1489",
+	0x1493:  "\u1489\u00b7",
+	0x1494:  "\u00b7\u148b",
+	0x1495:  "\u148b\u00b7",
+	0x1496:  "\u00b7\u148c",
+	0x1497:  "\u148c\u00b7",
+	0x1498:  "\u00b7J",
+	0x1499:  "J\u00b7",
+	0x149a:  "\u00b7\u148e",
+	0x149b:  "\u148e\u00b7",
+	0x149c:  "\u00b7\u1490",
+	0x149d:  "\u1490\u00b7",
+	0x149e:  "\u00b7\u1491",
+	0x149f:  "\u1491\u00b7",
+	0x14a5:  "\u0393",
+	0x14aa:  "L",
+	0x14ac:  "\u00b7\u14a3",
+	0x14ad:  "\u14a3\u00b7",
+	0x14ae:  "\u00b7\u0393",
+	0x14af:  "\u0393\u00b7",
+	0x14b0:  "\u00b7\u14a6",
+	0x14b1:  "\u14a6\u00b7",
+	0x14b2:  "\u00b7\u14a7",
+	0x14b3:  "\u14a7\u00b7",
+	0x14b4:  "\u00b7\u14a8",
+	0x14b5:  "\u14a8\u00b7",
+	0x14b6:  "\u00b7L",
+	0x14b7:  "l\u00b7",
+	0x14b8:  "\u00b7\u14ab",
+	0x14b9:  "\u14ab\u00b7",
+	0x14bf:  "2",
+	0x14c9:  "\u00b7\u14c0",
+	0x14ca:  "\u14c0\u00b7",
+	0x14cb:  "\u00b7\u14c7",
+	0x14cc:  "\u14c7\u00b7",
+	0x14cd:  "\u00b7\u14c8",
+	0x14ce:  "\u14c8\u00b7",
+	0x14d1:  "\u1421",
+	0x14dc:  "\u00b7\u14d3",
+	0x14dd:  "\u14d3\u00b7",
+	0x14de:  "\u00b7\u14d5",
+	0x14df:  "\u14d5\u00b7",
+	0x14e0:  "\u00b7\u14d6",
+	0x14e1:  "\u14d6\u00b7",
+	0x14e2:  "\u00b7\u14d7",
+	0x14e3:  "\u14d7\u00b7",
+	0x14e4:  "\u00b7\u14d8",
+	0x14e5:  "\u14d8\u00b7",
+	0x14e6:  "\u00b7\u14da",
+	0x14e7:  "\u14da\u00b7",
+	0x14e8:  "\u00b7\u14db",
+	0x14e9:  "\u14db\u00b7",
+	0x14f6:  "\u00b7\u14ed",
+	0x14f7:  "\u14ed\u00b7",
+	0x14f8:  "\u00b7\u14ef",
+	0x14f9:  "\u14ef\u00b7",
+	0x14fa:  "\u00b7\u14f0",
+	0x14fb:  "\u14f0\u00b7",
+	0x14fc:  "\u00b7\u14f1",
+	0x14fd:  "\u14f1\u00b7",
+	0x14fe:  "\u00b7\u14f2",
+	0x14ff:  "\u14f2\u00b7",
+	0x1500:  "\u00b7\u14f4",
+	0x1501:  "\u14f4\u00b7",
+	0x1502:  "\u00b7\u14f5",
+	0x1503:  "\u14f5\u00b7",
+	0x150c:  "\u150b<",
+	0x150d:  "\u150b\u1455",
+	0x150e:  "\u150bb",
+	0x150f:  "\u150b\u1490",
+	0x1517:  "\u00b7\u1510",
+	0x1518:  "\u1510\u00b7",
+	0x1519:  "\u00b7\u1511",
+	0x151a:  "\u1511\u00b7",
+	0x151b:  "\u00b7\u1512",
+	0x151c:  "\u1512\u00b7",
+	0x151d:  "\u00b7\u1513",
+	0x151e:  "\u1513\u00b7",
+	0x151f:  "\u00b7\u1514",
+	0x1520:  "\u1514\u00b7",
+	0x1521:  "\u00b7\u1515",
+	0x1522:  "\u1515\u00b7",
+	0x1523:  "\u00b7\u1516",
+	0x1524:  "\u1516\u00b7",
+	0x152f:  "\u00b74",
+	0x1530:  "4\u00b7",
+	0x1531:  "\u00b7\u1528",
+	0x1532:  "\u1528\u00b7",
+	0x1533:  "\u00b7\u1529",
+	0x1534:  "\u1529\u00b7",
+	0x1535:  "\u00b7\u152a",
+	0x1536:  "\u152a\u00b7",
+	0x1537:  "\u00b7\u152b",
+	0x1538:  "\u152b\u00b7",
+	0x1539:  "\u00b7\u152d",
+	0x153a:  "\u152d\u00b7",
+	0x153b:  "\u00b7\u152e",
+	0x153c:  "\u152e\u00b7",
+	0x1540:  "\u1429",
+	0x1541:  "x",
+	0x154e:  "\u00b7\u154c",
+	0x154f:  "\u154c\u00b7",
+	0x155b:  "\u00b7\u155a",
+	0x155c:  "\u155a\u00b7",
+	0x1568:  "\u00b7\u1567",
+	0x1569:  "\u1567\u00b7",
+	0x1577:  "\u1e9f",
+	0x157c:  "H",
+	0x157d:  "x",
+	0x157e:  "\u1550\u146c",
+	0x157f:  "\u1550P",
+	0x1580:  "\u1550\u146e",
+	0x1581:  "\u1550d",
+	0x1582:  "\u1550\u1470",
+	0x1583:  "\u1550b",
+	0x1584:  "\u1550b\u0307",
+	0x1585:  "\u1550\u1483",
+	0x1587:  "R",
+	0x158e:  "\u1595\u148a",
+	0x158f:  "\u1595\u148b",
+	0x1590:  "\u1595\u148c",
+	0x1591:  "\u1595J",
+	0x1592:  "\u1595\u148e",
+	0x1593:  "\u1595\u1490",
+	0x1594:  "\u1595\u1491",
+	0x15af:  "b",
+	0x15b4:  "F",
+	0x15b5:  "\u2132",
+	0x15b7:  "\ua7fb",
+	0x15c4:  "\u2c6f",
+	0x15c5:  "A",
+	0x15de:  "D",
+	0x15ea:  "D",
+	0x15ef:  "\u0460",
+	0x15f0:  "M",
+	0x15f7:  "B",
+	0x1602:  "\u1490",
+	0x1603:  "\u1489",
+	0x1604:  "\u14d3",
+	0x1607:  "\u14da",
+	0x1622:  "\u1543",
+	0x1623:  "\u1546",
+	0x1624:  "\u154a",
+	0x162e:  "\u01b1",
+	0x162f:  "\u03a9",
+	0x1634:  "\u01b1",
+	0x1635:  "\u03a9",
+	0x166d:  "X",
+	0x166e:  "x",
+	0x166f:  "\u1550\u146b",
+	0x1670:  "\u1595\u1489",
+	0x1671:  "\u1596\u148b",
+	0x1672:  "\u1596\u148c",
+	0x1673:  "\u1596J",
+	0x1674:  "\u1596\u148e",
+	0x1675:  "\u1596\u1490",
+	0x1676:  "\u1596\u1491",
+	0x1677:  "\u15a7\u00b7",
+	0x1678:  "\u15a8\u00b7",
+	0x1679:  "\u15a9\u00b7",
+	0x167a:  "\u15aa\u00b7",
+	0x167b:  "\u15ab\u00b7",
+	0x167c:  "\u15ac\u00b7",
+	0x167d:  "\u15ad\u00b7",
+	0x1680:  " ",
+	0x16b2:  "<",
+	0x16b7:  "X",
+	0x16c1:  "l",
+	0x16c2:  "\u16bd",
+	0x16cc:  "'",
+	0x16d5:  "K",
+	0x16d6:  "M",
+	0x16d8:  "\u03a8",
+	0x16e1:  "\u16bc",
+	0x16eb:  "\u00b7",
+	0x16ec:  ":",
+	0x16ed:  "+",
+	0x16f0:  "\u03a6",
+	0x1735:  "/",
+	0x17a3:  "\u17a2",
+	0x17b7:  "\u0e34",
+	0x17b8:  "\u0e35",
+	0x17b9:  "\u0e36",
+	0x17ba:  "\u0e37",
+	0x17c6:  "\u030a",
+	0x17cb:  "\u0e48",
+	0x17d3:  "\u030a",
+	0x17d4:  "\u0e2f",
+	0x17d5:  "\u0e5a",
+	0x17d9:  "\u0e4f",
+	0x17da:  "\u0e5b",
+	0x1803:  ":",
+	0x1809:  ":",
+	0x1855:  "\u1835",
+	0x1896:  "\u185c",
+	0x18b3:  "\u00b7\u18b1",
+	0x18b6:  "\u00b7\u18b4",
+	0x18b9:  "\u00b7\u18b8",
+	0x18c2:  "\u00b7\u18c0",
+	0x18c6:  "\u00b7\u14c2",
+	0x18c7:  "\u14c2\u00b7",
+	0x18c8:  "\u00b7\u14c3",
+	0x18c9:  "\u14c3\u00b7",
+	0x18ca:  "\u00b7\u14c4",
+	0x18cb:  "\u14c4\u00b7",
+	0x18cc:  "\u00b7\u14c5",
+	0x18cd:  "\u14c5\u00b7",
+	0x18ce:  "\u00b7\u1543",
+	0x18cf:  "\u00b7\u1546",
+	0x18d0:  "\u00b7\u1547",
+	0x18d1:  "\u00b7\u1548",
+	0x18d2:  "\u00b7\u1549",
+	0x18d3:  "\u00b7\u154b",
+	0x18db:  "\u18f5",
+	0x18dc:  "\u18df\u141e",
+	0x18dd:  "\u141e\u18df",
+	0x18e0:  "\u1543\u00b7",
+	0x18e3:  "\u155e\u00b7",
+	0x18e4:  "\u1566\u00b7",
+	0x18e5:  "\u156b\u00b7",
+	0x18e8:  "\u1586\u00b7",
+	0x18ea:  "\u1597\u00b7",
+	0x18ed:  "\u0460\u00b7",
+	0x18f0:  "\u15f4\u00b7",
+	0x18f2:  "\u161b\u00b7",
+	0x19d0:  "\u199e",
+	0x19d1:  "\u19b1",
+	0x1a80:  "\u1a45",
+	0x1a90:  "\u1a45",
+	0x1aa9:  "\u1aa8\u1aa8",
+	0x1aab:  "\u1aaa\u1aa8",
+	0x1ab4:  "\u06db",
+	0x1ab7:  "\u0328",
+	0x1b52:  "\u1b0d",
+	0x1b53:  "\u1b11",
+	0x1b58:  "\u1b28",
+	0x1b5c:  "\u1b50",
+	0x1b5f:  "\u1b5e\u1b5e",
+	0x1c3c:  "\u1c3b\u1c3b",
+	0x1c7f:  "\u1c7e\u1c7e",
+	0x1cd0:  "\u0302",
+	0x1cd2:  "\u0304",
+	0x1cd3:  "''",
+	0x1cd5:  "\u032b",
+	0x1cd8:  "\u032e",
+	0x1cd9:  "\u032d",
+	0x1cda:  "\u030e",
+	0x1cdc:  "\u0329",
+	0x1cdd:  "\u0323",
+	0x1cde:  "\u0324",
+	0x1ced:  "\u0316",
+	0x1d04:  "c",
+	0x1d08:  "\u025c",
+	0x1d0b:  "\u0138",
+	0x1d0d:  "\u028d",
+	0x1d0f:  "o",
+	0x1d10:  "\u0254",
+	0x1d11:  "o",
+	0x1d14:  "\u01ddo",
+	0x1d1c:  "u",
+	0x1d20:  "v",
+	0x1d21:  "w",
+	0x1d22:  "z",
+	0x1d24:  "\u01a8",
+	0x1d26:  "r",
+	0x1d27:  "\u028c",
+	0x1d28:  "\u03c0",
+	0x1d29:  "\u1d18",
+	0x1d2b:  "\u043b",
+	0x1d3e:  "\u18d6",
+	0x1d52:  "\u00ba",
+	0x1d6b:  "ue",
+	0x1d6e:  "f\u0334",
+	0x1d6f:  "rn\u0334",
+	0x1d70:  "n\u0334",
+	0x1d72:  "r\u0334",
+	0x1d73:  "\u027e\u0334",
+	0x1d74:  "s\u0334",
+	0x1d75:  "t\u0334",
+	0x1d76:  "z\u0334",
+	0x1d78:  "\u1d34",
+	0x1d7b:  "i\u0335",
+	0x1d7c:  "i\u0335",
+	0x1d7d:  "p\u0335",
+	0x1d7e:  "u\u0335",
+	0x1d7f:  "\u028a\u0335",
+	0x1d83:  "g",
+	0x1d8c:  "y",
+	0x1d90:  "\u024b",
+	0x1d9f:  "\u1d4b",
+	0x1da2:  "\u1d4d",
+	0x1dba:  "\u18d4",
+	0x1dbb:  "\u1646",
+	0x1dee:  "\u2dec",
+	0x1e43:  "\uab51",
+	0x1e9a:  "\u1ea3",
+	0x1e9d:  "f",
+	0x1eff:  "y",
+	0x1f7d:  "\u1ff4",
+	0x1fbd:  "'",
+	0x1fbe:  "i",
+	0x1fbf:  "'",
+	0x1fc0:  "~",
+	0x1fef:  "'",
+	0x1ff6:  "\u13ef",
+	0x1ffd:  "'",
+	0x1ffe:  "'",
+	0x2000:  " ",
+	0x2001:  " ",
+	0x2002:  " ",
+	0x2003:  " ",
+	0x2004:  " ",
+	0x2005:  " ",
+	0x2006:  " ",
+	0x2007:  " ",
+	0x2008:  " ",
+	0x2009:  " ",
+	0x200a:  " ",
+	0x2010:  "-",
+	0x2011:  "-",
+	0x2012:  "-",
+	0x2013:  "-",
+	0x2014:  "\u30fc",
+	0x2015:  "\u30fc",
+	0x2016:  "ll",
+	0x2018:  "'",
+	0x2019:  "'",
+	0x201a:  ",",
+	0x201b:  "'",
+	0x201c:  "''",
+	0x201d:  "''",
+	0x201f:  "''",
+	0x2022:  "\u00b7",
+	0x2024:  ".",
+	0x2025:  "..",
+	0x2026:  "...",
+	0x2027:  "\u00b7",
+	0x2028:  " ",
+	0x2029:  " ",
+	0x202f:  " ",
+	0x2030:  "\u00ba/\u2080\u2080",
+	0x2031:  "\u00ba/\u2080\u2080\u2080",
+	0x2032:  "'",
+	0x2033:  "''",
+	0x2034:  "'''",
+	0x2035:  "'",
+	0x2036:  "''",
+	0x2037:  "'''",
+	0x2039:  "<",
+	0x203a:  ">",
+	0x203c:  "!!",
+	0x203e:  "\u02c9",
+	0x2041:  "/",
+	0x2043:  "-",
+	0x2044:  "/",
+	0x2047:  "??",
+	0x2048:  "?!",
+	0x2049:  "!?",
+	0x204e:  "*",
+	0x2052:  "\u00ba/\u2080",
+	0x2053:  "~",
+	0x2057:  "''''",
+	0x205a:  ":",
+	0x205d:  "\u2d57",
+	0x205e:  "\u2d42",
+	0x205f:  " ",
+	0x2070:  "\u00ba",
+	0x2079:  "\ua770",
+	0x20a1:  "C\u20eb",
+	0x20a4:  "\u00a3",
+	0x20a5:  "rn\u0338",
+	0x20a8:  "Rs",
+	0x20a9:  "W\u0335",
+	0x20ab:  "d\u0335\u0331",
+	0x20ac:  "\ua792",
+	0x20ad:  "K\u0335",
+	0x20ae:  "T\u20eb",
+	0x20b6:  "lt",
+	0x20bd:  "\u0554",
+	0x20db:  "\u06db",
+	0x2100:  "a/c",
+	0x2101:  "a/s",
+	0x2102:  "C",
+	0x2103:  "\u00b0C",
+	0x2105:  "c/o",
+	0x2106:  "c/u",
+	0x2107:  "\u0190",
+	0x2108:  "\u042d",
+	0x2109:  "\u00b0F",
+	0x210a:  "g",
+	0x210b:  "H",
+	0x210c:  "H",
+	0x210d:  "H",
+	0x210e:  "h",
+	0x210f:  "h\u0335",
+	0x2110:  "l",
+	0x2111:  "l",
+	0x2112:  "L",
+	0x2113:  "l",
+	0x2115:  "N",
+	0x2116:  "No",
+	0x2119:  "P",
+	0x211a:  "Q",
+	0x211b:  "R",
+	0x211c:  "R",
+	0x211d:  "R",
+	0x2121:  "TEL",
+	0x2124:  "Z",
+	0x2126:  "\u03a9",
+	0x2127:  "\u01b1",
+	0x2128:  "Z",
+	0x2129:  "\u027f",
+	0x212a:  "K",
+	0x212c:  "B",
+	0x212d:  "C",
+	0x212e:  "e",
+	0x212f:  "e",
+	0x2130:  "E",
+	0x2131:  "F",
+	0x2133:  "M",
+	0x2134:  "o",
+	0x2135:  "\u05d0",
+	0x2136:  "\u05d1",
+	0x2137:  "\u05d2",
+	0x2138:  "\u05d3",
+	0x2139:  "i",
+	0x213b:  "FAX",
+	0x213c:  "\u03c0",
+	0x213d:  "y",
+	0x213e:  "\u0393",
+	0x213f:  "\u03a0",
+	0x2140:  "\u01a9",
+	0x2141:  "\ua4e8",
+	0x2142:  "\ua4f6",
+	0x2143:  "\U00016f00",
+	0x2145:  "D",
+	0x2146:  "d",
+	0x2147:  "e",
+	0x2148:  "i",
+	0x2149:  "j",
+	0x2160:  "l",
+	0x2161:  "ll",
+	0x2162:  "lll",
+	0x2163:  "lV",
+	0x2164:  "V",
+	0x2165:  "Vl",
+	0x2166:  "Vll",
+	0x2167:  "Vlll",
+	0x2168:  "lX",
+	0x2169:  "X",
+	0x216a:  "Xl",
+	0x216b:  "Xll",
+	0x216c:  "L",
+	0x216d:  "C",
+	0x216e:  "D",
+	0x216f:  "M",
+	0x2170:  "i",
+	0x2171:  "ii",
+	0x2172:  "iii",
+	0x2173:  "iv",
+	0x2174:  "v",
+	0x2175:  "vi",
+	0x2176:  "vii",
+	0x2177:  "viii",
+	0x2178:  "ix",
+	0x2179:  "x",
+	0x217a:  "xi",
+	0x217b:  "xii",
+	0x217c:  "l",
+	0x217d:  "c",
+	0x217e:  "d",
+	0x217f:  "rn",
+	0x2183:  "\u0186",
+	0x2184:  "\u0254",
+	0x2191:  "\u16cf",
+	0x2195:  "\u16e8",
+	0x21b5:  "\u21b2",
+	0x21ba:  "\U0001f10e",
+	0x21be:  "\u16da",
+	0x21bf:  "\u16d0",
+	0x2200:  "\u2c6f",
+	0x2203:  "\u018e",
+	0x2206:  "\u0394",
+	0x220f:  "\u03a0",
+	0x2211:  "\u01a9",
+	0x2212:  "-",
+	0x2214:  "+\u0307",
+	0x2215:  "/",
+	0x2216:  "\\",
+	0x2217:  "*",
+	0x2218:  "\u00b0",
+	0x2219:  "\u00b7",
+	0x221e:  "oo",
+	0x2223:  "l",
+	0x2225:  "ll",
+	0x2228:  "v",
+	0x2229:  "\u0548",
+	0x222a:  "U",
+	0x222b:  "\u0283",
+	0x222c:  "\u0283\u0283",
+	0x222d:  "\u0283\u0283\u0283",
+	0x222f:  "\u222e\u222e",
+	0x2230:  "\u222e\u222e\u222e",
+	0x2236:  ":",
+	0x2238:  "-\u0307",
+	0x223c:  "~",
+	0x2250:  "=\u0307",
+	0x2251:  "=\u0307\u0323",
+	0x2257:  "=\u030a",
+	0x2259:  "=\u0302",
+	0x225a:  "=\u0306",
+	0x225e:  "=\u036b",
+	0x2263:  "\u2261",
+	0x226a:  "<<",
+	0x226b:  ">>",
+	0x2282:  "\u1455",
+	0x2283:  "\u1450",
+	0x2295:  "\U000102a8",
+	0x2296:  "O\u0335",
+	0x2299:  "\u0298",
+	0x229d:  "O\u0335",
+	0x22a4:  "T",
+	0x22a5:  "\ua4d5",
+	0x22c0:  "\u2227",
+	0x22c1:  "v",
+	0x22c2:  "\u0548",
+	0x22c3:  "U",
+	0x22c4:  "\u16dc",
+	0x22c5:  "\u00b7",
+	0x22c8:  "\u16de",
+	0x22d6:  "<\u00b7",
+	0x22d7:  "\u00b7>",
+	0x22d8:  "<<<",
+	0x22d9:  ">>>",
+	0x22ee:  "\u2d57",
+	0x22ef:  "\u00b7\u00b7\u00b7",
+	0x22f4:  "\ua793",
+	0x22ff:  "E",
+	0x2300:  "\u2205",
+	0x2325:  "\u2324",
+	0x2329:  "\u276c",
+	0x232a:  "\u276d",
+	0x2341:  "\u303c",
+	0x2359:  "\u0394\u0332",
+	0x235a:  "\u16dc\u0332",
+	0x235c:  "\u00b0\u0332",
+	0x235f:  "\u229b",
+	0x2361:  "T\u0308",
+	0x2362:  "\u2207\u0308",
+	0x2363:  "\u22c6\u0308",
+	0x2364:  "\u00b0\u0308",
+	0x2365:  "\u0629",
+	0x2368:  "~\u0308",
+	0x2369:  "\u1435",
+	0x236b:  "\u2207\u0334",
+	0x236c:  "O\u0335",
+	0x2373:  "i",
+	0x2374:  "p",
+	0x2375:  "\u03c9",
+	0x2376:  "a\u0332",
+	0x2377:  "\ua793\u0332",
+	0x2378:  "i\u0332",
+	0x2379:  "\u03c9\u0332",
+	0x237a:  "a",
+	0x237f:  "\u16bd",
+	0x239c:  "\u4e28",
+	0x239f:  "\u4e28",
+	0x23a2:  "\u4e28",
+	0x23a5:  "\u4e28",
+	0x23aa:  "\u4e28",
+	0x23ae:  "\u4e28",
+	0x23c1:  "\u2355",
+	0x23c2:  "\u234e",
+	0x23c3:  "\u234b",
+	0x23c6:  "\u236d",
+	0x23e8:  "\u2081\u2080",
+	0x23fc:  "\u23fb",
+	0x23fd:  "l",
+	0x23fe:  "\u263e",
+	0x244a:  "\\\\",
+	0x2460:  "\u2780",
+	0x2461:  "\u2781",
+	0x2462:  "\u2782",
+	0x2463:  "\u2783",
+	0x2464:  "\u2784",
+	0x2465:  "\u2785",
+	0x2466:  "\u2786",
+	0x2467:  "\u2787",
+	0x2468:  "\u2788",
+	0x2469:  "\u2789",
+	0x2474:  "(l)",
+	0x2475:  "(2)",
+	0x2476:  "(3)",
+	0x2477:  "(4)",
+	0x2478:  "(5)",
+	0x2479:  "(6)",
+	0x247a:  "(7)",
+	0x247b:  "(8)",
+	0x247c:  "(9)",
+	0x247d:  "(lO)",
+	0x247e:  "(ll)",
+	0x247f:  "(l2)",
+	0x2480:  "(l3)",
+	0x2481:  "(l4)",
+	0x2482:  "(l5)",
+	0x2483:  "(l6)",
+	0x2484:  "(l7)",
+	0x2485:  "(l8)",
+	0x2486:  "(l9)",
+	0x2487:  "(2O)",
+	0x2488:  "l.",
+	0x2489:  "2.",
+	0x248a:  "3.",
+	0x248b:  "4.",
+	0x248c:  "5.",
+	0x248d:  "6.",
+	0x248e:  "7.",
+	0x248f:  "8.",
+	0x2490:  "9.",
+	0x2491:  "lO.",
+	0x2492:  "ll.",
+	0x2493:  "l2.",
+	0x2494:  "l3.",
+	0x2495:  "l4.",
+	0x2496:  "l5.",
+	0x2497:  "l6.",
+	0x2498:  "l7.",
+	0x2499:  "l8.",
+	0x249a:  "l9.",
+	0x249b:  "2O.",
+	0x249c:  "(a)",
+	0x249d:  "(b)",
+	0x249e:  "(c)",
+	0x249f:  "(d)",
+	0x24a0:  "(e)",
+	0x24a1:  "(f)",
+	0x24a2:  "(g)",
+	0x24a3:  "(h)",
+	0x24a4:  "(i)",
+	0x24a5:  "(j)",
+	0x24a6:  "(k)",
+	0x24a7:  "(l)",
+	0x24a8:  "(rn)",
+	0x24a9:  "(n)",
+	0x24aa:  "(o)",
+	0x24ab:  "(p)",
+	0x24ac:  "(q)",
+	0x24ad:  "(r)",
+	0x24ae:  "(s)",
+	0x24af:  "(t)",
+	0x24b0:  "(u)",
+	0x24b1:  "(v)",
+	0x24b2:  "(w)",
+	0x24b3:  "(x)",
+	0x24b4:  "(y)",
+	0x24b5:  "(z)",
+	0x24b8:  "\u00a9",
+	0x24c5:  "\u2117",
+	0x24c7:  "\u00ae",
+	0x24db:  "\u24be",
+	0x24ea:  "\U0001f10d",
+	0x2500:  "\u30fc",
+	0x2501:  "\u30fc",
+	0x2503:  "\u2502",
+	0x250f:  "\u250c",
+	0x2523:  "\u251c",
+	0x2571:  "/",
+	0x2573:  "X",
+	0x2588:  "\u220e",
+	0x2590:  "\u258c",
+	0x2594:  "\u02c9",
+	0x2597:  "\u2596",
+	0x259d:  "\u2598",
+	0x25a0:  "\u220e",
+	0x25b1:  "\u23e5",
+	0x25b3:  "\u0394",
+	0x25b7:  "\u22b3",
+	0x25b8:  "\u25b6",
+	0x25ba:  "\u25b6",
+	0x25bd:  "\U000102bc",
+	0x25c1:  "\u22b2",
+	0x25c7:  "\u16dc",
+	0x25ca:  "\u16dc",
+	0x25cb:  "\u00b0",
+	0x25ce:  "\u233e",
+	0x25e0:  "\u2312",
+	0x25e6:  "\u00b0",
+	0x2609:  "\u0298",
+	0x2610:  "\u25a1",
+	0x2625:  "\U0001099e",
+	0x2630:  "\u2cb6",
+	0x2638:  "\u2388",
+	0x264e:  "\u224f",
+	0x2662:  "\u16dc",
+	0x2669:  "\U0001d158\U0001d165",
+	0x266a:  "\U0001d158\U0001d165\U0001d16e",
+	0x26ac:  "\u0970",
+	0x2768:  "(",
+	0x2769:  ")",
+	0x276e:  "<",
+	0x276f:  ">",
+	0x2772:  "(",
+	0x2773:  ")",
+	0x2774:  "{",
+	0x2775:  "}",
+	0x2795:  "+",
+	0x2796:  "-",
+	0x2797:  "\u00f7",
+	0x27c2:  "\ua4d5",
+	0x27c8:  "\\\u1455",
+	0x27c9:  "\u1450/",
+	0x27cb:  "/",
+	0x27cd:  "\\",
+	0x27d9:  "T",
+	0x27e8:  "\u276c",
+	0x27e9:  "\u276d",
+	0x292b:  "x",
+	0x292c:  "x",
+	0x2963:  "\u16d0\u16da",
+	0x2965:  "\u21c3\u21c2",
+	0x296e:  "\u16d0\u21c2",
+	0x296f:  "\u21c3\u16da",
+	0x2999:  "\u2d42",
+	0x29b0:  "\u2349",
+	0x29be:  "\u233e",
+	0x29c4:  "\u303c",
+	0x29c5:  "\u2342",
+	0x29c7:  "\u233b",
+	0x29d6:  "\U000102c0",
+	0x29d9:  "\u299a",
+	0x29f4:  ":\u2192",
+	0x29f5:  "\\",
+	0x29f6:  "/\u0304",
+	0x29f8:  "/",
+	0x29f9:  "\\",
+	0x2a00:  "\u0298",
+	0x2a01:  "\U000102a8",
+	0x2a02:  "\u2297",
+	0x2a03:  "\u228d",
+	0x2a04:  "\u228e",
+	0x2a05:  "\u2293",
+	0x2a06:  "\u2294",
+	0x2a0c:  "\u0283\u0283\u0283\u0283",
+	0x2a1d:  "\u16de",
+	0x2a20:  ">>",
+	0x2a21:  "\u16da",
+	0x2a22:  "+\u030a",
+	0x2a23:  "+\u0302",
+	0x2a24:  "+\u0303",
+	0x2a25:  "+\u0323",
+	0x2a26:  "+\u0330",
+	0x2a27:  "+\u2082",
+	0x2a29:  "-\u0313",
+	0x2a2a:  "-\u0323",
+	0x2a2f:  "x",
+	0x2a30:  "x\u0307",
+	0x2a3d:  "\u2319",
+	0x2a3e:  "\u2a1f",
+	0x2a3f:  "\u2210",
+	0x2a6a:  "~\u0307",
+	0x2a6e:  "=\u20f0",
+	0x2a74:  "::=",
+	0x2a75:  "==",
+	0x2a76:  "===",
+	0x2aa5:  "><",
+	0x2aaa:  "\u15d5",
+	0x2aab:  "\u15d2",
+	0x2ad7:  "\u1450\u1455",
+	0x2afb:  "///",
+	0x2afd:  "//",
+	0x2bec:  "\u219e",
+	0x2bed:  "\u219f",
+	0x2bee:  "\u21a0",
+	0x2bef:  "\u21a1",
+	0x2c67:  "H\u0329",
+	0x2c69:  "K\u0329",
+	0x2c84:  "\u0393",
+	0x2c85:  "r",
+	0x2c86:  "\u0394",
+	0x2c88:  "\ua792",
+	0x2c89:  "\ua793",
+	0x2c8e:  "H",
+	0x2c92:  "l",
+	0x2c94:  "K",
+	0x2c95:  "\u0138",
+	0x2c96:  "\u03bb",
+	0x2c98:  "M",
+	0x2c9a:  "N",
+	0x2c9e:  "O",
+	0x2c9f:  "o",
+	0x2ca0:  "\u03a0",
+	0x2ca2:  "P",
+	0x2ca3:  "p",
+	0x2ca4:  "C",
+	0x2ca5:  "c",
+	0x2ca6:  "T",
+	0x2ca8:  "Y",
+	0x2caa:  "\u03a6",
+	0x2cab:  "\u0278",
+	0x2cac:  "X",
+	0x2cad:  "\u03c7",
+	0x2cae:  "\u03a8",
+	0x2cb1:  "\u03c9",
+	0x2cb4:  "<\u00b7",
+	0x2cba:  "-",
+	0x2cbc:  "\u0428",
+	0x2cbd:  "\u0448",
+	0x2cc6:  "/",
+	0x2cca:  "9",
+	0x2ccc:  "3",
+	0x2ccd:  "\u021d",
+	0x2cd0:  "L",
+	0x2cd1:  "\u029f",
+	0x2cd2:  "6",
+	0x2cdc:  "\u03ec",
+	0x2ce4:  "\u03d7",
+	0x2ce9:  "\u2627",
+	0x2cf9:  "\\\\",
+	0x2d31:  "O\u0335",
+	0x2d37:  "\u0245",
+	0x2d38:  "V",
+	0x2d39:  "E",
+	0x2d3a:  "\u018e",
+	0x2d41:  "O\u0338",
+	0x2d48:  "\u00b7\u00b7\u00b7",
+	0x2d49:  "\u01a9",
+	0x2d4f:  "l",
+	0x2d51:  "!",
+	0x2d54:  "O",
+	0x2d55:  "Q",
+	0x2d59:  "\u0298",
+	0x2d5d:  "X",
+	0x2d60:  "\u0394",
+	0x2d63:  "\u16ef",
+	0x2de8:  "\u1ddf",
+	0x2dea:  "\u030a",
+	0x2ded:  "\u0368",
+	0x2def:  "\u036f",
+	0x2df6:  "\u0363",
+	0x2df7:  "\u0364",
+	0x2e1a:  "-\u0308",
+	0x2e1e:  "~\u0307",
+	0x2e1f:  "~\u0323",
+	0x2e26:  "\u1455",
+	0x2e27:  "\u1450",
+	0x2e28:  "((",
+	0x2e29:  "))",
+	0x2e2a:  "\u2235",
+	0x2e2b:  "\u2234",
+	0x2e2c:  "\u2237",
+	0x2e2e:  "\u061f",
+	0x2e30:  "\u00b0",
+	0x2e31:  "\u00b7",
+	0x2e32:  "\u060c",
+	0x2e35:  "\u061b",
+	0x2e39:  "\u1e9f",
+	0x2e3d:  "\u2d42",
+	0x2e3f:  "\u00b6",
+	0x2e40:  "=",
+	0x2e82:  "\u4e5b",
+	0x2e83:  "\u4e5a",
+	0x2e85:  "\u4ebb",
+	0x2e89:  "\u5202",
+	0x2e8b:  "\u353e",
+	0x2e8e:  "\u5140",
+	0x2e8f:  "\u5c23",
+	0x2e90:  "\u5c22",
+	0x2e92:  "\u5df3",
+	0x2e93:  "\u5e7a",
+	0x2e94:  "\u5f51",
+	0x2e96:  "\u5fc4",
+	0x2e97:  "\u38fa",
+	0x2e98:  "\u624c",
+	0x2e99:  "\u6535",
+	0x2e9b:  "\u65e1",
+	0x2e9e:  "\u6b7a",
+	0x2e9f:  "\u6bcd",
+	0x2ea0:  "\u6c11",
+	0x2ea1:  "\u6c35",
+	0x2ea2:  "\u6c3a",
+	0x2ea3:  "\u706c",
+	0x2ea4:  "\u722b",
+	0x2ea6:  "\u4e2c",
+	0x2ea8:  "\u72ad",
+	0x2eab:  "\u7f52",
+	0x2ead:  "\u793b",
+	0x2eaf:  "\u7cf9",
+	0x2eb1:  "\u7f53",
+	0x2eb2:  "\u7f52",
+	0x2eb9:  "\u8002",
+	0x2eba:  "\u8080",
+	0x2ebe:  "\u8279",
+	0x2ebf:  "\u8279",
+	0x2ec0:  "\u8279",
+	0x2ec1:  "\u864e",
+	0x2ec2:  "\u8864",
+	0x2ec3:  "\u8980",
+	0x2ec4:  "\u897f",
+	0x2ec5:  "\u89c1",
+	0x2ec8:  "\u8ba0",
+	0x2ec9:  "\u8d1d",
+	0x2ecb:  "\u8f66",
+	0x2ecc:  "\u8fb6",
+	0x2ecd:  "\u8fb6",
+	0x2ecf:  "\u961d",
+	0x2ed0:  "\u9485",
+	0x2ed1:  "\u9577",
+	0x2ed2:  "\u9578",
+	0x2ed3:  "\u957f",
+	0x2ed4:  "\u95e8",
+	0x2ed6:  "\u961d",
+	0x2ed8:  "\u9752",
+	0x2ed9:  "\u97e6",
+	0x2eda:  "\u9875",
+	0x2edb:  "\u98ce",
+	0x2edc:  "\u98de",
+	0x2edd:  "\u98df",
+	0x2edf:  "\u98e0",
+	0x2ee0:  "\u9963",
+	0x2ee2:  "\u9a6c",
+	0x2ee4:  "\u9b3c",
+	0x2ee5:  "\u9c7c",
+	0x2ee8:  "\u9ea6",
+	0x2ee9:  "\u9ec4",
+	0x2eeb:  "\u6589",
+	0x2eec:  "\u9f50",
+	0x2eed:  "\u6b6f",
+	0x2eee:  "\u9f7f",
+	0x2eef:  "\u7adc",
+	0x2ef0:  "\u9f99",
+	0x2ef2:  "\u4e80",
+	0x2ef3:  "\u9f9f",
+	0x2f00:  "\u30fc",
+	0x2f01:  "\u4e28",
+	0x2f02:  "\\",
+	0x2f03:  "/",
+	0x2f04:  "\u4e59",
+	0x2f05:  "\u4e85",
+	0x2f06:  "\u4e8c",
+	0x2f07:  "\u4ea0",
+	0x2f08:  "\u4eba",
+	0x2f09:  "\u513f",
+	0x2f0a:  "\u5165",
+	0x2f0b:  "\u516b",
+	0x2f0c:  "\u5182",
+	0x2f0d:  "\u5196",
+	0x2f0e:  "\u51ab",
+	0x2f0f:  "\u51e0",
+	0x2f10:  "\u51f5",
+	0x2f11:  "\u5200",
+	0x2f12:  "\u529b",
+	0x2f13:  "\u52f9",
+	0x2f14:  "\u5315",
+	0x2f15:  "\u531a",
+	0x2f16:  "\u5338",
+	0x2f17:  "\u5341",
+	0x2f18:  "\u535c",
+	0x2f19:  "\u5369",
+	0x2f1a:  "\u5382",
+	0x2f1b:  "\u53b6",
+	0x2f1c:  "\u53c8",
+	0x2f1d:  "\u53e3",
+	0x2f1e:  "\u53e3",
+	0x2f1f:  "\u571f",
+	0x2f20:  "\u571f",
+	0x2f21:  "\u5902",
+	0x2f22:  "\u590a",
+	0x2f23:  "\u5915",
+	0x2f24:  "\u5927",
+	0x2f25:  "\u5973",
+	0x2f26:  "\u5b50",
+	0x2f27:  "\u5b80",
+	0x2f28:  "\u5bf8",
+	0x2f29:  "\u5c0f",
+	0x2f2a:  "\u5c22",
+	0x2f2b:  "\u5c38",
+	0x2f2c:  "\u5c6e",
+	0x2f2d:  "\u5c71",
+	0x2f2e:  "\u5ddb",
+	0x2f2f:  "\u5de5",
+	0x2f30:  "\u5df1",
+	0x2f31:  "\u5dfe",
+	0x2f32:  "\u5e72",
+	0x2f33:  "\u5e7a",
+	0x2f34:  "\u5e7f",
+	0x2f35:  "\u5ef4",
+	0x2f36:  "\u5efe",
+	0x2f37:  "\u5f0b",
+	0x2f38:  "\u5f13",
+	0x2f39:  "\u5f50",
+	0x2f3a:  "\u5f61",
+	0x2f3b:  "\u5f73",
+	0x2f3c:  "\u5fc3",
+	0x2f3d:  "\u6208",
+	0x2f3e:  "\u6236",
+	0x2f3f:  "\u624b",
+	0x2f40:  "\u652f",
+	0x2f41:  "\u6534",
+	0x2f42:  "\u6587",
+	0x2f43:  "\u6597",
+	0x2f44:  "\u65a4",
+	0x2f45:  "\u65b9",
+	0x2f46:  "\u65e0",
+	0x2f47:  "\u65e5",
+	0x2f48:  "\u66f0",
+	0x2f49:  "\u6708",
+	0x2f4a:  "\u6728",
+	0x2f4b:  "\u6b20",
+	0x2f4c:  "\u6b62",
+	0x2f4d:  "\u6b79",
+	0x2f4e:  "\u6bb3",
+	0x2f4f:  "\u6bcb",
+	0x2f50:  "\u6bd4",
+	0x2f51:  "\u6bdb",
+	0x2f52:  "\u6c0f",
+	0x2f53:  "\u6c14",
+	0x2f54:  "\u6c34",
+	0x2f55:  "\u706b",
+	0x2f56:  "\u722a",
+	0x2f57:  "\u7236",
+	0x2f58:  "\u723b",
+	0x2f59:  "\u723f",
+	0x2f5a:  "\u7247",
+	0x2f5b:  "\u7259",
+	0x2f5c:  "\u725b",
+	0x2f5d:  "\u72ac",
+	0x2f5e:  "\u7384",
+	0x2f5f:  "\u7389",
+	0x2f60:  "\u74dc",
+	0x2f61:  "\u74e6",
+	0x2f62:  "\u7518",
+	0x2f63:  "\u751f",
+	0x2f64:  "\u7528",
+	0x2f65:  "\u7530",
+	0x2f66:  "\u758b",
+	0x2f67:  "\u7592",
+	0x2f68:  "\u7676",
+	0x2f69:  "\u767d",
+	0x2f6a:  "\u76ae",
+	0x2f6b:  "\u76bf",
+	0x2f6c:  "\u76ee",
+	0x2f6d:  "\u77db",
+	0x2f6e:  "\u77e2",
+	0x2f6f:  "\u77f3",
+	0x2f70:  "\u793a",
+	0x2f71:  "\u79b8",
+	0x2f72:  "\u79be",
+	0x2f73:  "\u7a74",
+	0x2f74:  "\u7acb",
+	0x2f75:  "\u7af9",
+	0x2f76:  "\u7c73",
+	0x2f77:  "\u7cf8",
+	0x2f78:  "\u7f36",
+	0x2f79:  "\u7f51",
+	0x2f7a:  "\u7f8a",
+	0x2f7b:  "\u7fbd",
+	0x2f7c:  "\u8001",
+	0x2f7d:  "\u800c",
+	0x2f7e:  "\u8012",
+	0x2f7f:  "\u8033",
+	0x2f80:  "\u807f",
+	0x2f81:  "\u8089",
+	0x2f82:  "\u81e3",
+	0x2f83:  "\u81ea",
+	0x2f84:  "\u81f3",
+	0x2f85:  "\u81fc",
+	0x2f86:  "\u820c",
+	0x2f87:  "\u821b",
+	0x2f88:  "\u821f",
+	0x2f89:  "\u826e",
+	0x2f8a:  "\u8272",
+	0x2f8b:  "\u8278",
+	0x2f8c:  "\u864d",
+	0x2f8d:  "\u866b",
+	0x2f8e:  "\u8840",
+	0x2f8f:  "\u884c",
+	0x2f90:  "\u8863",
+	0x2f91:  "\u897e",
+	0x2f92:  "\u898b",
+	0x2f93:  "\u89d2",
+	0x2f94:  "\u8a00",
+	0x2f95:  "\u8c37",
+	0x2f96:  "\u8c46",
+	0x2f97:  "\u8c55",
+	0x2f98:  "\u8c78",
+	0x2f99:  "\u8c9d",
+	0x2f9a:  "\u8d64",
+	0x2f9b:  "\u8d70",
+	0x2f9c:  "\u8db3",
+	0x2f9d:  "\u8eab",
+	0x2f9e:  "\u8eca",
+	0x2f9f:  "\u8f9b",
+	0x2fa0:  "\u8fb0",
+	0x2fa1:  "\u8fb5",
+	0x2fa2:  "\u9091",
+	0x2fa3:  "\u9149",
+	0x2fa4:  "\u91c6",
+	0x2fa5:  "\u91cc",
+	0x2fa6:  "\u91d1",
+	0x2fa7:  "\u9577",
+	0x2fa8:  "\u9580",
+	0x2fa9:  "\u961c",
+	0x2faa:  "\u96b6",
+	0x2fab:  "\u96b9",
+	0x2fac:  "\u96e8",
+	0x2fad:  "\u9751",
+	0x2fae:  "\u975e",
+	0x2faf:  "\u9762",
+	0x2fb0:  "\u9769",
+	0x2fb1:  "\u97cb",
+	0x2fb2:  "\u97ed",
+	0x2fb3:  "\u97f3",
+	0x2fb4:  "\u9801",
+	0x2fb5:  "\u98a8",
+	0x2fb6:  "\u98db",
+	0x2fb7:  "\u98df",
+	0x2fb8:  "\u9996",
+	0x2fb9:  "\u9999",
+	0x2fba:  "\u99ac",
+	0x2fbb:  "\u9aa8",
+	0x2fbc:  "\u9ad8",
+	0x2fbd:  "\u9adf",
+	0x2fbe:  "\u9b25",
+	0x2fbf:  "\u9b2f",
+	0x2fc0:  "\u9b32",
+	0x2fc1:  "\u9b3c",
+	0x2fc2:  "\u9b5a",
+	0x2fc3:  "\u9ce5",
+	0x2fc4:  "\u9e75",
+	0x2fc5:  "\u9e7f",
+	0x2fc6:  "\u9ea5",
+	0x2fc7:  "\u9ebb",
+	0x2fc8:  "\u9ec3",
+	0x2fc9:  "\u9ecd",
+	0x2fca:  "\u9ed1",
+	0x2fcb:  "\u9ef9",
+	0x2fcc:  "\u9efd",
+	0x2fcd:  "\u9f0e",
+	0x2fce:  "\u9f13",
+	0x2fcf:  "\u9f20",
+	0x2fd0:  "\u9f3b",
+	0x2fd1:  "\u9f4a",
+	0x2fd2:  "\u9f52",
+	0x2fd3:  "\u9f8d",
+	0x2fd4:  "\u9f9c",
+	0x2fd5:  "\u9fa0",
+	0x3002:  "\u02f3",
+	0x3003:  "''",
+	0x3007:  "O",
+	0x3008:  "\u276c",
+	0x3009:  "\u276d",
+	0x3012:  "\u20b8",
+	0x3014:  "(",
+	0x3015:  ")",
+	0x301a:  "\u27e6",
+	0x301b:  "\u27e7",
+	0x302c:  "\u0309",
+	0x302d:  "\u0325",
+	0x3033:  "/",
+	0x3036:  "\u20b8",
+	0x3038:  "\u5341",
+	0x3039:  "\u5344",
+	0x303a:  "\u5345",
+	0x304f:  "\u276c",
+	0x309a:  "\u030a",
+	0x309b:  "\uff9e",
+	0x309c:  "\uff9f",
+	0x30a0:  "=",
+	0x30a4:  "\u4ebb",
+	0x30a8:  "\u5de5",
+	0x30ab:  "\u529b",
+	0x30bf:  "\u5915",
+	0x30c8:  "\u535c",
+	0x30cb:  "\u4e8c",
+	0x30ce:  "/",
+	0x30cf:  "\u516b",
+	0x30d8:  "\u3078",
+	0x30ed:  "\u53e3",
+	0x30fb:  "\u00b7",
+	0x3131:  "\u1100",
+	0x3132:  "\u1100\u1100",
+	0x3133:  "\u1100\u1109",
+	0x3134:  "\u1102",
+	0x3135:  "\u1102\u110c",
+	0x3136:  "\u1102\u1112",
+	0x3137:  "\u1103",
+	0x3138:  "\u1103\u1103",
+	0x3139:  "\u1105",
+	0x313a:  "\u1105\u1100",
+	0x313b:  "\u1105\u1106",
+	0x313c:  "\u1105\u1107",
+	0x313d:  "\u1105\u1109",
+	0x313e:  "\u1105\u1110",
+	0x313f:  "\u1105\u1111",
+	0x3140:  "\u1105\u1112",
+	0x3141:  "\u1106",
+	0x3142:  "\u1107",
+	0x3143:  "\u1107\u1107",
+	0x3144:  "\u1107\u1109",
+	0x3145:  "\u1109",
+	0x3146:  "\u1109\u1109",
+	0x3147:  "\u110b",
+	0x3148:  "\u110c",
+	0x3149:  "\u110c\u110c",
+	0x314a:  "\u110e",
+	0x314b:  "\u110f",
+	0x314c:  "\u1110",
+	0x314d:  "\u1111",
+	0x314e:  "\u1112",
+	0x314f:  "\u1161",
+	0x3150:  "\u1161\u4e28",
+	0x3151:  "\u1163",
+	0x3152:  "\u1163\u4e28",
+	0x3153:  "\u1165",
+	0x3154:  "\u1165\u4e28",
+	0x3155:  "\u1167",
+	0x3156:  "\u1167\u4e28",
+	0x3157:  "\u1169",
+	0x3158:  "\u1169\u1161",
+	0x3159:  "\u1169\u1161\u4e28",
+	0x315a:  "\u1169\u4e28",
+	0x315b:  "\u116d",
+	0x315c:  "\u116e",
+	0x315d:  "\u116e\u1165",
+	0x315e:  "\u116e\u1165\u4e28",
+	0x315f:  "\u116e\u4e28",
+	0x3160:  "\u1172",
+	0x3161:  "\u30fc",
+	0x3162:  "\u30fc\u4e28",
+	0x3163:  "\u4e28",
+	0x3164:  "\u1160",
+	0x3165:  "\u1102\u1102",
+	0x3166:  "\u1102\u1103",
+	0x3167:  "\u1102\u1109",
+	0x3168:  "\u1102\u1140",
+	0x3169:  "\u1105\u1100\u1109",
+	0x316a:  "\u1105\u1103",
+	0x316b:  "\u1105\u1107\u1109",
+	0x316c:  "\u1105\u1140",
+	0x316d:  "\u1105\u1159",
+	0x316e:  "\u1106\u1107",
+	0x316f:  "\u1106\u1109",
+	0x3170:  "\u1106\u1140",
+	0x3171:  "\u1106\u110b",
+	0x3172:  "\u1107\u1100",
+	0x3173:  "\u1107\u1103",
+	0x3174:  "\u1107\u1109\u1100",
+	0x3175:  "\u1107\u1109\u1103",
+	0x3176:  "\u1107\u110c",
+	0x3177:  "\u1107\u1110",
+	0x3178:  "\u1107\u110b",
+	0x3179:  "\u1107\u1107\u110b",
+	0x317a:  "\u1109\u1100",
+	0x317b:  "\u1109\u1102",
+	0x317c:  "\u1109\u1103",
+	0x317d:  "\u1109\u1107",
+	0x317e:  "\u1109\u110c",
+	0x317f:  "\u1140",
+	0x3180:  "\u110b\u110b",
+	0x3181:  "\u114c",
+	0x3182:  "\u110b\u1109",
+	0x3183:  "\u110b\u1140",
+	0x3184:  "\u1111\u110b",
+	0x3185:  "\u1112\u1112",
+	0x3186:  "\u1159",
+	0x3187:  "\u116d\u1163",
+	0x3188:  "\u116d\u1163\u4e28",
+	0x3189:  "\u116d\u4e28",
+	0x318a:  "\u1172\u1167",
+	0x318b:  "\u1172\u1167\u4e28",
+	0x318c:  "\u1172\u4e28",
+	0x318d:  "\u119e",
+	0x318e:  "\u119e\u4e28",
+	0x31d0:  "\u30fc",
+	0x31d1:  "\u4e28",
+	0x31d3:  "/",
+	0x31d4:  "\\",
+	0x31d6:  "\u4e5b",
+	0x31da:  "\u4e85",
+	0x31db:  "\u276c",
+	0x31df:  "\u4e5a",
+	0x31e0:  "\u4e59",
+	0x3200:  "(\u1100)",
+	0x3201:  "(\u1102)",
+	0x3202:  "(\u1103)",
+	0x3203:  "(\u1105)",
+	0x3204:  "(\u1106)",
+	0x3205:  "(\u1107)",
+	0x3206:  "(\u1109)",
+	0x3207:  "(\u110b)",
+	0x3208:  "(\u110c)",
+	0x3209:  "(\u110e)",
+	0x320a:  "(\u110f)",
+	0x320b:  "(\u1110)",
+	0x320c:  "(\u1111)",
+	0x320d:  "(\u1112)",
+	0x320e:  "(\uac00)",
+	0x320f:  "(\ub098)",
+	0x3210:  "(\ub2e4)",
+	0x3211:  "(\ub77c)",
+	0x3212:  "(\ub9c8)",
+	0x3213:  "(\ubc14)",
+	0x3214:  "(\uc0ac)",
+	0x3215:  "(\uc544)",
+	0x3216:  "(\uc790)",
+	0x3217:  "(\ucc28)",
+	0x3218:  "(\uce74)",
+	0x3219:  "(\ud0c0)",
+	0x321a:  "(\ud30c)",
+	0x321b:  "(\ud558)",
+	0x321c:  "(\uc8fc)",
+	0x321d:  "(\uc624\uc804)",
+	0x321e:  "(\uc624\ud6c4)",
+	0x3220:  "(\u30fc)",
+	0x3221:  "(\u4e8c)",
+	0x3222:  "(\u4e09)",
+	0x3223:  "(\u56db)",
+	0x3224:  "(\u4e94)",
+	0x3225:  "(\u516d)",
+	0x3226:  "(\u4e03)",
+	0x3227:  "(\u516b)",
+	0x3228:  "(\u4e5d)",
+	0x3229:  "(\u5341)",
+	0x322a:  "(\u6708)",
+	0x322b:  "(\u706b)",
+	0x322c:  "(\u6c34)",
+	0x322d:  "(\u6728)",
+	0x322e:  "(\u91d1)",
+	0x322f:  "(\u571f)",
+	0x3230:  "(\u65e5)",
+	0x3231:  "(\u682a)",
+	0x3232:  "(\u6709)",
+	0x3233:  "(\u793e)",
+	0x3234:  "(\u540d)",
+	0x3235:  "(\u7279)",
+	0x3236:  "(\u8ca1)",
+	0x3237:  "(\u795d)",
+	0x3238:  "(\u52b4)",
+	0x3239:  "(\u4ee3)",
+	0x323a:  "(\u547c)",
+	0x323b:  "(\u5b66)",
+	0x323c:  "(\u76e3)",
+	0x323d:  "(\u4f01)",
+	0x323e:  "(\u8cc7)",
+	0x323f:  "(\u5354)",
+	0x3240:  "(\u796d)",
+	0x3241:  "(\u4f11)",
+	0x3242:  "(\u81ea)",
+	0x3243:  "(\u81f3)",
+	0x32c0:  "l\u6708",
+	0x32c1:  "2\u6708",
+	0x32c2:  "3\u6708",
+	0x32c3:  "4\u6708",
+	0x32c4:  "5\u6708",
+	0x32c5:  "6\u6708",
+	0x32c6:  "7\u6708",
+	0x32c7:  "8\u6708",
+	0x32c8:  "9\u6708",
+	0x32c9:  "lO\u6708",
+	0x32ca:  "ll\u6708",
+	0x32cb:  "l2\u6708",
+	0x3358:  "O\u70b9",
+	0x3359:  "l\u70b9",
+	0x335a:  "2\u70b9",
+	0x335b:  "3\u70b9",
+	0x335c:  "4\u70b9",
+	0x335d:  "5\u70b9",
+	0x335e:  "6\u70b9",
+	0x335f:  "7\u70b9",
+	0x3360:  "8\u70b9",
+	0x3361:  "9\u70b9",
+	0x3362:  "lO\u70b9",
+	0x3363:  "ll\u70b9",
+	0x3364:  "l2\u70b9",
+	0x3365:  "l3\u70b9",
+	0x3366:  "l4\u70b9",
+	0x3367:  "l5\u70b9",
+	0x3368:  "l6\u70b9",
+	0x3369:  "l7\u70b9",
+	0x336a:  "l8\u70b9",
+	0x336b:  "l9\u70b9",
+	0x336c:  "2O\u70b9",
+	0x336d:  "2l\u70b9",
+	0x336e:  "22\u70b9",
+	0x336f:  "23\u70b9",
+	0x3370:  "24\u70b9",
+	0x33e0:  "l\u65e5",
+	0x33e1:  "2\u65e5",
+	0x33e2:  "3\u65e5",
+	0x33e3:  "4\u65e5",
+	0x33e4:  "5\u65e5",
+	0x33e5:  "6\u65e5",
+	0x33e6:  "7\u65e5",
+	0x33e7:  "8\u65e5",
+	0x33e8:  "9\u65e5",
+	0x33e9:  "lO\u65e5",
+	0x33ea:  "ll\u65e5",
+	0x33eb:  "l2\u65e5",
+	0x33ec:  "l3\u65e5",
+	0x33ed:  "l4\u65e5",
+	0x33ee:  "l5\u65e5",
+	0x33ef:  "l6\u65e5",
+	0x33f0:  "l7\u65e5",
+	0x33f1:  "l8\u65e5",
+	0x33f2:  "l9\u65e5",
+	0x33f3:  "2O\u65e5",
+	0x33f4:  "2l\u65e5",
+	0x33f5:  "22\u65e5",
+	0x33f6:  "23\u65e5",
+	0x33f7:  "24\u65e5",
+	0x33f8:  "25\u65e5",
+	0x33f9:  "26\u65e5",
+	0x33fa:  "27\u65e5",
+	0x33fb:  "28\u65e5",
+	0x33fc:  "29\u65e5",
+	0x33fd:  "3O\u65e5",
+	0x33fe:  "3l\u65e5",
+	0x39b3:  "\u363d",
+	0x439b:  "\u3588",
+	0x4420:  "\u3b3b",
+	0x4e00:  "\u30fc",
+	0x4e36:  "\\",
+	0x4e3f:  "/",
+	0x5002:  "\u4f75",
+	0x503c:  "\u5024",
+	0x555f:  "\u5553",
+	0x56d7:  "\u53e3",
+	0x586b:  "\u5861",
+	0x58eb:  "\u571f",
+	0x58ff:  "\u58ab",
+	0x5b00:  "\u5aaf",
+	0x5e32:  "\u5e21",
+	0x5e50:  "\u3b3a",
+	0x6238:  "\u6236",
+	0x6409:  "\u3a41",
+	0x6663:  "\u403f",
+	0x6669:  "\u665a",
+	0x66f6:  "\u3ada",
+	0x6726:  "\u4443",
+	0x67ff:  "\u676e",
+	0x69e9:  "\u3ba3",
+	0x6a27:  "\u699d",
+	0x6f59:  "\u6e88",
+	0x784f:  "\u7814",
+	0x7d76:  "\u7d55",
+	0x80a6:  "\u670c",
+	0x80ca:  "\u6710",
+	0x80d0:  "\u670f",
+	0x80f6:  "\u3b35",
+	0x8101:  "\u6713",
+	0x8127:  "\u6718",
+	0x8141:  "\u80fc",
+	0x81a7:  "\u6723",
+	0x853f:  "\u848d",
+	0x8641:  "\u8637",
+	0x8a1e:  "\u46b6",
+	0x8a7d:  "\u8a2e",
+	0x8b8f:  "\u8b86",
+	0x8c63:  "\u8c5c",
+	0x8d86:  "\u8d7f",
+	0x8dfa:  "\u8de5",
+	0x8e9b:  "\u8e97",
+	0x8f27:  "\u8eff",
+	0x90de:  "\u90ce",
+	0x93ae:  "\u93ad",
+	0x96b8:  "\u96b7",
+	0x9e43:  "\u9e42",
+	0x9ed2:  "\u9ed1",
+	0x9fc3:  "\u4039",
+	0xa494:  "\ua2cd",
+	0xa49c:  "\ua0c0",
+	0xa49e:  "\ua04a",
+	0xa4a7:  "\ua458",
+	0xa4a8:  "\ua132",
+	0xa4ac:  "\ua050",
+	0xa4b0:  "\ua3c2",
+	0xa4ba:  "\ua3bf",
+	0xa4be:  "\ua2b1",
+	0xa4bf:  "\ua259",
+	0xa4c0:  "\ua3ab",
+	0xa4c2:  "\ua3b5",
+	0xa4d0:  "B",
+	0xa4d1:  "P",
+	0xa4d2:  "d",
+	0xa4d3:  "D",
+	0xa4d4:  "T",
+	0xa4d6:  "G",
+	0xa4d7:  "K",
+	0xa4d9:  "J",
+	0xa4da:  "C",
+	0xa4db:  "\u0186",
+	0xa4dc:  "Z",
+	0xa4dd:  "F",
+	0xa4de:  "\u2132",
+	0xa4df:  "M",
+	0xa4e0:  "N",
+	0xa4e1:  "L",
+	0xa4e2:  "S",
+	0xa4e3:  "R",
+	0xa4e5:  "\u0245",
+	0xa4e6:  "V",
+	0xa4e7:  "H",
+	0xa4ea:  "W",
+	0xa4eb:  "X",
+	0xa4ec:  "Y",
+	0xa4ed:  "\u1660",
+	0xa4ee:  "A",
+	0xa4ef:  "\u2c6f",
+	0xa4f0:  "E",
+	0xa4f1:  "\u018e",
+	0xa4f2:  "l",
+	0xa4f3:  "O",
+	0xa4f4:  "U",
+	0xa4f5:  "\u0548",
+	0xa4f7:  "\u15e1",
+	0xa4f8:  ".",
+	0xa4f9:  ",",
+	0xa4fa:  "..",
+	0xa4fb:  ".,",
+	0xa4fd:  ":",
+	0xa4fe:  "-.",
+	0xa4ff:  "=",
+	0xa60e:  ".",
+	0xa644:  "2",
+	0xa645:  "\u01a8",
+	0xa647:  "i",
+	0xa64d:  "\u03c9",
+	0xa650:  "\u042al",
+	0xa651:  "\u02c9bi",
+	0xa668:  "\u0298",
+	0xa66f:  "\u20e9",
+	0xa67c:  "\u0306",
+	0xa67e:  "\u02c7",
+	0xa695:  "h\u0314",
+	0xa698:  "OO",
+	0xa699:  "oo",
+	0xa69a:  "\U000102a8",
+	0xa6a1:  "\u0418",
+	0xa6b0:  "\u16b9",
+	0xa6b1:  "\u2c75",
+	0xa6cd:  "\u02a1",
+	0xa6ce:  "\u0245",
+	0xa6db:  "\u03a0",
+	0xa6df:  "V",
+	0xa6eb:  "?",
+	0xa6ef:  "2",
+	0xa6f0:  "\u0302",
+	0xa6f1:  "\u0304",
+	0xa6f4:  "\ua6f3\ua6f3",
+	0xa714:  "\u02eb",
+	0xa716:  "\u02ea",
+	0xa728:  "T3",
+	0xa729:  "t\u021d",
+	0xa731:  "s",
+	0xa732:  "AA",
+	0xa733:  "aa",
+	0xa734:  "AO",
+	0xa735:  "ao",
+	0xa736:  "AU",
+	0xa737:  "au",
+	0xa738:  "AV",
+	0xa739:  "av",
+	0xa73a:  "AV",
+	0xa73b:  "av",
+	0xa73c:  "AY",
+	0xa73d:  "ay",
+	0xa740:  "K\u0335",
+	0xa74a:  "O\u0335",
+	0xa74b:  "o\u0335",
+	0xa74e:  "OO",
+	0xa74f:  "oo",
+	0xa75a:  "2",
+	0xa761:  "w\u0326",
+	0xa76a:  "3",
+	0xa76b:  "\u021d",
+	0xa76e:  "9",
+	0xa777:  "tf",
+	0xa778:  "&",
+	0xa77a:  "\ua779",
+	0xa789:  ":",
+	0xa78c:  "'",
+	0xa78f:  "\u00b7",
+	0xa795:  "\ua727",
+	0xa798:  "F",
+	0xa799:  "f",
+	0xa79a:  "\U00010412",
+	0xa79b:  "\U0001043a",
+	0xa79d:  "\u029a",
+	0xa79e:  "\ua4e4",
+	0xa79f:  "u",
+	0xa7ab:  "3",
+	0xa7b1:  "\ua4d5",
+	0xa7b2:  "J",
+	0xa7b3:  "X",
+	0xa7b4:  "B",
+	0xa7b5:  "\u00df",
+	0xa7b6:  "\ua64c",
+	0xa7b7:  "\u03c9",
+	0xa7f7:  "\u30fc",
+	0xa830:  "\u0964",
+	0xa960:  "\u1103\u1106",
+	0xa961:  "\u1103\u1107",
+	0xa962:  "\u1103\u1109",
+	0xa963:  "\u1103\u110c",
+	0xa964:  "\u1105\u1100",
+	0xa965:  "\u1105\u1100\u1100",
+	0xa966:  "\u1105\u1103",
+	0xa967:  "\u1105\u1103\u1103",
+	0xa968:  "\u1105\u1106",
+	0xa969:  "\u1105\u1107",
+	0xa96a:  "\u1105\u1107\u1107",
+	0xa96b:  "\u1105\u1107\u110b",
+	0xa96c:  "\u1105\u1109",
+	0xa96d:  "\u1105\u110c",
+	0xa96e:  "\u1105\u110f",
+	0xa96f:  "\u1106\u1100",
+	0xa970:  "\u1106\u1103",
+	0xa971:  "\u1106\u1109",
+	0xa972:  "\u1107\u1109\u1110",
+	0xa973:  "\u1107\u110f",
+	0xa974:  "\u1107\u1112",
+	0xa975:  "\u1109\u1109\u1107",
+	0xa976:  "\u110b\u1105",
+	0xa977:  "\u110b\u1112",
+	0xa978:  "\u110c\u110c\u1112",
+	0xa979:  "\u1110\u1110",
+	0xa97a:  "\u1111\u1112",
+	0xa97b:  "\u1112\u1109",
+	0xa97c:  "\u1159\u1159",
+	0xa992:  "\u2c3f",
+	0xa9a3:  "\ua99d",
+	0xa9c6:  "\ua9d0",
+	0xa9cf:  "\u0662",
+	0xaa53:  "\uaa01",
+	0xaa56:  "\uaa23",
+	0xab32:  "e",
+	0xab35:  "f",
+	0xab3d:  "o",
+	0xab3e:  "o\u0338",
+	0xab3f:  "\u0254\u0338",
+	0xab41:  "\u01ddo\u0338",
+	0xab42:  "\u01ddo\u0335",
+	0xab47:  "r",
+	0xab48:  "r",
+	0xab4d:  "\u0283",
+	0xab4e:  "u",
+	0xab52:  "u",
+	0xab53:  "\u03c7",
+	0xab55:  "\u03c7",
+	0xab5a:  "y",
+	0xab60:  "\u0459",
+	0xab62:  "\u0254e",
+	0xab63:  "uo",
+	0xab70:  "\u1d05",
+	0xab71:  "\u0280",
+	0xab72:  "\u1d1b",
+	0xab74:  "o\u031b",
+	0xab75:  "i",
+	0xab7a:  "\u1d00",
+	0xab7b:  "\u1d0a",
+	0xab7c:  "\u1d07",
+	0xab7e:  "\u0242",
+	0xab80:  "\u2c76",
+	0xab81:  "r",
+	0xab83:  "w",
+	0xab87:  "\u028d",
+	0xab8b:  "\u029c",
+	0xab8e:  "o\u0335",
+	0xab90:  "\u0262",
+	0xab93:  "z",
+	0xab9b:  "\ua793",
+	0xab9c:  "u\u0335",
+	0xab9f:  "\u0185",
+	0xaba2:  "\u0280",
+	0xaba9:  "v",
+	0xabaa:  "s",
+	0xabae:  "\u029f",
+	0xabaf:  "c",
+	0xabb2:  "\u1d18",
+	0xabb6:  "\u0138",
+	0xabbb:  "o\u0335",
+	0xd7b0:  "\u1169\u1167",
+	0xd7b1:  "\u1169\u1169\u4e28",
+	0xd7b2:  "\u116d\u1161",
+	0xd7b3:  "\u116d\u1161\u4e28",
+	0xd7b4:  "\u116d\u1165",
+	0xd7b5:  "\u116e\u1167",
+	0xd7b6:  "\u116e\u4e28\u4e28",
+	0xd7b7:  "\u1172\u1161\u4e28",
+	0xd7b8:  "\u1172\u1169",
+	0xd7b9:  "\u30fc\u1161",
+	0xd7ba:  "\u30fc\u1165",
+	0xd7bb:  "\u30fc\u1165\u4e28",
+	0xd7bc:  "\u30fc\u1169",
+	0xd7bd:  "\u4e28\u1163\u1169",
+	0xd7be:  "\u4e28\u1163\u4e28",
+	0xd7bf:  "\u4e28\u1167",
+	0xd7c0:  "\u4e28\u1167\u4e28",
+	0xd7c1:  "\u4e28\u1169\u4e28",
+	0xd7c2:  "\u4e28\u116d",
+	0xd7c3:  "\u4e28\u1172",
+	0xd7c4:  "\u4e28\u4e28",
+	0xd7c5:  "\u119e\u1161",
+	0xd7c6:  "\u119e\u1165\u4e28",
+	0xd7cb:  "\u1102\u1105",
+	0xd7cc:  "\u1102\u110e",
+	0xd7cd:  "\u1103\u1103",
+	0xd7ce:  "\u1103\u1103\u1107",
+	0xd7cf:  "\u1103\u1107",
+	0xd7d0:  "\u1103\u1109",
+	0xd7d1:  "\u1103\u1109\u1100",
+	0xd7d2:  "\u1103\u110c",
+	0xd7d3:  "\u1103\u110e",
+	0xd7d4:  "\u1103\u1110",
+	0xd7d5:  "\u1105\u1100\u1100",
+	0xd7d6:  "\u1105\u1100\u1112",
+	0xd7d7:  "\u1105\u1105\u110f",
+	0xd7d8:  "\u1105\u1106\u1112",
+	0xd7d9:  "\u1105\u1107\u1103",
+	0xd7da:  "\u1105\u1107\u1111",
+	0xd7db:  "\u1105\u114c",
+	0xd7dc:  "\u1105\u1159\u1112",
+	0xd7dd:  "\u1105\u110b",
+	0xd7de:  "\u1106\u1102",
+	0xd7df:  "\u1106\u1102\u1102",
+	0xd7e0:  "\u1106\u1106",
+	0xd7e1:  "\u1106\u1107\u1109",
+	0xd7e2:  "\u1106\u110c",
+	0xd7e3:  "\u1107\u1103",
+	0xd7e4:  "\u1107\u1105\u1111",
+	0xd7e5:  "\u1107\u1106",
+	0xd7e6:  "\u1107\u1107",
+	0xd7e7:  "\u1107\u1109\u1103",
+	0xd7e8:  "\u1107\u110c",
+	0xd7e9:  "\u1107\u110e",
+	0xd7ea:  "\u1109\u1106",
+	0xd7eb:  "\u1109\u1107\u110b",
+	0xd7ec:  "\u1109\u1109\u1100",
+	0xd7ed:  "\u1109\u1109\u1103",
+	0xd7ee:  "\u1109\u1140",
+	0xd7ef:  "\u1109\u110c",
+	0xd7f0:  "\u1109\u110e",
+	0xd7f1:  "\u1109\u1110",
+	0xd7f2:  "\u1105\u1112",
+	0xd7f3:  "\u1140\u1107",
+	0xd7f4:  "\u1140\u1107\u110b",
+	0xd7f5:  "\u114c\u1106",
+	0xd7f6:  "\u114c\u1112",
+	0xd7f7:  "\u110c\u1107",
+	0xd7f8:  "\u110c\u1107\u1107",
+	0xd7f9:  "\u110c\u110c",
+	0xd7fa:  "\u1111\u1109",
+	0xd7fb:  "\u1111\u1110",
+	0xf900:  "\u8c48",
+	0xf901:  "\u66f4",
+	0xf902:  "\u8eca",
+	0xf903:  "\u8cc8",
+	0xf904:  "\u6ed1",
+	0xf905:  "\u4e32",
+	0xf906:  "\u53e5",
+	0xf907:  "\u9f9c",
+	0xf908:  "\u9f9c",
+	0xf909:  "\u5951",
+	0xf90a:  "\u91d1",
+	0xf90b:  "\u5587",
+	0xf90c:  "\u5948",
+	0xf90d:  "\u61f6",
+	0xf90e:  "\u7669",
+	0xf90f:  "\u7f85",
+	0xf910:  "\u863f",
+	0xf911:  "\u87ba",
+	0xf912:  "\u88f8",
+	0xf913:  "\u908f",
+	0xf914:  "\u6a02",
+	0xf915:  "\u6d1b",
+	0xf916:  "\u70d9",
+	0xf917:  "\u73de",
+	0xf918:  "\u843d",
+	0xf919:  "\u916a",
+	0xf91a:  "\u99f1",
+	0xf91b:  "\u4e82",
+	0xf91c:  "\u5375",
+	0xf91d:  "\u6b04",
+	0xf91e:  "\u721b",
+	0xf91f:  "\u862d",
+	0xf920:  "\u9e1e",
+	0xf921:  "\u5d50",
+	0xf922:  "\u6feb",
+	0xf923:  "\u85cd",
+	0xf924:  "\u8964",
+	0xf925:  "\u62c9",
+	0xf926:  "\u81d8",
+	0xf927:  "\u881f",
+	0xf928:  "\u5eca",
+	0xf929:  "\u6717",
+	0xf92a:  "\u6d6a",
+	0xf92b:  "\u72fc",
+	0xf92c:  "\u90ce",
+	0xf92d:  "\u4f86",
+	0xf92e:  "\u51b7",
+	0xf92f:  "\u52de",
+	0xf930:  "\u64c4",
+	0xf931:  "\u6ad3",
+	0xf932:  "\u7210",
+	0xf933:  "\u76e7",
+	0xf934:  "\u8001",
+	0xf935:  "\u8606",
+	0xf936:  "\u865c",
+	0xf937:  "\u8def",
+	0xf938:  "\u9732",
+	0xf939:  "\u9b6f",
+	0xf93a:  "\u9dfa",
+	0xf93b:  "\u788c",
+	0xf93c:  "\u797f",
+	0xf93d:  "\u7da0",
+	0xf93e:  "\u83c9",
+	0xf93f:  "\u9304",
+	0xf940:  "\u9e7f",
+	0xf941:  "\u8ad6",
+	0xf942:  "\u58df",
+	0xf943:  "\u5f04",
+	0xf944:  "\u7c60",
+	0xf945:  "\u807e",
+	0xf946:  "\u7262",
+	0xf947:  "\u78ca",
+	0xf948:  "\u8cc2",
+	0xf949:  "\u96f7",
+	0xf94a:  "\u58d8",
+	0xf94b:  "\u5c62",
+	0xf94c:  "\u6a13",
+	0xf94d:  "\u6dda",
+	0xf94e:  "\u6f0f",
+	0xf94f:  "\u7d2f",
+	0xf950:  "\u7e37",
+	0xf951:  "\u964b",
+	0xf952:  "\u52d2",
+	0xf953:  "\u808b",
+	0xf954:  "\u51dc",
+	0xf955:  "\u51cc",
+	0xf956:  "\u7a1c",
+	0xf957:  "\u7dbe",
+	0xf958:  "\u83f1",
+	0xf959:  "\u9675",
+	0xf95a:  "\u8b80",
+	0xf95b:  "\u62cf",
+	0xf95c:  "\u6a02",
+	0xf95d:  "\u8afe",
+	0xf95e:  "\u4e39",
+	0xf95f:  "\u5be7",
+	0xf960:  "\u6012",
+	0xf961:  "\u7387",
+	0xf962:  "\u7570",
+	0xf963:  "\u5317",
+	0xf964:  "\u78fb",
+	0xf965:  "\u4fbf",
+	0xf966:  "\u5fa9",
+	0xf967:  "\u4e0d",
+	0xf968:  "\u6ccc",
+	0xf969:  "\u6578",
+	0xf96a:  "\u7d22",
+	0xf96b:  "\u53c3",
+	0xf96c:  "\u585e",
+	0xf96d:  "\u7701",
+	0xf96e:  "\u8449",
+	0xf96f:  "\u8aaa",
+	0xf970:  "\u6bba",
+	0xf971:  "\u8fb0",
+	0xf972:  "\u6c88",
+	0xf973:  "\u62fe",
+	0xf974:  "\u82e5",
+	0xf975:  "\u63a0",
+	0xf976:  "\u7565",
+	0xf977:  "\u4eae",
+	0xf978:  "\u5169",
+	0xf979:  "\u51c9",
+	0xf97a:  "\u6881",
+	0xf97b:  "\u7ce7",
+	0xf97c:  "\u826f",
+	0xf97d:  "\u8ad2",
+	0xf97e:  "\u91cf",
+	0xf97f:  "\u52f5",
+	0xf980:  "\u5442",
+	0xf981:  "\u5973",
+	0xf982:  "\u5eec",
+	0xf983:  "\u65c5",
+	0xf984:  "\u6ffe",
+	0xf985:  "\u792a",
+	0xf986:  "\u95ad",
+	0xf987:  "\u9a6a",
+	0xf988:  "\u9e97",
+	0xf989:  "\u9ece",
+	0xf98a:  "\u529b",
+	0xf98b:  "\u66c6",
+	0xf98c:  "\u6b77",
+	0xf98d:  "\u8f62",
+	0xf98e:  "\u5e74",
+	0xf98f:  "\u6190",
+	0xf990:  "\u6200",
+	0xf991:  "\u649a",
+	0xf992:  "\u6f23",
+	0xf993:  "\u7149",
+	0xf994:  "\u7489",
+	0xf995:  "\u79ca",
+	0xf996:  "\u7df4",
+	0xf997:  "\u806f",
+	0xf998:  "\u8f26",
+	0xf999:  "\u84ee",
+	0xf99a:  "\u9023",
+	0xf99b:  "\u934a",
+	0xf99c:  "\u5217",
+	0xf99d:  "\u52a3",
+	0xf99e:  "\u54bd",
+	0xf99f:  "\u70c8",
+	0xf9a0:  "\u88c2",
+	0xf9a1:  "\u8aaa",
+	0xf9a2:  "\u5ec9",
+	0xf9a3:  "\u5ff5",
+	0xf9a4:  "\u637b",
+	0xf9a5:  "\u6bae",
+	0xf9a6:  "\u7c3e",
+	0xf9a7:  "\u7375",
+	0xf9a8:  "\u4ee4",
+	0xf9a9:  "\u56f9",
+	0xf9aa:  "\u5be7",
+	0xf9ab:  "\u5dba",
+	0xf9ac:  "\u601c",
+	0xf9ad:  "\u73b2",
+	0xf9ae:  "\u7469",
+	0xf9af:  "\u7f9a",
+	0xf9b0:  "\u8046",
+	0xf9b1:  "\u9234",
+	0xf9b2:  "\u96f6",
+	0xf9b3:  "\u9748",
+	0xf9b4:  "\u9818",
+	0xf9b5:  "\u4f8b",
+	0xf9b6:  "\u79ae",
+	0xf9b7:  "\u91b4",
+	0xf9b8:  "\u96b7",
+	0xf9b9:  "\u60e1",
+	0xf9ba:  "\u4e86",
+	0xf9bb:  "\u50da",
+	0xf9bc:  "\u5bee",
+	0xf9bd:  "\u5c3f",
+	0xf9be:  "\u6599",
+	0xf9bf:  "\u6a02",
+	0xf9c0:  "\u71ce",
+	0xf9c1:  "\u7642",
+	0xf9c2:  "\u84fc",
+	0xf9c3:  "\u907c",
+	0xf9c4:  "\u9f8d",
+	0xf9c5:  "\u6688",
+	0xf9c6:  "\u962e",
+	0xf9c7:  "\u5289",
+	0xf9c8:  "\u677b",
+	0xf9c9:  "\u67f3",
+	0xf9ca:  "\u6d41",
+	0xf9cb:  "\u6e9c",
+	0xf9cc:  "\u7409",
+	0xf9cd:  "\u7559",
+	0xf9ce:  "\u786b",
+	0xf9cf:  "\u7d10",
+	0xf9d0:  "\u985e",
+	0xf9d1:  "\u516d",
+	0xf9d2:  "\u622e",
+	0xf9d3:  "\u9678",
+	0xf9d4:  "\u502b",
+	0xf9d5:  "\u5d19",
+	0xf9d6:  "\u6dea",
+	0xf9d7:  "\u8f2a",
+	0xf9d8:  "\u5f8b",
+	0xf9d9:  "\u6144",
+	0xf9da:  "\u6817",
+	0xf9db:  "\u7387",
+	0xf9dc:  "\u9686",
+	0xf9dd:  "\u5229",
+	0xf9de:  "\u540f",
+	0xf9df:  "\u5c65",
+	0xf9e0:  "\u6613",
+	0xf9e1:  "\u674e",
+	0xf9e2:  "\u68a8",
+	0xf9e3:  "\u6ce5",
+	0xf9e4:  "\u7406",
+	0xf9e5:  "\u75e2",
+	0xf9e6:  "\u7f79",
+	0xf9e7:  "\u88cf",
+	0xf9e8:  "\u88e1",
+	0xf9e9:  "\u91cc",
+	0xf9ea:  "\u96e2",
+	0xf9eb:  "\u533f",
+	0xf9ec:  "\u6eba",
+	0xf9ed:  "\u541d",
+	0xf9ee:  "\u71d0",
+	0xf9ef:  "\u7498",
+	0xf9f0:  "\u85fa",
+	0xf9f1:  "\u96a3",
+	0xf9f2:  "\u9c57",
+	0xf9f3:  "\u9e9f",
+	0xf9f4:  "\u6797",
+	0xf9f5:  "\u6dcb",
+	0xf9f6:  "\u81e8",
+	0xf9f7:  "\u7acb",
+	0xf9f8:  "\u7b20",
+	0xf9f9:  "\u7c92",
+	0xf9fa:  "\u72c0",
+	0xf9fb:  "\u7099",
+	0xf9fc:  "\u8b58",
+	0xf9fd:  "\u4ec0",
+	0xf9fe:  "\u8336",
+	0xf9ff:  "\u523a",
+	0xfa00:  "\u5207",
+	0xfa01:  "\u5ea6",
+	0xfa02:  "\u62d3",
+	0xfa03:  "\u7cd6",
+	0xfa04:  "\u5b85",
+	0xfa05:  "\u6d1e",
+	0xfa06:  "\u66b4",
+	0xfa07:  "\u8f3b",
+	0xfa08:  "\u884c",
+	0xfa09:  "\u964d",
+	0xfa0a:  "\u898b",
+	0xfa0b:  "\u5ed3",
+	0xfa0c:  "\u5140",
+	0xfa0d:  "\u55c0",
+	0xfa10:  "\u585a",
+	0xfa12:  "\u6674",
+	0xfa15:  "\u51de",
+	0xfa16:  "\u732a",
+	0xfa17:  "\u76ca",
+	0xfa18:  "\u793c",
+	0xfa19:  "\u795e",
+	0xfa1a:  "\u7965",
+	0xfa1b:  "\u798f",
+	0xfa1c:  "\u9756",
+	0xfa1d:  "\u7cbe",
+	0xfa1e:  "\u7fbd",
+	0xfa20:  "\u8612",
+	0xfa22:  "\u8af8",
+	0xfa25:  "\u9038",
+	0xfa26:  "\u90fd",
+	0xfa2a:  "\u98ef",
+	0xfa2b:  "\u98fc",
+	0xfa2c:  "\u9928",
+	0xfa2d:  "\u9db4",
+	0xfa2e:  "\u90ce",
+	0xfa2f:  "\u96b7",
+	0xfa30:  "\u4fae",
+	0xfa31:  "\u50e7",
+	0xfa32:  "\u514d",
+	0xfa33:  "\u52c9",
+	0xfa34:  "\u52e4",
+	0xfa35:  "\u5351",
+	0xfa36:  "\u559d",
+	0xfa37:  "\u5606",
+	0xfa38:  "\u5668",
+	0xfa39:  "\u5840",
+	0xfa3a:  "\u58a8",
+	0xfa3b:  "\u5c64",
+	0xfa3c:  "\u5c6e",
+	0xfa3d:  "\u6094",
+	0xfa3e:  "\u6168",
+	0xfa3f:  "\u618e",
+	0xfa40:  "\u61f2",
+	0xfa41:  "\u654f",
+	0xfa42:  "\u65e2",
+	0xfa43:  "\u6691",
+	0xfa44:  "\u6885",
+	0xfa45:  "\u6d77",
+	0xfa46:  "\u6e1a",
+	0xfa47:  "\u6f22",
+	0xfa48:  "\u716e",
+	0xfa49:  "\u722b",
+	0xfa4a:  "\u7422",
+	0xfa4b:  "\u7891",
+	0xfa4c:  "\u793e",
+	0xfa4d:  "\u7949",
+	0xfa4e:  "\u7948",
+	0xfa4f:  "\u7950",
+	0xfa50:  "\u7956",
+	0xfa51:  "\u795d",
+	0xfa52:  "\u798d",
+	0xfa53:  "\u798e",
+	0xfa54:  "\u7a40",
+	0xfa55:  "\u7a81",
+	0xfa56:  "\u7bc0",
+	0xfa57:  "\u7df4",
+	0xfa58:  "\u7e09",
+	0xfa59:  "\u7e41",
+	0xfa5a:  "\u7f72",
+	0xfa5b:  "\u8005",
+	0xfa5c:  "\u81ed",
+	0xfa5d:  "\u8279",
+	0xfa5e:  "\u8279",
+	0xfa5f:  "\u8457",
+	0xfa60:  "\u8910",
+	0xfa61:  "\u8996",
+	0xfa62:  "\u8b01",
+	0xfa63:  "\u8b39",
+	0xfa64:  "\u8cd3",
+	0xfa65:  "\u8d08",
+	0xfa66:  "\u8fb6",
+	0xfa67:  "\u9038",
+	0xfa68:  "\u96e3",
+	0xfa69:  "\u97ff",
+	0xfa6a:  "\u983b",
+	0xfa6b:  "\u6075",
+	0xfa6c:  "\U000242ee",
+	0xfa6d:  "\u8218",
+	0xfa70:  "\u4e26",
+	0xfa71:  "\u51b5",
+	0xfa72:  "\u5168",
+	0xfa73:  "\u4f80",
+	0xfa74:  "\u5145",
+	0xfa75:  "\u5180",
+	0xfa76:  "\u52c7",
+	0xfa77:  "\u52fa",
+	0xfa78:  "\u559d",
+	0xfa79:  "\u5555",
+	0xfa7a:  "\u5599",
+	0xfa7b:  "\u55e2",
+	0xfa7c:  "\u585a",
+	0xfa7d:  "\u58b3",
+	0xfa7e:  "\u5944",
+	0xfa7f:  "\u5954",
+	0xfa80:  "\u5a62",
+	0xfa81:  "\u5b28",
+	0xfa82:  "\u5ed2",
+	0xfa83:  "\u5ed9",
+	0xfa84:  "\u5f69",
+	0xfa85:  "\u5fad",
+	0xfa86:  "\u60d8",
+	0xfa87:  "\u614e",
+	0xfa88:  "\u6108",
+	0xfa89:  "\u618e",
+	0xfa8a:  "\u6160",
+	0xfa8b:  "\u61f2",
+	0xfa8c:  "\u6234",
+	0xfa8d:  "\u63c4",
+	0xfa8e:  "\u641c",
+	0xfa8f:  "\u6452",
+	0xfa90:  "\u6556",
+	0xfa91:  "\u6674",
+	0xfa92:  "\u6717",
+	0xfa93:  "\u671b",
+	0xfa94:  "\u6756",
+	0xfa95:  "\u6b79",
+	0xfa96:  "\u6bba",
+	0xfa97:  "\u6d41",
+	0xfa98:  "\u6edb",
+	0xfa99:  "\u6ecb",
+	0xfa9a:  "\u6f22",
+	0xfa9b:  "\u701e",
+	0xfa9c:  "\u716e",
+	0xfa9d:  "\u77a7",
+	0xfa9e:  "\u7235",
+	0xfa9f:  "\u72af",
+	0xfaa0:  "\u732a",
+	0xfaa1:  "\u7471",
+	0xfaa2:  "\u7506",
+	0xfaa3:  "\u753b",
+	0xfaa4:  "\u761d",
+	0xfaa5:  "\u761f",
+	0xfaa6:  "\u76ca",
+	0xfaa7:  "\u76db",
+	0xfaa8:  "\u76f4",
+	0xfaa9:  "\u774a",
+	0xfaaa:  "\u7740",
+	0xfaab:  "\u78cc",
+	0xfaac:  "\u7ab1",
+	0xfaad:  "\u7bc0",
+	0xfaae:  "\u7c7b",
+	0xfaaf:  "\u7d5b",
+	0xfab0:  "\u7df4",
+	0xfab1:  "\u7f3e",
+	0xfab2:  "\u8005",
+	0xfab3:  "\u8352",
+	0xfab4:  "\u83ef",
+	0xfab5:  "\u8779",
+	0xfab6:  "\u8941",
+	0xfab7:  "\u8986",
+	0xfab8:  "\u8996",
+	0xfab9:  "\u8abf",
+	0xfaba:  "\u8af8",
+	0xfabb:  "\u8acb",
+	0xfabc:  "\u8b01",
+	0xfabd:  "\u8afe",
+	0xfabe:  "\u8aed",
+	0xfabf:  "\u8b39",
+	0xfac0:  "\u8b8a",
+	0xfac1:  "\u8d08",
+	0xfac2:  "\u8f38",
+	0xfac3:  "\u9072",
+	0xfac4:  "\u9199",
+	0xfac5:  "\u9276",
+	0xfac6:  "\u967c",
+	0xfac7:  "\u96e3",
+	0xfac8:  "\u9756",
+	0xfac9:  "\u97db",
+	0xfaca:  "\u97ff",
+	0xfacb:  "\u980b",
+	0xfacc:  "\u983b",
+	0xfacd:  "\u9b12",
+	0xface:  "\u9f9c",
+	0xfacf:  "\U0002284a",
+	0xfad0:  "\U00022844",
+	0xfad1:  "\U000233d5",
+	0xfad2:  "\u3b9d",
+	0xfad3:  "\u4018",
+	0xfad4:  "\u4039",
+	0xfad5:  "\U00025249",
+	0xfad6:  "\U00025cd0",
+	0xfad7:  "\U00027ed3",
+	0xfad8:  "\u9f43",
+	0xfad9:  "\u9f8e",
+	0xfb00:  "ff",
+	0xfb01:  "fi",
+	0xfb02:  "fl",
+	0xfb03:  "ffi",
+	0xfb04:  "ffl",
+	0xfb06:  "st",
+	0xfb13:  "\u0574\u0576",
+	0xfb14:  "\u0574\u0565",
+	0xfb15:  "\u0574\u056b",
+	0xfb16:  "\u057e\u0576",
+	0xfb17:  "\u0574\u056d",
+	0xfb20:  "\u05e2",
+	0xfb21:  "\u05d0",
+	0xfb22:  "\u05d3",
+	0xfb23:  "\u05d4",
+	0xfb24:  "\u05db",
+	0xfb25:  "\u05dc",
+	0xfb26:  "\u05dd",
+	0xfb27:  "\u05e8",
+	0xfb28:  "\u05ea",
+	0xfb29:  "-\u0307",
+	0xfb2b:  "\ufb2a",
+	0xfb2d:  "\ufb2c",
+	0xfb2f:  "\ufb2e",
+	0xfb30:  "\ufb2e",
+	0xfb39:  "\ufb1d",
+	0xfb49:  "\ufb2a",
+	0xfb4f:  "\u05d0\u05dc",
+	0xfb50:  "\u0671",
+	0xfb51:  "\u0671",
+	0xfb52:  "\u067b",
+	0xfb53:  "\u067b",
+	0xfb54:  "\u067b",
+	0xfb55:  "\u067b",
+	0xfb56:  "\u0649\u06db",
+	0xfb57:  "\u0649\u06db",
+	0xfb58:  "\u0649\u06db",
+	0xfb59:  "\u0649\u06db",
+	0xfb5a:  "\u0680",
+	0xfb5b:  "\u0680",
+	0xfb5c:  "\u0680",
+	0xfb5d:  "\u0680",
+	0xfb5e:  "\u067a",
+	0xfb5f:  "\u067a",
+	0xfb60:  "\u067a",
+	0xfb61:  "\u067a",
+	0xfb62:  "\u067f",
+	0xfb63:  "\u067f",
+	0xfb64:  "\u067f",
+	0xfb65:  "\u067f",
+	0xfb66:  "\u0649\u0615",
+	0xfb67:  "\u0649\u0615",
+	0xfb68:  "\u0649\u0615",
+	0xfb69:  "\u0649\u0615",
+	0xfb6a:  "\u06a1\u06db",
+	0xfb6b:  "\u06a1\u06db",
+	0xfb6c:  "\u06a1\u06db",
+	0xfb6d:  "\u06a1\u06db",
+	0xfb6e:  "\u06a6",
+	0xfb6f:  "\u06a6",
+	0xfb70:  "\u06a6",
+	0xfb71:  "\u06a6",
+	0xfb72:  "\u0684",
+	0xfb73:  "\u0684",
+	0xfb74:  "\u0684",
+	0xfb75:  "\u0684",
+	0xfb76:  "\u0683",
+	0xfb77:  "\u0683",
+	0xfb78:  "\u0683",
+	0xfb79:  "\u0683",
+	0xfb7a:  "\u0686",
+	0xfb7b:  "\u0686",
+	0xfb7c:  "\u0686",
+	0xfb7d:  "\u0686",
+	0xfb7e:  "\u0687",
+	0xfb7f:  "\u0687",
+	0xfb80:  "\u0687",
+	0xfb81:  "\u0687",
+	0xfb82:  "\u068d",
+	0xfb83:  "\u068d",
+	0xfb84:  "\u068c",
+	0xfb85:  "\u068c",
+	0xfb86:  "\u062f\u06db",
+	0xfb87:  "\u062f\u06db",
+	0xfb88:  "\u062f\u0615",
+	0xfb89:  "\u062f\u0615",
+	0xfb8a:  "\u0631\u06db",
+	0xfb8b:  "\u0631\u06db",
+	0xfb8c:  "\u0631\u0615",
+	0xfb8d:  "\u0631\u0615",
+	0xfb8e:  "\u0643",
+	0xfb8f:  "\u0643",
+	0xfb90:  "\u0643",
+	0xfb91:  "\u0643",
+	0xfb92:  "\u06af",
+	0xfb93:  "\u06af",
+	0xfb94:  "\u06af",
+	0xfb95:  "\u06af",
+	0xfb96:  "\u06b3",
+	0xfb97:  "\u06b3",
+	0xfb98:  "\u06b3",
+	0xfb99:  "\u06b3",
+	0xfb9a:  "\u06b1",
+	0xfb9b:  "\u06b1",
+	0xfb9c:  "\u06b1",
+	0xfb9d:  "\u06b1",
+	0xfb9e:  "\u0649",
+	0xfb9f:  "\u0649",
+	0xfba0:  "\u0649\u0615",
+	0xfba1:  "\u0649\u0615",
+	0xfba2:  "\u0649\u0615",
+	0xfba3:  "\u0649\u0615",
+	0xfba4:  "\u06c0",
+	0xfba5:  "\u06c0",
+	0xfba6:  "o",
+	0xfba7:  "o",
+	0xfba8:  "o",
+	0xfba9:  "o",
+	0xfbaa:  "o",
+	0xfbab:  "o",
+	0xfbac:  "o",
+	0xfbad:  "o",
+	0xfbae:  "\u0649",
+	0xfbaf:  "\u0649",
+	0xfbb0:  "\u06d3",
+	0xfbb1:  "\u06d3",
+	0xfbd3:  "\u0643\u06db",
+	0xfbd4:  "\u0643\u06db",
+	0xfbd5:  "\u0643\u06db",
+	0xfbd6:  "\u0643\u06db",
+	0xfbd7:  "\u0648\u0313",
+	0xfbd8:  "\u0648\u0313",
+	0xfbd9:  "\u0648\u0306",
+	0xfbda:  "\u0648\u0306",
+	0xfbdb:  "\u0648\u0670",
+	0xfbdc:  "\u0648\u0670",
+	0xfbdd:  "\u0648\u0313\u0674",
+	0xfbde:  "\u0648\u06db",
+	0xfbdf:  "\u0648\u06db",
+	0xfbe0:  "\u06c5",
+	0xfbe1:  "\u06c5",
+	0xfbe2:  "\u0648\u0302",
+	0xfbe3:  "\u0648\u0302",
+	0xfbe4:  "\u067b",
+	0xfbe5:  "\u067b",
+	0xfbe6:  "\u067b",
+	0xfbe7:  "\u067b",
+	0xfbe8:  "\u0649",
+	0xfbe9:  "\u0649",
+	0xfbea:  "\u0649\u0674l",
+	0xfbeb:  "\u0649\u0674l",
+	0xfbec:  "\u0649\u0674o",
+	0xfbed:  "\u0649\u0674o",
+	0xfbee:  "\u0649\u0674\u0648",
+	0xfbef:  "\u0649\u0674\u0648",
+	0xfbf0:  "\u0649\u0674\u0648\u0313",
+	0xfbf1:  "\u0649\u0674\u0648\u0313",
+	0xfbf2:  "\u0649\u0674\u0648\u0306",
+	0xfbf3:  "\u0649\u0674\u0648\u0306",
+	0xfbf4:  "\u0649\u0674\u0648\u0670",
+	0xfbf5:  "\u0649\u0674\u0648\u0670",
+	0xfbf6:  "\u0649\u0674\u067b",
+	0xfbf7:  "\u0649\u0674\u067b",
+	0xfbf8:  "\u0649\u0674\u067b",
+	0xfbf9:  "\u0649\u0674\u0649",
+	0xfbfa:  "\u0649\u0674\u0649",
+	0xfbfb:  "\u0649\u0674\u0649",
+	0xfbfc:  "\u0649",
+	0xfbfd:  "\u0649",
+	0xfbfe:  "\u0649",
+	0xfbff:  "\u0649",
+	0xfc00:  "\u0649\u0674\u062c",
+	0xfc01:  "\u0649\u0674\u062d",
+	0xfc02:  "\u0649\u0674\u0645",
+	0xfc03:  "\u0649\u0674\u0649",
+	0xfc04:  "\u0649\u0674\u0649",
+	0xfc05:  "\u0628\u062c",
+	0xfc06:  "\u0628\u062d",
+	0xfc07:  "\u0628\u062e",
+	0xfc08:  "\u0628\u0645",
+	0xfc09:  "\u0628\u0649",
+	0xfc0a:  "\u0628\u0649",
+	0xfc0b:  "\u062a\u062c",
+	0xfc0c:  "\u062a\u062d",
+	0xfc0d:  "\u062a\u062e",
+	0xfc0e:  "\u062a\u0645",
+	0xfc0f:  "\u062a\u0649",
+	0xfc10:  "\u062a\u0649",
+	0xfc11:  "\u0649\u06db\u062c",
+	0xfc12:  "\u0649\u06db\u0645",
+	0xfc13:  "\u0649\u06db\u0649",
+	0xfc14:  "\u0649\u06db\u0649",
+	0xfc15:  "\u062c\u062d",
+	0xfc16:  "\u062c\u0645",
+	0xfc17:  "\u062d\u062c",
+	0xfc18:  "\u062d\u0645",
+	0xfc19:  "\u062e\u062c",
+	0xfc1a:  "\u062e\u062d",
+	0xfc1b:  "\u062e\u0645",
+	0xfc1c:  "\u0633\u062c",
+	0xfc1d:  "\u0633\u062d",
+	0xfc1e:  "\u0633\u062e",
+	0xfc1f:  "\u0633\u0645",
+	0xfc20:  "\u0635\u062d",
+	0xfc21:  "\u0635\u0645",
+	0xfc22:  "\u0636\u062c",
+	0xfc23:  "\u0636\u062d",
+	0xfc24:  "\u0636\u062e",
+	0xfc25:  "\u0636\u0645",
+	0xfc26:  "\u0637\u062d",
+	0xfc27:  "\u0637\u0645",
+	0xfc28:  "\u0638\u0645",
+	0xfc29:  "\u0639\u062c",
+	0xfc2a:  "\u0639\u0645",
+	0xfc2b:  "\u063a\u062c",
+	0xfc2c:  "\u063a\u0645",
+	0xfc2d:  "\u0641\u062c",
+	0xfc2e:  "\u0641\u062d",
+	0xfc2f:  "\u0641\u062e",
+	0xfc30:  "\u0641\u0645",
+	0xfc31:  "\u0641\u0649",
+	0xfc32:  "\u0641\u0649",
+	0xfc33:  "\u0642\u062d",
+	0xfc34:  "\u0642\u0645",
+	0xfc35:  "\u0642\u0649",
+	0xfc36:  "\u0642\u0649",
+	0xfc37:  "\u0643l",
+	0xfc38:  "\u0643\u062c",
+	0xfc39:  "\u0643\u062d",
+	0xfc3a:  "\u0643\u062e",
+	0xfc3b:  "\u0643\u0644",
+	0xfc3c:  "\u0643\u0645",
+	0xfc3d:  "\u0643\u0649",
+	0xfc3e:  "\u0643\u0649",
+	0xfc3f:  "\u0644\u062c",
+	0xfc40:  "\u0644\u062d",
+	0xfc41:  "\u0644\u062e",
+	0xfc42:  "\u0644\u0645",
+	0xfc43:  "\u0644\u0649",
+	0xfc44:  "\u0644\u0649",
+	0xfc45:  "\u0645\u062c",
+	0xfc46:  "\u0645\u062d",
+	0xfc47:  "\u0645\u062e",
+	0xfc48:  "\u0645\u0645",
+	0xfc49:  "\u0645\u0649",
+	0xfc4a:  "\u0645\u0649",
+	0xfc4b:  "\u0628\u062e",
+	0xfc4c:  "\u0646\u062d",
+	0xfc4d:  "\u0646\u062e",
+	0xfc4e:  "\u0646\u0645",
+	0xfc4f:  "\u0646\u0649",
+	0xfc50:  "\u0646\u0649",
+	0xfc51:  "o\u062c",
+	0xfc52:  "o\u0645",
+	0xfc53:  "o\u0649",
+	0xfc54:  "o\u0649",
+	0xfc55:  "\u0649\u062c",
+	0xfc56:  "\u0649\u062d",
+	0xfc57:  "\u0649\u062e",
+	0xfc58:  "\u0649\u0645",
+	0xfc59:  "\u0649\u0649",
+	0xfc5a:  "\u0649\u0649",
+	0xfc5b:  "\u0630\u0670",
+	0xfc5c:  "\u0631\u0670",
+	0xfc5d:  "\u0649\u0670",
+	0xfc5e:  "\ufe72\u0651",
+	0xfc5f:  "\ufe74\u0651",
+	0xfc60:  "\ufe76\u0651",
+	0xfc61:  "\ufe78\u0651",
+	0xfc62:  "\ufe7a\u0651",
+	0xfc63:  "\ufe7c\u0670",
+	0xfc64:  "\u0649\u0674\u0631",
+	0xfc65:  "\u0649\u0674\u0632",
+	0xfc66:  "\u0649\u0674\u0645",
+	0xfc67:  "\u0649\u0674\u0646",
+	0xfc68:  "\u0649\u0674\u0649",
+	0xfc69:  "\u0649\u0674\u0649",
+	0xfc6a:  "\u0628\u0631",
+	0xfc6b:  "\u0628\u0632",
+	0xfc6c:  "\u0628\u0645",
+	0xfc6d:  "\u0628\u0646",
+	0xfc6e:  "\u0628\u0649",
+	0xfc6f:  "\u0628\u0649",
+	0xfc70:  "\u062a\u0631",
+	0xfc71:  "\u062a\u0632",
+	0xfc72:  "\u062a\u0645",
+	0xfc73:  "\u062a\u0646",
+	0xfc74:  "\u062a\u0649",
+	0xfc75:  "\u062a\u0649",
+	0xfc76:  "\u0649\u06db\u0631",
+	0xfc77:  "\u0649\u06db\u0632",
+	0xfc78:  "\u0649\u06db\u0645",
+	0xfc79:  "\u0649\u06db\u0646",
+	0xfc7a:  "\u0649\u06db\u0649",
+	0xfc7b:  "\u0649\u06db\u0649",
+	0xfc7c:  "\u0641\u0649",
+	0xfc7d:  "\u0641\u0649",
+	0xfc7e:  "\u0642\u0649",
+	0xfc7f:  "\u0642\u0649",
+	0xfc80:  "\u0643l",
+	0xfc81:  "\u0643\u0644",
+	0xfc82:  "\u0643\u0645",
+	0xfc83:  "\u0643\u0649",
+	0xfc84:  "\u0643\u0649",
+	0xfc85:  "\u0644\u0645",
+	0xfc86:  "\u0644\u0649",
+	0xfc87:  "\u0644\u0649",
+	0xfc88:  "\u0645l",
+	0xfc89:  "\u0645\u0645",
+	0xfc8a:  "\u0646\u0631",
+	0xfc8b:  "\u0646\u0632",
+	0xfc8c:  "\u0646\u0645",
+	0xfc8d:  "\u0646\u0646",
+	0xfc8e:  "\u0646\u0649",
+	0xfc8f:  "\u0646\u0649",
+	0xfc90:  "\u0649\u0670",
+	0xfc91:  "\u0649\u0631",
+	0xfc92:  "\u0649\u0632",
+	0xfc93:  "\u0649\u0645",
+	0xfc94:  "\u0649\u0646",
+	0xfc95:  "\u0649\u0649",
+	0xfc96:  "\u0649\u0649",
+	0xfc97:  "\u0649\u0674\u062c",
+	0xfc98:  "\u0649\u0674\u062d",
+	0xfc99:  "\u0649\u0674\u062e",
+	0xfc9a:  "\u0649\u0674\u0645",
+	0xfc9b:  "\u0649\u0674o",
+	0xfc9c:  "\u0628\u062c",
+	0xfc9d:  "\u0628\u062d",
+	0xfc9e:  "\u0628\u062e",
+	0xfc9f:  "\u0628\u0645",
+	0xfca0:  "\u0628o",
+	0xfca1:  "\u062a\u062c",
+	0xfca2:  "\u062a\u062d",
+	0xfca3:  "\u062a\u062e",
+	0xfca4:  "\u062a\u0645",
+	0xfca5:  "\u062ao",
+	0xfca6:  "\u0649\u06db\u0645",
+	0xfca7:  "\u062c\u062d",
+	0xfca8:  "\u062c\u0645",
+	0xfca9:  "\u062d\u062c",
+	0xfcaa:  "\u062d\u0645",
+	0xfcab:  "\u062e\u062c",
+	0xfcac:  "\u062e\u0645",
+	0xfcad:  "\u0633\u062c",
+	0xfcae:  "\u0633\u062d",
+	0xfcaf:  "\u0633\u062e",
+	0xfcb0:  "\u0633\u0645",
+	0xfcb1:  "\u0635\u062d",
+	0xfcb2:  "\u0635\u062e",
+	0xfcb3:  "\u0635\u0645",
+	0xfcb4:  "\u0636\u062c",
+	0xfcb5:  "\u0636\u062d",
+	0xfcb6:  "\u0636\u062e",
+	0xfcb7:  "\u0636\u0645",
+	0xfcb8:  "\u0637\u062d",
+	0xfcb9:  "\u0638\u0645",
+	0xfcba:  "\u0639\u062c",
+	0xfcbb:  "\u0639\u0645",
+	0xfcbc:  "\u063a\u062c",
+	0xfcbd:  "\u063a\u0645",
+	0xfcbe:  "\u0641\u062c",
+	0xfcbf:  "\u0641\u062d",
+	0xfcc0:  "\u0641\u062e",
+	0xfcc1:  "\u0641\u0645",
+	0xfcc2:  "\u0642\u062d",
+	0xfcc3:  "\u0642\u0645",
+	0xfcc4:  "\u0643\u062c",
+	0xfcc5:  "\u0643\u062d",
+	0xfcc6:  "\u0643\u062e",
+	0xfcc7:  "\u0643\u0644",
+	0xfcc8:  "\u0643\u0645",
+	0xfcc9:  "\u0644\u062c",
+	0xfcca:  "\u0644\u062d",
+	0xfccb:  "\u0644\u062e",
+	0xfccc:  "\u0644\u0645",
+	0xfccd:  "\u0644o",
+	0xfcce:  "\u0645\u062c",
+	0xfccf:  "\u0645\u062d",
+	0xfcd0:  "\u0645\u062e",
+	0xfcd1:  "\u0645\u0645",
+	0xfcd2:  "\u0628\u062e",
+	0xfcd3:  "\u0646\u062d",
+	0xfcd4:  "\u0646\u062e",
+	0xfcd5:  "\u0646\u0645",
+	0xfcd6:  "\u0646o",
+	0xfcd7:  "o\u062c",
+	0xfcd8:  "o\u0645",
+	0xfcd9:  "o\u0670",
+	0xfcda:  "\u0649\u062c",
+	0xfcdb:  "\u0649\u062d",
+	0xfcdc:  "\u0649\u062e",
+	0xfcdd:  "\u0649\u0645",
+	0xfcde:  "\u0649o",
+	0xfcdf:  "\u0649\u0674\u0645",
+	0xfce0:  "\u0649\u0674o",
+	0xfce1:  "\u0628\u0645",
+	0xfce2:  "\u0628o",
+	0xfce3:  "\u062a\u0645",
+	0xfce4:  "\u062ao",
+	0xfce5:  "\u0649\u06db\u0645",
+	0xfce6:  "\u0649\u06dbo",
+	0xfce7:  "\u0633\u0645",
+	0xfce8:  "\u0633o",
+	0xfce9:  "\u0633\u06db\u0645",
+	0xfcea:  "\u0633\u06dbo",
+	0xfceb:  "\u0643\u0644",
+	0xfcec:  "\u0643\u0645",
+	0xfced:  "\u0644\u0645",
+	0xfcee:  "\u0646\u0645",
+	0xfcef:  "\u0646o",
+	0xfcf0:  "\u0649\u0645",
+	0xfcf1:  "\u0649o",
+	0xfcf2:  "\ufe77\u0651",
+	0xfcf3:  "\ufe79\u0651",
+	0xfcf4:  "\ufe7b\u0651",
+	0xfcf5:  "\u0637\u0649",
+	0xfcf6:  "\u0637\u0649",
+	0xfcf7:  "\u0639\u0649",
+	0xfcf8:  "\u0639\u0649",
+	0xfcf9:  "\u063a\u0649",
+	0xfcfa:  "\u063a\u0649",
+	0xfcfb:  "\u0633\u0649",
+	0xfcfc:  "\u0633\u0649",
+	0xfcfd:  "\u0633\u06db\u0649",
+	0xfcfe:  "\u0633\u06db\u0649",
+	0xfcff:  "\u062d\u0649",
+	0xfd00:  "\u062d\u0649",
+	0xfd01:  "\u062c\u0649",
+	0xfd02:  "\u062c\u0649",
+	0xfd03:  "\u062e\u0649",
+	0xfd04:  "\u062e\u0649",
+	0xfd05:  "\u0635\u0649",
+	0xfd06:  "\u0635\u0649",
+	0xfd07:  "\u0636\u0649",
+	0xfd08:  "\u0636\u0649",
+	0xfd09:  "\u0633\u06db\u062c",
+	0xfd0a:  "\u0633\u06db\u062d",
+	0xfd0b:  "\u0633\u06db\u062e",
+	0xfd0c:  "\u0633\u06db\u0645",
+	0xfd0d:  "\u0633\u06db\u0631",
+	0xfd0e:  "\u0633\u0631",
+	0xfd0f:  "\u0635\u0631",
+	0xfd10:  "\u0636\u0631",
+	0xfd11:  "\u0637\u0649",
+	0xfd12:  "\u0637\u0649",
+	0xfd13:  "\u0639\u0649",
+	0xfd14:  "\u0639\u0649",
+	0xfd15:  "\u063a\u0649",
+	0xfd16:  "\u063a\u0649",
+	0xfd17:  "\u0633\u0649",
+	0xfd18:  "\u0633\u0649",
+	0xfd19:  "\u0633\u06db\u0649",
+	0xfd1a:  "\u0633\u06db\u0649",
+	0xfd1b:  "\u062d\u0649",
+	0xfd1c:  "\u062d\u0649",
+	0xfd1d:  "\u062c\u0649",
+	0xfd1e:  "\u062c\u0649",
+	0xfd1f:  "\u062e\u0649",
+	0xfd20:  "\u062e\u0649",
+	0xfd21:  "\u0635\u0649",
+	0xfd22:  "\u0635\u0649",
+	0xfd23:  "\u0636\u0649",
+	0xfd24:  "\u0636\u0649",
+	0xfd25:  "\u0633\u06db\u062c",
+	0xfd26:  "\u0633\u06db\u062d",
+	0xfd27:  "\u0633\u06db\u062e",
+	0xfd28:  "\u0633\u06db\u0645",
+	0xfd29:  "\u0633\u06db\u0631",
+	0xfd2a:  "\u0633\u0631",
+	0xfd2b:  "\u0635\u0631",
+	0xfd2c:  "\u0636\u0631",
+	0xfd2d:  "\u0633\u06db\u062c",
+	0xfd2e:  "\u0633\u06db\u062d",
+	0xfd2f:  "\u0633\u06db\u062e",
+	0xfd30:  "\u0633\u06db\u0645",
+	0xfd31:  "\u0633o",
+	0xfd32:  "\u0633\u06dbo",
+	0xfd33:  "\u0637\u0645",
+	0xfd34:  "\u0633\u062c",
+	0xfd35:  "\u0633\u062d",
+	0xfd36:  "\u0633\u062e",
+	0xfd37:  "\u0633\u06db\u062c",
+	0xfd38:  "\u0633\u06db\u062d",
+	0xfd39:  "\u0633\u06db\u062e",
+	0xfd3a:  "\u0637\u0645",
+	0xfd3b:  "\u0638\u0645",
+	0xfd3c:  "l\u030b",
+	0xfd3d:  "l\u030b",
+	0xfd3e:  "(",
+	0xfd3f:  ")",
+	0xfd50:  "\u062a\u062c\u0645",
+	0xfd51:  "\u062a\u062d\u062c",
+	0xfd52:  "\u062a\u062d\u062c",
+	0xfd53:  "\u062a\u062d\u0645",
+	0xfd54:  "\u062a\u062e\u0645",
+	0xfd55:  "\u062a\u0645\u062c",
+	0xfd56:  "\u062a\u0645\u062d",
+	0xfd57:  "\u062a\u0645\u062e",
+	0xfd58:  "\u062c\u0645\u062d",
+	0xfd59:  "\u062c\u0645\u062d",
+	0xfd5a:  "\u062d\u0645\u0649",
+	0xfd5b:  "\u062d\u0645\u0649",
+	0xfd5c:  "\u0633\u062d\u062c",
+	0xfd5d:  "\u0633\u062c\u062d",
+	0xfd5e:  "\u0633\u062c\u0649",
+	0xfd5f:  "\u0633\u0645\u062d",
+	0xfd60:  "\u0633\u0645\u062d",
+	0xfd61:  "\u0633\u0645\u062c",
+	0xfd62:  "\u0633\u0645\u0645",
+	0xfd63:  "\u0633\u0645\u0645",
+	0xfd64:  "\u0635\u062d\u062d",
+	0xfd65:  "\u0635\u062d\u062d",
+	0xfd66:  "\u0635\u0645\u0645",
+	0xfd67:  "\u0633\u06db\u062d\u0645",
+	0xfd68:  "\u0633\u06db\u062d\u0645",
+	0xfd69:  "\u0633\u06db\u062c\u0649",
+	0xfd6a:  "\u0633\u06db\u0645\u062e",
+	0xfd6b:  "\u0633\u06db\u0645\u062e",
+	0xfd6c:  "\u0633\u06db\u0645\u0645",
+	0xfd6d:  "\u0633\u06db\u0645\u0645",
+	0xfd6e:  "\u0636\u062d\u0649",
+	0xfd6f:  "\u0636\u062e\u0645",
+	0xfd70:  "\u0636\u062e\u0645",
+	0xfd71:  "\u0637\u0645\u062d",
+	0xfd72:  "\u0637\u0645\u062d",
+	0xfd73:  "\u0637\u0645\u0645",
+	0xfd74:  "\u0637\u0645\u0649",
+	0xfd75:  "\u0639\u062c\u0645",
+	0xfd76:  "\u0639\u0645\u0645",
+	0xfd77:  "\u0639\u0645\u0645",
+	0xfd78:  "\u0639\u0645\u0649",
+	0xfd79:  "\u063a\u0645\u0645",
+	0xfd7a:  "\u063a\u0645\u0649",
+	0xfd7b:  "\u063a\u0645\u0649",
+	0xfd7c:  "\u0641\u062e\u0645",
+	0xfd7d:  "\u0641\u062e\u0645",
+	0xfd7e:  "\u0642\u0645\u062d",
+	0xfd7f:  "\u0642\u0645\u0645",
+	0xfd80:  "\u0644\u062d\u0645",
+	0xfd81:  "\u0644\u062d\u0649",
+	0xfd82:  "\u0644\u062d\u0649",
+	0xfd83:  "\u0644\u062c\u062c",
+	0xfd84:  "\u0644\u062c\u062c",
+	0xfd85:  "\u0644\u062e\u0645",
+	0xfd86:  "\u0644\u062e\u0645",
+	0xfd87:  "\u0644\u0645\u062d",
+	0xfd88:  "\u0644\u0645\u062d",
+	0xfd89:  "\u0645\u062d\u062c",
+	0xfd8a:  "\u0645\u062d\u0645",
+	0xfd8b:  "\u0645\u062d\u0649",
+	0xfd8c:  "\u0645\u062c\u062d",
+	0xfd8d:  "\u0645\u062c\u0645",
+	0xfd8e:  "\u0645\u062e\u062c",
+	0xfd8f:  "\u0645\u062e\u0645",
+	0xfd92:  "\u0645\u062c\u062e",
+	0xfd93:  "o\u0645\u062c",
+	0xfd94:  "o\u0645\u0645",
+	0xfd95:  "\u0646\u062d\u0645",
+	0xfd96:  "\u0646\u062d\u0649",
+	0xfd97:  "\u0646\u062c\u0645",
+	0xfd98:  "\u0646\u062c\u0645",
+	0xfd99:  "\u0646\u062c\u0649",
+	0xfd9a:  "\u0646\u0645\u0649",
+	0xfd9b:  "\u0646\u0645\u0649",
+	0xfd9c:  "\u0649\u0645\u0645",
+	0xfd9d:  "\u0649\u0645\u0645",
+	0xfd9e:  "\u0628\u062e\u0649",
+	0xfd9f:  "\u062a\u062c\u0649",
+	0xfda0:  "\u062a\u062c\u0649",
+	0xfda1:  "\u062a\u062e\u0649",
+	0xfda2:  "\u062a\u062e\u0649",
+	0xfda3:  "\u062a\u0645\u0649",
+	0xfda4:  "\u062a\u0645\u0649",
+	0xfda5:  "\u062c\u0645\u0649",
+	0xfda6:  "\u062c\u062d\u0649",
+	0xfda7:  "\u062c\u0645\u0649",
+	0xfda8:  "\u0633\u062e\u0649",
+	0xfda9:  "\u0635\u062d\u0649",
+	0xfdaa:  "\u0633\u06db\u062d\u0649",
+	0xfdab:  "\u0636\u062d\u0649",
+	0xfdac:  "\u0644\u062c\u0649",
+	0xfdad:  "\u0644\u0645\u0649",
+	0xfdae:  "\u0649\u062d\u0649",
+	0xfdaf:  "\u0649\u062c\u0649",
+	0xfdb0:  "\u0649\u0645\u0649",
+	0xfdb1:  "\u0645\u0645\u0649",
+	0xfdb2:  "\u0642\u0645\u0649",
+	0xfdb3:  "\u0646\u062d\u0649",
+	0xfdb4:  "\u0642\u0645\u062d",
+	0xfdb5:  "\u0644\u062d\u0645",
+	0xfdb6:  "\u0639\u0645\u0649",
+	0xfdb7:  "\u0643\u0645\u0649",
+	0xfdb8:  "\u0646\u062c\u062d",
+	0xfdb9:  "\u0645\u062e\u0649",
+	0xfdba:  "\u0644\u062c\u0645",
+	0xfdbb:  "\u0643\u0645\u0645",
+	0xfdbc:  "\u0644\u062c\u0645",
+	0xfdbd:  "\u0646\u062c\u062d",
+	0xfdbe:  "\u062c\u062d\u0649",
+	0xfdbf:  "\u062d\u062c\u0649",
+	0xfdc0:  "\u0645\u062c\u0649",
+	0xfdc1:  "\u0641\u0645\u0649",
+	0xfdc2:  "\u0628\u062d\u0649",
+	0xfdc3:  "\u0643\u0645\u0645",
+	0xfdc4:  "\u0639\u062c\u0645",
+	0xfdc5:  "\u0635\u0645\u0645",
+	0xfdc6:  "\u0633\u062e\u0649",
+	0xfdc7:  "\u0646\u062c\u0649",
+	0xfdf0:  "\u0635\u0644\u0649",
+	0xfdf1:  "\u0642\u0644\u0649",
+	0xfdf2:  "l\u0644\u0644\u0651\u0670o",
+	0xfdf3:  "l\u0643\u0628\u0631",
+	0xfdf4:  "\u0645\u062d\u0645\u062f",
+	0xfdf5:  "\u0635\u0644\u0639\u0645",
+	0xfdf6:  "\u0631\u0633\u0648\u0644",
+	0xfdf7:  "\u0639\u0644\u0649o",
+	0xfdf8:  "\u0648\u0633\u0644\u0645",
+	0xfdf9:  "\u0635\u0644\u0649",
+	0xfdfa:  "\u0635\u0644\u0649 l\u0644\u0644o \u0639\u0644\u0649o \u0648\u0633\u0644\u0645",
+	0xfdfb:  "\u062c\u0644 \u062c\u0644l\u0644o",
+	0xfdfc:  "\u0631\u0649l\u0644",
+	0xfe19:  "\u2d57",
+	0xfe30:  ":",
+	0xfe31:  "\u2502",
+	0xfe34:  "\u2307",
+	0xfe35:  "\u23dc",
+	0xfe36:  "\u23dd",
+	0xfe37:  "\u23de",
+	0xfe38:  "\u23df",
+	0xfe39:  "\u23e0",
+	0xfe3a:  "\u23e1",
+	0xfe49:  "\u02c9",
+	0xfe4a:  "\u02c9",
+	0xfe4b:  "\u02c9",
+	0xfe4c:  "\u02c9",
+	0xfe4d:  "_",
+	0xfe4e:  "_",
+	0xfe4f:  "_",
+	0xfe58:  "-",
+	0xfe68:  "\\",
+	0xfe80:  "\u0621",
+	0xfe81:  "\u0622",
+	0xfe82:  "\u0622",
+	0xfe83:  "l\u0674",
+	0xfe84:  "l\u0674",
+	0xfe85:  "\u0648\u0674",
+	0xfe86:  "\u0648\u0674",
+	0xfe87:  "l\u0655",
+	0xfe88:  "l\u0655",
+	0xfe89:  "\u0649\u0674",
+	0xfe8a:  "\u0649\u0674",
+	0xfe8b:  "\u0649\u0674",
+	0xfe8c:  "\u0649\u0674",
+	0xfe8d:  "l",
+	0xfe8e:  "l",
+	0xfe8f:  "\u0628",
+	0xfe90:  "\u0628",
+	0xfe91:  "\u0628",
+	0xfe92:  "\u0628",
+	0xfe93:  "\u0629",
+	0xfe94:  "\u0629",
+	0xfe95:  "\u062a",
+	0xfe96:  "\u062a",
+	0xfe97:  "\u062a",
+	0xfe98:  "\u062a",
+	0xfe99:  "\u0649\u06db",
+	0xfe9a:  "\u0649\u06db",
+	0xfe9b:  "\u0649\u06db",
+	0xfe9c:  "\u0649\u06db",
+	0xfe9d:  "\u062c",
+	0xfe9e:  "\u062c",
+	0xfe9f:  "\u062c",
+	0xfea0:  "\u062c",
+	0xfea1:  "\u062d",
+	0xfea2:  "\u062d",
+	0xfea3:  "\u062d",
+	0xfea4:  "\u062d",
+	0xfea5:  "\u062e",
+	0xfea6:  "\u062e",
+	0xfea7:  "\u062e",
+	0xfea8:  "\u062e",
+	0xfea9:  "\u062f",
+	0xfeaa:  "\u062f",
+	0xfeab:  "\u0630",
+	0xfeac:  "\u0630",
+	0xfead:  "\u0631",
+	0xfeae:  "\u0631",
+	0xfeaf:  "\u0632",
+	0xfeb0:  "\u0632",
+	0xfeb1:  "\u0633",
+	0xfeb2:  "\u0633",
+	0xfeb3:  "\u0633",
+	0xfeb4:  "\u0633",
+	0xfeb5:  "\u0633\u06db",
+	0xfeb6:  "\u0633\u06db",
+	0xfeb7:  "\u0633\u06db",
+	0xfeb8:  "\u0633\u06db",
+	0xfeb9:  "\u0635",
+	0xfeba:  "\u0635",
+	0xfebb:  "\u0635",
+	0xfebc:  "\u0635",
+	0xfebd:  "\u0636",
+	0xfebe:  "\u0636",
+	0xfebf:  "\u0636",
+	0xfec0:  "\u0636",
+	0xfec1:  "\u0637",
+	0xfec2:  "\u0637",
+	0xfec3:  "\u0637",
+	0xfec4:  "\u0637",
+	0xfec5:  "\u0638",
+	0xfec6:  "\u0638",
+	0xfec7:  "\u0638",
+	0xfec8:  "\u0638",
+	0xfec9:  "\u0639",
+	0xfeca:  "\u0639",
+	0xfecb:  "\u0639",
+	0xfecc:  "\u0639",
+	0xfecd:  "\u063a",
+	0xfece:  "\u063a",
+	0xfecf:  "\u063a",
+	0xfed0:  "\u063a",
+	0xfed1:  "\u0641",
+	0xfed2:  "\u0641",
+	0xfed3:  "\u0641",
+	0xfed4:  "\u0641",
+	0xfed5:  "\u0642",
+	0xfed6:  "\u0642",
+	0xfed7:  "\u0642",
+	0xfed8:  "\u0642",
+	0xfed9:  "\u0643",
+	0xfeda:  "\u0643",
+	0xfedb:  "\u0643",
+	0xfedc:  "\u0643",
+	0xfedd:  "\u0644",
+	0xfede:  "\u0644",
+	0xfedf:  "\u0644",
+	0xfee0:  "\u0644",
+	0xfee1:  "\u0645",
+	0xfee2:  "\u0645",
+	0xfee3:  "\u0645",
+	0xfee4:  "\u0645",
+	0xfee5:  "\u0646",
+	0xfee6:  "\u0646",
+	0xfee7:  "\u0646",
+	0xfee8:  "\u0646",
+	0xfee9:  "o",
+	0xfeea:  "o",
+	0xfeeb:  "o",
+	0xfeec:  "o",
+	0xfeed:  "\u0648",
+	0xfeee:  "\u0648",
+	0xfeef:  "\u0649",
+	0xfef0:  "\u0649",
+	0xfef1:  "\u0649",
+	0xfef2:  "\u0649",
+	0xfef3:  "\u0649",
+	0xfef4:  "\u0649",
+	0xfef5:  "\u0644\u0622",
+	0xfef6:  "\u0644\u0622",
+	0xfef7:  "\u0644l\u0674",
+	0xfef8:  "\u0644l\u0674",
+	0xfef9:  "\u0644l\u0655",
+	0xfefa:  "\u0644l\u0655",
+	0xfefb:  "\u0644l",
+	0xfefc:  "\u0644l",
+	0xff01:  "!",
+	0xff02:  "''",
+	0xff07:  "'",
+	0xff0d:  "\u30fc",
+	0xff1a:  ":",
+	0xff21:  "A",
+	0xff22:  "B",
+	0xff23:  "C",
+	0xff25:  "E",
+	0xff28:  "H",
+	0xff29:  "l",
+	0xff2a:  "J",
+	0xff2b:  "K",
+	0xff2d:  "M",
+	0xff2e:  "N",
+	0xff2f:  "O",
+	0xff30:  "P",
+	0xff33:  "S",
+	0xff34:  "T",
+	0xff38:  "X",
+	0xff39:  "Y",
+	0xff3a:  "Z",
+	0xff3b:  "(",
+	0xff3c:  "\\",
+	0xff3d:  ")",
+	0xff3e:  "\ufe3f",
+	0xff40:  "'",
+	0xff41:  "a",
+	0xff43:  "c",
+	0xff45:  "e",
+	0xff47:  "g",
+	0xff48:  "h",
+	0xff49:  "i",
+	0xff4a:  "j",
+	0xff4c:  "l",
+	0xff4f:  "o",
+	0xff50:  "p",
+	0xff53:  "s",
+	0xff56:  "v",
+	0xff58:  "x",
+	0xff59:  "y",
+	0xff5c:  "\u2502",
+	0xff5e:  "\u301c",
+	0xff65:  "\u00b7",
+	0xffe3:  "\u02c9",
+	0xffe8:  "l",
+	0xffed:  "\u25aa",
+	0x10101: "\u00b7",
+	0x1018e: "N\u030a",
+	0x10196: "X\u0335",
+	0x10197: "V\u0335",
+	0x10198: "l\u0335l\u0335S\u0335",
+	0x10199: "l\u0335l\u0335",
+	0x101a0: "\u2ce8",
+	0x10282: "B",
+	0x10285: "\u0394",
+	0x10286: "E",
+	0x10287: "F",
+	0x1028a: "l",
+	0x1028d: "\u0245",
+	0x10290: "X",
+	0x10292: "O",
+	0x10294: "\u16dc",
+	0x10295: "P",
+	0x10296: "S",
+	0x10297: "T",
+	0x1029b: "+",
+	0x102a0: "A",
+	0x102a1: "B",
+	0x102a2: "C",
+	0x102a3: "\u0394",
+	0x102a5: "F",
+	0x102ab: "O",
+	0x102ad: "\u03d8",
+	0x102b0: "M",
+	0x102b1: "T",
+	0x102b2: "Y",
+	0x102b3: "\u03a6",
+	0x102b4: "X",
+	0x102b5: "\u03a8",
+	0x102b6: "\u03a9",
+	0x102b8: "\u2d40",
+	0x102cf: "H",
+	0x102e1: "\u062f",
+	0x102e4: "\u0648",
+	0x102e8: "\u0637",
+	0x102f2: "\u0635",
+	0x102f5: "Z",
+	0x10301: "B",
+	0x10302: "C",
+	0x10309: "l",
+	0x10311: "M",
+	0x10312: "\u03d8",
+	0x10315: "T",
+	0x10317: "X",
+	0x1031a: "8",
+	0x1031f: "*",
+	0x10320: "l",
+	0x10322: "X",
+	0x103d1: "\U00010382",
+	0x103d3: "\U00010393",
+	0x10401: "\u0190",
+	0x10404: "O",
+	0x10411: "\ua4f6",
+	0x10415: "C",
+	0x1041b: "L",
+	0x1041f: "\u2c70",
+	0x10420: "S",
+	0x10423: "\u0186",
+	0x10425: "\u0418",
+	0x10429: "\ua793",
+	0x1042a: "\u029a",
+	0x1042c: "o",
+	0x1043d: "c",
+	0x1043f: "\u0277",
+	0x10442: "\u025e",
+	0x10443: "\u029f",
+	0x10448: "s",
+	0x1044b: "\u0254",
+	0x1044d: "\u1d0e",
+	0x104a0: "\U00010486",
+	0x104b0: "\u0245",
+	0x104b4: "R",
+	0x104bc: "\u04c3",
+	0x104c2: "O",
+	0x104c3: "\u0298",
+	0x104c4: "\u00de",
+	0x104cd: "\u040b",
+	0x104ce: "U",
+	0x104d0: "\u16e6",
+	0x104d1: "\u03a8",
+	0x104d2: "7",
+	0x104d8: "\u028c",
+	0x104db: "\u03bb",
+	0x104ea: "o",
+	0x104eb: "\ua669",
+	0x104f6: "u",
+	0x104f9: "\u03c8",
+	0x10513: "N",
+	0x10516: "O",
+	0x10518: "K",
+	0x1051c: "C",
+	0x1051d: "V",
+	0x10525: "F",
+	0x10526: "L",
+	0x10527: "X",
+	0x10a3a: "\u0323",
+	0x10a50: ".",
+	0x10a57: "\U00010a56\U00010a56",
+	0x10cfa: "\U00010ca5",
+	0x10cfc: "\U00010c82",
+	0x110bb: "\u0970",
+	0x111c7: "\u0970",
+	0x111ca: "\u0323",
+	0x111cb: "\u093a",
+	0x111db: "\ua8fc",
+	0x111dc: "\ua8fb",
+	0x111de: "\u2248",
+	0x11300: "\u030a",
+	0x11413: "\U00011434\U00011442\U00011412",
+	0x11419: "\U00011434\U00011442\U00011418",
+	0x11424: "\U00011434\U00011442\U00011423",
+	0x1142a: "\U00011434\U00011442\U00011429",
+	0x1142d: "\U00011434\U00011442\U0001142c",
+	0x1142f: "\U00011434\U00011442\U0001142e",
+	0x1144c: "\U0001144b\U0001144b",
+	0x11492: "\u0998",
+	0x11494: "\u099a",
+	0x11496: "\u099c",
+	0x11498: "\u099e",
+	0x11499: "\u099f",
+	0x1149b: "\u09a1",
+	0x1149d: "\u09b2",
+	0x1149e: "\u09a4",
+	0x1149f: "\u09a5",
+	0x114a0: "\u09a6",
+	0x114a1: "\u09a7",
+	0x114a2: "\u09a8",
+	0x114a3: "\u09aa",
+	0x114a7: "\u09ae",
+	0x114a8: "\u09af",
+	0x114a9: "\u09ac",
+	0x114aa: "\u09a3",
+	0x114ab: "\u09b0",
+	0x114ad: "\u09b7",
+	0x114ae: "\u09b8",
+	0x114b0: "\u09be",
+	0x114b1: "\u09bf",
+	0x114b9: "\u09c7",
+	0x114bc: "\u09cb",
+	0x114bd: "\u09d7",
+	0x114be: "\u09cc",
+	0x114bf: "\u0306\u0307",
+	0x114c1: "\u0983",
+	0x114c2: "\u09cd",
+	0x114c3: "\u0323",
+	0x114c4: "\u09bd",
+	0x114c5: "w\u0307",
+	0x114d0: "O",
+	0x114d1: "\u09e7",
+	0x114d2: "\u09e8",
+	0x114d6: "\u09ec",
+	0x115d8: "\U00011582",
+	0x115d9: "\U00011582",
+	0x115da: "\U00011583",
+	0x115db: "\U00011584",
+	0x115dc: "\U000115b2",
+	0x115dd: "\U000115b3",
+	0x11642: "\U00011641\U00011641",
+	0x11700: "rn",
+	0x11706: "v",
+	0x1170a: "w",
+	0x1170e: "w",
+	0x1170f: "w",
+	0x118a0: "V",
+	0x118a2: "F",
+	0x118a3: "L",
+	0x118a4: "Y",
+	0x118a6: "E",
+	0x118a8: "\u2207",
+	0x118a9: "Z",
+	0x118ac: "9",
+	0x118ae: "E",
+	0x118af: "4",
+	0x118b2: "L",
+	0x118b5: "O",
+	0x118b7: "\u16dc",
+	0x118b8: "U",
+	0x118bb: "5",
+	0x118bc: "T",
+	0x118c0: "v",
+	0x118c1: "s",
+	0x118c2: "F",
+	0x118c3: "i",
+	0x118c4: "z",
+	0x118c6: "7",
+	0x118c8: "o",
+	0x118ca: "3",
+	0x118cc: "9",
+	0x118ce: "\ua793",
+	0x118d5: "6",
+	0x118d6: "9",
+	0x118d7: "o",
+	0x118d8: "u",
+	0x118dc: "y",
+	0x118e0: "O",
+	0x118e3: "rn",
+	0x118e4: "\u0669",
+	0x118e5: "Z",
+	0x118e6: "W",
+	0x118e9: "C",
+	0x118ec: "X",
+	0x118ef: "W",
+	0x118f2: "C",
+	0x11ae6: "\U00011ae5\U00011aef",
+	0x11ae7: "\U00011ae5\U00011af0",
+	0x11ae8: "\U00011ae5\U00011ae5",
+	0x11ae9: "\U00011ae5\U00011ae5\U00011aef",
+	0x11aea: "\U00011ae5\U00011ae5\U00011af0",
+	0x11aec: "\U00011aeb\U00011aef",
+	0x11aed: "\U00011aeb\U00011aeb",
+	0x11aee: "\U00011aeb\U00011aeb\U00011aef",
+	0x11af4: "\U00011af3\U00011aef",
+	0x11af5: "\U00011af3\U00011af0",
+	0x11af6: "\U00011af3\U00011af3",
+	0x11af7: "\U00011af3\U00011af3\U00011aef",
+	0x11af8: "\U00011af3\U00011af3\U00011af0",
+	0x11c42: "\U00011c41\U00011c41",
+	0x11cb2: "\U00011caa",
+	0x12038: "\U0001039a",
+	0x132f9: "\U0001099e",
+	0x16f07: "\u0393",
+	0x16f08: "V",
+	0x16f0a: "T",
+	0x16f16: "L",
+	0x16f1a: "\u0394",
+	0x16f1c: "\ua658",
+	0x16f26: "\ua4f6",
+	0x16f28: "l",
+	0x16f2d: "\u0190",
+	0x16f35: "R",
+	0x16f3a: "S",
+	0x16f3b: "3",
+	0x16f3d: "\u0245",
+	0x16f3f: ">",
+	0x16f40: "A",
+	0x16f42: "U",
+	0x16f43: "Y",
+	0x16f51: "'",
+	0x16f52: "'",
+	0x1d114: "{",
+	0x1d16d: ".",
+	0x1d202: "\u04fe",
+	0x1d206: "3",
+	0x1d20b: "\u0418",
+	0x1d20d: "V",
+	0x1d20f: "\\",
+	0x1d212: "7",
+	0x1d213: "F",
+	0x1d214: "\U000102bc",
+	0x1d215: "\ua4f6",
+	0x1d216: "R",
+	0x1d217: "\u2c6f",
+	0x1d21a: "O\u0335",
+	0x1d21b: "\u2144",
+	0x1d21c: "\ua4d5",
+	0x1d221: "\u0190",
+	0x1d222: "\u0460",
+	0x1d22a: "L",
+	0x1d22b: "\ua4f6",
+	0x1d230: "\ua7fb",
+	0x1d236: "<",
+	0x1d237: ">",
+	0x1d238: "\u228f",
+	0x1d239: "\u2290",
+	0x1d23a: "/",
+	0x1d23b: "\\",
+	0x1d23f: "\u16cb",
+	0x1d245: "\u0548",
+	0x1d400: "A",
+	0x1d401: "B",
+	0x1d402: "C",
+	0x1d403: "D",
+	0x1d404: "E",
+	0x1d405: "F",
+	0x1d406: "G",
+	0x1d407: "H",
+	0x1d408: "l",
+	0x1d409: "J",
+	0x1d40a: "K",
+	0x1d40b: "L",
+	0x1d40c: "M",
+	0x1d40d: "N",
+	0x1d40e: "O",
+	0x1d40f: "P",
+	0x1d410: "Q",
+	0x1d411: "R",
+	0x1d412: "S",
+	0x1d413: "T",
+	0x1d414: "U",
+	0x1d415: "V",
+	0x1d416: "W",
+	0x1d417: "X",
+	0x1d418: "Y",
+	0x1d419: "Z",
+	0x1d41a: "a",
+	0x1d41b: "b",
+	0x1d41c: "c",
+	0x1d41d: "d",
+	0x1d41e: "e",
+	0x1d41f: "f",
+	0x1d420: "g",
+	0x1d421: "h",
+	0x1d422: "i",
+	0x1d423: "j",
+	0x1d424: "k",
+	0x1d425: "l",
+	0x1d426: "rn",
+	0x1d427: "n",
+	0x1d428: "o",
+	0x1d429: "p",
+	0x1d42a: "q",
+	0x1d42b: "r",
+	0x1d42c: "s",
+	0x1d42d: "t",
+	0x1d42e: "u",
+	0x1d42f: "v",
+	0x1d430: "w",
+	0x1d431: "x",
+	0x1d432: "y",
+	0x1d433: "z",
+	0x1d434: "A",
+	0x1d435: "B",
+	0x1d436: "C",
+	0x1d437: "D",
+	0x1d438: "E",
+	0x1d439: "F",
+	0x1d43a: "G",
+	0x1d43b: "H",
+	0x1d43c: "l",
+	0x1d43d: "J",
+	0x1d43e: "K",
+	0x1d43f: "L",
+	0x1d440: "M",
+	0x1d441: "N",
+	0x1d442: "O",
+	0x1d443: "P",
+	0x1d444: "Q",
+	0x1d445: "R",
+	0x1d446: "S",
+	0x1d447: "T",
+	0x1d448: "U",
+	0x1d449: "V",
+	0x1d44a: "W",
+	0x1d44b: "X",
+	0x1d44c: "Y",
+	0x1d44d: "Z",
+	0x1d44e: "a",
+	0x1d44f: "b",
+	0x1d450: "c",
+	0x1d451: "d",
+	0x1d452: "e",
+	0x1d453: "f",
+	0x1d454: "g",
+	0x1d456: "i",
+	0x1d457: "j",
+	0x1d458: "k",
+	0x1d459: "l",
+	0x1d45a: "rn",
+	0x1d45b: "n",
+	0x1d45c: "o",
+	0x1d45d: "p",
+	0x1d45e: "q",
+	0x1d45f: "r",
+	0x1d460: "s",
+	0x1d461: "t",
+	0x1d462: "u",
+	0x1d463: "v",
+	0x1d464: "w",
+	0x1d465: "x",
+	0x1d466: "y",
+	0x1d467: "z",
+	0x1d468: "A",
+	0x1d469: "B",
+	0x1d46a: "C",
+	0x1d46b: "D",
+	0x1d46c: "E",
+	0x1d46d: "F",
+	0x1d46e: "G",
+	0x1d46f: "H",
+	0x1d470: "l",
+	0x1d471: "J",
+	0x1d472: "K",
+	0x1d473: "L",
+	0x1d474: "M",
+	0x1d475: "N",
+	0x1d476: "O",
+	0x1d477: "P",
+	0x1d478: "Q",
+	0x1d479: "R",
+	0x1d47a: "S",
+	0x1d47b: "T",
+	0x1d47c: "U",
+	0x1d47d: "V",
+	0x1d47e: "W",
+	0x1d47f: "X",
+	0x1d480: "Y",
+	0x1d481: "Z",
+	0x1d482: "a",
+	0x1d483: "b",
+	0x1d484: "c",
+	0x1d485: "d",
+	0x1d486: "e",
+	0x1d487: "f",
+	0x1d488: "g",
+	0x1d489: "h",
+	0x1d48a: "i",
+	0x1d48b: "j",
+	0x1d48c: "k",
+	0x1d48d: "l",
+	0x1d48e: "rn",
+	0x1d48f: "n",
+	0x1d490: "o",
+	0x1d491: "p",
+	0x1d492: "q",
+	0x1d493: "r",
+	0x1d494: "s",
+	0x1d495: "t",
+	0x1d496: "u",
+	0x1d497: "v",
+	0x1d498: "w",
+	0x1d499: "x",
+	0x1d49a: "y",
+	0x1d49b: "z",
+	0x1d49c: "A",
+	0x1d49e: "C",
+	0x1d49f: "D",
+	0x1d4a2: "G",
+	0x1d4a5: "J",
+	0x1d4a6: "K",
+	0x1d4a9: "N",
+	0x1d4aa: "O",
+	0x1d4ab: "P",
+	0x1d4ac: "Q",
+	0x1d4ae: "S",
+	0x1d4af: "T",
+	0x1d4b0: "U",
+	0x1d4b1: "V",
+	0x1d4b2: "W",
+	0x1d4b3: "X",
+	0x1d4b4: "Y",
+	0x1d4b5: "Z",
+	0x1d4b6: "a",
+	0x1d4b7: "b",
+	0x1d4b8: "c",
+	0x1d4b9: "d",
+	0x1d4bb: "f",
+	0x1d4bd: "h",
+	0x1d4be: "i",
+	0x1d4bf: "j",
+	0x1d4c0: "k",
+	0x1d4c1: "l",
+	0x1d4c2: "rn",
+	0x1d4c3: "n",
+	0x1d4c5: "p",
+	0x1d4c6: "q",
+	0x1d4c7: "r",
+	0x1d4c8: "s",
+	0x1d4c9: "t",
+	0x1d4ca: "u",
+	0x1d4cb: "v",
+	0x1d4cc: "w",
+	0x1d4cd: "x",
+	0x1d4ce: "y",
+	0x1d4cf: "z",
+	0x1d4d0: "A",
+	0x1d4d1: "B",
+	0x1d4d2: "C",
+	0x1d4d3: "D",
+	0x1d4d4: "E",
+	0x1d4d5: "F",
+	0x1d4d6: "G",
+	0x1d4d7: "H",
+	0x1d4d8: "l",
+	0x1d4d9: "J",
+	0x1d4da: "K",
+	0x1d4db: "L",
+	0x1d4dc: "M",
+	0x1d4dd: "N",
+	0x1d4de: "O",
+	0x1d4df: "P",
+	0x1d4e0: "Q",
+	0x1d4e1: "R",
+	0x1d4e2: "S",
+	0x1d4e3: "T",
+	0x1d4e4: "U",
+	0x1d4e5: "V",
+	0x1d4e6: "W",
+	0x1d4e7: "X",
+	0x1d4e8: "Y",
+	0x1d4e9: "Z",
+	0x1d4ea: "a",
+	0x1d4eb: "b",
+	0x1d4ec: "c",
+	0x1d4ed: "d",
+	0x1d4ee: "e",
+	0x1d4ef: "f",
+	0x1d4f0: "g",
+	0x1d4f1: "h",
+	0x1d4f2: "i",
+	0x1d4f3: "j",
+	0x1d4f4: "k",
+	0x1d4f5: "l",
+	0x1d4f6: "rn",
+	0x1d4f7: "n",
+	0x1d4f8: "o",
+	0x1d4f9: "p",
+	0x1d4fa: "q",
+	0x1d4fb: "r",
+	0x1d4fc: "s",
+	0x1d4fd: "t",
+	0x1d4fe: "u",
+	0x1d4ff: "v",
+	0x1d500: "w",
+	0x1d501: "x",
+	0x1d502: "y",
+	0x1d503: "z",
+	0x1d504: "A",
+	0x1d505: "B",
+	0x1d507: "D",
+	0x1d508: "E",
+	0x1d509: "F",
+	0x1d50a: "G",
+	0x1d50d: "J",
+	0x1d50e: "K",
+	0x1d50f: "L",
+	0x1d510: "M",
+	0x1d511: "N",
+	0x1d512: "O",
+	0x1d513: "P",
+	0x1d514: "Q",
+	0x1d516: "S",
+	0x1d517: "T",
+	0x1d518: "U",
+	0x1d519: "V",
+	0x1d51a: "W",
+	0x1d51b: "X",
+	0x1d51c: "Y",
+	0x1d51e: "a",
+	0x1d51f: "b",
+	0x1d520: "c",
+	0x1d521: "d",
+	0x1d522: "e",
+	0x1d523: "f",
+	0x1d524: "g",
+	0x1d525: "h",
+	0x1d526: "i",
+	0x1d527: "j",
+	0x1d528: "k",
+	0x1d529: "l",
+	0x1d52a: "rn",
+	0x1d52b: "n",
+	0x1d52c: "o",
+	0x1d52d: "p",
+	0x1d52e: "q",
+	0x1d52f: "r",
+	0x1d530: "s",
+	0x1d531: "t",
+	0x1d532: "u",
+	0x1d533: "v",
+	0x1d534: "w",
+	0x1d535: "x",
+	0x1d536: "y",
+	0x1d537: "z",
+	0x1d538: "A",
+	0x1d539: "B",
+	0x1d53b: "D",
+	0x1d53c: "E",
+	0x1d53d: "F",
+	0x1d53e: "G",
+	0x1d540: "l",
+	0x1d541: "J",
+	0x1d542: "K",
+	0x1d543: "L",
+	0x1d544: "M",
+	0x1d546: "O",
+	0x1d54a: "S",
+	0x1d54b: "T",
+	0x1d54c: "U",
+	0x1d54d: "V",
+	0x1d54e: "W",
+	0x1d54f: "X",
+	0x1d550: "Y",
+	0x1d552: "a",
+	0x1d553: "b",
+	0x1d554: "c",
+	0x1d555: "d",
+	0x1d556: "e",
+	0x1d557: "f",
+	0x1d558: "g",
+	0x1d559: "h",
+	0x1d55a: "i",
+	0x1d55b: "j",
+	0x1d55c: "k",
+	0x1d55d: "l",
+	0x1d55e: "rn",
+	0x1d55f: "n",
+	0x1d560: "o",
+	0x1d561: "p",
+	0x1d562: "q",
+	0x1d563: "r",
+	0x1d564: "s",
+	0x1d565: "t",
+	0x1d566: "u",
+	0x1d567: "v",
+	0x1d568: "w",
+	0x1d569: "x",
+	0x1d56a: "y",
+	0x1d56b: "z",
+	0x1d56c: "A",
+	0x1d56d: "B",
+	0x1d56e: "C",
+	0x1d56f: "D",
+	0x1d570: "E",
+	0x1d571: "F",
+	0x1d572: "G",
+	0x1d573: "H",
+	0x1d574: "l",
+	0x1d575: "J",
+	0x1d576: "K",
+	0x1d577: "L",
+	0x1d578: "M",
+	0x1d579: "N",
+	0x1d57a: "O",
+	0x1d57b: "P",
+	0x1d57c: "Q",
+	0x1d57d: "R",
+	0x1d57e: "S",
+	0x1d57f: "T",
+	0x1d580: "U",
+	0x1d581: "V",
+	0x1d582: "W",
+	0x1d583: "X",
+	0x1d584: "Y",
+	0x1d585: "Z",
+	0x1d586: "a",
+	0x1d587: "b",
+	0x1d588: "c",
+	0x1d589: "d",
+	0x1d58a: "e",
+	0x1d58b: "f",
+	0x1d58c: "g",
+	0x1d58d: "h",
+	0x1d58e: "i",
+	0x1d58f: "j",
+	0x1d590: "k",
+	0x1d591: "l",
+	0x1d592: "rn",
+	0x1d593: "n",
+	0x1d594: "o",
+	0x1d595: "p",
+	0x1d596: "q",
+	0x1d597: "r",
+	0x1d598: "s",
+	0x1d599: "t",
+	0x1d59a: "u",
+	0x1d59b: "v",
+	0x1d59c: "w",
+	0x1d59d: "x",
+	0x1d59e: "y",
+	0x1d59f: "z",
+	0x1d5a0: "A",
+	0x1d5a1: "B",
+	0x1d5a2: "C",
+	0x1d5a3: "D",
+	0x1d5a4: "E",
+	0x1d5a5: "F",
+	0x1d5a6: "G",
+	0x1d5a7: "H",
+	0x1d5a8: "l",
+	0x1d5a9: "J",
+	0x1d5aa: "K",
+	0x1d5ab: "L",
+	0x1d5ac: "M",
+	0x1d5ad: "N",
+	0x1d5ae: "O",
+	0x1d5af: "P",
+	0x1d5b0: "Q",
+	0x1d5b1: "R",
+	0x1d5b2: "S",
+	0x1d5b3: "T",
+	0x1d5b4: "U",
+	0x1d5b5: "V",
+	0x1d5b6: "W",
+	0x1d5b7: "X",
+	0x1d5b8: "Y",
+	0x1d5b9: "Z",
+	0x1d5ba: "a",
+	0x1d5bb: "b",
+	0x1d5bc: "c",
+	0x1d5bd: "d",
+	0x1d5be: "e",
+	0x1d5bf: "f",
+	0x1d5c0: "g",
+	0x1d5c1: "h",
+	0x1d5c2: "i",
+	0x1d5c3: "j",
+	0x1d5c4: "k",
+	0x1d5c5: "l",
+	0x1d5c6: "rn",
+	0x1d5c7: "n",
+	0x1d5c8: "o",
+	0x1d5c9: "p",
+	0x1d5ca: "q",
+	0x1d5cb: "r",
+	0x1d5cc: "s",
+	0x1d5cd: "t",
+	0x1d5ce: "u",
+	0x1d5cf: "v",
+	0x1d5d0: "w",
+	0x1d5d1: "x",
+	0x1d5d2: "y",
+	0x1d5d3: "z",
+	0x1d5d4: "A",
+	0x1d5d5: "B",
+	0x1d5d6: "C",
+	0x1d5d7: "D",
+	0x1d5d8: "E",
+	0x1d5d9: "F",
+	0x1d5da: "G",
+	0x1d5db: "H",
+	0x1d5dc: "l",
+	0x1d5dd: "J",
+	0x1d5de: "K",
+	0x1d5df: "L",
+	0x1d5e0: "M",
+	0x1d5e1: "N",
+	0x1d5e2: "O",
+	0x1d5e3: "P",
+	0x1d5e4: "Q",
+	0x1d5e5: "R",
+	0x1d5e6: "S",
+	0x1d5e7: "T",
+	0x1d5e8: "U",
+	0x1d5e9: "V",
+	0x1d5ea: "W",
+	0x1d5eb: "X",
+	0x1d5ec: "Y",
+	0x1d5ed: "Z",
+	0x1d5ee: "a",
+	0x1d5ef: "b",
+	0x1d5f0: "c",
+	0x1d5f1: "d",
+	0x1d5f2: "e",
+	0x1d5f3: "f",
+	0x1d5f4: "g",
+	0x1d5f5: "h",
+	0x1d5f6: "i",
+	0x1d5f7: "j",
+	0x1d5f8: "k",
+	0x1d5f9: "l",
+	0x1d5fa: "rn",
+	0x1d5fb: "n",
+	0x1d5fc: "o",
+	0x1d5fd: "p",
+	0x1d5fe: "q",
+	0x1d5ff: "r",
+	0x1d600: "s",
+	0x1d601: "t",
+	0x1d602: "u",
+	0x1d603: "v",
+	0x1d604: "w",
+	0x1d605: "x",
+	0x1d606: "y",
+	0x1d607: "z",
+	0x1d608: "A",
+	0x1d609: "B",
+	0x1d60a: "C",
+	0x1d60b: "D",
+	0x1d60c: "E",
+	0x1d60d: "F",
+	0x1d60e: "G",
+	0x1d60f: "H",
+	0x1d610: "l",
+	0x1d611: "J",
+	0x1d612: "K",
+	0x1d613: "L",
+	0x1d614: "M",
+	0x1d615: "N",
+	0x1d616: "O",
+	0x1d617: "P",
+	0x1d618: "Q",
+	0x1d619: "R",
+	0x1d61a: "S",
+	0x1d61b: "T",
+	0x1d61c: "U",
+	0x1d61d: "V",
+	0x1d61e: "W",
+	0x1d61f: "X",
+	0x1d620: "Y",
+	0x1d621: "Z",
+	0x1d622: "a",
+	0x1d623: "b",
+	0x1d624: "c",
+	0x1d625: "d",
+	0x1d626: "e",
+	0x1d627: "f",
+	0x1d628: "g",
+	0x1d629: "h",
+	0x1d62a: "i",
+	0x1d62b: "j",
+	0x1d62c: "k",
+	0x1d62d: "l",
+	0x1d62e: "rn",
+	0x1d62f: "n",
+	0x1d630: "o",
+	0x1d631: "p",
+	0x1d632: "q",
+	0x1d633: "r",
+	0x1d634: "s",
+	0x1d635: "t",
+	0x1d636: "u",
+	0x1d637: "v",
+	0x1d638: "w",
+	0x1d639: "x",
+	0x1d63a: "y",
+	0x1d63b: "z",
+	0x1d63c: "A",
+	0x1d63d: "B",
+	0x1d63e: "C",
+	0x1d63f: "D",
+	0x1d640: "E",
+	0x1d641: "F",
+	0x1d642: "G",
+	0x1d643: "H",
+	0x1d644: "l",
+	0x1d645: "J",
+	0x1d646: "K",
+	0x1d647: "L",
+	0x1d648: "M",
+	0x1d649: "N",
+	0x1d64a: "O",
+	0x1d64b: "P",
+	0x1d64c: "Q",
+	0x1d64d: "R",
+	0x1d64e: "S",
+	0x1d64f: "T",
+	0x1d650: "U",
+	0x1d651: "V",
+	0x1d652: "W",
+	0x1d653: "X",
+	0x1d654: "Y",
+	0x1d655: "Z",
+	0x1d656: "a",
+	0x1d657: "b",
+	0x1d658: "c",
+	0x1d659: "d",
+	0x1d65a: "e",
+	0x1d65b: "f",
+	0x1d65c: "g",
+	0x1d65d: "h",
+	0x1d65e: "i",
+	0x1d65f: "j",
+	0x1d660: "k",
+	0x1d661: "l",
+	0x1d662: "rn",
+	0x1d663: "n",
+	0x1d664: "o",
+	0x1d665: "p",
+	0x1d666: "q",
+	0x1d667: "r",
+	0x1d668: "s",
+	0x1d669: "t",
+	0x1d66a: "u",
+	0x1d66b: "v",
+	0x1d66c: "w",
+	0x1d66d: "x",
+	0x1d66e: "y",
+	0x1d66f: "z",
+	0x1d670: "A",
+	0x1d671: "B",
+	0x1d672: "C",
+	0x1d673: "D",
+	0x1d674: "E",
+	0x1d675: "F",
+	0x1d676: "G",
+	0x1d677: "H",
+	0x1d678: "l",
+	0x1d679: "J",
+	0x1d67a: "K",
+	0x1d67b: "L",
+	0x1d67c: "M",
+	0x1d67d: "N",
+	0x1d67e: "O",
+	0x1d67f: "P",
+	0x1d680: "Q",
+	0x1d681: "R",
+	0x1d682: "S",
+	0x1d683: "T",
+	0x1d684: "U",
+	0x1d685: "V",
+	0x1d686: "W",
+	0x1d687: "X",
+	0x1d688: "Y",
+	0x1d689: "Z",
+	0x1d68a: "a",
+	0x1d68b: "b",
+	0x1d68c: "c",
+	0x1d68d: "d",
+	0x1d68e: "e",
+	0x1d68f: "f",
+	0x1d690: "g",
+	0x1d691: "h",
+	0x1d692: "i",
+	0x1d693: "j",
+	0x1d694: "k",
+	0x1d695: "l",
+	0x1d696: "rn",
+	0x1d697: "n",
+	0x1d698: "o",
+	0x1d699: "p",
+	0x1d69a: "q",
+	0x1d69b: "r",
+	0x1d69c: "s",
+	0x1d69d: "t",
+	0x1d69e: "u",
+	0x1d69f: "v",
+	0x1d6a0: "w",
+	0x1d6a1: "x",
+	0x1d6a2: "y",
+	0x1d6a3: "z",
+	0x1d6a4: "i",
+	0x1d6a5: "\u0237",
+	0x1d6a8: "A",
+	0x1d6a9: "B",
+	0x1d6aa: "\u0393",
+	0x1d6ab: "\u0394",
+	0x1d6ac: "E",
+	0x1d6ad: "Z",
+	0x1d6ae: "H",
+	0x1d6af: "O\u0335",
+	0x1d6b0: "l",
+	0x1d6b1: "K",
+	0x1d6b2: "\u0245",
+	0x1d6b3: "M",
+	0x1d6b4: "N",
+	0x1d6b5: "\u039e",
+	0x1d6b6: "O",
+	0x1d6b7: "\u03a0",
+	0x1d6b8: "P",
+	0x1d6b9: "O\u0335",
+	0x1d6ba: "\u01a9",
+	0x1d6bb: "T",
+	0x1d6bc: "Y",
+	0x1d6bd: "\u03a6",
+	0x1d6be: "X",
+	0x1d6bf: "\u03a8",
+	0x1d6c0: "\u03a9",
+	0x1d6c1: "\u2207",
+	0x1d6c2: "a",
+	0x1d6c3: "\u00df",
+	0x1d6c4: "y",
+	0x1d6c5: "\u1e9f",
+	0x1d6c6: "\ua793",
+	0x1d6c7: "\u03b6",
+	0x1d6c8: "n\u0329",
+	0x1d6c9: "O\u0335",
+	0x1d6ca: "i",
+	0x1d6cb: "\u0138",
+	0x1d6cc: "\u03bb",
+	0x1d6cd: "\u03bc",
+	0x1d6ce: "v",
+	0x1d6cf: "\u03be",
+	0x1d6d0: "o",
+	0x1d6d1: "\u03c0",
+	0x1d6d2: "p",
+	0x1d6d3: "\u03c2",
+	0x1d6d4: "o",
+	0x1d6d5: "\u1d1b",
+	0x1d6d6: "u",
+	0x1d6d7: "\u0278",
+	0x1d6d8: "\u03c7",
+	0x1d6d9: "\u03c8",
+	0x1d6da: "\u03c9",
+	0x1d6db: "\u2202",
+	0x1d6dc: "\ua793",
+	0x1d6dd: "O\u0335",
+	0x1d6de: "\u0138",
+	0x1d6df: "\u0278",
+	0x1d6e0: "p",
+	0x1d6e1: "\u03c0",
+	0x1d6e2: "A",
+	0x1d6e3: "B",
+	0x1d6e4: "\u0393",
+	0x1d6e5: "\u0394",
+	0x1d6e6: "E",
+	0x1d6e7: "Z",
+	0x1d6e8: "H",
+	0x1d6e9: "O\u0335",
+	0x1d6ea: "l",
+	0x1d6eb: "K",
+	0x1d6ec: "\u0245",
+	0x1d6ed: "M",
+	0x1d6ee: "N",
+	0x1d6ef: "\u039e",
+	0x1d6f0: "O",
+	0x1d6f1: "\u03a0",
+	0x1d6f2: "P",
+	0x1d6f3: "O\u0335",
+	0x1d6f4: "\u01a9",
+	0x1d6f5: "T",
+	0x1d6f6: "Y",
+	0x1d6f7: "\u03a6",
+	0x1d6f8: "X",
+	0x1d6f9: "\u03a8",
+	0x1d6fa: "\u03a9",
+	0x1d6fb: "\u2207",
+	0x1d6fc: "a",
+	0x1d6fd: "\u00df",
+	0x1d6fe: "y",
+	0x1d6ff: "\u1e9f",
+	0x1d700: "\ua793",
+	0x1d701: "\u03b6",
+	0x1d702: "n\u0329",
+	0x1d703: "O\u0335",
+	0x1d704: "i",
+	0x1d705: "\u0138",
+	0x1d706: "\u03bb",
+	0x1d707: "\u03bc",
+	0x1d708: "v",
+	0x1d709: "\u03be",
+	0x1d70a: "o",
+	0x1d70b: "\u03c0",
+	0x1d70c: "p",
+	0x1d70d: "\u03c2",
+	0x1d70e: "o",
+	0x1d70f: "\u1d1b",
+	0x1d710: "u",
+	0x1d711: "\u0278",
+	0x1d712: "\u03c7",
+	0x1d713: "\u03c8",
+	0x1d714: "\u03c9",
+	0x1d715: "\u2202",
+	0x1d716: "\ua793",
+	0x1d717: "O\u0335",
+	0x1d718: "\u0138",
+	0x1d719: "\u0278",
+	0x1d71a: "p",
+	0x1d71b: "\u03c0",
+	0x1d71c: "A",
+	0x1d71d: "B",
+	0x1d71e: "\u0393",
+	0x1d71f: "\u0394",
+	0x1d720: "E",
+	0x1d721: "Z",
+	0x1d722: "H",
+	0x1d723: "O\u0335",
+	0x1d724: "l",
+	0x1d725: "K",
+	0x1d726: "\u0245",
+	0x1d727: "M",
+	0x1d728: "N",
+	0x1d729: "\u039e",
+	0x1d72a: "O",
+	0x1d72b: "\u03a0",
+	0x1d72c: "P",
+	0x1d72d: "O\u0335",
+	0x1d72e: "\u01a9",
+	0x1d72f: "T",
+	0x1d730: "Y",
+	0x1d731: "\u03a6",
+	0x1d732: "X",
+	0x1d733: "\u03a8",
+	0x1d734: "\u03a9",
+	0x1d735: "\u2207",
+	0x1d736: "a",
+	0x1d737: "\u00df",
+	0x1d738: "y",
+	0x1d739: "\u1e9f",
+	0x1d73a: "\ua793",
+	0x1d73b: "\u03b6",
+	0x1d73c: "n\u0329",
+	0x1d73d: "O\u0335",
+	0x1d73e: "i",
+	0x1d73f: "\u0138",
+	0x1d740: "\u03bb",
+	0x1d741: "\u03bc",
+	0x1d742: "v",
+	0x1d743: "\u03be",
+	0x1d744: "o",
+	0x1d745: "\u03c0",
+	0x1d746: "p",
+	0x1d747: "\u03c2",
+	0x1d748: "o",
+	0x1d749: "\u1d1b",
+	0x1d74a: "u",
+	0x1d74b: "\u0278",
+	0x1d74c: "\u03c7",
+	0x1d74d: "\u03c8",
+	0x1d74e: "\u03c9",
+	0x1d74f: "\u2202",
+	0x1d750: "\ua793",
+	0x1d751: "O\u0335",
+	0x1d752: "\u0138",
+	0x1d753: "\u0278",
+	0x1d754: "p",
+	0x1d755: "\u03c0",
+	0x1d756: "A",
+	0x1d757: "B",
+	0x1d758: "\u0393",
+	0x1d759: "\u0394",
+	0x1d75a: "E",
+	0x1d75b: "Z",
+	0x1d75c: "H",
+	0x1d75d: "O\u0335",
+	0x1d75e: "l",
+	0x1d75f: "K",
+	0x1d760: "\u0245",
+	0x1d761: "M",
+	0x1d762: "N",
+	0x1d763: "\u039e",
+	0x1d764: "O",
+	0x1d765: "\u03a0",
+	0x1d766: "P",
+	0x1d767: "O\u0335",
+	0x1d768: "\u01a9",
+	0x1d769: "T",
+	0x1d76a: "Y",
+	0x1d76b: "\u03a6",
+	0x1d76c: "X",
+	0x1d76d: "\u03a8",
+	0x1d76e: "\u03a9",
+	0x1d76f: "\u2207",
+	0x1d770: "a",
+	0x1d771: "\u00df",
+	0x1d772: "y",
+	0x1d773: "\u1e9f",
+	0x1d774: "\ua793",
+	0x1d775: "\u03b6",
+	0x1d776: "n\u0329",
+	0x1d777: "O\u0335",
+	0x1d778: "i",
+	0x1d779: "\u0138",
+	0x1d77a: "\u03bb",
+	0x1d77b: "\u03bc",
+	0x1d77c: "v",
+	0x1d77d: "\u03be",
+	0x1d77e: "o",
+	0x1d77f: "\u03c0",
+	0x1d780: "p",
+	0x1d781: "\u03c2",
+	0x1d782: "o",
+	0x1d783: "\u1d1b",
+	0x1d784: "u",
+	0x1d785: "\u0278",
+	0x1d786: "\u03c7",
+	0x1d787: "\u03c8",
+	0x1d788: "\u03c9",
+	0x1d789: "\u2202",
+	0x1d78a: "\ua793",
+	0x1d78b: "O\u0335",
+	0x1d78c: "\u0138",
+	0x1d78d: "\u0278",
+	0x1d78e: "p",
+	0x1d78f: "\u03c0",
+	0x1d790: "A",
+	0x1d791: "B",
+	0x1d792: "\u0393",
+	0x1d793: "\u0394",
+	0x1d794: "E",
+	0x1d795: "Z",
+	0x1d796: "H",
+	0x1d797: "O\u0335",
+	0x1d798: "l",
+	0x1d799: "K",
+	0x1d79a: "\u0245",
+	0x1d79b: "M",
+	0x1d79c: "N",
+	0x1d79d: "\u039e",
+	0x1d79e: "O",
+	0x1d79f: "\u03a0",
+	0x1d7a0: "P",
+	0x1d7a1: "O\u0335",
+	0x1d7a2: "\u01a9",
+	0x1d7a3: "T",
+	0x1d7a4: "Y",
+	0x1d7a5: "\u03a6",
+	0x1d7a6: "X",
+	0x1d7a7: "\u03a8",
+	0x1d7a8: "\u03a9",
+	0x1d7a9: "\u2207",
+	0x1d7aa: "a",
+	0x1d7ab: "\u00df",
+	0x1d7ac: "y",
+	0x1d7ad: "\u1e9f",
+	0x1d7ae: "\ua793",
+	0x1d7af: "\u03b6",
+	0x1d7b0: "n\u0329",
+	0x1d7b1: "O\u0335",
+	0x1d7b2: "i",
+	0x1d7b3: "\u0138",
+	0x1d7b4: "\u03bb",
+	0x1d7b5: "\u03bc",
+	0x1d7b6: "v",
+	0x1d7b7: "\u03be",
+	0x1d7b8: "o",
+	0x1d7b9: "\u03c0",
+	0x1d7ba: "p",
+	0x1d7bb: "\u03c2",
+	0x1d7bc: "o",
+	0x1d7bd: "\u1d1b",
+	0x1d7be: "u",
+	0x1d7bf: "\u0278",
+	0x1d7c0: "\u03c7",
+	0x1d7c1: "\u03c8",
+	0x1d7c2: "\u03c9",
+	0x1d7c3: "\u2202",
+	0x1d7c4: "\ua793",
+	0x1d7c5: "O\u0335",
+	0x1d7c6: "\u0138",
+	0x1d7c7: "\u0278",
+	0x1d7c8: "p",
+	0x1d7c9: "\u03c0",
+	0x1d7ca: "F",
+	0x1d7cb: "\u03dd",
+	0x1d7ce: "O",
+	0x1d7cf: "l",
+	0x1d7d0: "2",
+	0x1d7d1: "3",
+	0x1d7d2: "4",
+	0x1d7d3: "5",
+	0x1d7d4: "6",
+	0x1d7d5: "7",
+	0x1d7d6: "8",
+	0x1d7d7: "9",
+	0x1d7d8: "O",
+	0x1d7d9: "l",
+	0x1d7da: "2",
+	0x1d7db: "3",
+	0x1d7dc: "4",
+	0x1d7dd: "5",
+	0x1d7de: "6",
+	0x1d7df: "7",
+	0x1d7e0: "8",
+	0x1d7e1: "9",
+	0x1d7e2: "O",
+	0x1d7e3: "l",
+	0x1d7e4: "2",
+	0x1d7e5: "3",
+	0x1d7e6: "4",
+	0x1d7e7: "5",
+	0x1d7e8: "6",
+	0x1d7e9: "7",
+	0x1d7ea: "8",
+	0x1d7eb: "9",
+	0x1d7ec: "O",
+	0x1d7ed: "l",
+	0x1d7ee: "2",
+	0x1d7ef: "3",
+	0x1d7f0: "4",
+	0x1d7f1: "5",
+	0x1d7f2: "6",
+	0x1d7f3: "7",
+	0x1d7f4: "8",
+	0x1d7f5: "9",
+	0x1d7f6: "O",
+	0x1d7f7: "l",
+	0x1d7f8: "2",
+	0x1d7f9: "3",
+	0x1d7fa: "4",
+	0x1d7fb: "5",
+	0x1d7fc: "6",
+	0x1d7fd: "7",
+	0x1d7fe: "8",
+	0x1d7ff: "9",
+	0x1e8c7: "l",
+	0x1e8c8: "\u2220",
+	0x1e8c9: "\u0663",
+	0x1e8cb: "8",
+	0x1e8cc: "\u2202",
+	0x1e8cd: "\u2202\u0335",
+	0x1ee00: "l",
+	0x1ee01: "\u0628",
+	0x1ee02: "\u062c",
+	0x1ee03: "\u062f",
+	0x1ee05: "\u0648",
+	0x1ee06: "\u0632",
+	0x1ee07: "\u062d",
+	0x1ee08: "\u0637",
+	0x1ee09: "\u0649",
+	0x1ee0a: "\u0643",
+	0x1ee0b: "\u0644",
+	0x1ee0c: "\u0645",
+	0x1ee0d: "\u0646",
+	0x1ee0e: "\u0633",
+	0x1ee0f: "\u0639",
+	0x1ee10: "\u0641",
+	0x1ee11: "\u0635",
+	0x1ee12: "\u0642",
+	0x1ee13: "\u0631",
+	0x1ee14: "\u0633\u06db",
+	0x1ee15: "\u062a",
+	0x1ee16: "\u0649\u06db",
+	0x1ee17: "\u062e",
+	0x1ee18: "\u0630",
+	0x1ee19: "\u0636",
+	0x1ee1a: "\u0638",
+	0x1ee1b: "\u063a",
+	0x1ee1c: "\u0649",
+	0x1ee1d: "\u0649",
+	0x1ee1e: "\u06a1",
+	0x1ee1f: "\u06a1",
+	0x1ee21: "\u0628",
+	0x1ee22: "\u062c",
+	0x1ee24: "o",
+	0x1ee27: "\u062d",
+	0x1ee29: "\u0649",
+	0x1ee2a: "\u0643",
+	0x1ee2b: "\u0644",
+	0x1ee2c: "\u0645",
+	0x1ee2d: "\u0646",
+	0x1ee2e: "\u0633",
+	0x1ee2f: "\u0639",
+	0x1ee30: "\u0641",
+	0x1ee31: "\u0635",
+	0x1ee32: "\u0642",
+	0x1ee34: "\u0633\u06db",
+	0x1ee35: "\u062a",
+	0x1ee36: "\u0649\u06db",
+	0x1ee37: "\u062e",
+	0x1ee39: "\u0636",
+	0x1ee3b: "\u063a",
+	0x1ee42: "\u062c",
+	0x1ee47: "\u062d",
+	0x1ee49: "\u0649",
+	0x1ee4b: "\u0644",
+	0x1ee4d: "\u0646",
+	0x1ee4e: "\u0633",
+	0x1ee4f: "\u0639",
+	0x1ee51: "\u0635",
+	0x1ee52: "\u0642",
+	0x1ee54: "\u0633\u06db",
+	0x1ee57: "\u062e",
+	0x1ee59: "\u0636",
+	0x1ee5b: "\u063a",
+	0x1ee5d: "\u0649",
+	0x1ee5f: "\u06a1",
+	0x1ee61: "\u0628",
+	0x1ee62: "\u062c",
+	0x1ee64: "o",
+	0x1ee67: "\u062d",
+	0x1ee68: "\u0637",
+	0x1ee69: "\u0649",
+	0x1ee6a: "\u0643",
+	0x1ee6c: "\u0645",
+	0x1ee6d: "\u0646",
+	0x1ee6e: "\u0633",
+	0x1ee6f: "\u0639",
+	0x1ee70: "\u0641",
+	0x1ee71: "\u0635",
+	0x1ee72: "\u0642",
+	0x1ee74: "\u0633\u06db",
+	0x1ee75: "\u062a",
+	0x1ee76: "\u0649\u06db",
+	0x1ee77: "\u062e",
+	0x1ee79: "\u0636",
+	0x1ee7a: "\u0638",
+	0x1ee7b: "\u063a",
+	0x1ee7c: "\u0649",
+	0x1ee7e: "\u06a1",
+	0x1ee80: "l",
+	0x1ee81: "\u0628",
+	0x1ee82: "\u062c",
+	0x1ee83: "\u062f",
+	0x1ee84: "o",
+	0x1ee85: "\u0648",
+	0x1ee86: "\u0632",
+	0x1ee87: "\u062d",
+	0x1ee88: "\u0637",
+	0x1ee89: "\u0649",
+	0x1ee8b: "\u0644",
+	0x1ee8c: "\u0645",
+	0x1ee8d: "\u0646",
+	0x1ee8e: "\u0633",
+	0x1ee8f: "\u0639",
+	0x1ee90: "\u0641",
+	0x1ee91: "\u0635",
+	0x1ee92: "\u0642",
+	0x1ee93: "\u0631",
+	0x1ee94: "\u0633\u06db",
+	0x1ee95: "\u062a",
+	0x1ee96: "\u0649\u06db",
+	0x1ee97: "\u062e",
+	0x1ee98: "\u0630",
+	0x1ee99: "\u0636",
+	0x1ee9a: "\u0638",
+	0x1ee9b: "\u063a",
+	0x1eea1: "\u0628",
+	0x1eea2: "\u062c",
+	0x1eea3: "\u062f",
+	0x1eea5: "\u0648",
+	0x1eea6: "\u0632",
+	0x1eea7: "\u062d",
+	0x1eea8: "\u0637",
+	0x1eea9: "\u0649",
+	0x1eeab: "\u0644",
+	0x1eeac: "\u0645",
+	0x1eead: "\u0646",
+	0x1eeae: "\u0633",
+	0x1eeaf: "\u0639",
+	0x1eeb0: "\u0641",
+	0x1eeb1: "\u0635",
+	0x1eeb2: "\u0642",
+	0x1eeb3: "\u0631",
+	0x1eeb4: "\u0633\u06db",
+	0x1eeb5: "\u062a",
+	0x1eeb6: "\u0649\u06db",
+	0x1eeb7: "\u062e",
+	0x1eeb8: "\u0630",
+	0x1eeb9: "\u0636",
+	0x1eeba: "\u0638",
+	0x1eebb: "\u063a",
+	0x1f100: "O.",
+	0x1f101: "O,",
+	0x1f102: "l,",
+	0x1f103: "2,",
+	0x1f104: "3,",
+	0x1f105: "4,",
+	0x1f106: "5,",
+	0x1f107: "6,",
+	0x1f108: "7,",
+	0x1f109: "8,",
+	0x1f10a: "9,",
+	0x1f10f: "$\u20e0",
+	0x1f110: "(A)",
+	0x1f111: "(B)",
+	0x1f112: "(C)",
+	0x1f113: "(D)",
+	0x1f114: "(E)",
+	0x1f115: "(F)",
+	0x1f116: "(G)",
+	0x1f117: "(H)",
+	0x1f118: "(l)",
+	0x1f119: "(J)",
+	0x1f11a: "(K)",
+	0x1f11b: "(L)",
+	0x1f11c: "(M)",
+	0x1f11d: "(N)",
+	0x1f11e: "(O)",
+	0x1f11f: "(P)",
+	0x1f120: "(Q)",
+	0x1f121: "(R)",
+	0x1f122: "(S)",
+	0x1f123: "(T)",
+	0x1f124: "(U)",
+	0x1f125: "(V)",
+	0x1f126: "(W)",
+	0x1f127: "(X)",
+	0x1f128: "(Y)",
+	0x1f129: "(Z)",
+	0x1f12a: "(S)",
+	0x1f16d: "\u33c4\t\u20dd",
+	0x1f16e: "C\u20e0",
+	0x1f240: "(\u672c)",
+	0x1f241: "(\u4e09)",
+	0x1f242: "(\u4e8c)",
+	0x1f243: "(\u5b89)",
+	0x1f244: "(\u70b9)",
+	0x1f245: "(\u6253)",
+	0x1f246: "(\u76d7)",
+	0x1f247: "(\u52dd)",
+	0x1f248: "(\u6557)",
+	0x1f312: "\u263d",
+	0x1f318: "\u263e",
+	0x1f319: "\u263d",
+	0x1f700: "QE",
+	0x1f701: "\ua658",
+	0x1f702: "\u0394",
+	0x1f704: "\U000102bc",
+	0x1f707: "AR",
+	0x1f708: "V\u1de4",
+	0x1f70a: "\u2629",
+	0x1f714: "O\u0335",
+	0x1f728: "\U000102a8",
+	0x1f73a: "\u29df",
+	0x1f74c: "C",
+	0x1f754: "\u16dc",
+	0x1f755: "\u22a1",
+	0x1f75c: "sss",
+	0x1f75e: "\u224f",
+	0x1f768: "T",
+	0x1f76b: "MB",
+	0x1f76c: "VB",
+	0x1f771: "\u22a0",
+	0x1fbf0: "O",
+	0x1fbf1: "l",
+	0x1fbf2: "2",
+	0x1fbf3: "3",
+	0x1fbf4: "4",
+	0x1fbf5: "5",
+	0x1fbf6: "6",
+	0x1fbf7: "7",
+	0x1fbf8: "8",
+	0x1fbf9: "9",
+	0x21fe8: "\u276c",
+	0x2f800: "\u4e3d",
+	0x2f801: "\u4e38",
+	0x2f802: "\u4e41",
+	0x2f803: "\U00020122",
+	0x2f804: "\u4f60",
+	0x2f805: "\u4fae",
+	0x2f806: "\u4fbb",
+	0x2f807: "\u4f75",
+	0x2f808: "\u507a",
+	0x2f809: "\u5099",
+	0x2f80a: "\u50e7",
+	0x2f80b: "\u50cf",
+	0x2f80c: "\u349e",
+	0x2f80d: "\U0002063a",
+	0x2f80e: "\u514d",
+	0x2f80f: "\u5154",
+	0x2f810: "\u5164",
+	0x2f811: "\u5177",
+	0x2f812: "\U0002051c",
+	0x2f813: "\u34b9",
+	0x2f814: "\u5167",
+	0x2f815: "\u518d",
+	0x2f816: "\U0002054b",
+	0x2f817: "\u5197",
+	0x2f818: "\u51a4",
+	0x2f819: "\u4ecc",
+	0x2f81a: "\u51ac",
+	0x2f81b: "\u51b5",
+	0x2f81c: "\U000291df",
+	0x2f81d: "\u51f5",
+	0x2f81e: "\u5203",
+	0x2f81f: "\u34df",
+	0x2f820: "\u523b",
+	0x2f821: "\u5246",
+	0x2f822: "\u5272",
+	0x2f823: "\u5277",
+	0x2f824: "\u3515",
+	0x2f825: "\u52c7",
+	0x2f826: "\u52c9",
+	0x2f827: "\u52e4",
+	0x2f828: "\u52fa",
+	0x2f829: "\u5305",
+	0x2f82a: "\u5306",
+	0x2f82b: "\u5317",
+	0x2f82c: "\u5349",
+	0x2f82d: "\u5351",
+	0x2f82e: "\u535a",
+	0x2f82f: "\u5373",
+	0x2f830: "\u537d",
+	0x2f831: "\u537f",
+	0x2f832: "\u537f",
+	0x2f833: "\u537f",
+	0x2f834: "\U00020a2c",
+	0x2f835: "\u7070",
+	0x2f836: "\u53ca",
+	0x2f837: "\u53df",
+	0x2f838: "\U00020b63",
+	0x2f839: "\u53eb",
+	0x2f83a: "\u53f1",
+	0x2f83b: "\u5406",
+	0x2f83c: "\u549e",
+	0x2f83d: "\u5438",
+	0x2f83e: "\u5448",
+	0x2f83f: "\u5468",
+	0x2f840: "\u54a2",
+	0x2f841: "\u54f6",
+	0x2f842: "\u5510",
+	0x2f843: "\u5553",
+	0x2f844: "\u5563",
+	0x2f845: "\u5584",
+	0x2f846: "\u5584",
+	0x2f847: "\u5599",
+	0x2f848: "\u55ab",
+	0x2f849: "\u55b3",
+	0x2f84a: "\u55c2",
+	0x2f84b: "\u5716",
+	0x2f84c: "\u5606",
+	0x2f84d: "\u5717",
+	0x2f84e: "\u5651",
+	0x2f84f: "\u5674",
+	0x2f850: "\u5207",
+	0x2f851: "\u58ee",
+	0x2f852: "\u57ce",
+	0x2f853: "\u57f4",
+	0x2f854: "\u580d",
+	0x2f855: "\u578b",
+	0x2f856: "\u5832",
+	0x2f857: "\u5831",
+	0x2f858: "\u58ac",
+	0x2f859: "\U000214e4",
+	0x2f85a: "\u58f2",
+	0x2f85b: "\u58f7",
+	0x2f85c: "\u5906",
+	0x2f85d: "\u591a",
+	0x2f85e: "\u5922",
+	0x2f85f: "\u5962",
+	0x2f860: "\U000216a8",
+	0x2f861: "\U000216ea",
+	0x2f862: "\u59ec",
+	0x2f863: "\u5a1b",
+	0x2f864: "\u5a27",
+	0x2f865: "\u59d8",
+	0x2f866: "\u5a66",
+	0x2f867: "\u36ee",
+	0x2f868: "\u36fc",
+	0x2f869: "\u5b08",
+	0x2f86a: "\u5b3e",
+	0x2f86b: "\u5b3e",
+	0x2f86c: "\U000219c8",
+	0x2f86d: "\u5bc3",
+	0x2f86e: "\u5bd8",
+	0x2f86f: "\u5be7",
+	0x2f870: "\u5bf3",
+	0x2f871: "\U00021b18",
+	0x2f872: "\u5bff",
+	0x2f873: "\u5c06",
+	0x2f874: "\u5f53",
+	0x2f875: "\u5c22",
+	0x2f876: "\u3781",
+	0x2f877: "\u5c60",
+	0x2f878: "\u5c6e",
+	0x2f879: "\u5cc0",
+	0x2f87a: "\u5c8d",
+	0x2f87b: "\U00021de4",
+	0x2f87c: "\u5d43",
+	0x2f87d: "\U00021de6",
+	0x2f87e: "\u5d6e",
+	0x2f87f: "\u5d6b",
+	0x2f880: "\u5d7c",
+	0x2f881: "\u5de1",
+	0x2f882: "\u5de2",
+	0x2f883: "\u382f",
+	0x2f884: "\u5dfd",
+	0x2f885: "\u5e28",
+	0x2f886: "\u5e3d",
+	0x2f887: "\u5e69",
+	0x2f888: "\u3862",
+	0x2f889: "\U00022183",
+	0x2f88a: "\u387c",
+	0x2f88b: "\u5eb0",
+	0x2f88c: "\u5eb3",
+	0x2f88d: "\u5eb6",
+	0x2f88e: "\u5eca",
+	0x2f88f: "\U0002a392",
+	0x2f890: "\u5efe",
+	0x2f891: "\U00022331",
+	0x2f892: "\U00022331",
+	0x2f893: "\u8201",
+	0x2f894: "\u5f22",
+	0x2f895: "\u5f22",
+	0x2f896: "\u38c7",
+	0x2f897: "\U000232b8",
+	0x2f898: "\U000261da",
+	0x2f899: "\u5f62",
+	0x2f89a: "\u5f6b",
+	0x2f89b: "\u38e3",
+	0x2f89c: "\u5f9a",
+	0x2f89d: "\u5fcd",
+	0x2f89e: "\u5fd7",
+	0x2f89f: "\u5ff9",
+	0x2f8a0: "\u6081",
+	0x2f8a1: "\u393a",
+	0x2f8a2: "\u391c",
+	0x2f8a3: "\u6094",
+	0x2f8a4: "\U000226d4",
+	0x2f8a5: "\u60c7",
+	0x2f8a6: "\u6148",
+	0x2f8a7: "\u614c",
+	0x2f8a8: "\u614e",
+	0x2f8a9: "\u614c",
+	0x2f8aa: "\u617a",
+	0x2f8ab: "\u618e",
+	0x2f8ac: "\u61b2",
+	0x2f8ad: "\u61a4",
+	0x2f8ae: "\u61af",
+	0x2f8af: "\u61de",
+	0x2f8b0: "\u61f2",
+	0x2f8b1: "\u61f6",
+	0x2f8b2: "\u6210",
+	0x2f8b3: "\u621b",
+	0x2f8b4: "\u625d",
+	0x2f8b5: "\u62b1",
+	0x2f8b6: "\u62d4",
+	0x2f8b7: "\u6350",
+	0x2f8b8: "\U00022b0c",
+	0x2f8b9: "\u633d",
+	0x2f8ba: "\u62fc",
+	0x2f8bb: "\u6368",
+	0x2f8bc: "\u6383",
+	0x2f8bd: "\u63e4",
+	0x2f8be: "\U00022bf1",
+	0x2f8bf: "\u6422",
+	0x2f8c0: "\u63c5",
+	0x2f8c1: "\u63a9",
+	0x2f8c2: "\u3a2e",
+	0x2f8c3: "\u6469",
+	0x2f8c4: "\u647e",
+	0x2f8c5: "\u649d",
+	0x2f8c6: "\u6477",
+	0x2f8c7: "\u3a6c",
+	0x2f8c8: "\u654f",
+	0x2f8c9: "\u656c",
+	0x2f8ca: "\U0002300a",
+	0x2f8cb: "\u65e3",
+	0x2f8cc: "\u66f8",
+	0x2f8cd: "\u6649",
+	0x2f8ce: "\u3b19",
+	0x2f8cf: "\u6691",
+	0x2f8d0: "\u3b08",
+	0x2f8d1: "\u3ae4",
+	0x2f8d2: "\u5192",
+	0x2f8d3: "\u5195",
+	0x2f8d4: "\u6700",
+	0x2f8d5: "\u669c",
+	0x2f8d6: "\u80ad",
+	0x2f8d7: "\u43d9",
+	0x2f8d8: "\u6717",
+	0x2f8d9: "\u671b",
+	0x2f8da: "\u6721",
+	0x2f8db: "\u675e",
+	0x2f8dc: "\u6753",
+	0x2f8dd: "\U000233c3",
+	0x2f8de: "\u3b49",
+	0x2f8df: "\u67fa",
+	0x2f8e0: "\u6785",
+	0x2f8e1: "\u6852",
+	0x2f8e2: "\u6885",
+	0x2f8e3: "\U0002346d",
+	0x2f8e4: "\u688e",
+	0x2f8e5: "\u681f",
+	0x2f8e6: "\u6914",
+	0x2f8e7: "\u3b9d",
+	0x2f8e8: "\u6942",
+	0x2f8e9: "\u69a3",
+	0x2f8ea: "\u69ea",
+	0x2f8eb: "\u6aa8",
+	0x2f8ec: "\U000236a3",
+	0x2f8ed: "\u6adb",
+	0x2f8ee: "\u3c18",
+	0x2f8ef: "\u6b21",
+	0x2f8f0: "\U000238a7",
+	0x2f8f1: "\u6b54",
+	0x2f8f2: "\u3c4e",
+	0x2f8f3: "\u6b72",
+	0x2f8f4: "\u6b9f",
+	0x2f8f5: "\u6bba",
+	0x2f8f6: "\u6bbb",
+	0x2f8f7: "\U00023a8d",
+	0x2f8f8: "\U00021d0b",
+	0x2f8f9: "\U00023afa",
+	0x2f8fa: "\u6c4e",
+	0x2f8fb: "\U00023cbc",
+	0x2f8fc: "\u6cbf",
+	0x2f8fd: "\u6ccd",
+	0x2f8fe: "\u6c67",
+	0x2f8ff: "\u6d16",
+	0x2f900: "\u6d3e",
+	0x2f901: "\u6d77",
+	0x2f902: "\u6d41",
+	0x2f903: "\u6d69",
+	0x2f904: "\u6d78",
+	0x2f905: "\u6d85",
+	0x2f906: "\U00023d1e",
+	0x2f907: "\u6d34",
+	0x2f908: "\u6e2f",
+	0x2f909: "\u6e6e",
+	0x2f90a: "\u3d33",
+	0x2f90b: "\u6ecb",
+	0x2f90c: "\u6ec7",
+	0x2f90d: "\U00023ed1",
+	0x2f90e: "\u6df9",
+	0x2f90f: "\u6f6e",
+	0x2f910: "\U00023f5e",
+	0x2f911: "\U00023f8e",
+	0x2f912: "\u6fc6",
+	0x2f913: "\u7039",
+	0x2f914: "\u701e",
+	0x2f915: "\u701b",
+	0x2f916: "\u3d96",
+	0x2f917: "\u704a",
+	0x2f918: "\u707d",
+	0x2f919: "\u7077",
+	0x2f91a: "\u70ad",
+	0x2f91b: "\U00020525",
+	0x2f91c: "\u7145",
+	0x2f91d: "\U00024263",
+	0x2f91e: "\u719c",
+	0x2f91f: "\U000243ab",
+	0x2f920: "\u7228",
+	0x2f921: "\u7235",
+	0x2f922: "\u7250",
+	0x2f923: "\U00024608",
+	0x2f924: "\u7280",
+	0x2f925: "\u7295",
+	0x2f926: "\U00024735",
+	0x2f927: "\U00024814",
+	0x2f928: "\u737a",
+	0x2f929: "\u738b",
+	0x2f92a: "\u3eac",
+	0x2f92b: "\u73a5",
+	0x2f92c: "\u3eb8",
+	0x2f92d: "\u3eb8",
+	0x2f92e: "\u7447",
+	0x2f92f: "\u745c",
+	0x2f930: "\u7471",
+	0x2f931: "\u7485",
+	0x2f932: "\u74ca",
+	0x2f933: "\u3f1b",
+	0x2f934: "\u7524",
+	0x2f935: "\U00024c36",
+	0x2f936: "\u753e",
+	0x2f937: "\U00024c92",
+	0x2f938: "\u7570",
+	0x2f939: "\U0002219f",
+	0x2f93a: "\u7610",
+	0x2f93b: "\U00024fa1",
+	0x2f93c: "\U00024fb8",
+	0x2f93d: "\U00025044",
+	0x2f93e: "\u3ffc",
+	0x2f93f: "\u4008",
+	0x2f940: "\u76f4",
+	0x2f941: "\U000250f3",
+	0x2f942: "\U000250f2",
+	0x2f943: "\U00025119",
+	0x2f944: "\U00025133",
+	0x2f945: "\u771e",
+	0x2f946: "\u771f",
+	0x2f947: "\u771f",
+	0x2f948: "\u774a",
+	0x2f949: "\u4039",
+	0x2f94a: "\u778b",
+	0x2f94b: "\u4046",
+	0x2f94c: "\u4096",
+	0x2f94d: "\U0002541d",
+	0x2f94e: "\u784e",
+	0x2f94f: "\u788c",
+	0x2f950: "\u78cc",
+	0x2f951: "\u40e3",
+	0x2f952: "\U00025626",
+	0x2f953: "\u7956",
+	0x2f954: "\U0002569a",
+	0x2f955: "\U000256c5",
+	0x2f956: "\u798f",
+	0x2f957: "\u79eb",
+	0x2f958: "\u412f",
+	0x2f959: "\u7a40",
+	0x2f95a: "\u7a4a",
+	0x2f95b: "\u7a4f",
+	0x2f95c: "\U0002597c",
+	0x2f95d: "\U00025aa7",
+	0x2f95e: "\U00025aa7",
+	0x2f95f: "\u7aee",
+	0x2f960: "\u4202",
+	0x2f961: "\U00025bab",
+	0x2f962: "\u7bc6",
+	0x2f963: "\u7bc9",
+	0x2f964: "\u4227",
+	0x2f965: "\U00025c80",
+	0x2f966: "\u7cd2",
+	0x2f967: "\u42a0",
+	0x2f968: "\u7ce8",
+	0x2f969: "\u7ce3",
+	0x2f96a: "\u7d00",
+	0x2f96b: "\U00025f86",
+	0x2f96c: "\u7d63",
+	0x2f96d: "\u4301",
+	0x2f96e: "\u7dc7",
+	0x2f96f: "\u7e02",
+	0x2f970: "\u7e45",
+	0x2f971: "\u4334",
+	0x2f972: "\U00026228",
+	0x2f973: "\U00026247",
+	0x2f974: "\u4359",
+	0x2f975: "\U000262d9",
+	0x2f976: "\u7f7a",
+	0x2f977: "\U0002633e",
+	0x2f978: "\u7f95",
+	0x2f979: "\u7ffa",
+	0x2f97a: "\u8005",
+	0x2f97b: "\U000264da",
+	0x2f97c: "\U00026523",
+	0x2f97d: "\u8060",
+	0x2f97e: "\U000265a8",
+	0x2f97f: "\u8070",
+	0x2f980: "\U0002335f",
+	0x2f981: "\u43d5",
+	0x2f982: "\u80b2",
+	0x2f983: "\u8103",
+	0x2f984: "\u440b",
+	0x2f985: "\u813e",
+	0x2f986: "\u5ab5",
+	0x2f987: "\U000267a7",
+	0x2f988: "\U000267b5",
+	0x2f989: "\U00023393",
+	0x2f98a: "\U0002339c",
+	0x2f98b: "\u8201",
+	0x2f98c: "\u8204",
+	0x2f98d: "\u8f9e",
+	0x2f98e: "\u446b",
+	0x2f98f: "\u8291",
+	0x2f990: "\u828b",
+	0x2f991: "\u829d",
+	0x2f992: "\u52b3",
+	0x2f993: "\u82b1",
+	0x2f994: "\u82b3",
+	0x2f995: "\u82bd",
+	0x2f996: "\u82e6",
+	0x2f997: "\U00026b3c",
+	0x2f998: "\u82e5",
+	0x2f999: "\u831d",
+	0x2f99a: "\u8363",
+	0x2f99b: "\u83ad",
+	0x2f99c: "\u8323",
+	0x2f99d: "\u83bd",
+	0x2f99e: "\u83e7",
+	0x2f99f: "\u8457",
+	0x2f9a0: "\u8353",
+	0x2f9a1: "\u83ca",
+	0x2f9a2: "\u83cc",
+	0x2f9a3: "\u83dc",
+	0x2f9a4: "\U00026c36",
+	0x2f9a5: "\U00026d6b",
+	0x2f9a6: "\U00026cd5",
+	0x2f9a7: "\u452b",
+	0x2f9a8: "\u84f1",
+	0x2f9a9: "\u84f3",
+	0x2f9aa: "\u8516",
+	0x2f9ab: "\U000273ca",
+	0x2f9ac: "\u8564",
+	0x2f9ad: "\U00026f2c",
+	0x2f9ae: "\u455d",
+	0x2f9af: "\u4561",
+	0x2f9b0: "\U00026fb1",
+	0x2f9b1: "\U000270d2",
+	0x2f9b2: "\u456b",
+	0x2f9b3: "\u8650",
+	0x2f9b4: "\u865c",
+	0x2f9b5: "\u8667",
+	0x2f9b6: "\u8669",
+	0x2f9b7: "\u86a9",
+	0x2f9b8: "\u8688",
+	0x2f9b9: "\u870e",
+	0x2f9ba: "\u86e2",
+	0x2f9bb: "\u8779",
+	0x2f9bc: "\u8728",
+	0x2f9bd: "\u876b",
+	0x2f9be: "\u8786",
+	0x2f9bf: "\u45d7",
+	0x2f9c0: "\u87e1",
+	0x2f9c1: "\u8801",
+	0x2f9c2: "\u45f9",
+	0x2f9c3: "\u8860",
+	0x2f9c4: "\u8863",
+	0x2f9c5: "\U00027667",
+	0x2f9c6: "\u88d7",
+	0x2f9c7: "\u88de",
+	0x2f9c8: "\u4635",
+	0x2f9c9: "\u88fa",
+	0x2f9ca: "\u34bb",
+	0x2f9cb: "\U000278ae",
+	0x2f9cc: "\U00027966",
+	0x2f9cd: "\u46be",
+	0x2f9ce: "\u46c7",
+	0x2f9cf: "\u8aa0",
+	0x2f9d0: "\u8aed",
+	0x2f9d1: "\u8b8a",
+	0x2f9d2: "\u8c55",
+	0x2f9d3: "\U00027ca8",
+	0x2f9d4: "\u8cab",
+	0x2f9d5: "\u8cc1",
+	0x2f9d6: "\u8d1b",
+	0x2f9d7: "\u8d77",
+	0x2f9d8: "\U00027f2f",
+	0x2f9d9: "\U00020804",
+	0x2f9da: "\u8dcb",
+	0x2f9db: "\u8dbc",
+	0x2f9dc: "\u8df0",
+	0x2f9dd: "\U000208de",
+	0x2f9de: "\u8ed4",
+	0x2f9df: "\u8f38",
+	0x2f9e0: "\U000285d2",
+	0x2f9e1: "\U000285ed",
+	0x2f9e2: "\u9094",
+	0x2f9e3: "\u90f1",
+	0x2f9e4: "\u9111",
+	0x2f9e5: "\U0002872e",
+	0x2f9e6: "\u911b",
+	0x2f9e7: "\u9238",
+	0x2f9e8: "\u92d7",
+	0x2f9e9: "\u92d8",
+	0x2f9ea: "\u927c",
+	0x2f9eb: "\u93f9",
+	0x2f9ec: "\u9415",
+	0x2f9ed: "\U00028bfa",
+	0x2f9ee: "\u958b",
+	0x2f9ef: "\u4995",
+	0x2f9f0: "\u95b7",
+	0x2f9f1: "\U00028d77",
+	0x2f9f2: "\u49e6",
+	0x2f9f3: "\u96c3",
+	0x2f9f4: "\u5db2",
+	0x2f9f5: "\u9723",
+	0x2f9f6: "\U00029145",
+	0x2f9f7: "\U0002921a",
+	0x2f9f8: "\u4a6e",
+	0x2f9f9: "\u4a76",
+	0x2f9fa: "\u97e0",
+	0x2f9fb: "\U0002940a",
+	0x2f9fc: "\u4ab2",
+	0x2f9fd: "\U00029496",
+	0x2f9fe: "\u980b",
+	0x2f9ff: "\u980b",
+	0x2fa00: "\u9829",
+	0x2fa01: "\U000295b6",
+	0x2fa02: "\u98e2",
+	0x2fa03: "\u4b33",
+	0x2fa04: "\u9929",
+	0x2fa05: "\u99a7",
+	0x2fa06: "\u99c2",
+	0x2fa07: "\u99fe",
+	0x2fa08: "\u4bce",
+	0x2fa09: "\U00029b30",
+	0x2fa0a: "\u9b12",
+	0x2fa0b: "\u9c40",
+	0x2fa0c: "\u9cfd",
+	0x2fa0d: "\u4cce",
+	0x2fa0e: "\u4ced",
+	0x2fa0f: "\u9d67",
+	0x2fa10: "\U0002a0ce",
+	0x2fa11: "\u4cf8",
+	0x2fa12: "\U0002a105",
+	0x2fa13: "\U0002a20e",
+	0x2fa14: "\U0002a291",
+	0x2fa15: "\u9ebb",
+	0x2fa16: "\u4d56",
+	0x2fa17: "\u9ef9",
+	0x2fa18: "\u9efe",
+	0x2fa19: "\u9f05",
+	0x2fa1a: "\u9f0f",
+	0x2fa1b: "\u9f16",
+	0x2fa1c: "\u9f3b",
+	0x2fa1d: "\U0002a600",
+}
@@ -0,0 +1,103 @@
+package libveritas
+
+import "context"
+
+// Context-aware variants of the long-running calls.
+//
+// A native call cannot be interrupted once it started. These variants run it
+// on a separate goroutine and return ctx.Err() as soon as ctx is done; the
+// abandoned call finishes in the background and its result is destroyed.
+
+// VerifyContext is VerifyWith bounded by ctx.
+func (_self *Veritas) VerifyContext(ctx context.Context, qctx *QueryContext, msg *Message, options VerifyOptions) (*VerifiedMessage, error) {
+	return runContext(ctx, func() (*VerifiedMessage, error) {
+		return _self.VerifyWith(qctx, msg, options)
+	}, (*VerifiedMessage).Destroy)
+}
+
+// BuildContext is Build bounded by ctx.
+func (_self *MessageBuilder) BuildContext(ctx context.Context, chainProof []byte) (BuildResult, error) {
+	return runContext(ctx, func() (BuildResult, error) {
+		return _self.Build(chainProof)
+	}, func(result BuildResult) {
+		result.Destroy()
+	})
+}
+
+// ResolveContext drives the lookup to completion. fetch is called with each
+// batch of handles and returns their zones, typically from a resolveAll
+// request to a relay. The zones of all batches are expanded with
+// ExpandZones and returned.
+//
+// When ctx is done first, the lookup is left in an unspecified state: the
+// abandoned Advance may or may not have been applied, and may still be
+// running. Destroy the lookup and start a new one instead of reusing it.
+func (_self *Lookup) ResolveContext(ctx context.Context, fetch func(ctx context.Context, handles []string) ([]Zone, error)) ([]Zone, error) {
+	var zones []Zone
+	batch := _self.Start()
+	for len(batch) > 0 {
+		fetched, err := fetch(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, fetched...)
+		batch, err = runContext(ctx, func() ([]string, error) {
+			return _self.Advance(fetched)
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return runContext(ctx, func() ([]Zone, error) {
+		return _self.ExpandZones(zones)
+	}, nil)
+}
+
+// runContext runs call on its own goroutine and waits for it or for ctx.
+// When ctx wins, release is applied to the result of the abandoned call, if
+// it succeeds. A panic in call is raised again on the caller's goroutine.
+// Once the call was abandoned there is no caller left to raise it to, so it
+// is passed to the internal error hook and dropped.
+func runContext[T any](ctx context.Context, call func() (T, error), release func(T)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	type result struct {
+		value     T
+		err       error
+		panicking bool
+		panicked  any
+	}
+	done := make(chan result, 1)
+	go func() {
+		r := result{panicking: true}
+		defer func() {
+			if r.panicking {
+				r.panicked = recover()
+			}
+			done <- r
+		}()
+		r.value, r.err = call()
+		r.panicking = false
+	}()
+	select {
+	case r := <-done:
+		if r.panicking {
+			panic(r.panicked)
+		}
+		return r.value, r.err
+	case <-ctx.Done():
+		go func() {
+			r := <-done
+			if r.panicking {
+				recoveredInternalError(r.panicked, "abandoned call")
+				return
+			}
+			if r.err == nil && release != nil {
+				release(r.value)
+			}
+		}()
+		return zero, ctx.Err()
+	}
+}
@@ -0,0 +1,74 @@
+package libveritas
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunContextReturnsResult(t *testing.T) {
+	value, err := runContext(context.Background(), func() (int, error) { return 1, nil }, nil)
+	if value != 1 || err != nil {
+		t.Errorf("runContext = %d, %v", value, err)
+	}
+}
+
+func TestRunContextRaisesPanic(t *testing.T) {
+	defer func() {
+		if recover() != "boom" {
+			t.Error("panic of the call was not raised on the caller's goroutine")
+		}
+	}()
+	runContext(context.Background(), func() (int, error) { panic("boom") }, nil)
+}
+
+func TestRunContextReleasesAbandonedResult(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	unblock := make(chan struct{})
+	released := make(chan int, 1)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, err := runContext(ctx, func() (int, error) {
+		<-unblock
+		return 7, nil
+	}, func(value int) { released <- value })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	close(unblock)
+	if value := <-released; value != 7 {
+		t.Errorf("released %d, want 7", value)
+	}
+}
+
+func TestRunContextReportsPanicOfAbandonedCall(t *testing.T) {
+	reported := make(chan *InternalError, 1)
+	SetInternalErrorHook(func(err *InternalError) { reported <- err })
+	defer SetInternalErrorHook(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	unblock := make(chan struct{})
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, err := runContext(ctx, func() (int, error) {
+		<-unblock
+		panic("boom")
+	}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	close(unblock)
+	select {
+	case internal := <-reported:
+		if internal.Msg != "abandoned call: boom" {
+			t.Errorf("reported %q", internal.Msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("panic of the abandoned call was not reported")
+	}
+}
@@ -0,0 +1,250 @@
+package libveritas
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrInternal matches an *InternalError with errors.Is.
+var ErrInternal = fmt.Errorf("libveritas: internal error")
+
+// InternalError is an unexpected failure inside the native library, such as
+// a Rust panic, as opposed to a VeritasError the library reports on purpose.
+type InternalError struct {
+	// Panic message reported by the native library.
+	Msg string
+}
+
+func (err *InternalError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrInternal, err.Msg)
+}
+
+func (err *InternalError) Is(target error) bool {
+	return target == ErrInternal
+}
+
+var (
+	panicIsolation    atomic.Bool
+	internalErrorHook atomic.Pointer[func(*InternalError)]
+)
+
+// SetPanicIsolation controls how internal failures of the native library
+// reach callers. By default they panic with an *InternalError. When enabled,
+// functions that return an error report them as a *VeritasError wrapping the
+// *InternalError, so errors.Is(err, ErrInternal) holds. This covers Rust
+// panics as well as results and errors that do not read.
+//
+// Functions and methods without an error result cannot report it and keep
+// panicking with the *InternalError, after the hook ran. These are the
+// constructors NewMessageBuilder, NewQueryContext and NewRecordSet, the
+// accessors of Anchors, Lookup.Start, Message.ToBytes and ToBytesView,
+// RecordSet, UnsignedRecordSet, VerifiedMessage and Veritas, and
+// SigPrimaryZone, VerifyDefault, VerifyDevMode and VerifyEnableSnark. Panics
+// while lowering arguments, which are caused by the caller, such as a
+// sequence longer than math.MaxInt32, are not converted either.
+func SetPanicIsolation(enabled bool) {
+	panicIsolation.Store(enabled)
+}
+
+// SetInternalErrorHook registers a function called for every internal failure
+// of the native library, before it is returned or raised, so callers can
+// count and log them. The hook runs on the calling goroutine and must not
+// block. Passing nil removes the hook.
+func SetInternalErrorHook(hook func(*InternalError)) {
+	if hook == nil {
+		internalErrorHook.Store(nil)
+		return
+	}
+	internalErrorHook.Store(&hook)
+}
+
+func reportInternalError(err *InternalError) {
+	if hook := internalErrorHook.Load(); hook != nil {
+		(*hook)(err)
+	}
+}
+
+// internalError reports an unexpected native failure to the hook, then
+// returns it as a *VeritasError when panic isolation is enabled and the call
+// has a VeritasError result, and panics with an *InternalError otherwise.
+func internalError[E any](msg string) *E {
+	err := &InternalError{Msg: msg}
+	reportInternalError(err)
+	return isolateInternalError[E](err)
+}
+
+func isolateInternalError[E any](err *InternalError) *E {
+	if panicIsolation.Load() {
+		if wrapped, ok := any(&VeritasError{err: err}).(*E); ok {
+			return wrapped
+		}
+	}
+	panic(err)
+}
+
+// recoveredInternalError turns a value recovered while reading a call result
+// into an *InternalError. Internal errors raised by nested calls were
+// reported already.
+func recoveredInternalError(recovered any, what string) *InternalError {
+	if err, ok := recovered.(*InternalError); ok {
+		return err
+	}
+	err := &InternalError{Msg: fmt.Sprintf("%s: %v", what, recovered)}
+	reportInternalError(err)
+	return err
+}
+
+// liftResult reads the result of a successful call. A result that does not
+// read, because the native library does not match these bindings or is
+// broken, is returned as an internal error when panic isolation is enabled.
+func liftResult[R any, T any](lift func(R) T, value R) (result T, err error) {
+	if panicIsolation.Load() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = isolateInternalError[VeritasError](recoveredInternalError(recovered, "reading result"))
+			}
+		}()
+	}
+	return lift(value), nil
+}
+
+// ErrorCode is a machine-readable classification of an error, stable across
+// releases and suitable for API responses. The specific codes below the
+// variant codes are only set by checks done in Go: the native library
+// reports its errors as InvalidInput, VerificationFailed or Internal with a
+// message, and this package does not classify them further.
+type ErrorCode string
+
+const (
+	// Not an error of this package, or not classified further.
+	CodeUnknown ErrorCode = "unknown"
+	// Invalid input that none of the more specific codes covers.
+	CodeInvalidInput ErrorCode = "invalid_input"
+	// Failed verification that none of the more specific codes covers.
+	CodeVerificationFailed ErrorCode = "verification_failed"
+	// Unexpected failure inside the native library, see InternalError.
+	CodeInternal ErrorCode = "internal"
+	// The native library is not linked into this build.
+	CodeNativeUnavailable ErrorCode = "native_unavailable"
+
+	// Signature does not verify against the expected key.
+	CodeBadSignature ErrorCode = "bad_signature"
+	// Certificate or certificate chain does not decode.
+	CodeMalformedCertificate ErrorCode = "malformed_certificate"
+	// SIP-7 record set does not encode or decode.
+	CodeMalformedRecords ErrorCode = "malformed_records"
+	// Handle name is not valid.
+	CodeInvalidHandle ErrorCode = "invalid_handle"
+)
+
+// Sentinels for the specific error codes, for use with errors.Is. A
+// *VeritasError matches the sentinel of its Code as well as the sentinel of
+// its variant (ErrVeritasErrorInvalidInput, ErrVeritasErrorVerificationFailed).
+var (
+	ErrBadSignature         = fmt.Errorf("libveritas: bad signature")
+	ErrMalformedCertificate = fmt.Errorf("libveritas: malformed certificate")
+	ErrMalformedRecords     = fmt.Errorf("libveritas: malformed records")
+	ErrInvalidHandle        = fmt.Errorf("libveritas: invalid handle")
+)
+
+var errorCodeSentinels = map[ErrorCode]error{
+	CodeInternal:             ErrInternal,
+	CodeNativeUnavailable:    ErrNativeUnavailable,
+	CodeBadSignature:         ErrBadSignature,
+	CodeMalformedCertificate: ErrMalformedCertificate,
+	CodeMalformedRecords:     ErrMalformedRecords,
+	CodeInvalidHandle:        ErrInvalidHandle,
+}
+
+// ErrorDetails are the structured fields of a *VeritasError.
+type ErrorDetails struct {
+	Code ErrorCode `json:"code"`
+	// Message reported by the library.
+	Message string `json:"message"`
+	// Handle the error refers to, if known.
+	Handle string `json:"handle,omitempty"`
+}
+
+// Code returns the classification of the error.
+func (err *VeritasError) Code() ErrorCode {
+	return err.Details().Code
+}
+
+// Details returns the structured fields of the error. Errors raised in Go
+// carry them directly. The native library reports only a message, so its
+// errors have the code of their variant: CodeInvalidInput,
+// CodeVerificationFailed or CodeInternal.
+func (err *VeritasError) Details() ErrorDetails {
+	msg := veritasErrorMessage(err.err)
+	if err.details != nil {
+		details := *err.details
+		details.Message = msg
+		return details
+	}
+	return ErrorDetails{Code: variantErrorCode(err.err), Message: msg}
+}
+
+func (err *VeritasError) Is(target error) bool {
+	sentinel, ok := errorCodeSentinels[err.Code()]
+	return ok && target == sentinel
+}
+
+// ErrorCodeOf returns the code of err: that of a *VeritasError in its chain,
+// CodeNativeUnavailable or CodeInternal for the matching sentinels, and
+// CodeUnknown otherwise.
+func ErrorCodeOf(err error) ErrorCode {
+	var veritasErr *VeritasError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &veritasErr):
+		return veritasErr.Code()
+	case errors.Is(err, ErrNativeUnavailable):
+		return CodeNativeUnavailable
+	case errors.Is(err, ErrInternal):
+		return CodeInternal
+	default:
+		return CodeUnknown
+	}
+}
+
+// withErrorDetails attaches details to an error raised in Go.
+func withErrorDetails(err *VeritasError, details ErrorDetails) *VeritasError {
+	err.details = &details
+	return err
+}
+
+func newInvalidInputError(code ErrorCode, msg string) *VeritasError {
+	return withErrorDetails(NewVeritasErrorInvalidInput(msg), ErrorDetails{Code: code})
+}
+
+func newVerificationFailedError(code ErrorCode, msg string) *VeritasError {
+	return withErrorDetails(NewVeritasErrorVerificationFailed(msg), ErrorDetails{Code: code})
+}
+
+func veritasErrorMessage(variant error) string {
+	switch variant := variant.(type) {
+	case *VeritasErrorInvalidInput:
+		return variant.Msg
+	case *VeritasErrorVerificationFailed:
+		return variant.Msg
+	case *InternalError:
+		return variant.Msg
+	default:
+		return variant.Error()
+	}
+}
+
+func variantErrorCode(variant error) ErrorCode {
+	switch variant.(type) {
+	case *VeritasErrorInvalidInput:
+		return CodeInvalidInput
+	case *VeritasErrorVerificationFailed:
+		return CodeVerificationFailed
+	case *InternalError:
+		return CodeInternal
+	default:
+		return CodeUnknown
+	}
+}
@@ -0,0 +1,122 @@
+package libveritas
+
+import (
+	"errors"
+	"testing"
+)
+
+// withPanicIsolation enables isolation and counts reported internal errors
+// for the duration of a test.
+func withPanicIsolation(t *testing.T, enabled bool) *int {
+	t.Helper()
+	reported := new(int)
+	SetPanicIsolation(enabled)
+	SetInternalErrorHook(func(*InternalError) { *reported++ })
+	t.Cleanup(func() {
+		SetPanicIsolation(false)
+		SetInternalErrorHook(nil)
+	})
+	return reported
+}
+
+func TestLiftResultIsolatesPanics(t *testing.T) {
+	reported := withPanicIsolation(t, true)
+	_, err := liftResult(func(int) string { panic("junk remaining in buffer") }, 0)
+	if !errors.Is(err, ErrInternal) {
+		t.Fatalf("liftResult error = %v, want ErrInternal", err)
+	}
+	if code := ErrorCodeOf(err); code != CodeInternal {
+		t.Errorf("code = %q, want %q", code, CodeInternal)
+	}
+	if *reported != 1 {
+		t.Errorf("hook called %d times, want 1", *reported)
+	}
+
+	value, err := liftResult(func(n int) int { return n + 1 }, 1)
+	if err != nil || value != 2 {
+		t.Errorf("liftResult = %d, %v", value, err)
+	}
+}
+
+func TestLiftResultReportsNestedInternalErrorOnce(t *testing.T) {
+	reported := withPanicIsolation(t, true)
+	_, err := liftResult(func(int) int {
+		panic(internalError[error]("rustbuffer free failed"))
+	}, 0)
+	var internal *InternalError
+	if !errors.As(err, &internal) || internal.Msg != "rustbuffer free failed" {
+		t.Fatalf("liftResult error = %v, want the nested *InternalError", err)
+	}
+	if *reported != 1 {
+		t.Errorf("hook called %d times, want 1", *reported)
+	}
+}
+
+func TestLiftResultPanicsWithoutIsolation(t *testing.T) {
+	withPanicIsolation(t, false)
+	defer func() {
+		if recover() == nil {
+			t.Error("liftResult did not panic")
+		}
+	}()
+	liftResult(func(int) int { panic("boom") }, 0)
+}
+
+func TestInternalErrorWithIsolation(t *testing.T) {
+	reported := withPanicIsolation(t, true)
+	err := internalError[VeritasError]("rust panicked").AsError()
+	if !errors.Is(err, ErrInternal) {
+		t.Errorf("internalError = %v, want ErrInternal", err)
+	}
+	if *reported != 1 {
+		t.Errorf("hook called %d times, want 1", *reported)
+	}
+	defer func() {
+		if _, ok := recover().(*InternalError); !ok {
+			t.Error("internalError without a VeritasError result did not panic with *InternalError")
+		}
+	}()
+	internalError[error]("rust panicked")
+}
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		code     ErrorCode
+		sentinel error
+	}{
+		{"go invalid handle", newInvalidInputError(CodeInvalidHandle, "bad handle"), CodeInvalidHandle, ErrInvalidHandle},
+		{"go bad signature", newVerificationFailedError(CodeBadSignature, "bad sig"), CodeBadSignature, ErrBadSignature},
+		// Native errors are not classified by their wording.
+		{"native invalid input", NewVeritasErrorInvalidInput("invalid signature for alice@bitcoin"), CodeInvalidInput, ErrVeritasErrorInvalidInput},
+		{"native verification", NewVeritasErrorVerificationFailed("unknown anchor 100"), CodeVerificationFailed, ErrVeritasErrorVerificationFailed},
+		{"native internal", &VeritasError{err: &InternalError{Msg: "panicked"}}, CodeInternal, ErrInternal},
+		{"native unavailable", ErrNativeUnavailable, CodeNativeUnavailable, ErrNativeUnavailable},
+		{"foreign", errors.New("other"), CodeUnknown, nil},
+	}
+	for _, tt := range tests {
+		if code := ErrorCodeOf(tt.err); code != tt.code {
+			t.Errorf("%s: code = %q, want %q", tt.name, code, tt.code)
+		}
+		if tt.sentinel != nil && !errors.Is(tt.err, tt.sentinel) {
+			t.Errorf("%s: %v does not match %v", tt.name, tt.err, tt.sentinel)
+		}
+	}
+	if errors.Is(NewVeritasErrorInvalidInput("invalid signature"), ErrBadSignature) {
+		t.Error("native error matched ErrBadSignature by its message")
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	err := withErrorDetails(NewVeritasErrorInvalidInput("duplicate"),
+		ErrorDetails{Code: CodeMalformedCertificate, Handle: "alice@bitcoin"})
+	details := err.Details()
+	if details.Code != CodeMalformedCertificate || details.Handle != "alice@bitcoin" || details.Message != "duplicate" {
+		t.Errorf("Details = %+v", details)
+	}
+	details = NewVeritasErrorInvalidInput("no handle for alice@bitcoin at anchor 100").Details()
+	if details.Handle != "" || details.Code != CodeInvalidInput {
+		t.Errorf("native error details were parsed from the message: %+v", details)
+	}
+}
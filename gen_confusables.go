@@ -0,0 +1,144 @@
+//go:build ignore
+
+// gen_confusables generates confusables_table.go from the confusables.txt
+// data file of Unicode Technical Standard #39. By default the file is
+// downloaded from unicode.org; -in reads a local copy instead.
+//
+//	go run gen_confusables.go [-version 13.0.0] [-in confusables.txt]
+//
+// testdata/confusables.txt holds the mappings of the version the table is
+// generated from, without the per-line comments, so that the tests can
+// check the table against the generator. Update it together with -version.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	version := flag.String("version", "13.0.0", "Unicode version of the data file to download")
+	in := flag.String("in", "", "read the data file from this path instead of downloading it")
+	out := flag.String("out", "confusables_table.go", "output file")
+	flag.Parse()
+
+	source := *in
+	var data io.Reader
+	if source == "" {
+		source = fmt.Sprintf("https://www.unicode.org/Public/security/%s/confusables.txt", *version)
+		resp, err := http.Get(source)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			log.Fatalf("%s: %s", source, resp.Status)
+		}
+		data = resp.Body
+	} else {
+		f, err := os.Open(source)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		data = f
+	}
+
+	header, table, err := parse(data)
+	if err != nil {
+		log.Fatalf("%s: %v", source, err)
+	}
+	code, err := generate(header, table)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parse reads the mappings "source ; target ; type # comment", where source
+// is one code point and target a sequence, both in hex. It also returns
+// the leading comment lines, which name the file and its version.
+func parse(r io.Reader) (header []string, table map[rune]string, err error) {
+	table = make(map[rune]string)
+	scanner := bufio.NewScanner(r)
+	inHeader := true
+	for scanner.Scan() {
+		line := strings.TrimPrefix(scanner.Text(), "\ufeff")
+		if strings.HasPrefix(line, "#") {
+			if inHeader {
+				if comment := strings.TrimSpace(strings.TrimPrefix(line, "#")); comment != "" {
+					header = append(header, comment)
+				}
+			}
+			continue
+		}
+		inHeader = false
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) < 2 {
+			return nil, nil, fmt.Errorf("malformed line %q", scanner.Text())
+		}
+		sources, err := parseCodePoints(fields[0])
+		if err != nil || len(sources) != 1 {
+			return nil, nil, fmt.Errorf("malformed source in %q", scanner.Text())
+		}
+		target, err := parseCodePoints(fields[1])
+		if err != nil || len(target) == 0 {
+			return nil, nil, fmt.Errorf("malformed target in %q", scanner.Text())
+		}
+		table[sources[0]] = string(target)
+	}
+	return header, table, scanner.Err()
+}
+
+func parseCodePoints(field string) ([]rune, error) {
+	var runes []rune
+	for _, hex := range strings.Fields(field) {
+		cp, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return nil, err
+		}
+		runes = append(runes, rune(cp))
+	}
+	return runes, nil
+}
+
+func generate(header []string, table map[rune]string) ([]byte, error) {
+	sources := make([]rune, 0, len(table))
+	for source := range table {
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i] < sources[j] })
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by gen_confusables.go; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package libveritas\n\n")
+	fmt.Fprintf(&b, "// confusableTable maps each code point to the prototype it is confusable\n")
+	fmt.Fprintf(&b, "// with. Source:\n//\n")
+	for _, line := range header {
+		fmt.Fprintf(&b, "//\t%s\n", line)
+	}
+	fmt.Fprintf(&b, "var confusableTable = map[rune]string{\n")
+	for _, source := range sources {
+		fmt.Fprintf(&b, "\t%#04x: %+q,\n", source, table[source])
+	}
+	fmt.Fprintf(&b, "}\n")
+	return format.Source(b.Bytes())
+}
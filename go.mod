@@ -1,3 +1,14 @@
 module github.com/spacesprotocol/libveritas-go
 
 go 1.21
+
+require (
+	github.com/btcsuite/btcd/btcec/v2 v2.3.4
+	golang.org/x/text v0.14.0
+)
+
+require (
+	github.com/btcsuite/btcd/chaincfg/chainhash v1.0.1 // indirect
+	github.com/decred/dcrd/crypto/blake256 v1.0.0 // indirect
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1 // indirect
+)
@@ -0,0 +1,195 @@
+package libveritas
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Handle is a parsed handle name. The forms are
+//
+//	@space        the space itself
+//	label@space   a handle in a space
+//	a.b@space     a nested handle: a under b@space
+//
+// ParseHandle checks this structure: a single '@', a space name, and labels
+// separated by '.', none of them empty, in valid UTF-8 without white space
+// or control characters. It does not implement the protocol's charset and
+// length rules, nor a canonical form: those are decided by the native
+// library, which validates handles when they are passed to it, so a handle
+// accepted here can still be rejected there. Handles are kept as given and
+// compared exactly. The zero Handle is not valid.
+type Handle struct {
+	name string
+}
+
+// ParseHandle checks the structure of s. Errors have code
+// CodeInvalidHandle.
+func ParseHandle(s string) (Handle, error) {
+	if !utf8.ValidString(s) {
+		return Handle{}, invalidHandleError(s, "not valid UTF-8")
+	}
+	if i := strings.IndexFunc(s, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }); i >= 0 {
+		return Handle{}, invalidHandleError(s, fmt.Sprintf("contains %U", []rune(s[i:])[0]))
+	}
+	at := strings.IndexByte(s, '@')
+	if at < 0 {
+		return Handle{}, invalidHandleError(s, "missing '@'")
+	}
+	if strings.IndexByte(s[at+1:], '@') >= 0 {
+		return Handle{}, invalidHandleError(s, "more than one '@'")
+	}
+	if s[at+1:] == "" {
+		return Handle{}, invalidHandleError(s, "space name is empty")
+	}
+	if strings.IndexByte(s[at+1:], '.') >= 0 {
+		return Handle{}, invalidHandleError(s, "space name contains '.'")
+	}
+	if at > 0 {
+		for _, label := range strings.Split(s[:at], ".") {
+			if label == "" {
+				return Handle{}, invalidHandleError(s, "label is empty")
+			}
+		}
+	}
+	return Handle{name: s}, nil
+}
+
+// MustParseHandle is ParseHandle that panics on invalid input, for
+// constants.
+func MustParseHandle(s string) Handle {
+	handle, err := ParseHandle(s)
+	if err != nil {
+		panic(err)
+	}
+	return handle
+}
+
+func invalidHandleError(s string, reason string) error {
+	return withErrorDetails(
+		NewVeritasErrorInvalidInput(fmt.Sprintf("invalid handle %q: %s", s, reason)),
+		ErrorDetails{Code: CodeInvalidHandle, Handle: s},
+	)
+}
+
+// String returns the handle name, e.g. "alice@bitcoin".
+func (h Handle) String() string {
+	return h.name
+}
+
+// IsZero reports whether h is the zero Handle.
+func (h Handle) IsZero() bool {
+	return h.name == ""
+}
+
+// Space returns the space name without '@', e.g. "bitcoin".
+func (h Handle) Space() string {
+	return h.name[strings.IndexByte(h.name, '@')+1:]
+}
+
+// SpaceHandle returns the handle of the space itself, e.g. "@bitcoin".
+func (h Handle) SpaceHandle() Handle {
+	return Handle{name: "@" + h.Space()}
+}
+
+// Labels returns the labels before '@', innermost first: "a.b@space" has
+// labels ["a", "b"]. A space handle has none.
+func (h Handle) Labels() []string {
+	at := strings.IndexByte(h.name, '@')
+	if at <= 0 {
+		return nil
+	}
+	return strings.Split(h.name[:at], ".")
+}
+
+// IsSpace reports whether h names a space, like "@bitcoin".
+func (h Handle) IsSpace() bool {
+	return strings.HasPrefix(h.name, "@")
+}
+
+// IsNested reports whether h is a sub-handle, like "a.b@space".
+func (h Handle) IsNested() bool {
+	return len(h.Labels()) > 1
+}
+
+// Parent returns the handle h is nested under: "b@space" for "a.b@space",
+// "@space" for "b@space". A space handle has no parent.
+func (h Handle) Parent() (Handle, bool) {
+	if h.IsSpace() || h.IsZero() {
+		return Handle{}, false
+	}
+	labels := h.Labels()
+	if len(labels) == 1 {
+		return h.SpaceHandle(), true
+	}
+	return Handle{name: strings.Join(labels[1:], ".") + "@" + h.Space()}, true
+}
+
+// Equal reports whether h and other are the same handle. The comparison is
+// exact: handles are not case-folded or otherwise canonicalized.
+func (h Handle) Equal(other Handle) bool {
+	return h.name == other.name
+}
+
+// Compare orders handles by space, then parents before the handles nested
+// under them, then by label. It returns -1, 0 or +1.
+func (h Handle) Compare(other Handle) int {
+	if c := strings.Compare(h.Space(), other.Space()); c != 0 {
+		return c
+	}
+	a, b := h.Labels(), other.Labels()
+	for i := 1; i <= len(a) && i <= len(b); i++ {
+		if c := strings.Compare(a[len(a)-i], b[len(b)-i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (h Handle) MarshalText() ([]byte, error) {
+	return []byte(h.name), nil
+}
+
+func (h *Handle) UnmarshalText(text []byte) error {
+	handle, err := ParseHandle(string(text))
+	if err != nil {
+		return err
+	}
+	*h = handle
+	return nil
+}
+
+// ParseHandle parses the handle name of the zone.
+func (r Zone) ParseHandle() (Handle, error) {
+	return ParseHandle(r.Handle)
+}
+
+// ParseCanonical parses the canonical (flattened) name of the zone.
+func (r Zone) ParseCanonical() (Handle, error) {
+	return ParseHandle(r.Canonical)
+}
+
+// checkHandle checks the structure of a handle argument with ParseHandle,
+// for the FFI entry points taking handle strings.
+func checkHandle(s string) error {
+	_, err := ParseHandle(s)
+	return err
+}
+
+// checkHandles is checkHandle for a list of handles.
+func checkHandles(names []string) error {
+	for _, name := range names {
+		if err := checkHandle(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package libveritas
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseHandle(t *testing.T) {
+	tests := []struct {
+		in     string
+		ok     bool
+		space  string
+		labels []string
+		parent string
+	}{
+		{in: "@bitcoin", ok: true, space: "bitcoin"},
+		{in: "alice@bitcoin", ok: true, space: "bitcoin", labels: []string{"alice"}, parent: "@bitcoin"},
+		{in: "a.b@bitcoin", ok: true, space: "bitcoin", labels: []string{"a", "b"}, parent: "b@bitcoin"},
+		{in: "a.b.c@x", ok: true, space: "x", labels: []string{"a", "b", "c"}, parent: "b.c@x"},
+		{in: ""},
+		{in: "alice"},
+		{in: "alice@"},
+		{in: "@"},
+		{in: "a@b@c"},
+		{in: ".alice@bitcoin"},
+		{in: "a..b@bitcoin"},
+		{in: "alice.@bitcoin"},
+		{in: "alice@bit.coin"},
+		{in: "al ice@bitcoin"},
+		{in: "alice@bitcoin\n"},
+		{in: "alice\x00@bitcoin"},
+		{in: "alice\u200b\u0085@bitcoin"},
+		{in: "\xffalice@bitcoin"},
+	}
+	for _, tt := range tests {
+		handle, err := ParseHandle(tt.in)
+		if !tt.ok {
+			if !errors.Is(err, ErrInvalidHandle) {
+				t.Errorf("ParseHandle(%q) = %v, want ErrInvalidHandle", tt.in, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseHandle(%q): %v", tt.in, err)
+			continue
+		}
+		if handle.String() != tt.in || handle.Space() != tt.space || !slices.Equal(handle.Labels(), tt.labels) {
+			t.Errorf("ParseHandle(%q) = %q, space %q, labels %q", tt.in, handle, handle.Space(), handle.Labels())
+		}
+		parent, ok := handle.Parent()
+		if ok != (tt.parent != "") || parent.String() != tt.parent {
+			t.Errorf("%q.Parent() = %q, %v, want %q", tt.in, parent, ok, tt.parent)
+		}
+		if handle.IsSpace() != (tt.labels == nil) || handle.IsNested() != (len(tt.labels) > 1) {
+			t.Errorf("%q: IsSpace %v, IsNested %v", tt.in, handle.IsSpace(), handle.IsNested())
+		}
+	}
+}
+
+func TestParseHandleErrorDetails(t *testing.T) {
+	_, err := ParseHandle("alice")
+	var veritasErr *VeritasError
+	if !errors.As(err, &veritasErr) {
+		t.Fatalf("error %v is not a *VeritasError", err)
+	}
+	if details := veritasErr.Details(); details.Code != CodeInvalidHandle || details.Handle != "alice" {
+		t.Errorf("Details = %+v", details)
+	}
+}
+
+func TestHandleCompare(t *testing.T) {
+	ordered := []string{"@a", "x@a", "y.x@a", "y@a", "@b", "a@b"}
+	for i := range ordered {
+		for j := range ordered {
+			a, b := MustParseHandle(ordered[i]), MustParseHandle(ordered[j])
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := a.Compare(b); got != want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestHandleText(t *testing.T) {
+	var decoded struct{ Handle Handle }
+	if err := json.Unmarshal([]byte(`{"Handle":"a.b@bitcoin"}`), &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !decoded.Handle.Equal(MustParseHandle("a.b@bitcoin")) {
+		t.Errorf("Unmarshal = %q", decoded.Handle)
+	}
+	data, err := json.Marshal(decoded)
+	if err != nil || string(data) != `{"Handle":"a.b@bitcoin"}` {
+		t.Errorf("Marshal = %s, %v", data, err)
+	}
+	if err := json.Unmarshal([]byte(`{"Handle":"bitcoin"}`), &decoded); !errors.Is(err, ErrInvalidHandle) {
+		t.Errorf("Unmarshal of invalid handle = %v, want ErrInvalidHandle", err)
+	}
+}
+
+func TestHandleEqualIsExact(t *testing.T) {
+	if MustParseHandle("Alice@bitcoin").Equal(MustParseHandle("alice@bitcoin")) {
+		t.Error("Equal folds case")
+	}
+	if !MustParseHandle("alice@bitcoin").Equal(MustParseHandle("alice@bitcoin")) {
+		t.Error("Equal of the same handle is false")
+	}
+}
+
+func TestCheckHandles(t *testing.T) {
+	if err := checkHandles([]string{"@bitcoin", "alice@bitcoin"}); err != nil {
+		t.Errorf("checkHandles: %v", err)
+	}
+	if err := checkHandles([]string{"@bitcoin", "alice"}); !errors.Is(err, ErrInvalidHandle) {
+		t.Errorf("checkHandles = %v, want ErrInvalidHandle", err)
+	}
+}
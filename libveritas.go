@@ -0,0 +1,78 @@
+// Package libveritas provides Go bindings for the libveritas verifier.
+//
+// Most functionality calls into the native libveritas_uniffi library through
+// cgo. The SIP-7 record codec and Schnorr signature checks are implemented in
+// pure Go and work in every build.
+//
+// The native library is linked when cgo is enabled on a platform with a
+// prebuilt archive under native/ (linux/amd64, darwin/arm64, windows/amd64).
+// The libveritas_dynamic and libveritas_pkgconfig build tags link a
+// system-installed shared library instead, on any platform; see
+// link_dynamic.go and link_pkgconfig.go. Without cgo, or when building with
+// the libveritas_nocgo tag, the package still compiles and the FFI entry
+// points return ErrNativeUnavailable.
+package libveritas
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ErrNativeUnavailable is returned by FFI entry points when the package was
+// built without the native library. Check for it with errors.Is.
+var ErrNativeUnavailable = fmt.Errorf("libveritas: native library not available in this build")
+
+// NativeAvailable reports whether the native library is linked into this build.
+func NativeAvailable() bool {
+	return nativeAvailable
+}
+
+// ErrContractMismatch matches a *ContractError with errors.Is.
+var ErrContractMismatch = fmt.Errorf("libveritas: native library does not match bindings")
+
+// ContractError reports that the linked native library was built for a
+// different API than these bindings. Rebuild or upgrade libveritas_uniffi so
+// both sides come from the same release.
+type ContractError struct {
+	// FFI symbol whose value did not match.
+	Symbol   string
+	Expected uint32
+	Actual   uint32
+}
+
+func (err *ContractError) Error() string {
+	return fmt.Sprintf("%s: %s is %d, expected %d", ErrContractMismatch, err.Symbol, err.Actual, err.Expected)
+}
+
+func (err *ContractError) Is(target error) bool {
+	return target == ErrContractMismatch
+}
+
+var (
+	initOnce sync.Once
+	initErr  error
+)
+
+// Init checks that the linked native library matches these bindings and
+// returns a *ContractError when it does not, or ErrNativeUnavailable in builds
+// without the native library. The check runs once; later calls return the
+// same result. FFI entry points with an error result run it lazily and return
+// its error. Entry points without one (NewMessageBuilder, NewQueryContext,
+// NewRecordSet, SigPrimaryZone, VerifyDefault, VerifyDevMode and
+// VerifyEnableSnark) panic with it instead; call Init at startup to refuse to
+// start with a clear message rather than panic later. Builds without the
+// native library do not panic; see NativeAvailable.
+func Init() error {
+	initOnce.Do(func() {
+		initErr = uniffiCheckChecksums()
+	})
+	return initErr
+}
+
+// mustInit is used by FFI entry points without an error result, which panic
+// with the error of Init as documented on each of them.
+func mustInit() {
+	if err := Init(); err != nil {
+		panic(err)
+	}
+}
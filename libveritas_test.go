@@ -0,0 +1,27 @@
+package libveritas
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestContractError(t *testing.T) {
+	err := error(&ContractError{Symbol: "uniffi_libveritas_uniffi_checksum_func_decode_zone", Expected: 2404, Actual: 1})
+	if !errors.Is(err, ErrContractMismatch) {
+		t.Errorf("errors.Is(%v, ErrContractMismatch) = false", err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "decode_zone is 1, expected 2404") {
+		t.Errorf("Error() = %q", msg)
+	}
+}
+
+func TestInitIsStable(t *testing.T) {
+	first := Init()
+	if second := Init(); second != first {
+		t.Errorf("Init = %v, then %v", first, second)
+	}
+	if (first == nil) != NativeAvailable() {
+		t.Errorf("Init = %v with NativeAvailable %v", first, NativeAvailable())
+	}
+}
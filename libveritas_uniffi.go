@@ -1,15 +1,15 @@
+//go:build cgo && !libveritas_nocgo && (libveritas_dynamic || libveritas_pkgconfig || (linux && amd64) || (darwin && arm64) || (windows && amd64))
+
 package libveritas
 
-// #cgo linux,amd64 LDFLAGS: ${SRCDIR}/native/linux-amd64/liblibveritas_uniffi.a -lm -ldl -lpthread
-// #cgo darwin,arm64 LDFLAGS: ${SRCDIR}/native/darwin-arm64/liblibveritas_uniffi.a -framework Security -framework CoreFoundation -lm
-// #cgo windows,amd64 LDFLAGS: ${SRCDIR}/native/windows-amd64/libveritas_uniffi.lib -lws2_32 -lbcrypt -luserenv -lntdll
-// #include <libveritas_uniffi.h>
+// #include <libveritas_uniffi_shims.h>
 import "C"
 
 import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"math"
 	"runtime"
@@ -17,6 +17,8 @@ import (
 	"unsafe"
 )
 
+const nativeAvailable = true
+
 // This is needed, because as of go 1.24
 // type RustBuffer C.RustBuffer cannot have methods,
 // RustBuffer is treated as non-local type
@@ -94,7 +96,18 @@ func (cb GoRustBuffer) ToGoBytes() []byte {
 }
 
 func stringToRustBuffer(str string) C.RustBuffer {
-	return bytesToRustBuffer([]byte(str))
+	if len(str) == 0 {
+		return C.RustBuffer{}
+	}
+	// The string data is only read by Rust during this call, so it can be
+	// passed without copying it into a byte slice first.
+	foreign := C.ForeignBytes{
+		len:  C.int(len(str)),
+		data: (*C.uchar)(unsafe.Pointer(unsafe.StringData(str))),
+	}
+	return rustCall(func(status *C.RustCallStatus) C.RustBuffer {
+		return C.ffi_libveritas_uniffi_rustbuffer_from_bytes(foreign, status)
+	})
 }
 
 func bytesToRustBuffer(b []byte) C.RustBuffer {
@@ -113,6 +126,19 @@ func bytesToRustBuffer(b []byte) C.RustBuffer {
 	})
 }
 
+// allocRustBuffer allocates a RustBuffer of size bytes and returns it with a
+// slice over its memory, so that it can be filled without an intermediate Go
+// copy. The slice must not be used after the buffer is handed to Rust.
+func allocRustBuffer(size int) (C.RustBuffer, []byte) {
+	if size == 0 {
+		return C.RustBuffer{}, nil
+	}
+	buf := rustCall(func(status *C.RustCallStatus) C.RustBuffer {
+		return C.ffi_libveritas_uniffi_rustbuffer_alloc(C.uint64_t(size), status)
+	})
+	return buf, unsafe.Slice((*byte)(unsafe.Pointer(buf.data)), size)
+}
+
 type BufLifter[GoType any] interface {
 	Lift(value RustBufferI) GoType
 }
@@ -130,16 +156,11 @@ type BufWriter[GoType any] interface {
 }
 
 func LowerIntoRustBuffer[GoType any](bufWriter BufWriter[GoType], value GoType) C.RustBuffer {
-	// This might be not the most efficient way but it does not require knowing allocation size
-	// beforehand
+	// This does not require knowing the allocation size beforehand. Types
+	// with a known size, like []byte, write into the RustBuffer directly.
 	var buffer bytes.Buffer
 	bufWriter.Write(&buffer, value)
-
-	bytes, err := io.ReadAll(&buffer)
-	if err != nil {
-		panic(fmt.Errorf("reading written data: %w", err))
-	}
-	return bytesToRustBuffer(bytes)
+	return bytesToRustBuffer(buffer.Bytes())
 }
 
 func LiftFromRustBuffer[GoType any](bufReader BufReader[GoType], rbuf RustBufferI) GoType {
@@ -166,41 +187,43 @@ func checkCallStatus[E any](converter BufReader[*E], status C.RustCallStatus) *E
 	case 0:
 		return nil
 	case 1:
-		return LiftFromRustBuffer(converter, GoRustBuffer{inner: status.errorBuf})
-	case 2:
-		// when the rust code sees a panic, it tries to construct a rustBuffer
-		// with the message.  but if that code panics, then it just sends back
-		// an empty buffer.
-		if status.errorBuf.len > 0 {
-			panic(fmt.Errorf("%s", FfiConverterStringINSTANCE.Lift(GoRustBuffer{inner: status.errorBuf})))
-		} else {
-			panic(fmt.Errorf("Rust panicked while handling Rust panic"))
+		if converter == nil {
+			return internalError[E]("function not returning an error returned an error")
 		}
+		return liftCallError(converter, status.errorBuf)
+	case 2:
+		return internalError[E](liftPanicMessage(status))
 	default:
-		panic(fmt.Errorf("unknown status code: %d", status.code))
+		return internalError[E](fmt.Sprintf("unknown status code: %d", status.code))
 	}
 }
 
 func checkCallStatusUnknown(status C.RustCallStatus) error {
-	switch status.code {
-	case 0:
-		return nil
-	case 1:
-		panic(fmt.Errorf("function not returning an error returned an error"))
-	case 2:
-		// when the rust code sees a panic, it tries to construct a C.RustBuffer
-		// with the message.  but if that code panics, then it just sends back
-		// an empty buffer.
-		if status.errorBuf.len > 0 {
-			panic(fmt.Errorf("%s", FfiConverterStringINSTANCE.Lift(GoRustBuffer{
-				inner: status.errorBuf,
-			})))
-		} else {
-			panic(fmt.Errorf("Rust panicked while handling Rust panic"))
-		}
-	default:
-		return fmt.Errorf("unknown status code: %d", status.code)
+	return checkCallStatus[VeritasError](nil, status).AsError()
+}
+
+// liftPanicMessage reads the message of a Rust panic out of the status.
+func liftPanicMessage(status C.RustCallStatus) string {
+	// when the rust code sees a panic, it tries to construct a rustBuffer
+	// with the message.  but if that code panics, then it just sends back
+	// an empty buffer.
+	if status.errorBuf.len > 0 {
+		return FfiConverterStringINSTANCE.Lift(GoRustBuffer{inner: status.errorBuf})
+	}
+	return "Rust panicked while handling Rust panic"
+}
+
+// liftCallError reads the error of a failed call. A buffer that does not
+// read is an internal error when panic isolation is enabled.
+func liftCallError[E any](converter BufReader[*E], buf C.RustBuffer) (err *E) {
+	if panicIsolation.Load() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = isolateInternalError[E](recoveredInternalError(recovered, "reading error"))
+			}
+		}()
 	}
+	return LiftFromRustBuffer(converter, GoRustBuffer{inner: buf})
 }
 
 func rustCall[U any](callback func(*C.RustCallStatus) U) U {
@@ -211,10 +234,6 @@ func rustCall[U any](callback func(*C.RustCallStatus) U) U {
 	return returnValue
 }
 
-type NativeError interface {
-	AsError() error
-}
-
 func writeInt8(writer io.Writer, value int8) {
 	if err := binary.Write(writer, binary.BigEndian, value); err != nil {
 		panic(err)
@@ -355,571 +374,485 @@ func readFloat64(reader io.Reader) float64 {
 	return result
 }
 
-func init() {
+// UniFFI contract version these bindings were generated for.
+const uniffiBindingsContractVersion = 29
 
-	uniffiCheckChecksums()
+type uniffiChecksum struct {
+	symbol   string
+	expected uint16
+	actual   func() C.uint16_t
 }
 
-func uniffiCheckChecksums() {
-	// Get the bindings contract version from our ComponentInterface
-	bindingsContractVersion := 29
-	// Get the scaffolding contract version by calling the into the dylib
-	scaffoldingContractVersion := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint32_t {
-		return C.ffi_libveritas_uniffi_uniffi_contract_version()
-	})
-	if bindingsContractVersion != int(scaffoldingContractVersion) {
-		// If this happens try cleaning and rebuilding your project
-		panic("libveritas_uniffi: UniFFI contract version mismatch")
-	}
+// API checksums these bindings were generated for, in generation order.
+var uniffiChecksums = []uniffiChecksum{
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_func_create_certificate_chain",
+		expected: 19194,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_func_create_certificate_chain()
-		})
-		if checksum != 19194 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_func_create_certificate_chain: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_func_decode_certificate",
+		expected: 45631,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_func_decode_certificate()
-		})
-		if checksum != 45631 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_func_decode_certificate: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_func_decode_zone",
+		expected: 2404,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_func_decode_zone()
-		})
-		if checksum != 2404 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_func_decode_zone: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_func_hash_signable_message",
+		expected: 19660,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_func_hash_signable_message()
-		})
-		if checksum != 19660 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_func_hash_signable_message: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_func_sig_primary_zone",
+		expected: 31154,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_func_sig_primary_zone()
-		})
-		if checksum != 31154 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_func_sig_primary_zone: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_func_verify_default",
+		expected: 23067,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_func_verify_default()
-		})
-		if checksum != 23067 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_func_verify_default: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_func_verify_dev_mode",
+		expected: 61727,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_func_verify_dev_mode()
-		})
-		if checksum != 61727 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_func_verify_dev_mode: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_func_verify_enable_snark",
+		expected: 2995,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_func_verify_enable_snark()
-		})
-		if checksum != 2995 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_func_verify_enable_snark: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_func_verify_schnorr",
+		expected: 32215,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_func_verify_schnorr()
-		})
-		if checksum != 32215 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_func_verify_schnorr: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_func_verify_spaces_message",
+		expected: 40969,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_func_verify_spaces_message()
-		})
-		if checksum != 40969 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_func_verify_spaces_message: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_func_zone_is_better_than",
+		expected: 20955,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_func_zone_is_better_than()
-		})
-		if checksum != 20955 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_func_zone_is_better_than: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_func_zone_to_bytes",
+		expected: 41032,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_func_zone_to_bytes()
-		})
-		if checksum != 41032 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_func_zone_to_bytes: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_func_zone_to_json",
+		expected: 31167,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_func_zone_to_json()
-		})
-		if checksum != 31167 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_func_zone_to_json: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_anchors_compute_trust_set",
+		expected: 53033,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_anchors_compute_trust_set()
-		})
-		if checksum != 53033 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_anchors_compute_trust_set: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_lookup_advance",
+		expected: 31693,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_lookup_advance()
-		})
-		if checksum != 31693 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_lookup_advance: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_lookup_expand_zones",
+		expected: 36867,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_lookup_expand_zones()
-		})
-		if checksum != 36867 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_lookup_expand_zones: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_lookup_start",
+		expected: 23573,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_lookup_start()
-		})
-		if checksum != 23573 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_lookup_start: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_message_set_delegate_records",
+		expected: 43644,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_message_set_delegate_records()
-		})
-		if checksum != 43644 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_message_set_delegate_records: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_message_set_records",
+		expected: 14218,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_message_set_records()
-		})
-		if checksum != 14218 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_message_set_records: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_message_to_bytes",
+		expected: 11292,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_message_to_bytes()
-		})
-		if checksum != 11292 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_message_to_bytes: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_message_update",
+		expected: 34093,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_message_update()
-		})
-		if checksum != 34093 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_message_update: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_cert",
+		expected: 30962,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_cert()
-		})
-		if checksum != 30962 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_cert: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_chain",
+		expected: 45223,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_chain()
-		})
-		if checksum != 45223 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_chain: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_handle",
+		expected: 38921,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_handle()
-		})
-		if checksum != 38921 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_handle: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_records",
+		expected: 59027,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_records()
-		})
-		if checksum != 59027 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_records: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_update",
+		expected: 42583,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_update()
-		})
-		if checksum != 42583 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_messagebuilder_add_update: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_messagebuilder_build",
+		expected: 4355,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_messagebuilder_build()
-		})
-		if checksum != 4355 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_messagebuilder_build: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_messagebuilder_chain_proof_request",
+		expected: 17065,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_messagebuilder_chain_proof_request()
-		})
-		if checksum != 17065 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_messagebuilder_chain_proof_request: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_querycontext_add_request",
+		expected: 60666,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_querycontext_add_request()
-		})
-		if checksum != 60666 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_querycontext_add_request: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_querycontext_add_zone",
+		expected: 53280,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_querycontext_add_zone()
-		})
-		if checksum != 53280 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_querycontext_add_zone: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_recordset_is_empty",
+		expected: 27126,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_recordset_is_empty()
-		})
-		if checksum != 27126 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_recordset_is_empty: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_recordset_signing_id",
+		expected: 30761,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_recordset_signing_id()
-		})
-		if checksum != 30761 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_recordset_signing_id: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_recordset_to_bytes",
+		expected: 40275,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_recordset_to_bytes()
-		})
-		if checksum != 40275 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_recordset_to_bytes: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_recordset_unpack",
+		expected: 14406,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_recordset_unpack()
-		})
-		if checksum != 14406 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_recordset_unpack: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_canonical",
+		expected: 12843,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_canonical()
-		})
-		if checksum != 12843 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_canonical: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_flags",
+		expected: 49275,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_flags()
-		})
-		if checksum != 49275 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_flags: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_handle",
+		expected: 10118,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_handle()
-		})
-		if checksum != 10118 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_handle: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_is_delegate",
+		expected: 59568,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_is_delegate()
-		})
-		if checksum != 59568 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_is_delegate: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_pack_sig",
+		expected: 20213,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_pack_sig()
-		})
-		if checksum != 20213 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_pack_sig: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_set_flags",
+		expected: 34759,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_set_flags()
-		})
-		if checksum != 34759 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_set_flags: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_signable_bytes",
+		expected: 63118,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_signable_bytes()
-		})
-		if checksum != 63118 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_signable_bytes: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_signing_id",
+		expected: 34537,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_signing_id()
-		})
-		if checksum != 34537 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_unsignedrecordset_signing_id: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_verifiedmessage_certificates",
+		expected: 10994,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_verifiedmessage_certificates()
-		})
-		if checksum != 10994 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_verifiedmessage_certificates: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_verifiedmessage_message",
+		expected: 38387,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_verifiedmessage_message()
-		})
-		if checksum != 38387 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_verifiedmessage_message: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_verifiedmessage_message_bytes",
+		expected: 60117,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_verifiedmessage_message_bytes()
-		})
-		if checksum != 60117 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_verifiedmessage_message_bytes: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_verifiedmessage_zones",
+		expected: 535,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_verifiedmessage_zones()
-		})
-		if checksum != 535 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_verifiedmessage_zones: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_veritas_compute_trust_set",
+		expected: 20530,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_veritas_compute_trust_set()
-		})
-		if checksum != 20530 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_veritas_compute_trust_set: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_veritas_is_finalized",
+		expected: 15029,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_veritas_is_finalized()
-		})
-		if checksum != 15029 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_veritas_is_finalized: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_veritas_newest_anchor",
+		expected: 205,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_veritas_newest_anchor()
-		})
-		if checksum != 205 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_veritas_newest_anchor: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_veritas_oldest_anchor",
+		expected: 57268,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_veritas_oldest_anchor()
-		})
-		if checksum != 57268 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_veritas_oldest_anchor: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_veritas_sovereignty_for",
+		expected: 5317,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_veritas_sovereignty_for()
-		})
-		if checksum != 5317 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_veritas_sovereignty_for: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_veritas_verify",
+		expected: 24000,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_veritas_verify()
-		})
-		if checksum != 24000 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_veritas_verify: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_method_veritas_verify_with_options",
+		expected: 12524,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_method_veritas_verify_with_options()
-		})
-		if checksum != 12524 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_method_veritas_verify_with_options: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_constructor_anchors_from_json",
+		expected: 36150,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_constructor_anchors_from_json()
-		})
-		if checksum != 36150 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_constructor_anchors_from_json: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_constructor_lookup_new",
+		expected: 37506,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_constructor_lookup_new()
-		})
-		if checksum != 37506 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_constructor_lookup_new: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_constructor_message_new",
+		expected: 49208,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_constructor_message_new()
-		})
-		if checksum != 49208 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_constructor_message_new: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_constructor_messagebuilder_new",
+		expected: 51295,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_constructor_messagebuilder_new()
-		})
-		if checksum != 51295 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_constructor_messagebuilder_new: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_constructor_querycontext_new",
+		expected: 17395,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_constructor_querycontext_new()
-		})
-		if checksum != 17395 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_constructor_querycontext_new: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_constructor_recordset_new",
+		expected: 33356,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_constructor_recordset_new()
-		})
-		if checksum != 33356 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_constructor_recordset_new: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_constructor_recordset_pack",
+		expected: 59235,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_constructor_recordset_pack()
-		})
-		if checksum != 59235 {
-			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_constructor_recordset_pack: UniFFI API checksum mismatch")
-		}
-	}
+		},
+	},
 	{
-		checksum := rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint16_t {
+		symbol:   "uniffi_libveritas_uniffi_checksum_constructor_veritas_new",
+		expected: 7569,
+		actual: func() C.uint16_t {
 			return C.uniffi_libveritas_uniffi_checksum_constructor_veritas_new()
-		})
-		if checksum != 7569 {
+		},
+	},
+}
+
+func uniffiCheckChecksums() error {
+	// Get the scaffolding contract version by calling the into the dylib
+	if scaffoldingContractVersion := ContractVersion(); scaffoldingContractVersion != uniffiBindingsContractVersion {
+		// If this happens try cleaning and rebuilding your project
+		return &ContractError{
+			Symbol:   "ffi_libveritas_uniffi_uniffi_contract_version",
+			Expected: uniffiBindingsContractVersion,
+			Actual:   scaffoldingContractVersion,
+		}
+	}
+	for _, checksum := range uniffiChecksums {
+		if actual := uint16(checksum.actual()); actual != checksum.expected {
 			// If this happens try cleaning and rebuilding your project
-			panic("libveritas_uniffi: uniffi_libveritas_uniffi_checksum_constructor_veritas_new: UniFFI API checksum mismatch")
+			return &ContractError{
+				Symbol:   checksum.symbol,
+				Expected: uint32(checksum.expected),
+				Actual:   uint32(actual),
+			}
 		}
 	}
+	return nil
+}
+
+// ContractVersion returns the UniFFI contract version reported by the linked
+// native library.
+func ContractVersion() uint32 {
+	return uint32(C.ffi_libveritas_uniffi_uniffi_contract_version())
+}
+
+// LibraryVersion describes the linked native library. The library does not
+// export a release version, so this reports its UniFFI contract version and a
+// fingerprint of the API checksums it was built with. Two libraries with the
+// same string expose the same API.
+func LibraryVersion() string {
+	fingerprint := fnv.New32a()
+	for _, checksum := range uniffiChecksums {
+		binary.Write(fingerprint, binary.BigEndian, uint16(checksum.actual()))
+	}
+	return fmt.Sprintf("libveritas_uniffi contract %d, api %08x", ContractVersion(), fingerprint.Sum32())
 }
 
 type FfiConverterUint8 struct{}
@@ -942,10 +875,6 @@ func (FfiConverterUint8) Read(reader io.Reader) uint8 {
 	return readUint8(reader)
 }
 
-type FfiDestroyerUint8 struct{}
-
-func (FfiDestroyerUint8) Destroy(_ uint8) {}
-
 type FfiConverterUint32 struct{}
 
 var FfiConverterUint32INSTANCE = FfiConverterUint32{}
@@ -966,10 +895,6 @@ func (FfiConverterUint32) Read(reader io.Reader) uint32 {
 	return readUint32(reader)
 }
 
-type FfiDestroyerUint32 struct{}
-
-func (FfiDestroyerUint32) Destroy(_ uint32) {}
-
 type FfiConverterUint64 struct{}
 
 var FfiConverterUint64INSTANCE = FfiConverterUint64{}
@@ -990,10 +915,6 @@ func (FfiConverterUint64) Read(reader io.Reader) uint64 {
 	return readUint64(reader)
 }
 
-type FfiDestroyerUint64 struct{}
-
-func (FfiDestroyerUint64) Destroy(_ uint64) {}
-
 type FfiConverterBool struct{}
 
 var FfiConverterBoolINSTANCE = FfiConverterBool{}
@@ -1021,22 +942,16 @@ func (FfiConverterBool) Read(reader io.Reader) bool {
 	return readInt8(reader) != 0
 }
 
-type FfiDestroyerBool struct{}
-
-func (FfiDestroyerBool) Destroy(_ bool) {}
-
 type FfiConverterString struct{}
 
 var FfiConverterStringINSTANCE = FfiConverterString{}
 
 func (FfiConverterString) Lift(rb RustBufferI) string {
 	defer rb.Free()
-	reader := rb.AsReader()
-	b, err := io.ReadAll(reader)
-	if err != nil {
-		panic(fmt.Errorf("reading reader: %w", err))
+	if rb.Len() == 0 {
+		return ""
 	}
-	return string(b)
+	return string(unsafe.Slice((*byte)(rb.Data()), rb.Len()))
 }
 
 func (FfiConverterString) Read(reader io.Reader) string {
@@ -1075,16 +990,19 @@ func (FfiConverterString) Write(writer io.Writer, value string) {
 	}
 }
 
-type FfiDestroyerString struct{}
-
-func (FfiDestroyerString) Destroy(_ string) {}
-
 type FfiConverterBytes struct{}
 
 var FfiConverterBytesINSTANCE = FfiConverterBytes{}
 
 func (c FfiConverterBytes) Lower(value []byte) C.RustBuffer {
-	return LowerIntoRustBuffer[[]byte](c, value)
+	if len(value) > math.MaxInt32 {
+		panic("[]byte is too large to fit into Int32")
+	}
+	// Same layout as Write, filled in place.
+	buf, data := allocRustBuffer(4 + len(value))
+	binary.BigEndian.PutUint32(data, uint32(len(value)))
+	copy(data[4:], value)
+	return buf
 }
 
 func (c FfiConverterBytes) LowerExternal(value []byte) ExternalCRustBuffer {
@@ -1110,6 +1028,16 @@ func (c FfiConverterBytes) Lift(rb RustBufferI) []byte {
 	return LiftFromRustBuffer[[]byte](c, rb)
 }
 
+// LiftView wraps rb in a BytesView without copying it. The view owns rb.
+func (c FfiConverterBytes) LiftView(rb RustBufferI) *BytesView {
+	data := unsafe.Slice((*byte)(rb.Data()), rb.Len())
+	if len(data) < 4 || uint64(binary.BigEndian.Uint32(data)) != uint64(len(data)-4) {
+		rb.Free()
+		panic(fmt.Errorf("bad length prefix when lifting []byte view"))
+	}
+	return newBytesView(data[4:], rb.Free)
+}
+
 func (c FfiConverterBytes) Read(reader io.Reader) []byte {
 	length := readInt32(reader)
 	buffer := make([]byte, length)
@@ -1123,10 +1051,6 @@ func (c FfiConverterBytes) Read(reader io.Reader) []byte {
 	return buffer
 }
 
-type FfiDestroyerBytes struct{}
-
-func (FfiDestroyerBytes) Destroy(_ []byte) {}
-
 // Below is an implementation of synchronization requirements outlined in the link.
 // https://github.com/mozilla/uniffi-rs/blob/0dc031132d9493ca812c3af6e7dd60ad2ea95bf0/uniffi_bindgen/src/bindings/kotlin/templates/ObjectRuntime.kt#L31
 
@@ -1136,6 +1060,8 @@ type FfiObject struct {
 	cloneFunction func(unsafe.Pointer, *C.RustCallStatus) unsafe.Pointer
 	freeFunction  func(unsafe.Pointer, *C.RustCallStatus)
 	destroyed     atomic.Bool
+	// Set by trackObject; 0 if the object is not tracked.
+	trackingID uint64
 }
 
 func newFfiObject(
@@ -1151,22 +1077,58 @@ func newFfiObject(
 }
 
 func (ffiObject *FfiObject) incrementPointer(debugName string) unsafe.Pointer {
+	if err := ffiObject.acquire(debugName); err != nil {
+		panic(err)
+	}
+	return rustCall(func(status *C.RustCallStatus) unsafe.Pointer {
+		return ffiObject.cloneFunction(ffiObject.pointer, status)
+	})
+}
+
+// borrowPointer pins the object like incrementPointer, but returns its own
+// pointer without cloning it. Method calls pass it to the shims in
+// libveritas_uniffi_shims.h, which clone it within the same cgo call.
+func (ffiObject *FfiObject) borrowPointer(debugName string) unsafe.Pointer {
+	if err := ffiObject.acquire(debugName); err != nil {
+		panic(err)
+	}
+	return ffiObject.pointer
+}
+
+// acquire increments the call counter, which defers freeing the object
+// until the matching decrementPointer.
+func (ffiObject *FfiObject) acquire(debugName string) error {
 	for {
 		counter := ffiObject.callCounter.Load()
 		if counter <= -1 {
-			panic(fmt.Errorf("%v object has already been destroyed", debugName))
+			return fmt.Errorf("%v object has already been destroyed", debugName)
 		}
 		if counter == math.MaxInt64 {
-			panic(fmt.Errorf("%v object call counter would overflow", debugName))
+			return fmt.Errorf("%v object call counter would overflow", debugName)
 		}
 		if ffiObject.callCounter.CompareAndSwap(counter, counter+1) {
-			break
+			return nil
 		}
 	}
+}
 
-	return rustCall(func(status *C.RustCallStatus) unsafe.Pointer {
-		return ffiObject.cloneFunction(ffiObject.pointer, status)
-	})
+// pinObjectArg pins an object argument of an FFI call until unpin is called
+// after the call, so a concurrent Destroy cannot free it mid-call. The shim
+// making the call clones the object's pointer for Rust. A destroyed object
+// is reported as an error instead of a panic; callers check for nil
+// themselves.
+func pinObjectArg(name string, ffiObject *FfiObject) (unpin func(), err error) {
+	if err := ffiObject.acquire(name); err != nil {
+		return nil, newInvalidInputError(CodeInvalidInput, err.Error())
+	}
+	return ffiObject.decrementPointer, nil
+}
+
+// readPointer reads an object handle written by the native side. The value
+// is a pointer produced by Rust, not a Go pointer.
+func readPointer(reader io.Reader) unsafe.Pointer {
+	value := uintptr(readUint64(reader))
+	return *(*unsafe.Pointer)(unsafe.Pointer(&value))
 }
 
 func (ffiObject *FfiObject) decrementPointer() {
@@ -1184,20 +1146,24 @@ func (ffiObject *FfiObject) destroy() {
 }
 
 func (ffiObject *FfiObject) freeRustArcPtr() {
+	untrackObject(ffiObject.trackingID)
 	rustCall(func(status *C.RustCallStatus) int32 {
 		ffiObject.freeFunction(ffiObject.pointer, status)
 		return 0
 	})
 }
 
-type AnchorsInterface interface {
-	ComputeTrustSet() TrustSet
-}
 type Anchors struct {
 	ffiObject FfiObject
+	// The JSON the anchors were created from; see Entries.
+	source string
 }
 
 func AnchorsFromJson(json string) (*Anchors, error) {
+	if err := Init(); err != nil {
+		var _uniffiDefaultValue *Anchors
+		return _uniffiDefaultValue, err
+	}
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) unsafe.Pointer {
 		return C.uniffi_libveritas_uniffi_fn_constructor_anchors_from_json(FfiConverterStringINSTANCE.Lower(json), _uniffiStatus)
 	})
@@ -1205,20 +1171,25 @@ func AnchorsFromJson(json string) (*Anchors, error) {
 		var _uniffiDefaultValue *Anchors
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterAnchorsINSTANCE.Lift(_uniffiRV), nil
+		result, err := liftResult(FfiConverterAnchorsINSTANCE.Lift, _uniffiRV)
+		if err == nil {
+			result.source = json
+		}
+		return result, err
 	}
 }
 
 func (_self *Anchors) ComputeTrustSet() TrustSet {
-	_pointer := _self.ffiObject.incrementPointer("*Anchors")
+	_pointer := _self.ffiObject.borrowPointer("*Anchors")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterTrustSetINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_anchors_compute_trust_set(
+			inner: C.libveritas_anchors_compute_trust_set(
 				_pointer, _uniffiStatus),
 		}
 	}))
 }
+
 func (object *Anchors) Destroy() {
 	runtime.SetFinalizer(object, nil)
 	object.ffiObject.destroy()
@@ -1230,7 +1201,7 @@ var FfiConverterAnchorsINSTANCE = FfiConverterAnchors{}
 
 func (c FfiConverterAnchors) Lift(pointer unsafe.Pointer) *Anchors {
 	result := &Anchors{
-		newFfiObject(
+		ffiObject: newFfiObject(
 			pointer,
 			func(pointer unsafe.Pointer, status *C.RustCallStatus) unsafe.Pointer {
 				return C.uniffi_libveritas_uniffi_fn_clone_anchors(pointer, status)
@@ -1241,44 +1212,28 @@ func (c FfiConverterAnchors) Lift(pointer unsafe.Pointer) *Anchors {
 		),
 	}
 	runtime.SetFinalizer(result, (*Anchors).Destroy)
+	result.ffiObject.trackingID = trackObject("Anchors")
 	return result
 }
 
 func (c FfiConverterAnchors) Read(reader io.Reader) *Anchors {
-	return c.Lift(unsafe.Pointer(uintptr(readUint64(reader))))
+	return c.Lift(readPointer(reader))
 }
 
 func (c FfiConverterAnchors) Lower(value *Anchors) unsafe.Pointer {
-	// TODO: this is bad - all synchronization from ObjectRuntime.go is discarded here,
-	// because the pointer will be decremented immediately after this function returns,
-	// and someone will be left holding onto a non-locked pointer.
+	// The returned pointer is a clone, a reference of its own that the
+	// receiving side must free, so a later Destroy of value does not free it.
+	// value is pinned while it is cloned. Object arguments of FFI calls use
+	// pinObjectArg and the call shims instead, which clone within the call.
 	pointer := value.ffiObject.incrementPointer("*Anchors")
 	defer value.ffiObject.decrementPointer()
 	return pointer
-
 }
 
 func (c FfiConverterAnchors) Write(writer io.Writer, value *Anchors) {
 	writeUint64(writer, uint64(uintptr(c.Lower(value))))
 }
 
-type FfiDestroyerAnchors struct{}
-
-func (_ FfiDestroyerAnchors) Destroy(value *Anchors) {
-	value.Destroy()
-}
-
-// Batched iterative resolver for nested handle names.
-type LookupInterface interface {
-	// Feed zones from a resolveAll response.
-	// Returns the next batch of handles to look up (empty = done).
-	Advance(zones []Zone) ([]string, error)
-	// Expand zone handles using the alias map accumulated during resolution.
-	ExpandZones(zones []Zone) ([]Zone, error)
-	// Returns the first batch of handles to look up.
-	Start() []string
-}
-
 // Batched iterative resolver for nested handle names.
 type Lookup struct {
 	ffiObject FfiObject
@@ -1286,6 +1241,13 @@ type Lookup struct {
 
 // Create a lookup from a list of handle name strings.
 func NewLookup(names []string) (*Lookup, error) {
+	if err := Init(); err != nil {
+		var _uniffiDefaultValue *Lookup
+		return _uniffiDefaultValue, err
+	}
+	if err := checkHandles(names); err != nil {
+		return nil, err
+	}
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) unsafe.Pointer {
 		return C.uniffi_libveritas_uniffi_fn_constructor_lookup_new(FfiConverterSequenceStringINSTANCE.Lower(names), _uniffiStatus)
 	})
@@ -1293,18 +1255,18 @@ func NewLookup(names []string) (*Lookup, error) {
 		var _uniffiDefaultValue *Lookup
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterLookupINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterLookupINSTANCE.Lift, _uniffiRV)
 	}
 }
 
 // Feed zones from a resolveAll response.
 // Returns the next batch of handles to look up (empty = done).
 func (_self *Lookup) Advance(zones []Zone) ([]string, error) {
-	_pointer := _self.ffiObject.incrementPointer("*Lookup")
+	_pointer := _self.ffiObject.borrowPointer("*Lookup")
 	defer _self.ffiObject.decrementPointer()
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_lookup_advance(
+			inner: C.libveritas_lookup_advance(
 				_pointer, FfiConverterSequenceZoneINSTANCE.Lower(zones), _uniffiStatus),
 		}
 	})
@@ -1312,17 +1274,17 @@ func (_self *Lookup) Advance(zones []Zone) ([]string, error) {
 		var _uniffiDefaultValue []string
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterSequenceStringINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterSequenceStringINSTANCE.Lift, _uniffiRV)
 	}
 }
 
 // Expand zone handles using the alias map accumulated during resolution.
 func (_self *Lookup) ExpandZones(zones []Zone) ([]Zone, error) {
-	_pointer := _self.ffiObject.incrementPointer("*Lookup")
+	_pointer := _self.ffiObject.borrowPointer("*Lookup")
 	defer _self.ffiObject.decrementPointer()
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_lookup_expand_zones(
+			inner: C.libveritas_lookup_expand_zones(
 				_pointer, FfiConverterSequenceZoneINSTANCE.Lower(zones), _uniffiStatus),
 		}
 	})
@@ -1330,21 +1292,22 @@ func (_self *Lookup) ExpandZones(zones []Zone) ([]Zone, error) {
 		var _uniffiDefaultValue []Zone
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterSequenceZoneINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterSequenceZoneINSTANCE.Lift, _uniffiRV)
 	}
 }
 
 // Returns the first batch of handles to look up.
 func (_self *Lookup) Start() []string {
-	_pointer := _self.ffiObject.incrementPointer("*Lookup")
+	_pointer := _self.ffiObject.borrowPointer("*Lookup")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterSequenceStringINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_lookup_start(
+			inner: C.libveritas_lookup_start(
 				_pointer, _uniffiStatus),
 		}
 	}))
 }
+
 func (object *Lookup) Destroy() {
 	runtime.SetFinalizer(object, nil)
 	object.ffiObject.destroy()
@@ -1367,49 +1330,38 @@ func (c FfiConverterLookup) Lift(pointer unsafe.Pointer) *Lookup {
 		),
 	}
 	runtime.SetFinalizer(result, (*Lookup).Destroy)
+	result.ffiObject.trackingID = trackObject("Lookup")
 	return result
 }
 
 func (c FfiConverterLookup) Read(reader io.Reader) *Lookup {
-	return c.Lift(unsafe.Pointer(uintptr(readUint64(reader))))
+	return c.Lift(readPointer(reader))
 }
 
 func (c FfiConverterLookup) Lower(value *Lookup) unsafe.Pointer {
-	// TODO: this is bad - all synchronization from ObjectRuntime.go is discarded here,
-	// because the pointer will be decremented immediately after this function returns,
-	// and someone will be left holding onto a non-locked pointer.
+	// The returned pointer is a clone, a reference of its own that the
+	// receiving side must free, so a later Destroy of value does not free it.
+	// value is pinned while it is cloned. Object arguments of FFI calls use
+	// pinObjectArg and the call shims instead, which clone within the call.
 	pointer := value.ffiObject.incrementPointer("*Lookup")
 	defer value.ffiObject.decrementPointer()
 	return pointer
-
 }
 
 func (c FfiConverterLookup) Write(writer io.Writer, value *Lookup) {
 	writeUint64(writer, uint64(uintptr(c.Lower(value))))
 }
 
-type FfiDestroyerLookup struct{}
-
-func (_ FfiDestroyerLookup) Destroy(value *Lookup) {
-	value.Destroy()
-}
-
-type MessageInterface interface {
-	// Set delegate records on the message for a canonical name.
-	SetDelegateRecords(canonical string, recordsBytes []byte) error
-	// Set records on the message for a canonical name.
-	SetRecords(canonical string, recordsBytes []byte) error
-	// Serialize the message to bytes.
-	ToBytes() []byte
-	// Update records on this message.
-	Update(updates []DataUpdateEntry) error
-}
 type Message struct {
 	ffiObject FfiObject
 }
 
 // Decode a message from bytes.
 func NewMessage(bytes []byte) (*Message, error) {
+	if err := Init(); err != nil {
+		var _uniffiDefaultValue *Message
+		return _uniffiDefaultValue, err
+	}
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) unsafe.Pointer {
 		return C.uniffi_libveritas_uniffi_fn_constructor_message_new(FfiConverterBytesINSTANCE.Lower(bytes), _uniffiStatus)
 	})
@@ -1417,16 +1369,16 @@ func NewMessage(bytes []byte) (*Message, error) {
 		var _uniffiDefaultValue *Message
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterMessageINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterMessageINSTANCE.Lift, _uniffiRV)
 	}
 }
 
 // Set delegate records on the message for a canonical name.
 func (_self *Message) SetDelegateRecords(canonical string, recordsBytes []byte) error {
-	_pointer := _self.ffiObject.incrementPointer("*Message")
+	_pointer := _self.ffiObject.borrowPointer("*Message")
 	defer _self.ffiObject.decrementPointer()
 	_, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) bool {
-		C.uniffi_libveritas_uniffi_fn_method_message_set_delegate_records(
+		C.libveritas_message_set_delegate_records(
 			_pointer, FfiConverterStringINSTANCE.Lower(canonical), FfiConverterBytesINSTANCE.Lower(recordsBytes), _uniffiStatus)
 		return false
 	})
@@ -1435,10 +1387,10 @@ func (_self *Message) SetDelegateRecords(canonical string, recordsBytes []byte)
 
 // Set records on the message for a canonical name.
 func (_self *Message) SetRecords(canonical string, recordsBytes []byte) error {
-	_pointer := _self.ffiObject.incrementPointer("*Message")
+	_pointer := _self.ffiObject.borrowPointer("*Message")
 	defer _self.ffiObject.decrementPointer()
 	_, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) bool {
-		C.uniffi_libveritas_uniffi_fn_method_message_set_records(
+		C.libveritas_message_set_records(
 			_pointer, FfiConverterStringINSTANCE.Lower(canonical), FfiConverterBytesINSTANCE.Lower(recordsBytes), _uniffiStatus)
 		return false
 	})
@@ -1447,11 +1399,24 @@ func (_self *Message) SetRecords(canonical string, recordsBytes []byte) error {
 
 // Serialize the message to bytes.
 func (_self *Message) ToBytes() []byte {
-	_pointer := _self.ffiObject.incrementPointer("*Message")
+	_pointer := _self.ffiObject.borrowPointer("*Message")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterBytesINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_message_to_bytes(
+			inner: C.libveritas_message_to_bytes(
+				_pointer, _uniffiStatus),
+		}
+	}))
+}
+
+// Serialize the message to bytes, as a view of the native buffer.
+// Release the view when done with it.
+func (_self *Message) ToBytesView() *BytesView {
+	_pointer := _self.ffiObject.borrowPointer("*Message")
+	defer _self.ffiObject.decrementPointer()
+	return FfiConverterBytesINSTANCE.LiftView(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
+		return GoRustBuffer{
+			inner: C.libveritas_message_to_bytes(
 				_pointer, _uniffiStatus),
 		}
 	}))
@@ -1459,15 +1424,16 @@ func (_self *Message) ToBytes() []byte {
 
 // Update records on this message.
 func (_self *Message) Update(updates []DataUpdateEntry) error {
-	_pointer := _self.ffiObject.incrementPointer("*Message")
+	_pointer := _self.ffiObject.borrowPointer("*Message")
 	defer _self.ffiObject.decrementPointer()
 	_, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) bool {
-		C.uniffi_libveritas_uniffi_fn_method_message_update(
+		C.libveritas_message_update(
 			_pointer, FfiConverterSequenceDataUpdateEntryINSTANCE.Lower(updates), _uniffiStatus)
 		return false
 	})
 	return _uniffiErr.AsError()
 }
+
 func (object *Message) Destroy() {
 	runtime.SetFinalizer(object, nil)
 	object.ffiObject.destroy()
@@ -1490,63 +1456,38 @@ func (c FfiConverterMessage) Lift(pointer unsafe.Pointer) *Message {
 		),
 	}
 	runtime.SetFinalizer(result, (*Message).Destroy)
+	result.ffiObject.trackingID = trackObject("Message")
 	return result
 }
 
 func (c FfiConverterMessage) Read(reader io.Reader) *Message {
-	return c.Lift(unsafe.Pointer(uintptr(readUint64(reader))))
+	return c.Lift(readPointer(reader))
 }
 
 func (c FfiConverterMessage) Lower(value *Message) unsafe.Pointer {
-	// TODO: this is bad - all synchronization from ObjectRuntime.go is discarded here,
-	// because the pointer will be decremented immediately after this function returns,
-	// and someone will be left holding onto a non-locked pointer.
+	// The returned pointer is a clone, a reference of its own that the
+	// receiving side must free, so a later Destroy of value does not free it.
+	// value is pinned while it is cloned. Object arguments of FFI calls use
+	// pinObjectArg and the call shims instead, which clone within the call.
 	pointer := value.ffiObject.incrementPointer("*Message")
 	defer value.ffiObject.decrementPointer()
 	return pointer
-
 }
 
 func (c FfiConverterMessage) Write(writer io.Writer, value *Message) {
 	writeUint64(writer, uint64(uintptr(c.Lower(value))))
 }
 
-type FfiDestroyerMessage struct{}
-
-func (_ FfiDestroyerMessage) Destroy(value *Message) {
-	value.Destroy()
-}
-
-// Builder for constructing messages from update requests and chain proofs.
-type MessageBuilderInterface interface {
-	// Add a single certificate.
-	AddCert(certBytes []byte) error
-	// Add all certificates from a .spacecert chain.
-	AddChain(chainBytes []byte) error
-	// Add a .spacecert chain with records (sip7 wire bytes).
-	AddHandle(chainBytes []byte, recordsBytes []byte) error
-	// Add records for a handle (sip7 wire bytes).
-	AddRecords(handle string, recordsBytes []byte) error
-	// Add a full data update (records + optional delegate records).
-	AddUpdate(entry DataUpdateEntry) error
-	// Build the message from a ChainProof.
-	//
-	// Consumes the builder — cannot be called twice.
-	// Returns the message and unsigned record sets that need signing.
-	Build(chainProof []byte) (BuildResult, error)
-	// Returns the chain proof request as JSON.
-	//
-	// Send this to the provider/fabric to get the chain proofs needed for `build()`.
-	ChainProofRequest() (string, error)
-}
-
 // Builder for constructing messages from update requests and chain proofs.
 type MessageBuilder struct {
 	ffiObject FfiObject
 }
 
 // Create an empty builder.
+//
+// NewMessageBuilder panics with the error of Init if it fails.
 func NewMessageBuilder() *MessageBuilder {
+	mustInit()
 	return FfiConverterMessageBuilderINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) unsafe.Pointer {
 		return C.uniffi_libveritas_uniffi_fn_constructor_messagebuilder_new(_uniffiStatus)
 	}))
@@ -1554,10 +1495,10 @@ func NewMessageBuilder() *MessageBuilder {
 
 // Add a single certificate.
 func (_self *MessageBuilder) AddCert(certBytes []byte) error {
-	_pointer := _self.ffiObject.incrementPointer("*MessageBuilder")
+	_pointer := _self.ffiObject.borrowPointer("*MessageBuilder")
 	defer _self.ffiObject.decrementPointer()
 	_, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) bool {
-		C.uniffi_libveritas_uniffi_fn_method_messagebuilder_add_cert(
+		C.libveritas_messagebuilder_add_cert(
 			_pointer, FfiConverterBytesINSTANCE.Lower(certBytes), _uniffiStatus)
 		return false
 	})
@@ -1565,11 +1506,16 @@ func (_self *MessageBuilder) AddCert(certBytes []byte) error {
 }
 
 // Add all certificates from a .spacecert chain.
+//
+// The chain is parsed and checked with CertificateChain.Validate first.
 func (_self *MessageBuilder) AddChain(chainBytes []byte) error {
-	_pointer := _self.ffiObject.incrementPointer("*MessageBuilder")
+	if err := checkSpacecert(chainBytes); err != nil {
+		return err
+	}
+	_pointer := _self.ffiObject.borrowPointer("*MessageBuilder")
 	defer _self.ffiObject.decrementPointer()
 	_, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) bool {
-		C.uniffi_libveritas_uniffi_fn_method_messagebuilder_add_chain(
+		C.libveritas_messagebuilder_add_chain(
 			_pointer, FfiConverterBytesINSTANCE.Lower(chainBytes), _uniffiStatus)
 		return false
 	})
@@ -1577,11 +1523,16 @@ func (_self *MessageBuilder) AddChain(chainBytes []byte) error {
 }
 
 // Add a .spacecert chain with records (sip7 wire bytes).
+//
+// The chain is parsed and checked with CertificateChain.Validate first.
 func (_self *MessageBuilder) AddHandle(chainBytes []byte, recordsBytes []byte) error {
-	_pointer := _self.ffiObject.incrementPointer("*MessageBuilder")
+	if err := checkSpacecert(chainBytes); err != nil {
+		return err
+	}
+	_pointer := _self.ffiObject.borrowPointer("*MessageBuilder")
 	defer _self.ffiObject.decrementPointer()
 	_, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) bool {
-		C.uniffi_libveritas_uniffi_fn_method_messagebuilder_add_handle(
+		C.libveritas_messagebuilder_add_handle(
 			_pointer, FfiConverterBytesINSTANCE.Lower(chainBytes), FfiConverterBytesINSTANCE.Lower(recordsBytes), _uniffiStatus)
 		return false
 	})
@@ -1590,10 +1541,13 @@ func (_self *MessageBuilder) AddHandle(chainBytes []byte, recordsBytes []byte) e
 
 // Add records for a handle (sip7 wire bytes).
 func (_self *MessageBuilder) AddRecords(handle string, recordsBytes []byte) error {
-	_pointer := _self.ffiObject.incrementPointer("*MessageBuilder")
+	if err := checkHandle(handle); err != nil {
+		return err
+	}
+	_pointer := _self.ffiObject.borrowPointer("*MessageBuilder")
 	defer _self.ffiObject.decrementPointer()
 	_, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) bool {
-		C.uniffi_libveritas_uniffi_fn_method_messagebuilder_add_records(
+		C.libveritas_messagebuilder_add_records(
 			_pointer, FfiConverterStringINSTANCE.Lower(handle), FfiConverterBytesINSTANCE.Lower(recordsBytes), _uniffiStatus)
 		return false
 	})
@@ -1602,10 +1556,10 @@ func (_self *MessageBuilder) AddRecords(handle string, recordsBytes []byte) erro
 
 // Add a full data update (records + optional delegate records).
 func (_self *MessageBuilder) AddUpdate(entry DataUpdateEntry) error {
-	_pointer := _self.ffiObject.incrementPointer("*MessageBuilder")
+	_pointer := _self.ffiObject.borrowPointer("*MessageBuilder")
 	defer _self.ffiObject.decrementPointer()
 	_, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) bool {
-		C.uniffi_libveritas_uniffi_fn_method_messagebuilder_add_update(
+		C.libveritas_messagebuilder_add_update(
 			_pointer, FfiConverterDataUpdateEntryINSTANCE.Lower(entry), _uniffiStatus)
 		return false
 	})
@@ -1617,11 +1571,11 @@ func (_self *MessageBuilder) AddUpdate(entry DataUpdateEntry) error {
 // Consumes the builder — cannot be called twice.
 // Returns the message and unsigned record sets that need signing.
 func (_self *MessageBuilder) Build(chainProof []byte) (BuildResult, error) {
-	_pointer := _self.ffiObject.incrementPointer("*MessageBuilder")
+	_pointer := _self.ffiObject.borrowPointer("*MessageBuilder")
 	defer _self.ffiObject.decrementPointer()
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_messagebuilder_build(
+			inner: C.libveritas_messagebuilder_build(
 				_pointer, FfiConverterBytesINSTANCE.Lower(chainProof), _uniffiStatus),
 		}
 	})
@@ -1629,7 +1583,7 @@ func (_self *MessageBuilder) Build(chainProof []byte) (BuildResult, error) {
 		var _uniffiDefaultValue BuildResult
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterBuildResultINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterBuildResultINSTANCE.Lift, _uniffiRV)
 	}
 }
 
@@ -1637,11 +1591,11 @@ func (_self *MessageBuilder) Build(chainProof []byte) (BuildResult, error) {
 //
 // Send this to the provider/fabric to get the chain proofs needed for `build()`.
 func (_self *MessageBuilder) ChainProofRequest() (string, error) {
-	_pointer := _self.ffiObject.incrementPointer("*MessageBuilder")
+	_pointer := _self.ffiObject.borrowPointer("*MessageBuilder")
 	defer _self.ffiObject.decrementPointer()
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_messagebuilder_chain_proof_request(
+			inner: C.libveritas_messagebuilder_chain_proof_request(
 				_pointer, _uniffiStatus),
 		}
 	})
@@ -1649,9 +1603,10 @@ func (_self *MessageBuilder) ChainProofRequest() (string, error) {
 		var _uniffiDefaultValue string
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterStringINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterStringINSTANCE.Lift, _uniffiRV)
 	}
 }
+
 func (object *MessageBuilder) Destroy() {
 	runtime.SetFinalizer(object, nil)
 	object.ffiObject.destroy()
@@ -1674,45 +1629,37 @@ func (c FfiConverterMessageBuilder) Lift(pointer unsafe.Pointer) *MessageBuilder
 		),
 	}
 	runtime.SetFinalizer(result, (*MessageBuilder).Destroy)
+	result.ffiObject.trackingID = trackObject("MessageBuilder")
 	return result
 }
 
 func (c FfiConverterMessageBuilder) Read(reader io.Reader) *MessageBuilder {
-	return c.Lift(unsafe.Pointer(uintptr(readUint64(reader))))
+	return c.Lift(readPointer(reader))
 }
 
 func (c FfiConverterMessageBuilder) Lower(value *MessageBuilder) unsafe.Pointer {
-	// TODO: this is bad - all synchronization from ObjectRuntime.go is discarded here,
-	// because the pointer will be decremented immediately after this function returns,
-	// and someone will be left holding onto a non-locked pointer.
+	// The returned pointer is a clone, a reference of its own that the
+	// receiving side must free, so a later Destroy of value does not free it.
+	// value is pinned while it is cloned. Object arguments of FFI calls use
+	// pinObjectArg and the call shims instead, which clone within the call.
 	pointer := value.ffiObject.incrementPointer("*MessageBuilder")
 	defer value.ffiObject.decrementPointer()
 	return pointer
-
 }
 
 func (c FfiConverterMessageBuilder) Write(writer io.Writer, value *MessageBuilder) {
 	writeUint64(writer, uint64(uintptr(c.Lower(value))))
 }
 
-type FfiDestroyerMessageBuilder struct{}
-
-func (_ FfiDestroyerMessageBuilder) Destroy(value *MessageBuilder) {
-	value.Destroy()
-}
-
-type QueryContextInterface interface {
-	// Add a handle to verify (e.g. "alice@bitcoin").
-	// If no requests are added, all handles in the message are verified.
-	AddRequest(handle string) error
-	// Add a known zone from stored bytes (from a previous verification).
-	AddZone(zoneBytes []byte) error
-}
 type QueryContext struct {
 	ffiObject FfiObject
+	// Set by SandboxedVeritas.NewQueryContext.
+	log *queryLog
 }
 
+// NewQueryContext panics with the error of Init if it fails.
 func NewQueryContext() *QueryContext {
+	mustInit()
 	return FfiConverterQueryContextINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) unsafe.Pointer {
 		return C.uniffi_libveritas_uniffi_fn_constructor_querycontext_new(_uniffiStatus)
 	}))
@@ -1721,27 +1668,37 @@ func NewQueryContext() *QueryContext {
 // Add a handle to verify (e.g. "alice@bitcoin").
 // If no requests are added, all handles in the message are verified.
 func (_self *QueryContext) AddRequest(handle string) error {
-	_pointer := _self.ffiObject.incrementPointer("*QueryContext")
+	if err := checkHandle(handle); err != nil {
+		return err
+	}
+	_pointer := _self.ffiObject.borrowPointer("*QueryContext")
 	defer _self.ffiObject.decrementPointer()
 	_, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) bool {
-		C.uniffi_libveritas_uniffi_fn_method_querycontext_add_request(
+		C.libveritas_querycontext_add_request(
 			_pointer, FfiConverterStringINSTANCE.Lower(handle), _uniffiStatus)
 		return false
 	})
+	if _uniffiErr == nil {
+		_self.log.logRequest(handle)
+	}
 	return _uniffiErr.AsError()
 }
 
 // Add a known zone from stored bytes (from a previous verification).
 func (_self *QueryContext) AddZone(zoneBytes []byte) error {
-	_pointer := _self.ffiObject.incrementPointer("*QueryContext")
+	_pointer := _self.ffiObject.borrowPointer("*QueryContext")
 	defer _self.ffiObject.decrementPointer()
 	_, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) bool {
-		C.uniffi_libveritas_uniffi_fn_method_querycontext_add_zone(
+		C.libveritas_querycontext_add_zone(
 			_pointer, FfiConverterBytesINSTANCE.Lower(zoneBytes), _uniffiStatus)
 		return false
 	})
+	if _uniffiErr == nil {
+		_self.log.logZone(zoneBytes)
+	}
 	return _uniffiErr.AsError()
 }
+
 func (object *QueryContext) Destroy() {
 	runtime.SetFinalizer(object, nil)
 	object.ffiObject.destroy()
@@ -1753,7 +1710,7 @@ var FfiConverterQueryContextINSTANCE = FfiConverterQueryContext{}
 
 func (c FfiConverterQueryContext) Lift(pointer unsafe.Pointer) *QueryContext {
 	result := &QueryContext{
-		newFfiObject(
+		ffiObject: newFfiObject(
 			pointer,
 			func(pointer unsafe.Pointer, status *C.RustCallStatus) unsafe.Pointer {
 				return C.uniffi_libveritas_uniffi_fn_clone_querycontext(pointer, status)
@@ -1764,51 +1721,39 @@ func (c FfiConverterQueryContext) Lift(pointer unsafe.Pointer) *QueryContext {
 		),
 	}
 	runtime.SetFinalizer(result, (*QueryContext).Destroy)
+	result.ffiObject.trackingID = trackObject("QueryContext")
 	return result
 }
 
 func (c FfiConverterQueryContext) Read(reader io.Reader) *QueryContext {
-	return c.Lift(unsafe.Pointer(uintptr(readUint64(reader))))
+	return c.Lift(readPointer(reader))
 }
 
 func (c FfiConverterQueryContext) Lower(value *QueryContext) unsafe.Pointer {
-	// TODO: this is bad - all synchronization from ObjectRuntime.go is discarded here,
-	// because the pointer will be decremented immediately after this function returns,
-	// and someone will be left holding onto a non-locked pointer.
+	// The returned pointer is a clone, a reference of its own that the
+	// receiving side must free, so a later Destroy of value does not free it.
+	// value is pinned while it is cloned. Object arguments of FFI calls use
+	// pinObjectArg and the call shims instead, which clone within the call.
 	pointer := value.ffiObject.incrementPointer("*QueryContext")
 	defer value.ffiObject.decrementPointer()
 	return pointer
-
 }
 
 func (c FfiConverterQueryContext) Write(writer io.Writer, value *QueryContext) {
 	writeUint64(writer, uint64(uintptr(c.Lower(value))))
 }
 
-type FfiDestroyerQueryContext struct{}
-
-func (_ FfiDestroyerQueryContext) Destroy(value *QueryContext) {
-	value.Destroy()
-}
-
-// SIP-7 record set — wire-format encoded records.
-type RecordSetInterface interface {
-	IsEmpty() bool
-	// The 32-byte signing hash (Spaces signed-message prefix + SHA256).
-	SigningId() []byte
-	// Raw wire bytes.
-	ToBytes() []byte
-	// Parse all records.
-	Unpack() ([]ParsedRecord, error)
-}
-
 // SIP-7 record set — wire-format encoded records.
 type RecordSet struct {
 	ffiObject FfiObject
 }
 
 // Wrap raw wire bytes (lazy — no parsing until unpack).
+//
+// NewRecordSet panics with the error of Init if it fails. UnpackRecords
+// parses wire bytes without the native library.
 func NewRecordSet(data []byte) *RecordSet {
+	mustInit()
 	return FfiConverterRecordSetINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) unsafe.Pointer {
 		return C.uniffi_libveritas_uniffi_fn_constructor_recordset_new(FfiConverterBytesINSTANCE.Lower(data), _uniffiStatus)
 	}))
@@ -1816,6 +1761,10 @@ func NewRecordSet(data []byte) *RecordSet {
 
 // Pack records into wire format.
 func RecordSetPack(records []Record) (*RecordSet, error) {
+	if err := Init(); err != nil {
+		var _uniffiDefaultValue *RecordSet
+		return _uniffiDefaultValue, err
+	}
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) unsafe.Pointer {
 		return C.uniffi_libveritas_uniffi_fn_constructor_recordset_pack(FfiConverterSequenceRecordINSTANCE.Lower(records), _uniffiStatus)
 	})
@@ -1823,26 +1772,26 @@ func RecordSetPack(records []Record) (*RecordSet, error) {
 		var _uniffiDefaultValue *RecordSet
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterRecordSetINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterRecordSetINSTANCE.Lift, _uniffiRV)
 	}
 }
 
 func (_self *RecordSet) IsEmpty() bool {
-	_pointer := _self.ffiObject.incrementPointer("*RecordSet")
+	_pointer := _self.ffiObject.borrowPointer("*RecordSet")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterBoolINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) C.int8_t {
-		return C.uniffi_libveritas_uniffi_fn_method_recordset_is_empty(
+		return C.libveritas_recordset_is_empty(
 			_pointer, _uniffiStatus)
 	}))
 }
 
 // The 32-byte signing hash (Spaces signed-message prefix + SHA256).
 func (_self *RecordSet) SigningId() []byte {
-	_pointer := _self.ffiObject.incrementPointer("*RecordSet")
+	_pointer := _self.ffiObject.borrowPointer("*RecordSet")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterBytesINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_recordset_signing_id(
+			inner: C.libveritas_recordset_signing_id(
 				_pointer, _uniffiStatus),
 		}
 	}))
@@ -1850,11 +1799,11 @@ func (_self *RecordSet) SigningId() []byte {
 
 // Raw wire bytes.
 func (_self *RecordSet) ToBytes() []byte {
-	_pointer := _self.ffiObject.incrementPointer("*RecordSet")
+	_pointer := _self.ffiObject.borrowPointer("*RecordSet")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterBytesINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_recordset_to_bytes(
+			inner: C.libveritas_recordset_to_bytes(
 				_pointer, _uniffiStatus),
 		}
 	}))
@@ -1862,11 +1811,11 @@ func (_self *RecordSet) ToBytes() []byte {
 
 // Parse all records.
 func (_self *RecordSet) Unpack() ([]ParsedRecord, error) {
-	_pointer := _self.ffiObject.incrementPointer("*RecordSet")
+	_pointer := _self.ffiObject.borrowPointer("*RecordSet")
 	defer _self.ffiObject.decrementPointer()
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_recordset_unpack(
+			inner: C.libveritas_recordset_unpack(
 				_pointer, _uniffiStatus),
 		}
 	})
@@ -1874,9 +1823,10 @@ func (_self *RecordSet) Unpack() ([]ParsedRecord, error) {
 		var _uniffiDefaultValue []ParsedRecord
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterSequenceParsedRecordINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterSequenceParsedRecordINSTANCE.Lift, _uniffiRV)
 	}
 }
+
 func (object *RecordSet) Destroy() {
 	runtime.SetFinalizer(object, nil)
 	object.ffiObject.destroy()
@@ -1899,53 +1849,28 @@ func (c FfiConverterRecordSet) Lift(pointer unsafe.Pointer) *RecordSet {
 		),
 	}
 	runtime.SetFinalizer(result, (*RecordSet).Destroy)
+	result.ffiObject.trackingID = trackObject("RecordSet")
 	return result
 }
 
 func (c FfiConverterRecordSet) Read(reader io.Reader) *RecordSet {
-	return c.Lift(unsafe.Pointer(uintptr(readUint64(reader))))
+	return c.Lift(readPointer(reader))
 }
 
 func (c FfiConverterRecordSet) Lower(value *RecordSet) unsafe.Pointer {
-	// TODO: this is bad - all synchronization from ObjectRuntime.go is discarded here,
-	// because the pointer will be decremented immediately after this function returns,
-	// and someone will be left holding onto a non-locked pointer.
+	// The returned pointer is a clone, a reference of its own that the
+	// receiving side must free, so a later Destroy of value does not free it.
+	// value is pinned while it is cloned. Object arguments of FFI calls use
+	// pinObjectArg and the call shims instead, which clone within the call.
 	pointer := value.ffiObject.incrementPointer("*RecordSet")
 	defer value.ffiObject.decrementPointer()
 	return pointer
-
 }
 
 func (c FfiConverterRecordSet) Write(writer io.Writer, value *RecordSet) {
 	writeUint64(writer, uint64(uintptr(c.Lower(value))))
 }
 
-type FfiDestroyerRecordSet struct{}
-
-func (_ FfiDestroyerRecordSet) Destroy(value *RecordSet) {
-	value.Destroy()
-}
-
-// An unsigned record set pending signature.
-type UnsignedRecordSetInterface interface {
-	// The canonical/flattened name.
-	Canonical() string
-	// Current sig flags.
-	Flags() uint8
-	// The original handle name (before flattening).
-	Handle() string
-	// Whether these are delegate records.
-	IsDelegate() bool
-	// Pack the Sig record with the given signature. Returns signed RecordSet wire bytes.
-	PackSig(signature []byte) []byte
-	// Set sig flags (e.g. `SIG_PRIMARY_ZONE`).
-	SetFlags(flags uint8)
-	// The raw signable bytes (before hashing). Use when the signer doesn't take a digest.
-	SignableBytes() []byte
-	// The 32-byte signing hash (Spaces signed-message prefix + SHA256).
-	SigningId() []byte
-}
-
 // An unsigned record set pending signature.
 type UnsignedRecordSet struct {
 	ffiObject FfiObject
@@ -1953,11 +1878,11 @@ type UnsignedRecordSet struct {
 
 // The canonical/flattened name.
 func (_self *UnsignedRecordSet) Canonical() string {
-	_pointer := _self.ffiObject.incrementPointer("*UnsignedRecordSet")
+	_pointer := _self.ffiObject.borrowPointer("*UnsignedRecordSet")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterStringINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_unsignedrecordset_canonical(
+			inner: C.libveritas_unsignedrecordset_canonical(
 				_pointer, _uniffiStatus),
 		}
 	}))
@@ -1965,21 +1890,21 @@ func (_self *UnsignedRecordSet) Canonical() string {
 
 // Current sig flags.
 func (_self *UnsignedRecordSet) Flags() uint8 {
-	_pointer := _self.ffiObject.incrementPointer("*UnsignedRecordSet")
+	_pointer := _self.ffiObject.borrowPointer("*UnsignedRecordSet")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterUint8INSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint8_t {
-		return C.uniffi_libveritas_uniffi_fn_method_unsignedrecordset_flags(
+		return C.libveritas_unsignedrecordset_flags(
 			_pointer, _uniffiStatus)
 	}))
 }
 
 // The original handle name (before flattening).
 func (_self *UnsignedRecordSet) Handle() string {
-	_pointer := _self.ffiObject.incrementPointer("*UnsignedRecordSet")
+	_pointer := _self.ffiObject.borrowPointer("*UnsignedRecordSet")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterStringINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_unsignedrecordset_handle(
+			inner: C.libveritas_unsignedrecordset_handle(
 				_pointer, _uniffiStatus),
 		}
 	}))
@@ -1987,21 +1912,21 @@ func (_self *UnsignedRecordSet) Handle() string {
 
 // Whether these are delegate records.
 func (_self *UnsignedRecordSet) IsDelegate() bool {
-	_pointer := _self.ffiObject.incrementPointer("*UnsignedRecordSet")
+	_pointer := _self.ffiObject.borrowPointer("*UnsignedRecordSet")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterBoolINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) C.int8_t {
-		return C.uniffi_libveritas_uniffi_fn_method_unsignedrecordset_is_delegate(
+		return C.libveritas_unsignedrecordset_is_delegate(
 			_pointer, _uniffiStatus)
 	}))
 }
 
 // Pack the Sig record with the given signature. Returns signed RecordSet wire bytes.
 func (_self *UnsignedRecordSet) PackSig(signature []byte) []byte {
-	_pointer := _self.ffiObject.incrementPointer("*UnsignedRecordSet")
+	_pointer := _self.ffiObject.borrowPointer("*UnsignedRecordSet")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterBytesINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_unsignedrecordset_pack_sig(
+			inner: C.libveritas_unsignedrecordset_pack_sig(
 				_pointer, FfiConverterBytesINSTANCE.Lower(signature), _uniffiStatus),
 		}
 	}))
@@ -2009,10 +1934,10 @@ func (_self *UnsignedRecordSet) PackSig(signature []byte) []byte {
 
 // Set sig flags (e.g. `SIG_PRIMARY_ZONE`).
 func (_self *UnsignedRecordSet) SetFlags(flags uint8) {
-	_pointer := _self.ffiObject.incrementPointer("*UnsignedRecordSet")
+	_pointer := _self.ffiObject.borrowPointer("*UnsignedRecordSet")
 	defer _self.ffiObject.decrementPointer()
 	rustCall(func(_uniffiStatus *C.RustCallStatus) bool {
-		C.uniffi_libveritas_uniffi_fn_method_unsignedrecordset_set_flags(
+		C.libveritas_unsignedrecordset_set_flags(
 			_pointer, FfiConverterUint8INSTANCE.Lower(flags), _uniffiStatus)
 		return false
 	})
@@ -2020,11 +1945,11 @@ func (_self *UnsignedRecordSet) SetFlags(flags uint8) {
 
 // The raw signable bytes (before hashing). Use when the signer doesn't take a digest.
 func (_self *UnsignedRecordSet) SignableBytes() []byte {
-	_pointer := _self.ffiObject.incrementPointer("*UnsignedRecordSet")
+	_pointer := _self.ffiObject.borrowPointer("*UnsignedRecordSet")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterBytesINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_unsignedrecordset_signable_bytes(
+			inner: C.libveritas_unsignedrecordset_signable_bytes(
 				_pointer, _uniffiStatus),
 		}
 	}))
@@ -2032,15 +1957,16 @@ func (_self *UnsignedRecordSet) SignableBytes() []byte {
 
 // The 32-byte signing hash (Spaces signed-message prefix + SHA256).
 func (_self *UnsignedRecordSet) SigningId() []byte {
-	_pointer := _self.ffiObject.incrementPointer("*UnsignedRecordSet")
+	_pointer := _self.ffiObject.borrowPointer("*UnsignedRecordSet")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterBytesINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_unsignedrecordset_signing_id(
+			inner: C.libveritas_unsignedrecordset_signing_id(
 				_pointer, _uniffiStatus),
 		}
 	}))
 }
+
 func (object *UnsignedRecordSet) Destroy() {
 	runtime.SetFinalizer(object, nil)
 	object.ffiObject.destroy()
@@ -2063,51 +1989,38 @@ func (c FfiConverterUnsignedRecordSet) Lift(pointer unsafe.Pointer) *UnsignedRec
 		),
 	}
 	runtime.SetFinalizer(result, (*UnsignedRecordSet).Destroy)
+	result.ffiObject.trackingID = trackObject("UnsignedRecordSet")
 	return result
 }
 
 func (c FfiConverterUnsignedRecordSet) Read(reader io.Reader) *UnsignedRecordSet {
-	return c.Lift(unsafe.Pointer(uintptr(readUint64(reader))))
+	return c.Lift(readPointer(reader))
 }
 
 func (c FfiConverterUnsignedRecordSet) Lower(value *UnsignedRecordSet) unsafe.Pointer {
-	// TODO: this is bad - all synchronization from ObjectRuntime.go is discarded here,
-	// because the pointer will be decremented immediately after this function returns,
-	// and someone will be left holding onto a non-locked pointer.
+	// The returned pointer is a clone, a reference of its own that the
+	// receiving side must free, so a later Destroy of value does not free it.
+	// value is pinned while it is cloned. Object arguments of FFI calls use
+	// pinObjectArg and the call shims instead, which clone within the call.
 	pointer := value.ffiObject.incrementPointer("*UnsignedRecordSet")
 	defer value.ffiObject.decrementPointer()
 	return pointer
-
 }
 
 func (c FfiConverterUnsignedRecordSet) Write(writer io.Writer, value *UnsignedRecordSet) {
 	writeUint64(writer, uint64(uintptr(c.Lower(value))))
 }
 
-type FfiDestroyerUnsignedRecordSet struct{}
-
-func (_ FfiDestroyerUnsignedRecordSet) Destroy(value *UnsignedRecordSet) {
-	value.Destroy()
-}
-
-type VerifiedMessageInterface interface {
-	Certificates() [][]byte
-	// Get the verified message for rebroadcasting or updating.
-	Message() *Message
-	// Get the verified message as bytes.
-	MessageBytes() []byte
-	Zones() []Zone
-}
 type VerifiedMessage struct {
 	ffiObject FfiObject
 }
 
 func (_self *VerifiedMessage) Certificates() [][]byte {
-	_pointer := _self.ffiObject.incrementPointer("*VerifiedMessage")
+	_pointer := _self.ffiObject.borrowPointer("*VerifiedMessage")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterSequenceBytesINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_verifiedmessage_certificates(
+			inner: C.libveritas_verifiedmessage_certificates(
 				_pointer, _uniffiStatus),
 		}
 	}))
@@ -2115,36 +2028,50 @@ func (_self *VerifiedMessage) Certificates() [][]byte {
 
 // Get the verified message for rebroadcasting or updating.
 func (_self *VerifiedMessage) Message() *Message {
-	_pointer := _self.ffiObject.incrementPointer("*VerifiedMessage")
+	_pointer := _self.ffiObject.borrowPointer("*VerifiedMessage")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterMessageINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) unsafe.Pointer {
-		return C.uniffi_libveritas_uniffi_fn_method_verifiedmessage_message(
+		return C.libveritas_verifiedmessage_message(
 			_pointer, _uniffiStatus)
 	}))
 }
 
 // Get the verified message as bytes.
 func (_self *VerifiedMessage) MessageBytes() []byte {
-	_pointer := _self.ffiObject.incrementPointer("*VerifiedMessage")
+	_pointer := _self.ffiObject.borrowPointer("*VerifiedMessage")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterBytesINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_verifiedmessage_message_bytes(
+			inner: C.libveritas_verifiedmessage_message_bytes(
+				_pointer, _uniffiStatus),
+		}
+	}))
+}
+
+// Get the verified message as a view of the native buffer, without copying
+// it. Release the view when done with it.
+func (_self *VerifiedMessage) MessageBytesView() *BytesView {
+	_pointer := _self.ffiObject.borrowPointer("*VerifiedMessage")
+	defer _self.ffiObject.decrementPointer()
+	return FfiConverterBytesINSTANCE.LiftView(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
+		return GoRustBuffer{
+			inner: C.libveritas_verifiedmessage_message_bytes(
 				_pointer, _uniffiStatus),
 		}
 	}))
 }
 
 func (_self *VerifiedMessage) Zones() []Zone {
-	_pointer := _self.ffiObject.incrementPointer("*VerifiedMessage")
+	_pointer := _self.ffiObject.borrowPointer("*VerifiedMessage")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterSequenceZoneINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_verifiedmessage_zones(
+			inner: C.libveritas_verifiedmessage_zones(
 				_pointer, _uniffiStatus),
 		}
 	}))
 }
+
 func (object *VerifiedMessage) Destroy() {
 	runtime.SetFinalizer(object, nil)
 	object.ffiObject.destroy()
@@ -2167,104 +2094,100 @@ func (c FfiConverterVerifiedMessage) Lift(pointer unsafe.Pointer) *VerifiedMessa
 		),
 	}
 	runtime.SetFinalizer(result, (*VerifiedMessage).Destroy)
+	result.ffiObject.trackingID = trackObject("VerifiedMessage")
 	return result
 }
 
 func (c FfiConverterVerifiedMessage) Read(reader io.Reader) *VerifiedMessage {
-	return c.Lift(unsafe.Pointer(uintptr(readUint64(reader))))
+	return c.Lift(readPointer(reader))
 }
 
 func (c FfiConverterVerifiedMessage) Lower(value *VerifiedMessage) unsafe.Pointer {
-	// TODO: this is bad - all synchronization from ObjectRuntime.go is discarded here,
-	// because the pointer will be decremented immediately after this function returns,
-	// and someone will be left holding onto a non-locked pointer.
+	// The returned pointer is a clone, a reference of its own that the
+	// receiving side must free, so a later Destroy of value does not free it.
+	// value is pinned while it is cloned. Object arguments of FFI calls use
+	// pinObjectArg and the call shims instead, which clone within the call.
 	pointer := value.ffiObject.incrementPointer("*VerifiedMessage")
 	defer value.ffiObject.decrementPointer()
 	return pointer
-
 }
 
 func (c FfiConverterVerifiedMessage) Write(writer io.Writer, value *VerifiedMessage) {
 	writeUint64(writer, uint64(uintptr(c.Lower(value))))
 }
 
-type FfiDestroyerVerifiedMessage struct{}
-
-func (_ FfiDestroyerVerifiedMessage) Destroy(value *VerifiedMessage) {
-	value.Destroy()
-}
-
-type VeritasInterface interface {
-	ComputeTrustSet() TrustSet
-	IsFinalized(commitmentHeight uint32) bool
-	NewestAnchor() uint32
-	OldestAnchor() uint32
-	SovereigntyFor(commitmentHeight uint32) string
-	// Verify a message with default options.
-	Verify(ctx *QueryContext, msg *Message) (*VerifiedMessage, error)
-	// Verify a message with option flags (combine with bitwise OR).
-	VerifyWithOptions(ctx *QueryContext, msg *Message, options uint32) (*VerifiedMessage, error)
-}
 type Veritas struct {
 	ffiObject FfiObject
 }
 
 func NewVeritas(anchors *Anchors) (*Veritas, error) {
+	if err := Init(); err != nil {
+		var _uniffiDefaultValue *Veritas
+		return _uniffiDefaultValue, err
+	}
+	if anchors == nil {
+		return nil, newInvalidInputError(CodeInvalidInput, "anchors must not be nil")
+	}
+	_unpinAnchors, err := pinObjectArg("*Anchors", &anchors.ffiObject)
+	if err != nil {
+		return nil, err
+	}
+	defer _unpinAnchors()
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) unsafe.Pointer {
-		return C.uniffi_libveritas_uniffi_fn_constructor_veritas_new(FfiConverterAnchorsINSTANCE.Lower(anchors), _uniffiStatus)
+		return C.libveritas_veritas_new(anchors.ffiObject.pointer, _uniffiStatus)
 	})
 	if _uniffiErr != nil {
 		var _uniffiDefaultValue *Veritas
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterVeritasINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterVeritasINSTANCE.Lift, _uniffiRV)
 	}
 }
 
 func (_self *Veritas) ComputeTrustSet() TrustSet {
-	_pointer := _self.ffiObject.incrementPointer("*Veritas")
+	_pointer := _self.ffiObject.borrowPointer("*Veritas")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterTrustSetINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_veritas_compute_trust_set(
+			inner: C.libveritas_veritas_compute_trust_set(
 				_pointer, _uniffiStatus),
 		}
 	}))
 }
 
 func (_self *Veritas) IsFinalized(commitmentHeight uint32) bool {
-	_pointer := _self.ffiObject.incrementPointer("*Veritas")
+	_pointer := _self.ffiObject.borrowPointer("*Veritas")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterBoolINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) C.int8_t {
-		return C.uniffi_libveritas_uniffi_fn_method_veritas_is_finalized(
+		return C.libveritas_veritas_is_finalized(
 			_pointer, FfiConverterUint32INSTANCE.Lower(commitmentHeight), _uniffiStatus)
 	}))
 }
 
 func (_self *Veritas) NewestAnchor() uint32 {
-	_pointer := _self.ffiObject.incrementPointer("*Veritas")
+	_pointer := _self.ffiObject.borrowPointer("*Veritas")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterUint32INSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint32_t {
-		return C.uniffi_libveritas_uniffi_fn_method_veritas_newest_anchor(
+		return C.libveritas_veritas_newest_anchor(
 			_pointer, _uniffiStatus)
 	}))
 }
 
 func (_self *Veritas) OldestAnchor() uint32 {
-	_pointer := _self.ffiObject.incrementPointer("*Veritas")
+	_pointer := _self.ffiObject.borrowPointer("*Veritas")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterUint32INSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint32_t {
-		return C.uniffi_libveritas_uniffi_fn_method_veritas_oldest_anchor(
+		return C.libveritas_veritas_oldest_anchor(
 			_pointer, _uniffiStatus)
 	}))
 }
 
 func (_self *Veritas) SovereigntyFor(commitmentHeight uint32) string {
-	_pointer := _self.ffiObject.incrementPointer("*Veritas")
+	_pointer := _self.ffiObject.borrowPointer("*Veritas")
 	defer _self.ffiObject.decrementPointer()
 	return FfiConverterStringINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_method_veritas_sovereignty_for(
+			inner: C.libveritas_veritas_sovereignty_for(
 				_pointer, FfiConverterUint32INSTANCE.Lower(commitmentHeight), _uniffiStatus),
 		}
 	}))
@@ -2272,35 +2195,77 @@ func (_self *Veritas) SovereigntyFor(commitmentHeight uint32) string {
 
 // Verify a message with default options.
 func (_self *Veritas) Verify(ctx *QueryContext, msg *Message) (*VerifiedMessage, error) {
-	_pointer := _self.ffiObject.incrementPointer("*Veritas")
+	if ctx == nil || msg == nil {
+		return nil, newInvalidInputError(CodeInvalidInput, "query context and message must not be nil")
+	}
+	_unpinCtx, err := pinObjectArg("*QueryContext", &ctx.ffiObject)
+	if err != nil {
+		return nil, err
+	}
+	defer _unpinCtx()
+	_unpinMsg, err := pinObjectArg("*Message", &msg.ffiObject)
+	if err != nil {
+		return nil, err
+	}
+	defer _unpinMsg()
+	_pointer := _self.ffiObject.borrowPointer("*Veritas")
 	defer _self.ffiObject.decrementPointer()
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) unsafe.Pointer {
-		return C.uniffi_libveritas_uniffi_fn_method_veritas_verify(
-			_pointer, FfiConverterQueryContextINSTANCE.Lower(ctx), FfiConverterMessageINSTANCE.Lower(msg), _uniffiStatus)
+		return C.libveritas_veritas_verify(
+			_pointer, ctx.ffiObject.pointer, msg.ffiObject.pointer, _uniffiStatus)
 	})
 	if _uniffiErr != nil {
 		var _uniffiDefaultValue *VerifiedMessage
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterVerifiedMessageINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterVerifiedMessageINSTANCE.Lift, _uniffiRV)
 	}
 }
 
 // Verify a message with option flags (combine with bitwise OR).
+//
+// Deprecated: The flags are those of the native library (VerifyDevMode,
+// VerifyEnableSnark), not VerifyOptions. Use VerifyWith.
 func (_self *Veritas) VerifyWithOptions(ctx *QueryContext, msg *Message, options uint32) (*VerifiedMessage, error) {
-	_pointer := _self.ffiObject.incrementPointer("*Veritas")
+	return _self.verifyWithFlags(ctx, msg, options)
+}
+
+// Verify a message with options.
+func (_self *Veritas) VerifyWith(ctx *QueryContext, msg *Message, options VerifyOptions) (*VerifiedMessage, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	return _self.verifyWithFlags(ctx, msg, options.native())
+}
+
+func (_self *Veritas) verifyWithFlags(ctx *QueryContext, msg *Message, flags uint32) (*VerifiedMessage, error) {
+	if ctx == nil || msg == nil {
+		return nil, newInvalidInputError(CodeInvalidInput, "query context and message must not be nil")
+	}
+	_unpinCtx, err := pinObjectArg("*QueryContext", &ctx.ffiObject)
+	if err != nil {
+		return nil, err
+	}
+	defer _unpinCtx()
+	_unpinMsg, err := pinObjectArg("*Message", &msg.ffiObject)
+	if err != nil {
+		return nil, err
+	}
+	defer _unpinMsg()
+	_pointer := _self.ffiObject.borrowPointer("*Veritas")
 	defer _self.ffiObject.decrementPointer()
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) unsafe.Pointer {
-		return C.uniffi_libveritas_uniffi_fn_method_veritas_verify_with_options(
-			_pointer, FfiConverterQueryContextINSTANCE.Lower(ctx), FfiConverterMessageINSTANCE.Lower(msg), FfiConverterUint32INSTANCE.Lower(options), _uniffiStatus)
+		return C.libveritas_veritas_verify_with_options(
+			_pointer, ctx.ffiObject.pointer, msg.ffiObject.pointer, FfiConverterUint32INSTANCE.Lower(flags), _uniffiStatus)
 	})
 	if _uniffiErr != nil {
 		var _uniffiDefaultValue *VerifiedMessage
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterVerifiedMessageINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterVerifiedMessageINSTANCE.Lift, _uniffiRV)
 	}
 }
+
 func (object *Veritas) Destroy() {
 	runtime.SetFinalizer(object, nil)
 	object.ffiObject.destroy()
@@ -2323,44 +2288,28 @@ func (c FfiConverterVeritas) Lift(pointer unsafe.Pointer) *Veritas {
 		),
 	}
 	runtime.SetFinalizer(result, (*Veritas).Destroy)
+	result.ffiObject.trackingID = trackObject("Veritas")
 	return result
 }
 
 func (c FfiConverterVeritas) Read(reader io.Reader) *Veritas {
-	return c.Lift(unsafe.Pointer(uintptr(readUint64(reader))))
+	return c.Lift(readPointer(reader))
 }
 
 func (c FfiConverterVeritas) Lower(value *Veritas) unsafe.Pointer {
-	// TODO: this is bad - all synchronization from ObjectRuntime.go is discarded here,
-	// because the pointer will be decremented immediately after this function returns,
-	// and someone will be left holding onto a non-locked pointer.
+	// The returned pointer is a clone, a reference of its own that the
+	// receiving side must free, so a later Destroy of value does not free it.
+	// value is pinned while it is cloned. Object arguments of FFI calls use
+	// pinObjectArg and the call shims instead, which clone within the call.
 	pointer := value.ffiObject.incrementPointer("*Veritas")
 	defer value.ffiObject.decrementPointer()
 	return pointer
-
 }
 
 func (c FfiConverterVeritas) Write(writer io.Writer, value *Veritas) {
 	writeUint64(writer, uint64(uintptr(c.Lower(value))))
 }
 
-type FfiDestroyerVeritas struct{}
-
-func (_ FfiDestroyerVeritas) Destroy(value *Veritas) {
-	value.Destroy()
-}
-
-// Result of building a message.
-type BuildResult struct {
-	Message  *Message
-	Unsigned []*UnsignedRecordSet
-}
-
-func (r *BuildResult) Destroy() {
-	FfiDestroyerMessage{}.Destroy(r.Message)
-	FfiDestroyerSequenceUnsignedRecordSet{}.Destroy(r.Unsigned)
-}
-
 type FfiConverterBuildResult struct{}
 
 var FfiConverterBuildResultINSTANCE = FfiConverterBuildResult{}
@@ -2384,28 +2333,9 @@ func (c FfiConverterBuildResult) LowerExternal(value BuildResult) ExternalCRustB
 	return RustBufferFromC(LowerIntoRustBuffer[BuildResult](c, value))
 }
 
-func (c FfiConverterBuildResult) Write(writer io.Writer, value BuildResult) {
-	FfiConverterMessageINSTANCE.Write(writer, value.Message)
-	FfiConverterSequenceUnsignedRecordSetINSTANCE.Write(writer, value.Unsigned)
-}
-
-type FfiDestroyerBuildResult struct{}
-
-func (_ FfiDestroyerBuildResult) Destroy(value BuildResult) {
-	value.Destroy()
-}
-
-// Data update entry for Message.update() — no cert field.
-type DataUpdateEntry struct {
-	Name            string
-	Records         *[]byte
-	DelegateRecords *[]byte
-}
-
-func (r *DataUpdateEntry) Destroy() {
-	FfiDestroyerString{}.Destroy(r.Name)
-	FfiDestroyerOptionalBytes{}.Destroy(r.Records)
-	FfiDestroyerOptionalBytes{}.Destroy(r.DelegateRecords)
+func (c FfiConverterBuildResult) Write(writer io.Writer, value BuildResult) {
+	FfiConverterMessageINSTANCE.Write(writer, value.Message)
+	FfiConverterSequenceUnsignedRecordSetINSTANCE.Write(writer, value.Unsigned)
 }
 
 type FfiConverterDataUpdateEntry struct{}
@@ -2438,22 +2368,6 @@ func (c FfiConverterDataUpdateEntry) Write(writer io.Writer, value DataUpdateEnt
 	FfiConverterOptionalBytesINSTANCE.Write(writer, value.DelegateRecords)
 }
 
-type FfiDestroyerDataUpdateEntry struct{}
-
-func (_ FfiDestroyerDataUpdateEntry) Destroy(value DataUpdateEntry) {
-	value.Destroy()
-}
-
-type TrustSet struct {
-	Id    []byte
-	Roots [][]byte
-}
-
-func (r *TrustSet) Destroy() {
-	FfiDestroyerBytes{}.Destroy(r.Id)
-	FfiDestroyerSequenceBytes{}.Destroy(r.Roots)
-}
-
 type FfiConverterTrustSet struct{}
 
 var FfiConverterTrustSetINSTANCE = FfiConverterTrustSet{}
@@ -2482,42 +2396,6 @@ func (c FfiConverterTrustSet) Write(writer io.Writer, value TrustSet) {
 	FfiConverterSequenceBytesINSTANCE.Write(writer, value.Roots)
 }
 
-type FfiDestroyerTrustSet struct{}
-
-func (_ FfiDestroyerTrustSet) Destroy(value TrustSet) {
-	value.Destroy()
-}
-
-type Zone struct {
-	Anchor          uint32
-	AnchorHash      []byte
-	Sovereignty     string
-	Handle          string
-	Canonical       string
-	Alias           *string
-	ScriptPubkey    []byte
-	NumId           *string
-	Records         []byte
-	FallbackRecords []byte
-	Delegate        DelegateState
-	Commitment      CommitmentState
-}
-
-func (r *Zone) Destroy() {
-	FfiDestroyerUint32{}.Destroy(r.Anchor)
-	FfiDestroyerBytes{}.Destroy(r.AnchorHash)
-	FfiDestroyerString{}.Destroy(r.Sovereignty)
-	FfiDestroyerString{}.Destroy(r.Handle)
-	FfiDestroyerString{}.Destroy(r.Canonical)
-	FfiDestroyerOptionalString{}.Destroy(r.Alias)
-	FfiDestroyerBytes{}.Destroy(r.ScriptPubkey)
-	FfiDestroyerOptionalString{}.Destroy(r.NumId)
-	FfiDestroyerBytes{}.Destroy(r.Records)
-	FfiDestroyerBytes{}.Destroy(r.FallbackRecords)
-	FfiDestroyerDelegateState{}.Destroy(r.Delegate)
-	FfiDestroyerCommitmentState{}.Destroy(r.Commitment)
-}
-
 type FfiConverterZone struct{}
 
 var FfiConverterZoneINSTANCE = FfiConverterZone{}
@@ -2566,43 +2444,6 @@ func (c FfiConverterZone) Write(writer io.Writer, value Zone) {
 	FfiConverterCommitmentStateINSTANCE.Write(writer, value.Commitment)
 }
 
-type FfiDestroyerZone struct{}
-
-func (_ FfiDestroyerZone) Destroy(value Zone) {
-	value.Destroy()
-}
-
-type CommitmentState interface {
-	Destroy()
-}
-type CommitmentStateExists struct {
-	StateRoot   []byte
-	PrevRoot    *[]byte
-	RollingHash []byte
-	BlockHeight uint32
-	ReceiptHash *[]byte
-}
-
-func (e CommitmentStateExists) Destroy() {
-	FfiDestroyerBytes{}.Destroy(e.StateRoot)
-	FfiDestroyerOptionalBytes{}.Destroy(e.PrevRoot)
-	FfiDestroyerBytes{}.Destroy(e.RollingHash)
-	FfiDestroyerUint32{}.Destroy(e.BlockHeight)
-	FfiDestroyerOptionalBytes{}.Destroy(e.ReceiptHash)
-}
-
-type CommitmentStateEmpty struct {
-}
-
-func (e CommitmentStateEmpty) Destroy() {
-}
-
-type CommitmentStateUnknown struct {
-}
-
-func (e CommitmentStateUnknown) Destroy() {
-}
-
 type FfiConverterCommitmentState struct{}
 
 var FfiConverterCommitmentStateINSTANCE = FfiConverterCommitmentState{}
@@ -2618,6 +2459,7 @@ func (c FfiConverterCommitmentState) Lower(value CommitmentState) C.RustBuffer {
 func (c FfiConverterCommitmentState) LowerExternal(value CommitmentState) ExternalCRustBuffer {
 	return RustBufferFromC(LowerIntoRustBuffer[CommitmentState](c, value))
 }
+
 func (FfiConverterCommitmentState) Read(reader io.Reader) CommitmentState {
 	id := readInt32(reader)
 	switch id {
@@ -2657,39 +2499,6 @@ func (FfiConverterCommitmentState) Write(writer io.Writer, value CommitmentState
 	}
 }
 
-type FfiDestroyerCommitmentState struct{}
-
-func (_ FfiDestroyerCommitmentState) Destroy(value CommitmentState) {
-	value.Destroy()
-}
-
-type DelegateState interface {
-	Destroy()
-}
-type DelegateStateExists struct {
-	ScriptPubkey    []byte
-	FallbackRecords []byte
-	Records         []byte
-}
-
-func (e DelegateStateExists) Destroy() {
-	FfiDestroyerBytes{}.Destroy(e.ScriptPubkey)
-	FfiDestroyerBytes{}.Destroy(e.FallbackRecords)
-	FfiDestroyerBytes{}.Destroy(e.Records)
-}
-
-type DelegateStateEmpty struct {
-}
-
-func (e DelegateStateEmpty) Destroy() {
-}
-
-type DelegateStateUnknown struct {
-}
-
-func (e DelegateStateUnknown) Destroy() {
-}
-
 type FfiConverterDelegateState struct{}
 
 var FfiConverterDelegateStateINSTANCE = FfiConverterDelegateState{}
@@ -2705,6 +2514,7 @@ func (c FfiConverterDelegateState) Lower(value DelegateState) C.RustBuffer {
 func (c FfiConverterDelegateState) LowerExternal(value DelegateState) ExternalCRustBuffer {
 	return RustBufferFromC(LowerIntoRustBuffer[DelegateState](c, value))
 }
+
 func (FfiConverterDelegateState) Read(reader io.Reader) DelegateState {
 	id := readInt32(reader)
 	switch id {
@@ -2740,88 +2550,6 @@ func (FfiConverterDelegateState) Write(writer io.Writer, value DelegateState) {
 	}
 }
 
-type FfiDestroyerDelegateState struct{}
-
-func (_ FfiDestroyerDelegateState) Destroy(value DelegateState) {
-	value.Destroy()
-}
-
-// A parsed SIP-7 record (from unpacking). Includes `Malformed` for invalid rdata.
-type ParsedRecord interface {
-	Destroy()
-}
-type ParsedRecordSeq struct {
-	Version uint64
-}
-
-func (e ParsedRecordSeq) Destroy() {
-	FfiDestroyerUint64{}.Destroy(e.Version)
-}
-
-type ParsedRecordTxt struct {
-	Key   string
-	Value []string
-}
-
-func (e ParsedRecordTxt) Destroy() {
-	FfiDestroyerString{}.Destroy(e.Key)
-	FfiDestroyerSequenceString{}.Destroy(e.Value)
-}
-
-type ParsedRecordAddr struct {
-	Key   string
-	Value []string
-}
-
-func (e ParsedRecordAddr) Destroy() {
-	FfiDestroyerString{}.Destroy(e.Key)
-	FfiDestroyerSequenceString{}.Destroy(e.Value)
-}
-
-type ParsedRecordBlob struct {
-	Key   string
-	Value []byte
-}
-
-func (e ParsedRecordBlob) Destroy() {
-	FfiDestroyerString{}.Destroy(e.Key)
-	FfiDestroyerBytes{}.Destroy(e.Value)
-}
-
-type ParsedRecordSig struct {
-	Flags     uint8
-	Canonical string
-	Handle    string
-	Sig       []byte
-}
-
-func (e ParsedRecordSig) Destroy() {
-	FfiDestroyerUint8{}.Destroy(e.Flags)
-	FfiDestroyerString{}.Destroy(e.Canonical)
-	FfiDestroyerString{}.Destroy(e.Handle)
-	FfiDestroyerBytes{}.Destroy(e.Sig)
-}
-
-type ParsedRecordMalformed struct {
-	Rtype uint8
-	Rdata []byte
-}
-
-func (e ParsedRecordMalformed) Destroy() {
-	FfiDestroyerUint8{}.Destroy(e.Rtype)
-	FfiDestroyerBytes{}.Destroy(e.Rdata)
-}
-
-type ParsedRecordUnknown struct {
-	Rtype uint8
-	Rdata []byte
-}
-
-func (e ParsedRecordUnknown) Destroy() {
-	FfiDestroyerUint8{}.Destroy(e.Rtype)
-	FfiDestroyerBytes{}.Destroy(e.Rdata)
-}
-
 type FfiConverterParsedRecord struct{}
 
 var FfiConverterParsedRecordINSTANCE = FfiConverterParsedRecord{}
@@ -2837,6 +2565,7 @@ func (c FfiConverterParsedRecord) Lower(value ParsedRecord) C.RustBuffer {
 func (c FfiConverterParsedRecord) LowerExternal(value ParsedRecord) ExternalCRustBuffer {
 	return RustBufferFromC(LowerIntoRustBuffer[ParsedRecord](c, value))
 }
+
 func (FfiConverterParsedRecord) Read(reader io.Reader) ParsedRecord {
 	id := readInt32(reader)
 	switch id {
@@ -2918,78 +2647,6 @@ func (FfiConverterParsedRecord) Write(writer io.Writer, value ParsedRecord) {
 	}
 }
 
-type FfiDestroyerParsedRecord struct{}
-
-func (_ FfiDestroyerParsedRecord) Destroy(value ParsedRecord) {
-	value.Destroy()
-}
-
-// A single SIP-7 record (for constructing/packing).
-type Record interface {
-	Destroy()
-}
-type RecordSeq struct {
-	Version uint64
-}
-
-func (e RecordSeq) Destroy() {
-	FfiDestroyerUint64{}.Destroy(e.Version)
-}
-
-type RecordTxt struct {
-	Key   string
-	Value []string
-}
-
-func (e RecordTxt) Destroy() {
-	FfiDestroyerString{}.Destroy(e.Key)
-	FfiDestroyerSequenceString{}.Destroy(e.Value)
-}
-
-type RecordAddr struct {
-	Key   string
-	Value []string
-}
-
-func (e RecordAddr) Destroy() {
-	FfiDestroyerString{}.Destroy(e.Key)
-	FfiDestroyerSequenceString{}.Destroy(e.Value)
-}
-
-type RecordBlob struct {
-	Key   string
-	Value []byte
-}
-
-func (e RecordBlob) Destroy() {
-	FfiDestroyerString{}.Destroy(e.Key)
-	FfiDestroyerBytes{}.Destroy(e.Value)
-}
-
-type RecordSig struct {
-	Flags     uint8
-	Canonical string
-	Handle    string
-	Sig       []byte
-}
-
-func (e RecordSig) Destroy() {
-	FfiDestroyerUint8{}.Destroy(e.Flags)
-	FfiDestroyerString{}.Destroy(e.Canonical)
-	FfiDestroyerString{}.Destroy(e.Handle)
-	FfiDestroyerBytes{}.Destroy(e.Sig)
-}
-
-type RecordUnknown struct {
-	Rtype uint8
-	Rdata []byte
-}
-
-func (e RecordUnknown) Destroy() {
-	FfiDestroyerUint8{}.Destroy(e.Rtype)
-	FfiDestroyerBytes{}.Destroy(e.Rdata)
-}
-
 type FfiConverterRecord struct{}
 
 var FfiConverterRecordINSTANCE = FfiConverterRecord{}
@@ -3005,6 +2662,7 @@ func (c FfiConverterRecord) Lower(value Record) C.RustBuffer {
 func (c FfiConverterRecord) LowerExternal(value Record) ExternalCRustBuffer {
 	return RustBufferFromC(LowerIntoRustBuffer[Record](c, value))
 }
+
 func (FfiConverterRecord) Read(reader io.Reader) Record {
 	id := readInt32(reader)
 	switch id {
@@ -3077,95 +2735,6 @@ func (FfiConverterRecord) Write(writer io.Writer, value Record) {
 	}
 }
 
-type FfiDestroyerRecord struct{}
-
-func (_ FfiDestroyerRecord) Destroy(value Record) {
-	value.Destroy()
-}
-
-type VeritasError struct {
-	err error
-}
-
-// Convience method to turn *VeritasError into error
-// Avoiding treating nil pointer as non nil error interface
-func (err *VeritasError) AsError() error {
-	if err == nil {
-		return nil
-	} else {
-		return err
-	}
-}
-
-func (err VeritasError) Error() string {
-	return fmt.Sprintf("VeritasError: %s", err.err.Error())
-}
-
-func (err VeritasError) Unwrap() error {
-	return err.err
-}
-
-// Err* are used for checking error type with `errors.Is`
-var ErrVeritasErrorInvalidInput = fmt.Errorf("VeritasErrorInvalidInput")
-var ErrVeritasErrorVerificationFailed = fmt.Errorf("VeritasErrorVerificationFailed")
-
-// Variant structs
-type VeritasErrorInvalidInput struct {
-	Msg string
-}
-
-func NewVeritasErrorInvalidInput(
-	msg string,
-) *VeritasError {
-	return &VeritasError{err: &VeritasErrorInvalidInput{
-		Msg: msg}}
-}
-
-func (e VeritasErrorInvalidInput) destroy() {
-	FfiDestroyerString{}.Destroy(e.Msg)
-}
-
-func (err VeritasErrorInvalidInput) Error() string {
-	return fmt.Sprint("InvalidInput",
-		": ",
-
-		"Msg=",
-		err.Msg,
-	)
-}
-
-func (self VeritasErrorInvalidInput) Is(target error) bool {
-	return target == ErrVeritasErrorInvalidInput
-}
-
-type VeritasErrorVerificationFailed struct {
-	Msg string
-}
-
-func NewVeritasErrorVerificationFailed(
-	msg string,
-) *VeritasError {
-	return &VeritasError{err: &VeritasErrorVerificationFailed{
-		Msg: msg}}
-}
-
-func (e VeritasErrorVerificationFailed) destroy() {
-	FfiDestroyerString{}.Destroy(e.Msg)
-}
-
-func (err VeritasErrorVerificationFailed) Error() string {
-	return fmt.Sprint("VerificationFailed",
-		": ",
-
-		"Msg=",
-		err.Msg,
-	)
-}
-
-func (self VeritasErrorVerificationFailed) Is(target error) bool {
-	return target == ErrVeritasErrorVerificationFailed
-}
-
 type FfiConverterVeritasError struct{}
 
 var FfiConverterVeritasErrorINSTANCE = FfiConverterVeritasError{}
@@ -3187,11 +2756,11 @@ func (c FfiConverterVeritasError) Read(reader io.Reader) *VeritasError {
 
 	switch errorID {
 	case 1:
-		return &VeritasError{&VeritasErrorInvalidInput{
+		return &VeritasError{err: &VeritasErrorInvalidInput{
 			Msg: FfiConverterStringINSTANCE.Read(reader),
 		}}
 	case 2:
-		return &VeritasError{&VeritasErrorVerificationFailed{
+		return &VeritasError{err: &VeritasErrorVerificationFailed{
 			Msg: FfiConverterStringINSTANCE.Read(reader),
 		}}
 	default:
@@ -3213,20 +2782,6 @@ func (c FfiConverterVeritasError) Write(writer io.Writer, value *VeritasError) {
 	}
 }
 
-type FfiDestroyerVeritasError struct{}
-
-func (_ FfiDestroyerVeritasError) Destroy(value *VeritasError) {
-	switch variantValue := value.err.(type) {
-	case VeritasErrorInvalidInput:
-		variantValue.destroy()
-	case VeritasErrorVerificationFailed:
-		variantValue.destroy()
-	default:
-		_ = variantValue
-		panic(fmt.Sprintf("invalid error value `%v` in FfiDestroyerVeritasError.Destroy", value))
-	}
-}
-
 type FfiConverterOptionalString struct{}
 
 var FfiConverterOptionalStringINSTANCE = FfiConverterOptionalString{}
@@ -3260,14 +2815,6 @@ func (_ FfiConverterOptionalString) Write(writer io.Writer, value *string) {
 	}
 }
 
-type FfiDestroyerOptionalString struct{}
-
-func (_ FfiDestroyerOptionalString) Destroy(value *string) {
-	if value != nil {
-		FfiDestroyerString{}.Destroy(*value)
-	}
-}
-
 type FfiConverterOptionalBytes struct{}
 
 var FfiConverterOptionalBytesINSTANCE = FfiConverterOptionalBytes{}
@@ -3301,14 +2848,6 @@ func (_ FfiConverterOptionalBytes) Write(writer io.Writer, value *[]byte) {
 	}
 }
 
-type FfiDestroyerOptionalBytes struct{}
-
-func (_ FfiDestroyerOptionalBytes) Destroy(value *[]byte) {
-	if value != nil {
-		FfiDestroyerBytes{}.Destroy(*value)
-	}
-}
-
 type FfiConverterSequenceString struct{}
 
 var FfiConverterSequenceStringINSTANCE = FfiConverterSequenceString{}
@@ -3348,14 +2887,6 @@ func (c FfiConverterSequenceString) Write(writer io.Writer, value []string) {
 	}
 }
 
-type FfiDestroyerSequenceString struct{}
-
-func (FfiDestroyerSequenceString) Destroy(sequence []string) {
-	for _, value := range sequence {
-		FfiDestroyerString{}.Destroy(value)
-	}
-}
-
 type FfiConverterSequenceBytes struct{}
 
 var FfiConverterSequenceBytesINSTANCE = FfiConverterSequenceBytes{}
@@ -3395,14 +2926,6 @@ func (c FfiConverterSequenceBytes) Write(writer io.Writer, value [][]byte) {
 	}
 }
 
-type FfiDestroyerSequenceBytes struct{}
-
-func (FfiDestroyerSequenceBytes) Destroy(sequence [][]byte) {
-	for _, value := range sequence {
-		FfiDestroyerBytes{}.Destroy(value)
-	}
-}
-
 type FfiConverterSequenceUnsignedRecordSet struct{}
 
 var FfiConverterSequenceUnsignedRecordSetINSTANCE = FfiConverterSequenceUnsignedRecordSet{}
@@ -3442,14 +2965,6 @@ func (c FfiConverterSequenceUnsignedRecordSet) Write(writer io.Writer, value []*
 	}
 }
 
-type FfiDestroyerSequenceUnsignedRecordSet struct{}
-
-func (FfiDestroyerSequenceUnsignedRecordSet) Destroy(sequence []*UnsignedRecordSet) {
-	for _, value := range sequence {
-		FfiDestroyerUnsignedRecordSet{}.Destroy(value)
-	}
-}
-
 type FfiConverterSequenceDataUpdateEntry struct{}
 
 var FfiConverterSequenceDataUpdateEntryINSTANCE = FfiConverterSequenceDataUpdateEntry{}
@@ -3489,14 +3004,6 @@ func (c FfiConverterSequenceDataUpdateEntry) Write(writer io.Writer, value []Dat
 	}
 }
 
-type FfiDestroyerSequenceDataUpdateEntry struct{}
-
-func (FfiDestroyerSequenceDataUpdateEntry) Destroy(sequence []DataUpdateEntry) {
-	for _, value := range sequence {
-		FfiDestroyerDataUpdateEntry{}.Destroy(value)
-	}
-}
-
 type FfiConverterSequenceZone struct{}
 
 var FfiConverterSequenceZoneINSTANCE = FfiConverterSequenceZone{}
@@ -3536,14 +3043,6 @@ func (c FfiConverterSequenceZone) Write(writer io.Writer, value []Zone) {
 	}
 }
 
-type FfiDestroyerSequenceZone struct{}
-
-func (FfiDestroyerSequenceZone) Destroy(sequence []Zone) {
-	for _, value := range sequence {
-		FfiDestroyerZone{}.Destroy(value)
-	}
-}
-
 type FfiConverterSequenceParsedRecord struct{}
 
 var FfiConverterSequenceParsedRecordINSTANCE = FfiConverterSequenceParsedRecord{}
@@ -3583,14 +3082,6 @@ func (c FfiConverterSequenceParsedRecord) Write(writer io.Writer, value []Parsed
 	}
 }
 
-type FfiDestroyerSequenceParsedRecord struct{}
-
-func (FfiDestroyerSequenceParsedRecord) Destroy(sequence []ParsedRecord) {
-	for _, value := range sequence {
-		FfiDestroyerParsedRecord{}.Destroy(value)
-	}
-}
-
 type FfiConverterSequenceRecord struct{}
 
 var FfiConverterSequenceRecordINSTANCE = FfiConverterSequenceRecord{}
@@ -3630,16 +3121,12 @@ func (c FfiConverterSequenceRecord) Write(writer io.Writer, value []Record) {
 	}
 }
 
-type FfiDestroyerSequenceRecord struct{}
-
-func (FfiDestroyerSequenceRecord) Destroy(sequence []Record) {
-	for _, value := range sequence {
-		FfiDestroyerRecord{}.Destroy(value)
-	}
-}
-
 // Create a .spacecert file from a subject name and certificate bytes.
 func CreateCertificateChain(subject string, certBytesList [][]byte) ([]byte, error) {
+	if err := Init(); err != nil {
+		var _uniffiDefaultValue []byte
+		return _uniffiDefaultValue, err
+	}
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
 			inner: C.uniffi_libveritas_uniffi_fn_func_create_certificate_chain(FfiConverterStringINSTANCE.Lower(subject), FfiConverterSequenceBytesINSTANCE.Lower(certBytesList), _uniffiStatus),
@@ -3649,12 +3136,16 @@ func CreateCertificateChain(subject string, certBytesList [][]byte) ([]byte, err
 		var _uniffiDefaultValue []byte
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterBytesINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterBytesINSTANCE.Lift, _uniffiRV)
 	}
 }
 
 // Decode stored certificate bytes to JSON.
 func DecodeCertificate(bytes []byte) (string, error) {
+	if err := Init(); err != nil {
+		var _uniffiDefaultValue string
+		return _uniffiDefaultValue, err
+	}
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
 			inner: C.uniffi_libveritas_uniffi_fn_func_decode_certificate(FfiConverterBytesINSTANCE.Lower(bytes), _uniffiStatus),
@@ -3664,12 +3155,16 @@ func DecodeCertificate(bytes []byte) (string, error) {
 		var _uniffiDefaultValue string
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterStringINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterStringINSTANCE.Lift, _uniffiRV)
 	}
 }
 
 // Decode stored zone bytes to a Zone record.
 func DecodeZone(bytes []byte) (Zone, error) {
+	if err := Init(); err != nil {
+		var _uniffiDefaultValue Zone
+		return _uniffiDefaultValue, err
+	}
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
 			inner: C.uniffi_libveritas_uniffi_fn_func_decode_zone(FfiConverterBytesINSTANCE.Lower(bytes), _uniffiStatus),
@@ -3679,72 +3174,48 @@ func DecodeZone(bytes []byte) (Zone, error) {
 		var _uniffiDefaultValue Zone
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterZoneINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterZoneINSTANCE.Lift, _uniffiRV)
 	}
 }
 
-// Hash a message with the Spaces signed-message prefix (SHA256).
-// Returns the 32-byte digest suitable for Schnorr signing/verification.
-func HashSignableMessage(msg []byte) []byte {
-	return FfiConverterBytesINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
-		return GoRustBuffer{
-			inner: C.uniffi_libveritas_uniffi_fn_func_hash_signable_message(FfiConverterBytesINSTANCE.Lower(msg), _uniffiStatus),
-		}
-	}))
-}
-
+// SigPrimaryZone panics with the error of Init if it fails.
 func SigPrimaryZone() uint8 {
+	mustInit()
 	return FfiConverterUint8INSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint8_t {
 		return C.uniffi_libveritas_uniffi_fn_func_sig_primary_zone(_uniffiStatus)
 	}))
 }
 
+// VerifyDefault panics with the error of Init if it fails.
 func VerifyDefault() uint32 {
+	mustInit()
 	return FfiConverterUint32INSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint32_t {
 		return C.uniffi_libveritas_uniffi_fn_func_verify_default(_uniffiStatus)
 	}))
 }
 
+// VerifyDevMode panics with the error of Init if it fails.
 func VerifyDevMode() uint32 {
+	mustInit()
 	return FfiConverterUint32INSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint32_t {
 		return C.uniffi_libveritas_uniffi_fn_func_verify_dev_mode(_uniffiStatus)
 	}))
 }
 
+// VerifyEnableSnark panics with the error of Init if it fails.
 func VerifyEnableSnark() uint32 {
+	mustInit()
 	return FfiConverterUint32INSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) C.uint32_t {
 		return C.uniffi_libveritas_uniffi_fn_func_verify_enable_snark(_uniffiStatus)
 	}))
 }
 
-// Verify a raw Schnorr signature (no prefix, caller provides the 32-byte message hash).
-//
-// - `msg_hash`: 32-byte SHA256 hash
-// - `signature`: 64-byte Schnorr signature
-// - `pubkey`: 32-byte x-only public key
-func VerifySchnorr(msgHash []byte, signature []byte, pubkey []byte) error {
-	_, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) bool {
-		C.uniffi_libveritas_uniffi_fn_func_verify_schnorr(FfiConverterBytesINSTANCE.Lower(msgHash), FfiConverterBytesINSTANCE.Lower(signature), FfiConverterBytesINSTANCE.Lower(pubkey), _uniffiStatus)
-		return false
-	})
-	return _uniffiErr.AsError()
-}
-
-// Verify a Schnorr signature over a message using the Spaces signed-message prefix.
-//
-// - `msg`: raw message bytes (prefixed and hashed internally)
-// - `signature`: 64-byte Schnorr signature
-// - `pubkey`: 32-byte x-only public key
-func VerifySpacesMessage(msg []byte, signature []byte, pubkey []byte) error {
-	_, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) bool {
-		C.uniffi_libveritas_uniffi_fn_func_verify_spaces_message(FfiConverterBytesINSTANCE.Lower(msg), FfiConverterBytesINSTANCE.Lower(signature), FfiConverterBytesINSTANCE.Lower(pubkey), _uniffiStatus)
-		return false
-	})
-	return _uniffiErr.AsError()
-}
-
 // Compare two zones — returns true if `a` is fresher/better than `b`.
 func ZoneIsBetterThan(a Zone, b Zone) (bool, error) {
+	if err := Init(); err != nil {
+		var _uniffiDefaultValue bool
+		return _uniffiDefaultValue, err
+	}
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) C.int8_t {
 		return C.uniffi_libveritas_uniffi_fn_func_zone_is_better_than(FfiConverterZoneINSTANCE.Lower(a), FfiConverterZoneINSTANCE.Lower(b), _uniffiStatus)
 	})
@@ -3752,12 +3223,16 @@ func ZoneIsBetterThan(a Zone, b Zone) (bool, error) {
 		var _uniffiDefaultValue bool
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterBoolINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterBoolINSTANCE.Lift, _uniffiRV)
 	}
 }
 
 // Serialize a Zone record to bytes for storage.
 func ZoneToBytes(zone Zone) ([]byte, error) {
+	if err := Init(); err != nil {
+		var _uniffiDefaultValue []byte
+		return _uniffiDefaultValue, err
+	}
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
 			inner: C.uniffi_libveritas_uniffi_fn_func_zone_to_bytes(FfiConverterZoneINSTANCE.Lower(zone), _uniffiStatus),
@@ -3767,12 +3242,19 @@ func ZoneToBytes(zone Zone) ([]byte, error) {
 		var _uniffiDefaultValue []byte
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterBytesINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterBytesINSTANCE.Lift, _uniffiRV)
 	}
 }
 
 // Serialize a Zone record to JSON.
+//
+// The output is meant for display. It is not the format of Zone.MarshalJSON
+// and cannot be decoded with ZoneFromTaggedJson.
 func ZoneToJson(zone Zone) (string, error) {
+	if err := Init(); err != nil {
+		var _uniffiDefaultValue string
+		return _uniffiDefaultValue, err
+	}
 	_uniffiRV, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) RustBufferI {
 		return GoRustBuffer{
 			inner: C.uniffi_libveritas_uniffi_fn_func_zone_to_json(FfiConverterZoneINSTANCE.Lower(zone), _uniffiStatus),
@@ -3782,6 +3264,43 @@ func ZoneToJson(zone Zone) (string, error) {
 		var _uniffiDefaultValue string
 		return _uniffiDefaultValue, _uniffiErr
 	} else {
-		return FfiConverterStringINSTANCE.Lift(_uniffiRV), nil
+		return liftResult(FfiConverterStringINSTANCE.Lift, _uniffiRV)
+	}
+}
+
+// Native versions of HashSignableMessage, VerifySchnorr and
+// VerifySpacesMessage, which are implemented in Go. Tests use them to check
+// that both implementations agree.
+
+func nativeHashSignableMessage(msg []byte) ([]byte, error) {
+	if err := Init(); err != nil {
+		return nil, err
+	}
+	return FfiConverterBytesINSTANCE.Lift(rustCall(func(_uniffiStatus *C.RustCallStatus) RustBufferI {
+		return GoRustBuffer{
+			inner: C.uniffi_libveritas_uniffi_fn_func_hash_signable_message(FfiConverterBytesINSTANCE.Lower(msg), _uniffiStatus),
+		}
+	})), nil
+}
+
+func nativeVerifySchnorr(msgHash []byte, signature []byte, pubkey []byte) error {
+	if err := Init(); err != nil {
+		return err
+	}
+	_, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) bool {
+		C.uniffi_libveritas_uniffi_fn_func_verify_schnorr(FfiConverterBytesINSTANCE.Lower(msgHash), FfiConverterBytesINSTANCE.Lower(signature), FfiConverterBytesINSTANCE.Lower(pubkey), _uniffiStatus)
+		return false
+	})
+	return _uniffiErr.AsError()
+}
+
+func nativeVerifySpacesMessage(msg []byte, signature []byte, pubkey []byte) error {
+	if err := Init(); err != nil {
+		return err
 	}
+	_, _uniffiErr := rustCallWithError[VeritasError](FfiConverterVeritasError{}, func(_uniffiStatus *C.RustCallStatus) bool {
+		C.uniffi_libveritas_uniffi_fn_func_verify_spaces_message(FfiConverterBytesINSTANCE.Lower(msg), FfiConverterBytesINSTANCE.Lower(signature), FfiConverterBytesINSTANCE.Lower(pubkey), _uniffiStatus)
+		return false
+	})
+	return _uniffiErr.AsError()
 }
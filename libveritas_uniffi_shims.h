@@ -0,0 +1,195 @@
+// Call shims for libveritas_uniffi.
+//
+// A Rust method consumes one reference to its receiver and to each object
+// argument. Each shim clones those references and calls the method in the
+// same cgo call, instead of a separate cgo call per clone. The Go side keeps
+// the objects pinned with their call counters while the shim runs.
+//
+// The method also consumes its RustBuffer arguments, which the Go side
+// lowered before the call. When a clone fails the method is not called, so
+// the shim frees them instead; release lists the calls that do so.
+
+#ifndef LIBVERITAS_UNIFFI_SHIMS_H
+#define LIBVERITAS_UNIFFI_SHIMS_H
+
+#include <libveritas_uniffi.h>
+
+static inline void libveritas_free_arg(RustBuffer buf) {
+	RustCallStatus ignored = {0};
+	ffi_libveritas_uniffi_rustbuffer_free(buf, &ignored);
+}
+
+#define LIBVERITAS_METHOD(ret, object, method, params, args, release)          \
+	static inline ret libveritas_##object##_##method params {                 \
+		ret zero = {0};                                                        \
+		ptr = uniffi_libveritas_uniffi_fn_clone_##object(ptr, out_status);     \
+		if (out_status->code != 0) {                                           \
+			release;                                                           \
+			return zero;                                                       \
+		}                                                                      \
+		return uniffi_libveritas_uniffi_fn_method_##object##_##method args;    \
+	}
+
+#define LIBVERITAS_METHOD_VOID(object, method, params, args, release)          \
+	static inline void libveritas_##object##_##method params {                \
+		ptr = uniffi_libveritas_uniffi_fn_clone_##object(ptr, out_status);     \
+		if (out_status->code != 0) {                                           \
+			release;                                                           \
+			return;                                                            \
+		}                                                                      \
+		uniffi_libveritas_uniffi_fn_method_##object##_##method args;           \
+	}
+
+LIBVERITAS_METHOD(RustBuffer, anchors, compute_trust_set, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(RustBuffer, lookup, advance, (void* ptr, RustBuffer zones, RustCallStatus* out_status), (ptr, zones, out_status), libveritas_free_arg(zones))
+LIBVERITAS_METHOD(RustBuffer, lookup, expand_zones, (void* ptr, RustBuffer zones, RustCallStatus* out_status), (ptr, zones, out_status), libveritas_free_arg(zones))
+LIBVERITAS_METHOD(RustBuffer, lookup, start, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD_VOID(message, set_delegate_records, (void* ptr, RustBuffer canonical, RustBuffer records_bytes, RustCallStatus* out_status), (ptr, canonical, records_bytes, out_status), (libveritas_free_arg(canonical), libveritas_free_arg(records_bytes)))
+LIBVERITAS_METHOD_VOID(message, set_records, (void* ptr, RustBuffer canonical, RustBuffer records_bytes, RustCallStatus* out_status), (ptr, canonical, records_bytes, out_status), (libveritas_free_arg(canonical), libveritas_free_arg(records_bytes)))
+LIBVERITAS_METHOD(RustBuffer, message, to_bytes, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD_VOID(message, update, (void* ptr, RustBuffer updates, RustCallStatus* out_status), (ptr, updates, out_status), libveritas_free_arg(updates))
+LIBVERITAS_METHOD_VOID(messagebuilder, add_cert, (void* ptr, RustBuffer cert_bytes, RustCallStatus* out_status), (ptr, cert_bytes, out_status), libveritas_free_arg(cert_bytes))
+LIBVERITAS_METHOD_VOID(messagebuilder, add_chain, (void* ptr, RustBuffer chain_bytes, RustCallStatus* out_status), (ptr, chain_bytes, out_status), libveritas_free_arg(chain_bytes))
+LIBVERITAS_METHOD_VOID(messagebuilder, add_handle, (void* ptr, RustBuffer chain_bytes, RustBuffer records_bytes, RustCallStatus* out_status), (ptr, chain_bytes, records_bytes, out_status), (libveritas_free_arg(chain_bytes), libveritas_free_arg(records_bytes)))
+LIBVERITAS_METHOD_VOID(messagebuilder, add_records, (void* ptr, RustBuffer handle, RustBuffer records_bytes, RustCallStatus* out_status), (ptr, handle, records_bytes, out_status), (libveritas_free_arg(handle), libveritas_free_arg(records_bytes)))
+LIBVERITAS_METHOD_VOID(messagebuilder, add_update, (void* ptr, RustBuffer entry, RustCallStatus* out_status), (ptr, entry, out_status), libveritas_free_arg(entry))
+LIBVERITAS_METHOD(RustBuffer, messagebuilder, build, (void* ptr, RustBuffer chain_proof, RustCallStatus* out_status), (ptr, chain_proof, out_status), libveritas_free_arg(chain_proof))
+LIBVERITAS_METHOD(RustBuffer, messagebuilder, chain_proof_request, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD_VOID(querycontext, add_request, (void* ptr, RustBuffer handle, RustCallStatus* out_status), (ptr, handle, out_status), libveritas_free_arg(handle))
+LIBVERITAS_METHOD_VOID(querycontext, add_zone, (void* ptr, RustBuffer zone_bytes, RustCallStatus* out_status), (ptr, zone_bytes, out_status), libveritas_free_arg(zone_bytes))
+LIBVERITAS_METHOD(int8_t, recordset, is_empty, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(RustBuffer, recordset, signing_id, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(RustBuffer, recordset, to_bytes, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(RustBuffer, recordset, unpack, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(RustBuffer, unsignedrecordset, canonical, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(uint8_t, unsignedrecordset, flags, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(RustBuffer, unsignedrecordset, handle, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(int8_t, unsignedrecordset, is_delegate, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(RustBuffer, unsignedrecordset, pack_sig, (void* ptr, RustBuffer signature, RustCallStatus* out_status), (ptr, signature, out_status), libveritas_free_arg(signature))
+LIBVERITAS_METHOD_VOID(unsignedrecordset, set_flags, (void* ptr, uint8_t flags, RustCallStatus* out_status), (ptr, flags, out_status), (void)0)
+LIBVERITAS_METHOD(RustBuffer, unsignedrecordset, signable_bytes, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(RustBuffer, unsignedrecordset, signing_id, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(RustBuffer, verifiedmessage, certificates, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(void*, verifiedmessage, message, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(RustBuffer, verifiedmessage, message_bytes, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(RustBuffer, verifiedmessage, zones, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(RustBuffer, veritas, compute_trust_set, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(int8_t, veritas, is_finalized, (void* ptr, uint32_t commitment_height, RustCallStatus* out_status), (ptr, commitment_height, out_status), (void)0)
+LIBVERITAS_METHOD(uint32_t, veritas, newest_anchor, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(uint32_t, veritas, oldest_anchor, (void* ptr, RustCallStatus* out_status), (ptr, out_status), (void)0)
+LIBVERITAS_METHOD(RustBuffer, veritas, sovereignty_for, (void* ptr, uint32_t commitment_height, RustCallStatus* out_status), (ptr, commitment_height, out_status), (void)0)
+
+// Veritas.verify* also take a query context and a message. A clone that
+// already succeeded is released when a later one fails.
+static inline int libveritas_clone_verify_args(void** ptr, void** ctx, void** msg, RustCallStatus* out_status) {
+	RustCallStatus ignored = {0};
+	*ptr = uniffi_libveritas_uniffi_fn_clone_veritas(*ptr, out_status);
+	if (out_status->code != 0) {
+		return 0;
+	}
+	*ctx = uniffi_libveritas_uniffi_fn_clone_querycontext(*ctx, out_status);
+	if (out_status->code != 0) {
+		uniffi_libveritas_uniffi_fn_free_veritas(*ptr, &ignored);
+		return 0;
+	}
+	*msg = uniffi_libveritas_uniffi_fn_clone_message(*msg, out_status);
+	if (out_status->code != 0) {
+		uniffi_libveritas_uniffi_fn_free_veritas(*ptr, &ignored);
+		uniffi_libveritas_uniffi_fn_free_querycontext(*ctx, &ignored);
+		return 0;
+	}
+	return 1;
+}
+
+static inline void* libveritas_veritas_verify(void* ptr, void* ctx, void* msg, RustCallStatus* out_status) {
+	if (!libveritas_clone_verify_args(&ptr, &ctx, &msg, out_status)) {
+		return NULL;
+	}
+	return uniffi_libveritas_uniffi_fn_method_veritas_verify(ptr, ctx, msg, out_status);
+}
+
+static inline void* libveritas_veritas_verify_with_options(void* ptr, void* ctx, void* msg, uint32_t options, RustCallStatus* out_status) {
+	if (!libveritas_clone_verify_args(&ptr, &ctx, &msg, out_status)) {
+		return NULL;
+	}
+	return uniffi_libveritas_uniffi_fn_method_veritas_verify_with_options(ptr, ctx, msg, options, out_status);
+}
+
+static inline void* libveritas_veritas_new(void* anchors, RustCallStatus* out_status) {
+	anchors = uniffi_libveritas_uniffi_fn_clone_anchors(anchors, out_status);
+	if (out_status->code != 0) {
+		return NULL;
+	}
+	return uniffi_libveritas_uniffi_fn_constructor_veritas_new(anchors, out_status);
+}
+
+// Batch calls. Each input is lowered into a RustBuffer, with the u32
+// length prefix of a byte string when prefixed is set, and passed to the
+// operation. Every item gets its own status, so one failing item does not
+// affect the others.
+
+enum {
+	LIBVERITAS_BATCH_DECODE_ZONE = 0,
+	LIBVERITAS_BATCH_ZONE_TO_BYTES = 1,
+	LIBVERITAS_BATCH_UNPACK_RECORD_SET = 2,
+};
+
+typedef struct LibveritasBatchItem {
+	RustBuffer value;
+	RustCallStatus status;
+} LibveritasBatchItem;
+
+static inline RustBuffer libveritas_batch_lower(ForeignBytes input, int prefixed, RustCallStatus* out_status) {
+	uint64_t size = (uint64_t)input.len + (prefixed ? 4 : 0);
+	RustBuffer buf = ffi_libveritas_uniffi_rustbuffer_alloc(size, out_status);
+	if (out_status->code != 0) {
+		return buf;
+	}
+	uint8_t* out = buf.data;
+	if (prefixed) {
+		out[0] = (uint8_t)((uint32_t)input.len >> 24);
+		out[1] = (uint8_t)((uint32_t)input.len >> 16);
+		out[2] = (uint8_t)((uint32_t)input.len >> 8);
+		out[3] = (uint8_t)input.len;
+		out += 4;
+	}
+	for (int32_t i = 0; i < input.len; i++) {
+		out[i] = input.data[i];
+	}
+	return buf;
+}
+
+static inline void libveritas_batch(int op, const ForeignBytes* inputs, int32_t count, LibveritasBatchItem* items) {
+	for (int32_t i = 0; i < count; i++) {
+		RustCallStatus* status = &items[i].status;
+		RustBuffer arg = libveritas_batch_lower(inputs[i], op != LIBVERITAS_BATCH_ZONE_TO_BYTES, status);
+		if (status->code != 0) {
+			continue;
+		}
+		switch (op) {
+		case LIBVERITAS_BATCH_DECODE_ZONE:
+			items[i].value = uniffi_libveritas_uniffi_fn_func_decode_zone(arg, status);
+			break;
+		case LIBVERITAS_BATCH_ZONE_TO_BYTES:
+			items[i].value = uniffi_libveritas_uniffi_fn_func_zone_to_bytes(arg, status);
+			break;
+		case LIBVERITAS_BATCH_UNPACK_RECORD_SET: {
+			// The method consumes the only reference to the record set.
+			void* record_set = uniffi_libveritas_uniffi_fn_constructor_recordset_new(arg, status);
+			if (status->code == 0) {
+				items[i].value = uniffi_libveritas_uniffi_fn_method_recordset_unpack(record_set, status);
+			}
+			break;
+		}
+		}
+	}
+}
+
+static inline void libveritas_free_buffers(RustBuffer* bufs, int32_t count) {
+	for (int32_t i = 0; i < count; i++) {
+		RustCallStatus ignored = {0};
+		ffi_libveritas_uniffi_rustbuffer_free(bufs[i], &ignored);
+	}
+}
+
+#endif
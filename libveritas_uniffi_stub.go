@@ -0,0 +1,411 @@
+//go:build !cgo || libveritas_nocgo || !(libveritas_dynamic || libveritas_pkgconfig || (linux && amd64) || (darwin && arm64) || (windows && amd64))
+
+package libveritas
+
+// Stand-ins for the native bindings, used when the native library cannot be
+// linked. Constructors and fallible methods return ErrNativeUnavailable;
+// methods without an error result return zero values. RecordSet is backed by
+// the pure-Go SIP-7 codec and keeps working.
+
+const nativeAvailable = false
+
+func uniffiCheckChecksums() error {
+	return ErrNativeUnavailable
+}
+
+// ContractVersion returns 0 in builds without the native library.
+func ContractVersion() uint32 {
+	return 0
+}
+
+// LibraryVersion returns an empty string in builds without the native library.
+func LibraryVersion() string {
+	return ""
+}
+
+type Anchors struct {
+	source string
+}
+
+func AnchorsFromJson(json string) (*Anchors, error) {
+	return nil, ErrNativeUnavailable
+}
+
+func (_self *Anchors) ComputeTrustSet() TrustSet {
+	return TrustSet{}
+}
+func (object *Anchors) Destroy() {}
+
+// Batched iterative resolver for nested handle names.
+type Lookup struct{}
+
+// Create a lookup from a list of handle name strings.
+func NewLookup(names []string) (*Lookup, error) {
+	if err := checkHandles(names); err != nil {
+		return nil, err
+	}
+	return nil, ErrNativeUnavailable
+}
+
+// Feed zones from a resolveAll response.
+// Returns the next batch of handles to look up (empty = done).
+func (_self *Lookup) Advance(zones []Zone) ([]string, error) {
+	return nil, ErrNativeUnavailable
+}
+
+// Expand zone handles using the alias map accumulated during resolution.
+func (_self *Lookup) ExpandZones(zones []Zone) ([]Zone, error) {
+	return nil, ErrNativeUnavailable
+}
+
+// Returns the first batch of handles to look up.
+func (_self *Lookup) Start() []string {
+	return nil
+}
+func (object *Lookup) Destroy() {}
+
+type Message struct{}
+
+// Decode a message from bytes.
+func NewMessage(bytes []byte) (*Message, error) {
+	return nil, ErrNativeUnavailable
+}
+
+// Set delegate records on the message for a canonical name.
+func (_self *Message) SetDelegateRecords(canonical string, recordsBytes []byte) error {
+	return ErrNativeUnavailable
+}
+
+// Set records on the message for a canonical name.
+func (_self *Message) SetRecords(canonical string, recordsBytes []byte) error {
+	return ErrNativeUnavailable
+}
+
+// Serialize the message to bytes.
+func (_self *Message) ToBytes() []byte {
+	return nil
+}
+
+// Serialize the message to bytes, as a view of the native buffer.
+func (_self *Message) ToBytesView() *BytesView {
+	return newBytesView(nil, nil)
+}
+
+// Update records on this message.
+func (_self *Message) Update(updates []DataUpdateEntry) error {
+	return ErrNativeUnavailable
+}
+func (object *Message) Destroy() {}
+
+// Builder for constructing messages from update requests and chain proofs.
+type MessageBuilder struct{}
+
+// Create an empty builder.
+func NewMessageBuilder() *MessageBuilder {
+	return &MessageBuilder{}
+}
+
+// Add a single certificate.
+func (_self *MessageBuilder) AddCert(certBytes []byte) error {
+	return ErrNativeUnavailable
+}
+
+// Add all certificates from a .spacecert chain.
+func (_self *MessageBuilder) AddChain(chainBytes []byte) error {
+	return ErrNativeUnavailable
+}
+
+// Add a .spacecert chain with records (sip7 wire bytes).
+func (_self *MessageBuilder) AddHandle(chainBytes []byte, recordsBytes []byte) error {
+	return ErrNativeUnavailable
+}
+
+// Add records for a handle (sip7 wire bytes).
+func (_self *MessageBuilder) AddRecords(handle string, recordsBytes []byte) error {
+	if err := checkHandle(handle); err != nil {
+		return err
+	}
+	return ErrNativeUnavailable
+}
+
+// Add a full data update (records + optional delegate records).
+func (_self *MessageBuilder) AddUpdate(entry DataUpdateEntry) error {
+	return ErrNativeUnavailable
+}
+
+// Build the message from a ChainProof.
+func (_self *MessageBuilder) Build(chainProof []byte) (BuildResult, error) {
+	return BuildResult{}, ErrNativeUnavailable
+}
+
+// Returns the chain proof request as JSON.
+func (_self *MessageBuilder) ChainProofRequest() (string, error) {
+	return "", ErrNativeUnavailable
+}
+func (object *MessageBuilder) Destroy() {}
+
+// QueryContext only records requests and zones in this build, so that it can
+// still be passed to a SandboxedVeritas whose worker links the native library.
+type QueryContext struct {
+	log *queryLog
+}
+
+func NewQueryContext() *QueryContext {
+	return &QueryContext{log: &queryLog{}}
+}
+
+// Add a handle to verify (e.g. "alice@bitcoin").
+func (_self *QueryContext) AddRequest(handle string) error {
+	if err := checkHandle(handle); err != nil {
+		return err
+	}
+	_self.log.logRequest(handle)
+	return nil
+}
+
+// Add a known zone from stored bytes (from a previous verification).
+func (_self *QueryContext) AddZone(zoneBytes []byte) error {
+	_self.log.logZone(zoneBytes)
+	return nil
+}
+func (object *QueryContext) Destroy() {}
+
+// SIP-7 record set — wire-format encoded records.
+type RecordSet struct {
+	data []byte
+}
+
+// Wrap raw wire bytes (lazy — no parsing until unpack).
+func NewRecordSet(data []byte) *RecordSet {
+	return &RecordSet{data: append([]byte{}, data...)}
+}
+
+// Pack records into wire format.
+func RecordSetPack(records []Record) (*RecordSet, error) {
+	data, err := PackRecords(records)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordSet{data: data}, nil
+}
+
+func (_self *RecordSet) IsEmpty() bool {
+	return len(_self.data) == 0
+}
+
+// The 32-byte signing hash. Computing it requires the native library, so
+// this build returns nil.
+func (_self *RecordSet) SigningId() []byte {
+	return nil
+}
+
+// Raw wire bytes.
+func (_self *RecordSet) ToBytes() []byte {
+	return append([]byte{}, _self.data...)
+}
+
+// Parse all records.
+func (_self *RecordSet) Unpack() ([]ParsedRecord, error) {
+	return UnpackRecords(_self.data)
+}
+func (object *RecordSet) Destroy() {}
+
+// An unsigned record set pending signature.
+type UnsignedRecordSet struct{}
+
+// The canonical/flattened name.
+func (_self *UnsignedRecordSet) Canonical() string {
+	return ""
+}
+
+// Current sig flags.
+func (_self *UnsignedRecordSet) Flags() uint8 {
+	return 0
+}
+
+// The original handle name (before flattening).
+func (_self *UnsignedRecordSet) Handle() string {
+	return ""
+}
+
+// Whether these are delegate records.
+func (_self *UnsignedRecordSet) IsDelegate() bool {
+	return false
+}
+
+// Pack the Sig record with the given signature. Returns signed RecordSet wire bytes.
+func (_self *UnsignedRecordSet) PackSig(signature []byte) []byte {
+	return nil
+}
+
+// Set sig flags (e.g. `SIG_PRIMARY_ZONE`).
+func (_self *UnsignedRecordSet) SetFlags(flags uint8) {}
+
+// The raw signable bytes (before hashing).
+func (_self *UnsignedRecordSet) SignableBytes() []byte {
+	return nil
+}
+
+// The 32-byte signing hash (Spaces signed-message prefix + SHA256).
+func (_self *UnsignedRecordSet) SigningId() []byte {
+	return nil
+}
+func (object *UnsignedRecordSet) Destroy() {}
+
+type VerifiedMessage struct{}
+
+func (_self *VerifiedMessage) Certificates() [][]byte {
+	return nil
+}
+
+// Get the verified message for rebroadcasting or updating.
+func (_self *VerifiedMessage) Message() *Message {
+	return nil
+}
+
+// Get the verified message as bytes.
+func (_self *VerifiedMessage) MessageBytes() []byte {
+	return nil
+}
+
+// Get the verified message as a view of the native buffer.
+func (_self *VerifiedMessage) MessageBytesView() *BytesView {
+	return newBytesView(nil, nil)
+}
+
+func (_self *VerifiedMessage) Zones() []Zone {
+	return nil
+}
+func (object *VerifiedMessage) Destroy() {}
+
+type Veritas struct{}
+
+func NewVeritas(anchors *Anchors) (*Veritas, error) {
+	return nil, ErrNativeUnavailable
+}
+
+func (_self *Veritas) ComputeTrustSet() TrustSet {
+	return TrustSet{}
+}
+
+func (_self *Veritas) IsFinalized(commitmentHeight uint32) bool {
+	return false
+}
+
+func (_self *Veritas) NewestAnchor() uint32 {
+	return 0
+}
+
+func (_self *Veritas) OldestAnchor() uint32 {
+	return 0
+}
+
+func (_self *Veritas) SovereigntyFor(commitmentHeight uint32) string {
+	return ""
+}
+
+// Verify a message with default options.
+func (_self *Veritas) Verify(ctx *QueryContext, msg *Message) (*VerifiedMessage, error) {
+	return nil, ErrNativeUnavailable
+}
+
+// Verify a message with option flags (combine with bitwise OR).
+//
+// Deprecated: The flags are those of the native library (VerifyDevMode,
+// VerifyEnableSnark), not VerifyOptions. Use VerifyWith.
+func (_self *Veritas) VerifyWithOptions(ctx *QueryContext, msg *Message, options uint32) (*VerifiedMessage, error) {
+	return nil, ErrNativeUnavailable
+}
+
+// Verify a message with options.
+func (_self *Veritas) VerifyWith(ctx *QueryContext, msg *Message, options VerifyOptions) (*VerifiedMessage, error) {
+	return nil, ErrNativeUnavailable
+}
+func (object *Veritas) Destroy() {}
+
+// Create a .spacecert file from a subject name and certificate bytes.
+func CreateCertificateChain(subject string, certBytesList [][]byte) ([]byte, error) {
+	return nil, ErrNativeUnavailable
+}
+
+// Decode stored certificate bytes to JSON.
+func DecodeCertificate(bytes []byte) (string, error) {
+	return "", ErrNativeUnavailable
+}
+
+// Decode stored zone bytes to a Zone record.
+func DecodeZone(bytes []byte) (Zone, error) {
+	return Zone{}, ErrNativeUnavailable
+}
+
+func SigPrimaryZone() uint8 {
+	return 0
+}
+
+func VerifyDefault() uint32 {
+	return 0
+}
+
+func VerifyDevMode() uint32 {
+	return 0
+}
+
+func VerifyEnableSnark() uint32 {
+	return 0
+}
+
+// Compare two zones — returns true if `a` is fresher/better than `b`.
+func ZoneIsBetterThan(a Zone, b Zone) (bool, error) {
+	return false, ErrNativeUnavailable
+}
+
+// Serialize a Zone record to bytes for storage.
+func ZoneToBytes(zone Zone) ([]byte, error) {
+	return nil, ErrNativeUnavailable
+}
+
+// Serialize a Zone record to JSON.
+//
+// The output is meant for display. It is not the format of Zone.MarshalJSON
+// and cannot be decoded with ZoneFromTaggedJson.
+func ZoneToJson(zone Zone) (string, error) {
+	return "", ErrNativeUnavailable
+}
+
+// Decode stored zone bytes like DecodeZone, for a whole batch.
+func DecodeZones(blobs [][]byte) ([]Zone, error) {
+	return nil, ErrNativeUnavailable
+}
+
+// Serialize zones like ZoneToBytes, for a whole batch.
+func ZonesToBytes(zones []Zone) ([][]byte, error) {
+	return nil, ErrNativeUnavailable
+}
+
+// Parse record sets like NewRecordSet(data).Unpack(), for a whole batch.
+// Items that fail are reported in a *BatchError.
+func UnpackRecordSets(sets [][]byte) ([][]ParsedRecord, error) {
+	results := make([][]ParsedRecord, len(sets))
+	var batchErr BatchError
+	for i, set := range sets {
+		records, err := UnpackRecords(set)
+		if err != nil {
+			batchErr.add(i, err)
+			continue
+		}
+		results[i] = records
+	}
+	return results, batchErr.AsError()
+}
+
+func nativeHashSignableMessage(msg []byte) ([]byte, error) {
+	return nil, ErrNativeUnavailable
+}
+
+func nativeVerifySchnorr(msgHash []byte, signature []byte, pubkey []byte) error {
+	return ErrNativeUnavailable
+}
+
+func nativeVerifySpacesMessage(msg []byte, signature []byte, pubkey []byte) error {
+	return ErrNativeUnavailable
+}
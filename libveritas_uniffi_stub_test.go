@@ -0,0 +1,65 @@
+//go:build !cgo || libveritas_nocgo || !(libveritas_dynamic || libveritas_pkgconfig || (linux && amd64) || (darwin && arm64) || (windows && amd64))
+
+package libveritas
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestStubReportsNativeUnavailable(t *testing.T) {
+	if NativeAvailable() {
+		t.Fatal("NativeAvailable in a stub build")
+	}
+	if err := Init(); !errors.Is(err, ErrNativeUnavailable) {
+		t.Errorf("Init = %v, want ErrNativeUnavailable", err)
+	}
+	calls := map[string]func() error{
+		"AnchorsFromJson": func() error { _, err := AnchorsFromJson("[]"); return err },
+		"NewMessage":      func() error { _, err := NewMessage([]byte{0}); return err },
+		"DecodeZone":      func() error { _, err := DecodeZone([]byte{0}); return err },
+		"DecodeCertificate": func() error {
+			_, err := DecodeCertificate([]byte{0})
+			return err
+		},
+		"CreateCertificateChain": func() error {
+			_, err := CreateCertificateChain("alice@bitcoin", nil)
+			return err
+		},
+		"MessageBuilder.AddChain": func() error { return NewMessageBuilder().AddChain(nil) },
+		"ReadSpacecert": func() error {
+			chain := encodeSpacecert("alice@bitcoin", [][]byte{{1}})
+			_, err := ReadSpacecert(bytes.NewReader(chain))
+			return err
+		},
+	}
+	for name, call := range calls {
+		err := call()
+		if !errors.Is(err, ErrNativeUnavailable) {
+			t.Errorf("%s = %v, want ErrNativeUnavailable", name, err)
+		}
+		if code := ErrorCodeOf(err); code != CodeNativeUnavailable {
+			t.Errorf("%s code = %q, want %q", name, code, CodeNativeUnavailable)
+		}
+	}
+}
+
+func TestStubRecordSetUsesGoCodec(t *testing.T) {
+	records := []Record{RecordSeq{Version: 1}, RecordTxt{Key: "name", Value: []string{"alice"}}}
+	set, err := RecordSetPack(records)
+	if err != nil {
+		t.Fatalf("RecordSetPack: %v", err)
+	}
+	packed, err := PackRecords(records)
+	if err != nil {
+		t.Fatalf("PackRecords: %v", err)
+	}
+	if string(set.ToBytes()) != string(packed) {
+		t.Errorf("RecordSetPack = %x, want %x", set.ToBytes(), packed)
+	}
+	parsed, err := NewRecordSet(packed).Unpack()
+	if err != nil || len(parsed) != 2 {
+		t.Errorf("Unpack = %v, %v", parsed, err)
+	}
+}
@@ -0,0 +1,270 @@
+//go:build cgo && !libveritas_nocgo && (libveritas_dynamic || libveritas_pkgconfig || (linux && amd64) || (darwin && arm64) || (windows && amd64))
+
+package libveritas
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The tests below race calls against Destroy and are meant to be run with
+// go test -race.
+
+const destroyStressRounds = 200
+
+// callDuringDestroy runs call on several goroutines while destroy runs, and
+// fails the test if a call panics for any reason other than the object
+// being destroyed already.
+func callDuringDestroy(t *testing.T, call func(), destroy func()) {
+	t.Helper()
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					if err, ok := recovered.(error); !ok || !strings.Contains(err.Error(), "already been destroyed") {
+						t.Errorf("call panicked: %v", recovered)
+					}
+				}
+			}()
+			<-start
+			for j := 0; j < 10; j++ {
+				call()
+			}
+		}()
+	}
+	close(start)
+	time.Sleep(time.Microsecond)
+	destroy()
+	wg.Wait()
+}
+
+func TestDestroyDuringMethodCalls(t *testing.T) {
+	for i := 0; i < destroyStressRounds; i++ {
+		qctx := NewQueryContext()
+		callDuringDestroy(t, func() { qctx.AddRequest("alice@bitcoin") }, qctx.Destroy)
+	}
+}
+
+func TestDestroyDuringArgumentUse(t *testing.T) {
+	if _, err := AnchorsFromJson("[]"); err != nil {
+		t.Skipf("AnchorsFromJson: %v", err)
+	}
+	for i := 0; i < destroyStressRounds; i++ {
+		anchors, err := AnchorsFromJson("[]")
+		if err != nil {
+			t.Fatalf("AnchorsFromJson: %v", err)
+		}
+		callDuringDestroy(t, func() {
+			// A destroyed argument is reported as an error.
+			if veritas, err := NewVeritas(anchors); err == nil {
+				veritas.Destroy()
+			}
+		}, anchors.Destroy)
+	}
+}
+
+// TestDestroyDuringVerify destroys the arguments of Verify and VerifyWith
+// while they run, which must fail the calls with an error rather than a
+// panic.
+func TestDestroyDuringVerify(t *testing.T) {
+	msgBytes := benchFixture(t, "LIBVERITAS_BENCH_MESSAGE")
+	anchors, err := AnchorsFromJson(string(benchFixture(t, "LIBVERITAS_BENCH_ANCHORS")))
+	if err != nil {
+		t.Fatalf("AnchorsFromJson: %v", err)
+	}
+	defer anchors.Destroy()
+	veritas, err := NewVeritas(anchors)
+	if err != nil {
+		t.Fatalf("NewVeritas: %v", err)
+	}
+	defer veritas.Destroy()
+	for i := 0; i < destroyStressRounds/10; i++ {
+		qctx := NewQueryContext()
+		msg, err := NewMessage(msgBytes)
+		if err != nil {
+			t.Fatalf("NewMessage: %v", err)
+		}
+		callDuringDestroy(t, func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					t.Errorf("Verify panicked on a destroyed argument: %v", recovered)
+				}
+			}()
+			if verified, err := veritas.Verify(qctx, msg); err == nil {
+				verified.Destroy()
+			}
+			if verified, err := veritas.VerifyWith(qctx, msg, VerifyOptionsDefault); err == nil {
+				verified.Destroy()
+			}
+		}, func() {
+			qctx.Destroy()
+			msg.Destroy()
+		})
+	}
+}
+
+func TestDestroyIsIdempotent(t *testing.T) {
+	qctx := NewQueryContext()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			qctx.Destroy()
+		}()
+	}
+	wg.Wait()
+	if err := qctx.ffiObject.acquire("*QueryContext"); err == nil {
+		t.Error("destroyed object could still be acquired")
+	}
+}
+
+// The method benchmarks report cgo-calls/op. With the call shims a method
+// call on an object crosses into C once, where cloning the receiver took a
+// crossing of its own before.
+func reportCgoCalls(b *testing.B, start int64) {
+	b.ReportMetric(float64(runtime.NumCgoCall()-start)/float64(b.N), "cgo-calls/op")
+}
+
+func BenchmarkRecordSetIsEmpty(b *testing.B) {
+	set := NewRecordSet(nil)
+	defer set.Destroy()
+	b.ResetTimer()
+	start := runtime.NumCgoCall()
+	for i := 0; i < b.N; i++ {
+		set.IsEmpty()
+	}
+	reportCgoCalls(b, start)
+}
+
+func BenchmarkQueryContextAddRequest(b *testing.B) {
+	qctx := NewQueryContext()
+	defer qctx.Destroy()
+	b.ResetTimer()
+	start := runtime.NumCgoCall()
+	for i := 0; i < b.N; i++ {
+		qctx.AddRequest("alice@bitcoin")
+	}
+	reportCgoCalls(b, start)
+}
+
+func BenchmarkNewVeritas(b *testing.B) {
+	anchors, err := AnchorsFromJson("[]")
+	if err != nil {
+		b.Skipf("AnchorsFromJson: %v", err)
+	}
+	defer anchors.Destroy()
+	b.ResetTimer()
+	start := runtime.NumCgoCall()
+	for i := 0; i < b.N; i++ {
+		if veritas, err := NewVeritas(anchors); err == nil {
+			veritas.Destroy()
+		}
+	}
+	reportCgoCalls(b, start)
+}
+
+// The buffer benchmarks below and TestDestroyDuringVerify need a verifiable
+// message, which this repository has no fixture for. They read it from the
+// files named by LIBVERITAS_BENCH_MESSAGE (an encoded message) and
+// LIBVERITAS_BENCH_ANCHORS (anchors in the JSON format of AnchorsFromJson),
+// and skip without them.
+
+func benchFixture(tb testing.TB, env string) []byte {
+	tb.Helper()
+	path := os.Getenv(env)
+	if path == "" {
+		tb.Skipf("%s not set", env)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("reading %s: %v", env, err)
+	}
+	return data
+}
+
+func BenchmarkNewMessage(b *testing.B) {
+	msgBytes := benchFixture(b, "LIBVERITAS_BENCH_MESSAGE")
+	b.ReportAllocs()
+	b.SetBytes(int64(len(msgBytes)))
+	for i := 0; i < b.N; i++ {
+		msg, err := NewMessage(msgBytes)
+		if err != nil {
+			b.Fatalf("NewMessage: %v", err)
+		}
+		msg.Destroy()
+	}
+}
+
+func BenchmarkDecodeZone(b *testing.B) {
+	zoneBytes, err := ZoneToBytes(Zone{
+		Handle:          "alice@bitcoin",
+		Canonical:       "alice@bitcoin",
+		Sovereignty:     "sovereign",
+		AnchorHash:      make([]byte, 32),
+		ScriptPubkey:    make([]byte, 34),
+		Records:         make([]byte, 4096),
+		FallbackRecords: make([]byte, 0),
+		Delegate:        DelegateStateEmpty{},
+		Commitment:      CommitmentStateEmpty{},
+	})
+	if err != nil {
+		b.Skipf("ZoneToBytes: %v", err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(zoneBytes)))
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeZone(zoneBytes); err != nil {
+			b.Fatalf("DecodeZone: %v", err)
+		}
+	}
+}
+
+func BenchmarkMessageBytes(b *testing.B) {
+	msgBytes := benchFixture(b, "LIBVERITAS_BENCH_MESSAGE")
+	anchors, err := AnchorsFromJson(string(benchFixture(b, "LIBVERITAS_BENCH_ANCHORS")))
+	if err != nil {
+		b.Fatalf("AnchorsFromJson: %v", err)
+	}
+	defer anchors.Destroy()
+	veritas, err := NewVeritas(anchors)
+	if err != nil {
+		b.Fatalf("NewVeritas: %v", err)
+	}
+	defer veritas.Destroy()
+	msg, err := NewMessage(msgBytes)
+	if err != nil {
+		b.Fatalf("NewMessage: %v", err)
+	}
+	defer msg.Destroy()
+	qctx := NewQueryContext()
+	defer qctx.Destroy()
+	verified, err := veritas.Verify(qctx, msg)
+	if err != nil {
+		b.Fatalf("Verify: %v", err)
+	}
+	defer verified.Destroy()
+
+	b.Run("copy", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msgBytes)))
+		for i := 0; i < b.N; i++ {
+			verified.MessageBytes()
+		}
+	})
+	b.Run("view", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msgBytes)))
+		for i := 0; i < b.N; i++ {
+			verified.MessageBytesView().Release()
+		}
+	})
+}
@@ -0,0 +1,690 @@
+package libveritas
+
+import "fmt"
+
+// Types shared by the native bindings and the no-cgo build. None of them
+// reference cgo, so they stay available when the native library is not linked.
+
+type NativeError interface {
+	AsError() error
+}
+
+type FfiDestroyerUint8 struct{}
+
+func (FfiDestroyerUint8) Destroy(_ uint8) {}
+
+type FfiDestroyerUint32 struct{}
+
+func (FfiDestroyerUint32) Destroy(_ uint32) {}
+
+type FfiDestroyerUint64 struct{}
+
+func (FfiDestroyerUint64) Destroy(_ uint64) {}
+
+type FfiDestroyerBool struct{}
+
+func (FfiDestroyerBool) Destroy(_ bool) {}
+
+type FfiDestroyerString struct{}
+
+func (FfiDestroyerString) Destroy(_ string) {}
+
+type FfiDestroyerBytes struct{}
+
+func (FfiDestroyerBytes) Destroy(_ []byte) {}
+
+type AnchorsInterface interface {
+	ComputeTrustSet() TrustSet
+}
+
+type FfiDestroyerAnchors struct{}
+
+func (_ FfiDestroyerAnchors) Destroy(value *Anchors) {
+	value.Destroy()
+}
+
+// Batched iterative resolver for nested handle names.
+type LookupInterface interface {
+	// Feed zones from a resolveAll response.
+	// Returns the next batch of handles to look up (empty = done).
+	Advance(zones []Zone) ([]string, error)
+	// Expand zone handles using the alias map accumulated during resolution.
+	ExpandZones(zones []Zone) ([]Zone, error)
+	// Returns the first batch of handles to look up.
+	Start() []string
+}
+
+type FfiDestroyerLookup struct{}
+
+func (_ FfiDestroyerLookup) Destroy(value *Lookup) {
+	value.Destroy()
+}
+
+type MessageInterface interface {
+	// Set delegate records on the message for a canonical name.
+	SetDelegateRecords(canonical string, recordsBytes []byte) error
+	// Set records on the message for a canonical name.
+	SetRecords(canonical string, recordsBytes []byte) error
+	// Serialize the message to bytes.
+	ToBytes() []byte
+	// Update records on this message.
+	Update(updates []DataUpdateEntry) error
+}
+
+type FfiDestroyerMessage struct{}
+
+func (_ FfiDestroyerMessage) Destroy(value *Message) {
+	value.Destroy()
+}
+
+// Builder for constructing messages from update requests and chain proofs.
+type MessageBuilderInterface interface {
+	// Add a single certificate.
+	AddCert(certBytes []byte) error
+	// Add all certificates from a .spacecert chain.
+	AddChain(chainBytes []byte) error
+	// Add a .spacecert chain with records (sip7 wire bytes).
+	AddHandle(chainBytes []byte, recordsBytes []byte) error
+	// Add records for a handle (sip7 wire bytes).
+	AddRecords(handle string, recordsBytes []byte) error
+	// Add a full data update (records + optional delegate records).
+	AddUpdate(entry DataUpdateEntry) error
+	// Build the message from a ChainProof.
+	//
+	// Consumes the builder — cannot be called twice.
+	// Returns the message and unsigned record sets that need signing.
+	Build(chainProof []byte) (BuildResult, error)
+	// Returns the chain proof request as JSON.
+	//
+	// Send this to the provider/fabric to get the chain proofs needed for `build()`.
+	ChainProofRequest() (string, error)
+}
+
+type FfiDestroyerMessageBuilder struct{}
+
+func (_ FfiDestroyerMessageBuilder) Destroy(value *MessageBuilder) {
+	value.Destroy()
+}
+
+type QueryContextInterface interface {
+	// Add a handle to verify (e.g. "alice@bitcoin").
+	// If no requests are added, all handles in the message are verified.
+	AddRequest(handle string) error
+	// Add a known zone from stored bytes (from a previous verification).
+	AddZone(zoneBytes []byte) error
+}
+
+type FfiDestroyerQueryContext struct{}
+
+func (_ FfiDestroyerQueryContext) Destroy(value *QueryContext) {
+	value.Destroy()
+}
+
+// SIP-7 record set — wire-format encoded records.
+type RecordSetInterface interface {
+	IsEmpty() bool
+	// The 32-byte signing hash (Spaces signed-message prefix + SHA256).
+	SigningId() []byte
+	// Raw wire bytes.
+	ToBytes() []byte
+	// Parse all records.
+	Unpack() ([]ParsedRecord, error)
+}
+
+type FfiDestroyerRecordSet struct{}
+
+func (_ FfiDestroyerRecordSet) Destroy(value *RecordSet) {
+	value.Destroy()
+}
+
+// An unsigned record set pending signature.
+type UnsignedRecordSetInterface interface {
+	// The canonical/flattened name.
+	Canonical() string
+	// Current sig flags.
+	Flags() uint8
+	// The original handle name (before flattening).
+	Handle() string
+	// Whether these are delegate records.
+	IsDelegate() bool
+	// Pack the Sig record with the given signature. Returns signed RecordSet wire bytes.
+	PackSig(signature []byte) []byte
+	// Set sig flags (e.g. `SIG_PRIMARY_ZONE`).
+	SetFlags(flags uint8)
+	// The raw signable bytes (before hashing). Use when the signer doesn't take a digest.
+	SignableBytes() []byte
+	// The 32-byte signing hash (Spaces signed-message prefix + SHA256).
+	SigningId() []byte
+}
+
+type FfiDestroyerUnsignedRecordSet struct{}
+
+func (_ FfiDestroyerUnsignedRecordSet) Destroy(value *UnsignedRecordSet) {
+	value.Destroy()
+}
+
+type VerifiedMessageInterface interface {
+	Certificates() [][]byte
+	// Get the verified message for rebroadcasting or updating.
+	Message() *Message
+	// Get the verified message as bytes.
+	MessageBytes() []byte
+	Zones() []Zone
+}
+
+type FfiDestroyerVerifiedMessage struct{}
+
+func (_ FfiDestroyerVerifiedMessage) Destroy(value *VerifiedMessage) {
+	value.Destroy()
+}
+
+type VeritasInterface interface {
+	ComputeTrustSet() TrustSet
+	IsFinalized(commitmentHeight uint32) bool
+	NewestAnchor() uint32
+	OldestAnchor() uint32
+	SovereigntyFor(commitmentHeight uint32) string
+	// Verify a message with default options.
+	Verify(ctx *QueryContext, msg *Message) (*VerifiedMessage, error)
+	// Verify a message with options.
+	VerifyWith(ctx *QueryContext, msg *Message, options VerifyOptions) (*VerifiedMessage, error)
+	// Verify a message with option flags (combine with bitwise OR).
+	//
+	// Deprecated: Use VerifyWith.
+	VerifyWithOptions(ctx *QueryContext, msg *Message, options uint32) (*VerifiedMessage, error)
+}
+
+type FfiDestroyerVeritas struct{}
+
+func (_ FfiDestroyerVeritas) Destroy(value *Veritas) {
+	value.Destroy()
+}
+
+// Result of building a message.
+type BuildResult struct {
+	Message  *Message
+	Unsigned []*UnsignedRecordSet
+}
+
+func (r *BuildResult) Destroy() {
+	FfiDestroyerMessage{}.Destroy(r.Message)
+	FfiDestroyerSequenceUnsignedRecordSet{}.Destroy(r.Unsigned)
+}
+
+type FfiDestroyerBuildResult struct{}
+
+func (_ FfiDestroyerBuildResult) Destroy(value BuildResult) {
+	value.Destroy()
+}
+
+// Data update entry for Message.update() — no cert field.
+type DataUpdateEntry struct {
+	Name            string
+	Records         *[]byte
+	DelegateRecords *[]byte
+}
+
+func (r *DataUpdateEntry) Destroy() {
+	FfiDestroyerString{}.Destroy(r.Name)
+	FfiDestroyerOptionalBytes{}.Destroy(r.Records)
+	FfiDestroyerOptionalBytes{}.Destroy(r.DelegateRecords)
+}
+
+type FfiDestroyerDataUpdateEntry struct{}
+
+func (_ FfiDestroyerDataUpdateEntry) Destroy(value DataUpdateEntry) {
+	value.Destroy()
+}
+
+type TrustSet struct {
+	Id    []byte
+	Roots [][]byte
+}
+
+func (r *TrustSet) Destroy() {
+	FfiDestroyerBytes{}.Destroy(r.Id)
+	FfiDestroyerSequenceBytes{}.Destroy(r.Roots)
+}
+
+type FfiDestroyerTrustSet struct{}
+
+func (_ FfiDestroyerTrustSet) Destroy(value TrustSet) {
+	value.Destroy()
+}
+
+type Zone struct {
+	Anchor          uint32
+	AnchorHash      []byte
+	Sovereignty     string
+	Handle          string
+	Canonical       string
+	Alias           *string
+	ScriptPubkey    []byte
+	NumId           *string
+	Records         []byte
+	FallbackRecords []byte
+	Delegate        DelegateState
+	Commitment      CommitmentState
+}
+
+func (r *Zone) Destroy() {
+	FfiDestroyerUint32{}.Destroy(r.Anchor)
+	FfiDestroyerBytes{}.Destroy(r.AnchorHash)
+	FfiDestroyerString{}.Destroy(r.Sovereignty)
+	FfiDestroyerString{}.Destroy(r.Handle)
+	FfiDestroyerString{}.Destroy(r.Canonical)
+	FfiDestroyerOptionalString{}.Destroy(r.Alias)
+	FfiDestroyerBytes{}.Destroy(r.ScriptPubkey)
+	FfiDestroyerOptionalString{}.Destroy(r.NumId)
+	FfiDestroyerBytes{}.Destroy(r.Records)
+	FfiDestroyerBytes{}.Destroy(r.FallbackRecords)
+	FfiDestroyerDelegateState{}.Destroy(r.Delegate)
+	FfiDestroyerCommitmentState{}.Destroy(r.Commitment)
+}
+
+type FfiDestroyerZone struct{}
+
+func (_ FfiDestroyerZone) Destroy(value Zone) {
+	value.Destroy()
+}
+
+type CommitmentState interface {
+	Destroy()
+}
+
+type CommitmentStateExists struct {
+	StateRoot   []byte
+	PrevRoot    *[]byte
+	RollingHash []byte
+	BlockHeight uint32
+	ReceiptHash *[]byte
+}
+
+func (e CommitmentStateExists) Destroy() {
+	FfiDestroyerBytes{}.Destroy(e.StateRoot)
+	FfiDestroyerOptionalBytes{}.Destroy(e.PrevRoot)
+	FfiDestroyerBytes{}.Destroy(e.RollingHash)
+	FfiDestroyerUint32{}.Destroy(e.BlockHeight)
+	FfiDestroyerOptionalBytes{}.Destroy(e.ReceiptHash)
+}
+
+type CommitmentStateEmpty struct {
+}
+
+func (e CommitmentStateEmpty) Destroy() {
+}
+
+type CommitmentStateUnknown struct {
+}
+
+func (e CommitmentStateUnknown) Destroy() {
+}
+
+type FfiDestroyerCommitmentState struct{}
+
+func (_ FfiDestroyerCommitmentState) Destroy(value CommitmentState) {
+	value.Destroy()
+}
+
+type DelegateState interface {
+	Destroy()
+}
+
+type DelegateStateExists struct {
+	ScriptPubkey    []byte
+	FallbackRecords []byte
+	Records         []byte
+}
+
+func (e DelegateStateExists) Destroy() {
+	FfiDestroyerBytes{}.Destroy(e.ScriptPubkey)
+	FfiDestroyerBytes{}.Destroy(e.FallbackRecords)
+	FfiDestroyerBytes{}.Destroy(e.Records)
+}
+
+type DelegateStateEmpty struct {
+}
+
+func (e DelegateStateEmpty) Destroy() {
+}
+
+type DelegateStateUnknown struct {
+}
+
+func (e DelegateStateUnknown) Destroy() {
+}
+
+type FfiDestroyerDelegateState struct{}
+
+func (_ FfiDestroyerDelegateState) Destroy(value DelegateState) {
+	value.Destroy()
+}
+
+// A parsed SIP-7 record (from unpacking). Includes `Malformed` for invalid rdata.
+type ParsedRecord interface {
+	Destroy()
+}
+
+type ParsedRecordSeq struct {
+	Version uint64
+}
+
+func (e ParsedRecordSeq) Destroy() {
+	FfiDestroyerUint64{}.Destroy(e.Version)
+}
+
+type ParsedRecordTxt struct {
+	Key   string
+	Value []string
+}
+
+func (e ParsedRecordTxt) Destroy() {
+	FfiDestroyerString{}.Destroy(e.Key)
+	FfiDestroyerSequenceString{}.Destroy(e.Value)
+}
+
+type ParsedRecordAddr struct {
+	Key   string
+	Value []string
+}
+
+func (e ParsedRecordAddr) Destroy() {
+	FfiDestroyerString{}.Destroy(e.Key)
+	FfiDestroyerSequenceString{}.Destroy(e.Value)
+}
+
+type ParsedRecordBlob struct {
+	Key   string
+	Value []byte
+}
+
+func (e ParsedRecordBlob) Destroy() {
+	FfiDestroyerString{}.Destroy(e.Key)
+	FfiDestroyerBytes{}.Destroy(e.Value)
+}
+
+type ParsedRecordSig struct {
+	Flags     uint8
+	Canonical string
+	Handle    string
+	Sig       []byte
+}
+
+func (e ParsedRecordSig) Destroy() {
+	FfiDestroyerUint8{}.Destroy(e.Flags)
+	FfiDestroyerString{}.Destroy(e.Canonical)
+	FfiDestroyerString{}.Destroy(e.Handle)
+	FfiDestroyerBytes{}.Destroy(e.Sig)
+}
+
+type ParsedRecordMalformed struct {
+	Rtype uint8
+	Rdata []byte
+}
+
+func (e ParsedRecordMalformed) Destroy() {
+	FfiDestroyerUint8{}.Destroy(e.Rtype)
+	FfiDestroyerBytes{}.Destroy(e.Rdata)
+}
+
+type ParsedRecordUnknown struct {
+	Rtype uint8
+	Rdata []byte
+}
+
+func (e ParsedRecordUnknown) Destroy() {
+	FfiDestroyerUint8{}.Destroy(e.Rtype)
+	FfiDestroyerBytes{}.Destroy(e.Rdata)
+}
+
+type FfiDestroyerParsedRecord struct{}
+
+func (_ FfiDestroyerParsedRecord) Destroy(value ParsedRecord) {
+	value.Destroy()
+}
+
+// A single SIP-7 record (for constructing/packing).
+type Record interface {
+	Destroy()
+}
+
+type RecordSeq struct {
+	Version uint64
+}
+
+func (e RecordSeq) Destroy() {
+	FfiDestroyerUint64{}.Destroy(e.Version)
+}
+
+type RecordTxt struct {
+	Key   string
+	Value []string
+}
+
+func (e RecordTxt) Destroy() {
+	FfiDestroyerString{}.Destroy(e.Key)
+	FfiDestroyerSequenceString{}.Destroy(e.Value)
+}
+
+type RecordAddr struct {
+	Key   string
+	Value []string
+}
+
+func (e RecordAddr) Destroy() {
+	FfiDestroyerString{}.Destroy(e.Key)
+	FfiDestroyerSequenceString{}.Destroy(e.Value)
+}
+
+type RecordBlob struct {
+	Key   string
+	Value []byte
+}
+
+func (e RecordBlob) Destroy() {
+	FfiDestroyerString{}.Destroy(e.Key)
+	FfiDestroyerBytes{}.Destroy(e.Value)
+}
+
+type RecordSig struct {
+	Flags     uint8
+	Canonical string
+	Handle    string
+	Sig       []byte
+}
+
+func (e RecordSig) Destroy() {
+	FfiDestroyerUint8{}.Destroy(e.Flags)
+	FfiDestroyerString{}.Destroy(e.Canonical)
+	FfiDestroyerString{}.Destroy(e.Handle)
+	FfiDestroyerBytes{}.Destroy(e.Sig)
+}
+
+type RecordUnknown struct {
+	Rtype uint8
+	Rdata []byte
+}
+
+func (e RecordUnknown) Destroy() {
+	FfiDestroyerUint8{}.Destroy(e.Rtype)
+	FfiDestroyerBytes{}.Destroy(e.Rdata)
+}
+
+type FfiDestroyerRecord struct{}
+
+func (_ FfiDestroyerRecord) Destroy(value Record) {
+	value.Destroy()
+}
+
+type VeritasError struct {
+	err error
+	// Set for errors raised in Go; see Details.
+	details *ErrorDetails
+}
+
+// Convience method to turn *VeritasError into error
+// Avoiding treating nil pointer as non nil error interface
+func (err *VeritasError) AsError() error {
+	if err == nil {
+		return nil
+	} else {
+		return err
+	}
+}
+
+func (err VeritasError) Error() string {
+	return fmt.Sprintf("VeritasError: %s", err.err.Error())
+}
+
+func (err VeritasError) Unwrap() error {
+	return err.err
+}
+
+// Err* are used for checking error type with `errors.Is`
+var ErrVeritasErrorInvalidInput = fmt.Errorf("VeritasErrorInvalidInput")
+
+var ErrVeritasErrorVerificationFailed = fmt.Errorf("VeritasErrorVerificationFailed")
+
+// Variant structs
+type VeritasErrorInvalidInput struct {
+	Msg string
+}
+
+func NewVeritasErrorInvalidInput(
+	msg string,
+) *VeritasError {
+	return &VeritasError{err: &VeritasErrorInvalidInput{
+		Msg: msg}}
+}
+
+func (e VeritasErrorInvalidInput) destroy() {
+	FfiDestroyerString{}.Destroy(e.Msg)
+}
+
+func (err VeritasErrorInvalidInput) Error() string {
+	return fmt.Sprint("InvalidInput",
+		": ",
+
+		"Msg=",
+		err.Msg,
+	)
+}
+
+func (self VeritasErrorInvalidInput) Is(target error) bool {
+	return target == ErrVeritasErrorInvalidInput
+}
+
+type VeritasErrorVerificationFailed struct {
+	Msg string
+}
+
+func NewVeritasErrorVerificationFailed(
+	msg string,
+) *VeritasError {
+	return &VeritasError{err: &VeritasErrorVerificationFailed{
+		Msg: msg}}
+}
+
+func (e VeritasErrorVerificationFailed) destroy() {
+	FfiDestroyerString{}.Destroy(e.Msg)
+}
+
+func (err VeritasErrorVerificationFailed) Error() string {
+	return fmt.Sprint("VerificationFailed",
+		": ",
+
+		"Msg=",
+		err.Msg,
+	)
+}
+
+func (self VeritasErrorVerificationFailed) Is(target error) bool {
+	return target == ErrVeritasErrorVerificationFailed
+}
+
+type FfiDestroyerVeritasError struct{}
+
+func (_ FfiDestroyerVeritasError) Destroy(value *VeritasError) {
+	switch variantValue := value.err.(type) {
+	case *VeritasErrorInvalidInput:
+		variantValue.destroy()
+	case *VeritasErrorVerificationFailed:
+		variantValue.destroy()
+	case *InternalError:
+	default:
+		_ = variantValue
+		panic(fmt.Sprintf("invalid error value `%v` in FfiDestroyerVeritasError.Destroy", value))
+	}
+}
+
+type FfiDestroyerOptionalString struct{}
+
+func (_ FfiDestroyerOptionalString) Destroy(value *string) {
+	if value != nil {
+		FfiDestroyerString{}.Destroy(*value)
+	}
+}
+
+type FfiDestroyerOptionalBytes struct{}
+
+func (_ FfiDestroyerOptionalBytes) Destroy(value *[]byte) {
+	if value != nil {
+		FfiDestroyerBytes{}.Destroy(*value)
+	}
+}
+
+type FfiDestroyerSequenceString struct{}
+
+func (FfiDestroyerSequenceString) Destroy(sequence []string) {
+	for _, value := range sequence {
+		FfiDestroyerString{}.Destroy(value)
+	}
+}
+
+type FfiDestroyerSequenceBytes struct{}
+
+func (FfiDestroyerSequenceBytes) Destroy(sequence [][]byte) {
+	for _, value := range sequence {
+		FfiDestroyerBytes{}.Destroy(value)
+	}
+}
+
+type FfiDestroyerSequenceUnsignedRecordSet struct{}
+
+func (FfiDestroyerSequenceUnsignedRecordSet) Destroy(sequence []*UnsignedRecordSet) {
+	for _, value := range sequence {
+		FfiDestroyerUnsignedRecordSet{}.Destroy(value)
+	}
+}
+
+type FfiDestroyerSequenceDataUpdateEntry struct{}
+
+func (FfiDestroyerSequenceDataUpdateEntry) Destroy(sequence []DataUpdateEntry) {
+	for _, value := range sequence {
+		FfiDestroyerDataUpdateEntry{}.Destroy(value)
+	}
+}
+
+type FfiDestroyerSequenceZone struct{}
+
+func (FfiDestroyerSequenceZone) Destroy(sequence []Zone) {
+	for _, value := range sequence {
+		FfiDestroyerZone{}.Destroy(value)
+	}
+}
+
+type FfiDestroyerSequenceParsedRecord struct{}
+
+func (FfiDestroyerSequenceParsedRecord) Destroy(sequence []ParsedRecord) {
+	for _, value := range sequence {
+		FfiDestroyerParsedRecord{}.Destroy(value)
+	}
+}
+
+type FfiDestroyerSequenceRecord struct{}
+
+func (FfiDestroyerSequenceRecord) Destroy(sequence []Record) {
+	for _, value := range sequence {
+		FfiDestroyerRecord{}.Destroy(value)
+	}
+}
@@ -0,0 +1,45 @@
+// Package libveritastest provides test helpers for code that uses
+// libveritas.
+package libveritastest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	libveritas "github.com/spacesprotocol/libveritas-go"
+)
+
+// LeakCheck enables object tracking for the rest of the test and fails the
+// test if native objects created during it are still alive when it ends,
+// reporting where each was created. Objects that were created before the
+// call are ignored. Tests running in parallel may see each other's
+// objects, so use it in tests that do not call t.Parallel.
+func LeakCheck(t testing.TB) {
+	t.Helper()
+	previous := libveritas.SetObjectTracking(true)
+	// IDs increase, so objects created from now on have higher IDs than any
+	// live object. Objects freed already are not reported again.
+	var start uint64
+	for _, object := range libveritas.LiveObjects() {
+		start = object.ID
+	}
+	t.Cleanup(func() {
+		defer libveritas.SetObjectTracking(previous)
+		var leaked []libveritas.LiveObject
+		for _, object := range libveritas.LiveObjects() {
+			if object.ID > start {
+				leaked = append(leaked, object)
+			}
+		}
+		if len(leaked) == 0 {
+			return
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "libveritas: %d native object(s) not freed:", len(leaked))
+		for _, object := range leaked {
+			fmt.Fprintf(&b, "\n\n%s created at:\n%s", object.Type, object.Stack)
+		}
+		t.Error(b.String())
+	})
+}
@@ -0,0 +1,52 @@
+package libveritastest
+
+import (
+	"strings"
+	"testing"
+
+	libveritas "github.com/spacesprotocol/libveritas-go"
+)
+
+// recordingTB records what LeakCheck reports instead of failing the test.
+type recordingTB struct {
+	testing.TB
+	cleanups []func()
+	errors   []string
+}
+
+func (tb *recordingTB) Helper()                {}
+func (tb *recordingTB) Cleanup(cleanup func()) { tb.cleanups = append(tb.cleanups, cleanup) }
+func (tb *recordingTB) Error(args ...any)      { tb.errors = append(tb.errors, args[0].(string)) }
+
+func (tb *recordingTB) finish() {
+	for i := len(tb.cleanups) - 1; i >= 0; i-- {
+		tb.cleanups[i]()
+	}
+}
+
+func TestLeakCheck(t *testing.T) {
+	if !libveritas.NativeAvailable() {
+		t.Skip("native library not available")
+	}
+	tb := &recordingTB{TB: t}
+	LeakCheck(tb)
+	freed := libveritas.NewQueryContext()
+	freed.Destroy()
+	leaked := libveritas.NewQueryContext()
+	defer leaked.Destroy()
+	tb.finish()
+
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "1 native object(s) not freed") ||
+		!strings.Contains(tb.errors[0], "QueryContext created at") {
+		t.Errorf("LeakCheck reported %q", tb.errors)
+	}
+}
+
+func TestLeakCheckPasses(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	LeakCheck(tb)
+	tb.finish()
+	if len(tb.errors) != 0 {
+		t.Errorf("LeakCheck reported %q", tb.errors)
+	}
+}
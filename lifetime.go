@@ -0,0 +1,248 @@
+package libveritas
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Deterministic lifetimes.
+//
+// Native objects are freed by a finalizer when they become unreachable, but
+// that can happen late or never. Close frees them right away. Close is
+// idempotent, and calls still running on the object finish first.
+//
+// Using an object after Close surfaces in two ways. Calling a method on it
+// panics. Passing it as an argument, as with the QueryContext and Message of
+// Veritas.Verify, returns an error with code CodeInvalidInput instead, since
+// the argument may belong to, and be closed by, another part of the program.
+
+var (
+	_ io.Closer = (*Anchors)(nil)
+	_ io.Closer = (*Lookup)(nil)
+	_ io.Closer = (*Message)(nil)
+	_ io.Closer = (*MessageBuilder)(nil)
+	_ io.Closer = (*QueryContext)(nil)
+	_ io.Closer = (*RecordSet)(nil)
+	_ io.Closer = (*UnsignedRecordSet)(nil)
+	_ io.Closer = (*VerifiedMessage)(nil)
+	_ io.Closer = (*Veritas)(nil)
+	_ io.Closer = (*BuildResult)(nil)
+	_ io.Closer = (*SandboxedVeritas)(nil)
+	_ io.Closer = (*BytesView)(nil)
+	_ io.Closer = (*AtomicVeritas)(nil)
+	_ io.Closer = (*VeritasManager)(nil)
+)
+
+// Close frees the native object. It is equivalent to Destroy.
+func (object *Anchors) Close() error {
+	if object != nil {
+		object.Destroy()
+	}
+	return nil
+}
+
+// Close frees the native object. It is equivalent to Destroy.
+func (object *Lookup) Close() error {
+	if object != nil {
+		object.Destroy()
+	}
+	return nil
+}
+
+// Close frees the native object. It is equivalent to Destroy.
+func (object *Message) Close() error {
+	if object != nil {
+		object.Destroy()
+	}
+	return nil
+}
+
+// Close frees the native object. It is equivalent to Destroy.
+func (object *MessageBuilder) Close() error {
+	if object != nil {
+		object.Destroy()
+	}
+	return nil
+}
+
+// Close frees the native object. It is equivalent to Destroy.
+func (object *QueryContext) Close() error {
+	if object != nil {
+		object.Destroy()
+	}
+	return nil
+}
+
+// Close frees the native object. It is equivalent to Destroy.
+func (object *RecordSet) Close() error {
+	if object != nil {
+		object.Destroy()
+	}
+	return nil
+}
+
+// Close frees the native object. It is equivalent to Destroy.
+func (object *UnsignedRecordSet) Close() error {
+	if object != nil {
+		object.Destroy()
+	}
+	return nil
+}
+
+// Close frees the native object. It is equivalent to Destroy.
+func (object *VerifiedMessage) Close() error {
+	if object != nil {
+		object.Destroy()
+	}
+	return nil
+}
+
+// Close frees the native object. It is equivalent to Destroy.
+func (object *Veritas) Close() error {
+	if object != nil {
+		object.Destroy()
+	}
+	return nil
+}
+
+// Close frees the message and the unsigned record sets of the result. It is
+// equivalent to Destroy.
+func (r *BuildResult) Close() error {
+	if r != nil {
+		r.Destroy()
+	}
+	return nil
+}
+
+// Close stops the worker. It is equivalent to Destroy.
+func (_self *SandboxedVeritas) Close() error {
+	if _self != nil {
+		_self.Destroy()
+	}
+	return nil
+}
+
+// Close is equivalent to Release.
+func (v *BytesView) Close() error {
+	if v != nil {
+		v.Release()
+	}
+	return nil
+}
+
+// Object tracking.
+//
+// With tracking enabled, every native object records the stack that created
+// it until it is freed. It is meant for tests and debugging: capturing a
+// stack per object is not free. Set LIBVERITAS_TRACK_OBJECTS=1 to enable it
+// from the start of the process. Package libveritastest builds a leak check
+// for tests on it.
+
+// LiveObject describes a tracked native object that was not freed yet.
+type LiveObject struct {
+	// Sequence number of the object; later objects have higher ones.
+	ID uint64
+	// Type name, such as "Veritas".
+	Type string
+	// Stack of the goroutine that created the object.
+	Stack string
+}
+
+type trackedObject struct {
+	typeName string
+	pcs      []uintptr
+}
+
+var (
+	objectTracking atomic.Bool
+	trackedMu      sync.Mutex
+	// Keyed by sequence number rather than by object, so that tracking does
+	// not keep objects reachable and their finalizers still run.
+	trackedObjects = map[uint64]trackedObject{}
+	trackedSeq     uint64
+	// Size of trackedObjects, so untrackObject can skip the lock.
+	trackedCount atomic.Int64
+)
+
+func init() {
+	if os.Getenv("LIBVERITAS_TRACK_OBJECTS") != "" {
+		objectTracking.Store(true)
+	}
+}
+
+// SetObjectTracking turns tracking of live native objects on or off and
+// returns the previous setting. Objects created while tracking is off are
+// never reported.
+func SetObjectTracking(enabled bool) bool {
+	return objectTracking.Swap(enabled)
+}
+
+// LiveObjects returns the tracked objects that were not freed yet, oldest
+// first.
+func LiveObjects() []LiveObject {
+	trackedMu.Lock()
+	live := make([]LiveObject, 0, len(trackedObjects))
+	for id, object := range trackedObjects {
+		live = append(live, LiveObject{ID: id, Type: object.typeName, Stack: formatStack(object.pcs)})
+	}
+	trackedMu.Unlock()
+	sort.Slice(live, func(i, j int) bool { return live[i].ID < live[j].ID })
+	return live
+}
+
+// LiveObjectCounts returns the number of tracked live objects per type.
+func LiveObjectCounts() map[string]int {
+	trackedMu.Lock()
+	defer trackedMu.Unlock()
+	counts := make(map[string]int)
+	for _, object := range trackedObjects {
+		counts[object.typeName]++
+	}
+	return counts
+}
+
+// trackObject records a new native object if tracking is enabled, and
+// returns the ID that identifies it until untrackObject, or 0.
+func trackObject(typeName string) uint64 {
+	if !objectTracking.Load() {
+		return 0
+	}
+	pcs := make([]uintptr, 32)
+	// Skip runtime.Callers, trackObject and the converter's Lift.
+	pcs = pcs[:runtime.Callers(3, pcs)]
+	trackedMu.Lock()
+	defer trackedMu.Unlock()
+	trackedSeq++
+	trackedObjects[trackedSeq] = trackedObject{typeName: typeName, pcs: pcs}
+	trackedCount.Store(int64(len(trackedObjects)))
+	return trackedSeq
+}
+
+func untrackObject(id uint64) {
+	if id == 0 || trackedCount.Load() == 0 {
+		return
+	}
+	trackedMu.Lock()
+	defer trackedMu.Unlock()
+	delete(trackedObjects, id)
+	trackedCount.Store(int64(len(trackedObjects)))
+}
+
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package libveritas
+
+import "testing"
+
+func TestObjectTracking(t *testing.T) {
+	previous := SetObjectTracking(true)
+	defer SetObjectTracking(previous)
+
+	first := trackObject("Veritas")
+	second := trackObject("Message")
+	if first == 0 || second <= first {
+		t.Fatalf("trackObject IDs = %d, %d", first, second)
+	}
+	defer untrackObject(second)
+
+	live := liveSince(first - 1)
+	if len(live) != 2 || live[0].ID != first || live[0].Type != "Veritas" || live[1].Type != "Message" {
+		t.Fatalf("LiveObjects = %+v", live)
+	}
+	if live[0].Stack == "" {
+		t.Error("LiveObject has no stack")
+	}
+	if counts := LiveObjectCounts(); counts["Veritas"] < 1 || counts["Message"] < 1 {
+		t.Errorf("LiveObjectCounts = %v", counts)
+	}
+
+	untrackObject(first)
+	if live := liveSince(first - 1); len(live) != 1 || live[0].ID != second {
+		t.Errorf("LiveObjects after untrack = %+v", live)
+	}
+}
+
+func TestObjectTrackingDisabled(t *testing.T) {
+	previous := SetObjectTracking(false)
+	defer SetObjectTracking(previous)
+	if id := trackObject("Veritas"); id != 0 {
+		t.Errorf("trackObject with tracking off = %d, want 0", id)
+	}
+	untrackObject(0)
+}
+
+func TestCloseNil(t *testing.T) {
+	closers := map[string]interface{ Close() error }{
+		"*BuildResult": (*BuildResult)(nil),
+		"*BytesView":   (*BytesView)(nil),
+		"*Message":     (*Message)(nil),
+		"*Veritas":     (*Veritas)(nil),
+	}
+	for name, closer := range closers {
+		if err := closer.Close(); err != nil {
+			t.Errorf("%s.Close = %v", name, err)
+		}
+	}
+}
+
+func liveSince(id uint64) []LiveObject {
+	var live []LiveObject
+	for _, object := range LiveObjects() {
+		if object.ID > id {
+			live = append(live, object)
+		}
+	}
+	return live
+}
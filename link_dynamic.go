@@ -0,0 +1,19 @@
+//go:build cgo && !libveritas_nocgo && libveritas_dynamic && !libveritas_pkgconfig
+
+package libveritas
+
+// libveritas_dynamic: link against a system-installed shared library
+// (liblibveritas_uniffi.so, .dylib or .dll). Point the linker and loader at
+// it through the environment, e.g.
+//
+//	CGO_LDFLAGS="-L/opt/libveritas/lib -Wl,-rpath,/opt/libveritas/lib" go build -tags libveritas_dynamic
+//
+// To check such a build, run the tests with the same settings, where
+// TestInitIsStable fails if the library does not match these bindings, and
+// look at the library the test binary loads (otool -L on darwin):
+//
+//	go test -tags libveritas_dynamic -run TestInit .
+//	go test -c -tags libveritas_dynamic -o libveritas.test . && ldd libveritas.test | grep libveritas_uniffi
+
+// #cgo LDFLAGS: -llibveritas_uniffi
+import "C"
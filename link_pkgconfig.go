@@ -0,0 +1,15 @@
+//go:build cgo && !libveritas_nocgo && libveritas_pkgconfig
+
+package libveritas
+
+// libveritas_pkgconfig: link against a system-installed shared library
+// located through the libveritas_uniffi pkg-config module. Set
+// PKG_CONFIG_PATH when the .pc file is installed outside the default search
+// path. pkgconfig/libveritas_uniffi.pc.in is a template for packagers, who
+// fill in the install prefix and the library version, e.g.
+//
+//	sed -e 's|@PREFIX@|/usr/local|' -e 's|@VERSION@|0.1.0|' \
+//		pkgconfig/libveritas_uniffi.pc.in > /usr/local/lib/pkgconfig/libveritas_uniffi.pc
+
+// #cgo pkg-config: libveritas_uniffi
+import "C"
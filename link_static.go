@@ -0,0 +1,10 @@
+//go:build cgo && !libveritas_nocgo && !libveritas_dynamic && !libveritas_pkgconfig && ((linux && amd64) || (darwin && arm64) || (windows && amd64))
+
+package libveritas
+
+// Default mode: link the prebuilt static archive shipped under native/.
+
+// #cgo linux,amd64 LDFLAGS: ${SRCDIR}/native/linux-amd64/liblibveritas_uniffi.a -lm -ldl -lpthread
+// #cgo darwin,arm64 LDFLAGS: ${SRCDIR}/native/darwin-arm64/liblibveritas_uniffi.a -framework Security -framework CoreFoundation -lm
+// #cgo windows,amd64 LDFLAGS: ${SRCDIR}/native/windows-amd64/libveritas_uniffi.lib -lws2_32 -lbcrypt -luserenv -lntdll
+import "C"
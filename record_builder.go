@@ -0,0 +1,203 @@
+package libveritas
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// DefaultMaxRecordSetSize is the default MaxSize of a RecordSetBuilder.
+// There is no protocol maximum to report sizes against: the SIP-7 encoding
+// does not bound the size of a record set, so this is a limit chosen by this
+// package to keep record sets small.
+const DefaultMaxRecordSetSize = 4096
+
+// RecordSetBuilder assembles a record set and checks it before packing:
+// exactly one Seq record, no duplicate keys per record type, no empty keys or
+// values, and an encoded size within MaxSize. Problems are collected as
+// records are added and reported together by Err, Pack and Build.
+//
+//	data, err := libveritas.NewRecordSetBuilder().
+//		Seq(1).
+//		Txt("website", "https://example.com").
+//		Addr("btc", "bc1q...").
+//		Pack()
+type RecordSetBuilder struct {
+	// MaxSize is the largest encoded size of the record set, in bytes.
+	// Zero disables the check.
+	MaxSize int
+
+	seq      *RecordSeq
+	records  []Record
+	keys     map[recordKey]bool
+	size     int
+	problems []string
+}
+
+type recordKey struct {
+	rtype uint8
+	key   string
+}
+
+// NewRecordSetBuilder returns an empty builder, with MaxSize set to
+// DefaultMaxRecordSetSize.
+func NewRecordSetBuilder() *RecordSetBuilder {
+	return &RecordSetBuilder{MaxSize: DefaultMaxRecordSetSize, keys: make(map[recordKey]bool)}
+}
+
+// Seq sets the version of the record set. It must be called exactly once.
+func (b *RecordSetBuilder) Seq(version uint64) *RecordSetBuilder {
+	if b.seq != nil {
+		b.problemf("seq: set more than once")
+		return b
+	}
+	record := RecordSeq{Version: version}
+	if b.add(record, "seq", nil) {
+		b.seq = &record
+	}
+	return b
+}
+
+// Txt adds a text record with one or more values.
+func (b *RecordSetBuilder) Txt(key string, values ...string) *RecordSetBuilder {
+	if b.checkValues("txt", RecordTypeTxt, key, values) {
+		b.add(RecordTxt{Key: key, Value: slices.Clone(values)}, "txt "+key, &recordKey{RecordTypeTxt, key})
+	}
+	return b
+}
+
+// Addr adds an address record with one or more values.
+func (b *RecordSetBuilder) Addr(key string, values ...string) *RecordSetBuilder {
+	if b.checkValues("addr", RecordTypeAddr, key, values) {
+		b.add(RecordAddr{Key: key, Value: slices.Clone(values)}, "addr "+key, &recordKey{RecordTypeAddr, key})
+	}
+	return b
+}
+
+// Blob adds a binary record. data is copied.
+func (b *RecordSetBuilder) Blob(key string, data []byte) *RecordSetBuilder {
+	if !b.checkKey("blob", RecordTypeBlob, key) {
+		return b
+	}
+	switch {
+	case len(data) == 0:
+		b.problemf("blob %s: empty value", key)
+		return b
+	case b.MaxSize > 0 && len(data) > b.MaxSize:
+		b.problemf("blob %s: %d bytes, at most %d allowed in a record set", key, len(data), b.MaxSize)
+		return b
+	}
+	b.add(RecordBlob{Key: key, Value: bytes.Clone(data)}, "blob "+key, &recordKey{RecordTypeBlob, key})
+	return b
+}
+
+func (b *RecordSetBuilder) checkValues(kind string, rtype uint8, key string, values []string) bool {
+	if !b.checkKey(kind, rtype, key) {
+		return false
+	}
+	if len(values) == 0 {
+		b.problemf("%s %s: no values", kind, key)
+		return false
+	}
+	for i, value := range values {
+		if value == "" {
+			b.problemf("%s %s: value %d is empty", kind, key, i)
+			return false
+		}
+	}
+	return true
+}
+
+func (b *RecordSetBuilder) checkKey(kind string, rtype uint8, key string) bool {
+	if key == "" {
+		b.problemf("%s: empty key", kind)
+		return false
+	}
+	if b.keys[recordKey{rtype, key}] {
+		b.problemf("%s %s: duplicate key", kind, key)
+		return false
+	}
+	return true
+}
+
+// add encodes record to account for its size and to catch the encoding
+// errors of PackRecords early. The key of an accepted record is reserved,
+// so that a rejected record can be added again once corrected.
+func (b *RecordSetBuilder) add(record Record, name string, key *recordKey) bool {
+	_, rdata, err := sip7EncodeRdata(record)
+	if err != nil {
+		b.problemf("%s: %s", name, err)
+		return false
+	}
+	if key != nil {
+		b.keys[*key] = true
+	}
+	b.size += 1 + sip7CompactSizeLen(uint64(len(rdata))) + len(rdata)
+	if _, ok := record.(RecordSeq); !ok {
+		b.records = append(b.records, record)
+	}
+	return true
+}
+
+func (b *RecordSetBuilder) problemf(format string, args ...any) {
+	b.problems = append(b.problems, fmt.Sprintf(format, args...))
+}
+
+// Size returns the exact encoded size of the records added so far.
+func (b *RecordSetBuilder) Size() int {
+	return b.size
+}
+
+// Remaining returns how many bytes can still be added before the record
+// set exceeds MaxSize. It is negative once the limit is exceeded, and
+// math.MaxInt without a limit.
+func (b *RecordSetBuilder) Remaining() int {
+	if b.MaxSize <= 0 {
+		return math.MaxInt
+	}
+	return b.MaxSize - b.size
+}
+
+// Records returns the records added so far, the Seq record first.
+func (b *RecordSetBuilder) Records() []Record {
+	records := make([]Record, 0, len(b.records)+1)
+	if b.seq != nil {
+		records = append(records, *b.seq)
+	}
+	return append(records, b.records...)
+}
+
+// Err reports every problem found so far, including a missing Seq record
+// and an exceeded size limit. The error has code CodeMalformedRecords.
+func (b *RecordSetBuilder) Err() error {
+	problems := b.problems
+	if b.seq == nil {
+		problems = append(problems[:len(problems):len(problems)], "seq: missing")
+	}
+	if b.MaxSize > 0 && b.size > b.MaxSize {
+		problems = append(problems[:len(problems):len(problems)],
+			fmt.Sprintf("record set is %d bytes, at most %d allowed", b.size, b.MaxSize))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return newInvalidInputError(CodeMalformedRecords, "invalid record set: "+strings.Join(problems, "; "))
+}
+
+// Pack validates the records and encodes them into SIP-7 wire bytes.
+func (b *RecordSetBuilder) Pack() ([]byte, error) {
+	if err := b.Err(); err != nil {
+		return nil, err
+	}
+	return PackRecords(b.Records())
+}
+
+// Build validates the records and packs them with RecordSetPack.
+func (b *RecordSetBuilder) Build() (*RecordSet, error) {
+	if err := b.Err(); err != nil {
+		return nil, err
+	}
+	return RecordSetPack(b.Records())
+}
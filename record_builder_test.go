@@ -0,0 +1,102 @@
+package libveritas
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRecordSetBuilderPack(t *testing.T) {
+	b := NewRecordSetBuilder().Seq(1).Txt("name", "alice").Addr("btc", "bc1q").Blob("avatar", []byte{1, 2})
+	data, err := b.Pack()
+	if err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+	if len(data) != b.Size() {
+		t.Errorf("Size = %d, packed %d bytes", b.Size(), len(data))
+	}
+	want, err := PackRecords([]Record{
+		RecordSeq{Version: 1},
+		RecordTxt{Key: "name", Value: []string{"alice"}},
+		RecordAddr{Key: "btc", Value: []string{"bc1q"}},
+		RecordBlob{Key: "avatar", Value: []byte{1, 2}},
+	})
+	if err != nil || string(data) != string(want) {
+		t.Errorf("Pack = %x, want %x (%v)", data, want, err)
+	}
+}
+
+func TestRecordSetBuilderProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *RecordSetBuilder
+		problem string
+	}{
+		{"missing seq", NewRecordSetBuilder().Txt("a", "b"), "seq: missing"},
+		{"seq twice", NewRecordSetBuilder().Seq(1).Seq(2), "seq: set more than once"},
+		{"duplicate key", NewRecordSetBuilder().Seq(1).Txt("a", "b").Txt("a", "c"), "txt a: duplicate key"},
+		{"empty key", NewRecordSetBuilder().Seq(1).Addr("", "x"), "addr: empty key"},
+		{"no values", NewRecordSetBuilder().Seq(1).Txt("a"), "txt a: no values"},
+		{"empty value", NewRecordSetBuilder().Seq(1).Txt("a", "b", ""), "txt a: value 1 is empty"},
+		{"empty blob", NewRecordSetBuilder().Seq(1).Blob("a", nil), "blob a: empty value"},
+		{"large blob", NewRecordSetBuilder().Seq(1).Blob("a", make([]byte, DefaultMaxRecordSetSize+1)), "at most 4096 allowed"},
+		{"large set", NewRecordSetBuilder().Seq(1).Blob("a", make([]byte, 3000)).Blob("b", make([]byte, 3000)), "record set is"},
+	}
+	for _, tt := range tests {
+		err := tt.builder.Err()
+		if !errors.Is(err, ErrMalformedRecords) || !strings.Contains(err.Error(), tt.problem) {
+			t.Errorf("%s: Err = %v, want %q", tt.name, err, tt.problem)
+		}
+		if _, err := tt.builder.Pack(); err == nil {
+			t.Errorf("%s: Pack succeeded", tt.name)
+		}
+	}
+}
+
+func TestRecordSetBuilderMaxSize(t *testing.T) {
+	b := NewRecordSetBuilder()
+	b.MaxSize = 16
+	b.Seq(1).Txt("a", "b")
+	if remaining := b.Remaining(); remaining != 16-b.Size() {
+		t.Errorf("Remaining = %d with size %d", remaining, b.Size())
+	}
+	if b.Blob("c", make([]byte, 20)).Err() == nil {
+		t.Error("blob over MaxSize accepted")
+	}
+
+	unlimited := NewRecordSetBuilder()
+	unlimited.MaxSize = 0
+	unlimited.Seq(1).Blob("a", make([]byte, 3*DefaultMaxRecordSetSize))
+	if err := unlimited.Err(); err != nil {
+		t.Errorf("Err without a limit: %v", err)
+	}
+	if unlimited.Remaining() <= 0 {
+		t.Errorf("Remaining without a limit = %d", unlimited.Remaining())
+	}
+}
+
+func TestRecordSetBuilderCopiesInput(t *testing.T) {
+	blob := []byte{1, 2, 3}
+	values := []string{"alice"}
+	b := NewRecordSetBuilder().Seq(1).Blob("avatar", blob).Txt("name", values...)
+	blob[0] = 9
+	values[0] = "mallory"
+	records := b.Records()
+	if got := records[1].(RecordBlob).Value; got[0] != 1 {
+		t.Errorf("blob changed with the caller's slice: %x", got)
+	}
+	if got := records[2].(RecordTxt).Value; got[0] != "alice" {
+		t.Errorf("txt changed with the caller's slice: %q", got)
+	}
+}
+
+func TestRecordSetBuilderRejectedKeyReusable(t *testing.T) {
+	b := NewRecordSetBuilder().Seq(1).Txt("k").Txt("k", "v").Blob("b", nil).Blob("b", []byte{1})
+	err := b.Err()
+	if err == nil || strings.Contains(err.Error(), "duplicate key") {
+		t.Errorf("Err = %v, want only the rejected records reported", err)
+	}
+	if records := b.Records(); len(records) != 3 {
+		t.Errorf("Records = %+v, want seq, txt k and blob b", records)
+	}
+}
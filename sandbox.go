@@ -0,0 +1,641 @@
+package libveritas
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Out-of-process verification.
+//
+// A SandboxedVeritas runs Veritas.Verify in a worker process, so that an
+// abort, a hang or runaway memory use inside the native verifier only takes
+// down the worker. The worker is restarted on the next call.
+//
+// The parent and the worker exchange frames over the worker's stdin and
+// stdout. Each frame is a u32 big-endian length followed by a JSON payload.
+// The first request initializes the worker with the anchors; every later
+// request is a verification and is answered with exactly one response.
+
+var (
+	// ErrSandboxTimeout is returned when a sandboxed call does not finish
+	// within SandboxConfig.Timeout. The worker is killed.
+	ErrSandboxTimeout = fmt.Errorf("libveritas: sandbox call timed out")
+	// ErrSandboxCrashed is returned when the worker exits or breaks the
+	// protocol during a call.
+	ErrSandboxCrashed = fmt.Errorf("libveritas: sandbox worker crashed")
+	// ErrSandboxClosed is returned by calls on a destroyed SandboxedVeritas.
+	ErrSandboxClosed = fmt.Errorf("libveritas: sandbox is closed")
+)
+
+const (
+	// DefaultSandboxTimeout is used when SandboxConfig.Timeout is zero.
+	DefaultSandboxTimeout = 30 * time.Second
+
+	sandboxWorkerEnv = "LIBVERITAS_SANDBOX_WORKER"
+
+	// Upper bound for a single frame, in either direction.
+	sandboxMaxFrame = 64 << 20
+)
+
+// SandboxConfig configures the worker process of a SandboxedVeritas.
+type SandboxConfig struct {
+	// Path and Args of the worker command. By default the current executable
+	// is started again, which then must call MaybeRunSandboxWorker first
+	// thing in main. A custom worker calls MaybeRunSandboxWorker or
+	// ServeSandbox in the same way.
+	Path string
+	Args []string
+	// Env is added to the environment inherited by the worker.
+	Env []string
+	// Timeout bounds each call, including a restart of the worker.
+	// Defaults to DefaultSandboxTimeout.
+	Timeout time.Duration
+	// MemoryLimit caps the address space of the worker, in bytes, with
+	// RLIMIT_AS. Zero means no limit. The limit is set on the worker
+	// process after it started and before it is sent the first request, so
+	// it also applies to custom workers. Only supported on linux; other
+	// platforms, including darwin, which does not enforce RLIMIT_AS, fail
+	// NewSandboxedVeritas when it is set.
+	MemoryLimit uint64
+	// Stderr receives the standard error of the worker. Defaults to
+	// os.Stderr.
+	Stderr io.Writer
+}
+
+// SandboxedVeritas verifies messages like Veritas, but in a supervised
+// worker process. Calls are serialized.
+//
+// Only VerifyBytes and VerifyBytesContext keep untrusted input out of this
+// process. Verify, VerifyWith and VerifyContext mirror the Veritas
+// API and take a *Message, which NewMessage already decoded in this process;
+// they isolate the verification but not the decoding.
+//
+// The QueryContext passed to a sandboxed call must come from NewQueryContext
+// of the SandboxedVeritas, which records its requests and zones for the
+// worker. Nil selects an empty context.
+type SandboxedVeritas struct {
+	config      SandboxConfig
+	anchorsJson string
+
+	// Held while talking to the worker; a channel so that waiting for it
+	// can be cancelled.
+	lock   chan struct{}
+	worker *sandboxWorker
+	closed bool
+}
+
+// NewSandboxedVeritas starts a worker and initializes it with anchors in the
+// JSON format accepted by AnchorsFromJson.
+func NewSandboxedVeritas(anchorsJson string, config SandboxConfig) (*SandboxedVeritas, error) {
+	if config.MemoryLimit != 0 && !sandboxMemoryLimitSupported {
+		return nil, fmt.Errorf("libveritas: sandbox memory limits are not supported on this platform")
+	}
+	if config.Path == "" {
+		path, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("libveritas: locating sandbox worker: %w", err)
+		}
+		config.Path = path
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultSandboxTimeout
+	}
+	if config.Stderr == nil {
+		config.Stderr = os.Stderr
+	}
+	sandbox := &SandboxedVeritas{
+		config:      config,
+		anchorsJson: anchorsJson,
+		lock:        make(chan struct{}, 1),
+	}
+	if err := sandbox.start(context.Background(), time.Now().Add(config.Timeout)); err != nil {
+		return nil, err
+	}
+	return sandbox, nil
+}
+
+// Verify a message with default options. The message was decoded in this
+// process; see VerifyBytes for untrusted input.
+func (_self *SandboxedVeritas) Verify(ctx *QueryContext, msg *Message) (*SandboxVerifiedMessage, error) {
+	return _self.verify(context.Background(), ctx, msg.ToBytes(), nil)
+}
+
+// Verify a message with options. The message was decoded in this process;
+// see VerifyBytes for untrusted input.
+func (_self *SandboxedVeritas) VerifyWith(ctx *QueryContext, msg *Message, options VerifyOptions) (*SandboxVerifiedMessage, error) {
+	return _self.verify(context.Background(), ctx, msg.ToBytes(), &options)
+}
+
+// VerifyContext is VerifyWith bounded by ctx. Unlike
+// Veritas.VerifyContext, cancellation stops the verification: the worker is
+// killed and restarted on the next call. See VerifyBytesContext for
+// untrusted input.
+func (_self *SandboxedVeritas) VerifyContext(ctx context.Context, qctx *QueryContext, msg *Message, options VerifyOptions) (*SandboxVerifiedMessage, error) {
+	return _self.verify(ctx, qctx, msg.ToBytes(), &options)
+}
+
+// VerifyBytes verifies encoded message bytes with options. Unlike
+// Verify, the message is only decoded inside the worker, which makes this
+// the method to use for untrusted input.
+func (_self *SandboxedVeritas) VerifyBytes(ctx *QueryContext, msgBytes []byte, options VerifyOptions) (*SandboxVerifiedMessage, error) {
+	return _self.verify(context.Background(), ctx, msgBytes, &options)
+}
+
+// VerifyBytesContext is VerifyBytes bounded by ctx.
+func (_self *SandboxedVeritas) VerifyBytesContext(ctx context.Context, qctx *QueryContext, msgBytes []byte, options VerifyOptions) (*SandboxVerifiedMessage, error) {
+	return _self.verify(ctx, qctx, msgBytes, &options)
+}
+
+// NewQueryContext returns a QueryContext for calls of this sandbox. Unlike
+// one from the package-level NewQueryContext, it records the requests and
+// zones added to it, so that they can be replayed in the worker.
+func (_self *SandboxedVeritas) NewQueryContext() *QueryContext {
+	ctx := NewQueryContext()
+	if ctx.log == nil {
+		ctx.log = &queryLog{}
+	}
+	return ctx
+}
+
+// Destroy stops the worker. Later calls return ErrSandboxClosed.
+func (_self *SandboxedVeritas) Destroy() {
+	_self.lock <- struct{}{}
+	defer func() { <-_self.lock }()
+	_self.closed = true
+	if _self.worker != nil {
+		_self.worker.stop()
+		_self.worker = nil
+	}
+}
+
+func (_self *SandboxedVeritas) verify(ctx context.Context, qctx *QueryContext, msgBytes []byte, options *VerifyOptions) (*SandboxVerifiedMessage, error) {
+	if options != nil {
+		if err := options.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	request := sandboxRequest{Op: sandboxOpVerify, Message: msgBytes, Options: options}
+	if qctx != nil {
+		if qctx.log == nil {
+			return nil, fmt.Errorf("libveritas: QueryContext of a sandboxed call must come from SandboxedVeritas.NewQueryContext")
+		}
+		request.Requests, request.Zones = qctx.log.snapshot()
+	}
+	response, err := _self.call(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+	if err := response.Error.err(); err != nil {
+		return nil, err
+	}
+	return &SandboxVerifiedMessage{
+		zones:        response.Zones,
+		certificates: response.Certificates,
+		messageBytes: response.Message,
+	}, nil
+}
+
+// call sends one request to the worker, restarting it if needed.
+func (_self *SandboxedVeritas) call(ctx context.Context, request *sandboxRequest) (*sandboxResponse, error) {
+	select {
+	case _self.lock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-_self.lock }()
+	if _self.closed {
+		return nil, ErrSandboxClosed
+	}
+	deadline := time.Now().Add(_self.config.Timeout)
+	if _self.worker == nil {
+		if err := _self.start(ctx, deadline); err != nil {
+			return nil, err
+		}
+	}
+	response, err := _self.worker.roundTrip(ctx, request, deadline)
+	if err != nil {
+		_self.worker = nil
+		return nil, err
+	}
+	return response, nil
+}
+
+// start launches and initializes a worker. Callers hold lock, or own the
+// SandboxedVeritas exclusively.
+func (_self *SandboxedVeritas) start(ctx context.Context, deadline time.Time) error {
+	worker, err := startSandboxWorker(_self.config)
+	if err != nil {
+		return err
+	}
+	response, err := worker.roundTrip(ctx, &sandboxRequest{Op: sandboxOpInit, Anchors: _self.anchorsJson}, deadline)
+	if err != nil {
+		return err
+	}
+	if err := response.Error.err(); err != nil {
+		worker.stop()
+		return err
+	}
+	_self.worker = worker
+	return nil
+}
+
+// SandboxVerifiedMessage is the result of a sandboxed verification. It holds
+// plain Go values and needs no cleanup.
+type SandboxVerifiedMessage struct {
+	zones        []Zone
+	certificates [][]byte
+	messageBytes []byte
+}
+
+func (_self *SandboxVerifiedMessage) Certificates() [][]byte {
+	return _self.certificates
+}
+
+// Decode the verified message in this process. Returns nil if it cannot be
+// decoded, for example in builds without the native library.
+func (_self *SandboxVerifiedMessage) Message() *Message {
+	msg, err := NewMessage(_self.messageBytes)
+	if err != nil {
+		return nil
+	}
+	return msg
+}
+
+// Get the verified message as bytes.
+func (_self *SandboxVerifiedMessage) MessageBytes() []byte {
+	return _self.messageBytes
+}
+
+func (_self *SandboxVerifiedMessage) Zones() []Zone {
+	return _self.zones
+}
+
+type sandboxWorker struct {
+	cmd    *exec.Cmd
+	stdin  *os.File
+	stdout *os.File
+	exited chan struct{}
+	// Result of cmd.Wait, set before exited is closed.
+	waitErr error
+}
+
+func startSandboxWorker(config SandboxConfig) (*sandboxWorker, error) {
+	cmd := exec.Command(config.Path, config.Args...)
+	cmd.Env = append(os.Environ(), config.Env...)
+	cmd.Env = append(cmd.Env, sandboxWorkerEnv+"=1")
+	cmd.Stderr = config.Stderr
+
+	// Plain pipes rather than cmd.StdoutPipe, so that Wait does not close
+	// the read end before a response written just before exit is read.
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("libveritas: starting sandbox worker: %w", err)
+	}
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		stdinReader.Close()
+		stdinWriter.Close()
+		return nil, fmt.Errorf("libveritas: starting sandbox worker: %w", err)
+	}
+	cmd.Stdin = stdinReader
+	cmd.Stdout = stdoutWriter
+	err = cmd.Start()
+	stdinReader.Close()
+	stdoutWriter.Close()
+	if err != nil {
+		stdinWriter.Close()
+		stdoutReader.Close()
+		return nil, fmt.Errorf("libveritas: starting sandbox worker: %w", err)
+	}
+	worker := &sandboxWorker{
+		cmd:    cmd,
+		stdin:  stdinWriter,
+		stdout: stdoutReader,
+		exited: make(chan struct{}),
+	}
+	go func() {
+		worker.waitErr = cmd.Wait()
+		close(worker.exited)
+	}()
+	if config.MemoryLimit != 0 {
+		if err := limitSandboxMemory(cmd.Process.Pid, config.MemoryLimit); err != nil {
+			worker.stop()
+			return nil, fmt.Errorf("libveritas: setting sandbox memory limit: %w", err)
+		}
+	}
+	return worker, nil
+}
+
+// roundTrip sends a request and reads its response. On any failure,
+// including cancellation of ctx, the worker is killed and must not be used
+// again.
+func (w *sandboxWorker) roundTrip(ctx context.Context, request *sandboxRequest, deadline time.Time) (*sandboxResponse, error) {
+	type result struct {
+		response *sandboxResponse
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var response sandboxResponse
+		err := writeSandboxFrame(w.stdin, request)
+		if err == nil {
+			err = readSandboxFrame(w.stdout, &response)
+		}
+		done <- result{&response, err}
+	}()
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		if r.err != nil {
+			w.stop()
+			if w.waitErr != nil {
+				return nil, fmt.Errorf("%w: %v", ErrSandboxCrashed, w.waitErr)
+			}
+			return nil, fmt.Errorf("%w: %v", ErrSandboxCrashed, r.err)
+		}
+		return r.response, nil
+	case <-timer.C:
+		w.stop()
+		<-done
+		return nil, ErrSandboxTimeout
+	case <-ctx.Done():
+		w.stop()
+		<-done
+		return nil, ctx.Err()
+	}
+}
+
+// stop kills the worker and waits for it to exit.
+func (w *sandboxWorker) stop() {
+	w.cmd.Process.Kill()
+	<-w.exited
+	w.stdin.Close()
+	w.stdout.Close()
+}
+
+// MaybeRunSandboxWorker turns the process into a sandbox worker when it was
+// started by a SandboxedVeritas, and returns immediately otherwise. Call it
+// at the start of main, before anything writes to stdout. In a worker it
+// serves requests until the parent goes away and then exits the process.
+func MaybeRunSandboxWorker() {
+	if os.Getenv(sandboxWorkerEnv) == "" {
+		return
+	}
+	os.Exit(runSandboxWorker())
+}
+
+func runSandboxWorker() int {
+	// Report native panics as errors rather than dying on them.
+	SetPanicIsolation(true)
+	// Keep stray output of the host program away from the protocol.
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	if err := ServeSandbox(os.Stdin, out); err != nil {
+		fmt.Fprintf(os.Stderr, "libveritas sandbox: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+// ServeSandbox runs the worker side of the sandbox protocol, reading
+// requests from r and writing responses to w until r reaches EOF. It is the
+// building block of MaybeRunSandboxWorker for workers that manage their own
+// process setup.
+func ServeSandbox(r io.Reader, w io.Writer) error {
+	var veritas *Veritas
+	defer func() {
+		if veritas != nil {
+			veritas.Destroy()
+		}
+	}()
+	for {
+		var request sandboxRequest
+		if err := readSandboxFrame(r, &request); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		var response sandboxResponse
+		switch request.Op {
+		case sandboxOpInit:
+			if veritas != nil {
+				veritas.Destroy()
+				veritas = nil
+			}
+			v, err := sandboxInit(request.Anchors)
+			response.Error = newSandboxError(err)
+			veritas = v
+		case sandboxOpVerify:
+			if veritas == nil {
+				response.Error = newSandboxError(fmt.Errorf("sandbox worker is not initialized"))
+				break
+			}
+			response = sandboxVerify(veritas, &request)
+		default:
+			response.Error = newSandboxError(fmt.Errorf("unknown sandbox op %d", request.Op))
+		}
+		if err := writeSandboxFrame(w, &response); err != nil {
+			return err
+		}
+	}
+}
+
+func sandboxInit(anchorsJson string) (*Veritas, error) {
+	return newVeritasFromJson(anchorsJson)
+}
+
+func sandboxVerify(veritas *Veritas, request *sandboxRequest) sandboxResponse {
+	ctx := NewQueryContext()
+	defer ctx.Destroy()
+	for _, handle := range request.Requests {
+		if err := ctx.AddRequest(handle); err != nil {
+			return sandboxResponse{Error: newSandboxError(err)}
+		}
+	}
+	for _, zone := range request.Zones {
+		if err := ctx.AddZone(zone); err != nil {
+			return sandboxResponse{Error: newSandboxError(err)}
+		}
+	}
+	msg, err := NewMessage(request.Message)
+	if err != nil {
+		return sandboxResponse{Error: newSandboxError(err)}
+	}
+	defer msg.Destroy()
+
+	var verified *VerifiedMessage
+	if request.Options == nil {
+		verified, err = veritas.Verify(ctx, msg)
+	} else {
+		verified, err = veritas.VerifyWith(ctx, msg, *request.Options)
+	}
+	if err != nil {
+		return sandboxResponse{Error: newSandboxError(err)}
+	}
+	defer verified.Destroy()
+
+	return sandboxResponse{
+		Zones:        verified.Zones(),
+		Certificates: verified.Certificates(),
+		Message:      verified.MessageBytes(),
+	}
+}
+
+// queryLog records the input of a QueryContext so that it can be replayed
+// in a sandbox worker. The methods do nothing on a nil log.
+type queryLog struct {
+	mu       sync.Mutex
+	requests []string
+	zones    [][]byte
+}
+
+func (l *queryLog) logRequest(handle string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.requests = append(l.requests, handle)
+}
+
+func (l *queryLog) logZone(zoneBytes []byte) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.zones = append(l.zones, append([]byte{}, zoneBytes...))
+}
+
+func (l *queryLog) snapshot() ([]string, [][]byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.requests...), append([][]byte(nil), l.zones...)
+}
+
+const (
+	sandboxOpInit uint8 = iota
+	sandboxOpVerify
+)
+
+type sandboxRequest struct {
+	Op uint8
+	// Init
+	Anchors string `json:",omitempty"`
+	// Verify. Nil Options selects the default options.
+	Requests []string       `json:",omitempty"`
+	Zones    [][]byte       `json:",omitempty"`
+	Message  []byte         `json:",omitempty"`
+	Options  *VerifyOptions `json:",omitempty"`
+}
+
+type sandboxResponse struct {
+	Error        *sandboxError `json:",omitempty"`
+	Zones        []Zone        `json:",omitempty"`
+	Certificates [][]byte      `json:",omitempty"`
+	Message      []byte        `json:",omitempty"`
+}
+
+const (
+	sandboxErrOther uint8 = iota
+	sandboxErrInvalidInput
+	sandboxErrVerificationFailed
+	sandboxErrInternal
+	sandboxErrNativeUnavailable
+)
+
+type sandboxError struct {
+	Kind uint8
+	Msg  string
+	// Details of a *VeritasError, as reported in the worker.
+	Details *ErrorDetails `json:",omitempty"`
+}
+
+func newSandboxError(err error) *sandboxError {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrNativeUnavailable) {
+		return &sandboxError{Kind: sandboxErrNativeUnavailable}
+	}
+	var veritasErr *VeritasError
+	if errors.As(err, &veritasErr) {
+		details := veritasErr.Details()
+		switch variant := veritasErr.err.(type) {
+		case *VeritasErrorInvalidInput:
+			return &sandboxError{Kind: sandboxErrInvalidInput, Msg: variant.Msg, Details: &details}
+		case *VeritasErrorVerificationFailed:
+			return &sandboxError{Kind: sandboxErrVerificationFailed, Msg: variant.Msg, Details: &details}
+		case *InternalError:
+			return &sandboxError{Kind: sandboxErrInternal, Msg: variant.Msg, Details: &details}
+		}
+	}
+	return &sandboxError{Kind: sandboxErrOther, Msg: err.Error()}
+}
+
+// err rebuilds the error reported by the worker.
+func (e *sandboxError) err() error {
+	if e == nil {
+		return nil
+	}
+	var err *VeritasError
+	switch e.Kind {
+	case sandboxErrInvalidInput:
+		err = NewVeritasErrorInvalidInput(e.Msg)
+	case sandboxErrVerificationFailed:
+		err = NewVeritasErrorVerificationFailed(e.Msg)
+	case sandboxErrInternal:
+		err = &VeritasError{err: &InternalError{Msg: e.Msg}}
+	case sandboxErrNativeUnavailable:
+		return ErrNativeUnavailable
+	default:
+		return fmt.Errorf("libveritas: sandbox worker: %s", e.Msg)
+	}
+	if e.Details != nil {
+		err = withErrorDetails(err, *e.Details)
+	}
+	return err
+}
+
+func writeSandboxFrame(w io.Writer, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if len(payload) > sandboxMaxFrame {
+		return fmt.Errorf("sandbox frame of %d bytes exceeds limit", len(payload))
+	}
+	frame := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(payload)), uint32(len(payload)))
+	_, err = w.Write(append(frame, payload...))
+	return err
+}
+
+// readSandboxFrame returns io.EOF only if r ends cleanly between frames.
+func readSandboxFrame(r io.Reader, v any) error {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return err
+	}
+	length := binary.BigEndian.Uint32(header[:])
+	if length > sandboxMaxFrame {
+		return fmt.Errorf("sandbox frame of %d bytes exceeds limit", length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return json.Unmarshal(payload, v)
+}
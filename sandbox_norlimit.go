@@ -0,0 +1,11 @@
+//go:build !linux
+
+package libveritas
+
+import "fmt"
+
+const sandboxMemoryLimitSupported = false
+
+func limitSandboxMemory(pid int, bytes uint64) error {
+	return fmt.Errorf("memory limits are not supported on this platform")
+}
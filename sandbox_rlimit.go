@@ -0,0 +1,21 @@
+//go:build linux
+
+package libveritas
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+const sandboxMemoryLimitSupported = true
+
+// limitSandboxMemory sets RLIMIT_AS of the process pid with prlimit(2).
+func limitSandboxMemory(pid int, bytes uint64) error {
+	// struct rlimit64 of the kernel, the same on all architectures.
+	limit := struct{ cur, max uint64 }{bytes, bytes}
+	_, _, errno := syscall.RawSyscall6(syscall.SYS_PRLIMIT64, uintptr(pid), syscall.RLIMIT_AS, uintptr(unsafe.Pointer(&limit)), 0, 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
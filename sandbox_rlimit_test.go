@@ -0,0 +1,62 @@
+//go:build linux
+
+package libveritas
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The fake workers of sandbox_test.go serve the protocol themselves, so
+// they stand in for custom workers that never call MaybeRunSandboxWorker.
+
+// workerMemoryLimit returns the soft limit on the address space of the
+// worker of sandbox, as shown in /proc.
+func workerMemoryLimit(t *testing.T, sandbox *SandboxedVeritas) string {
+	t.Helper()
+	f, err := os.Open(fmt.Sprintf("/proc/%d/limits", sandbox.worker.cmd.Process.Pid))
+	if err != nil {
+		t.Fatalf("reading worker limits: %v", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line, ok := strings.CutPrefix(scanner.Text(), "Max address space"); ok {
+			return strings.Fields(line)[0]
+		}
+	}
+	t.Fatal("no address space limit in /proc")
+	return ""
+}
+
+func TestSandboxMemoryLimit(t *testing.T) {
+	const limit uint64 = 1 << 40
+	sandbox := newTestSandboxWith(t, "ok", SandboxConfig{MemoryLimit: limit})
+	if got := workerMemoryLimit(t, sandbox); got != fmt.Sprint(limit) {
+		t.Errorf("worker address space limit = %s, want %d", got, limit)
+	}
+	if _, err := sandbox.VerifyBytes(nil, []byte{1}, VerifyOptionsDefault); err != nil {
+		t.Errorf("VerifyBytes under the limit: %v", err)
+	}
+}
+
+func TestSandboxMemoryLimitEnforced(t *testing.T) {
+	if strconv.IntSize < 64 {
+		t.Skip("the fake worker cannot allocate 2 GiB")
+	}
+	unlimited := newTestSandboxWith(t, "alloc", SandboxConfig{})
+	if _, err := unlimited.VerifyBytes(nil, nil, VerifyOptionsDefault); err != nil {
+		t.Fatalf("allocating without a limit: %v", err)
+	}
+	// The worker starts with more than 1 GiB of address space, which
+	// leaves less than the 2 GiB it allocates.
+	limited := newTestSandboxWith(t, "alloc", SandboxConfig{MemoryLimit: 3 << 30})
+	if _, err := limited.VerifyBytes(nil, nil, VerifyOptionsDefault); !errors.Is(err, ErrSandboxCrashed) {
+		t.Errorf("error = %v, want ErrSandboxCrashed", err)
+	}
+}
@@ -0,0 +1,230 @@
+package libveritas
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// The test binary doubles as a fake sandbox worker, selected by
+// sandboxTestWorkerEnv, so that the supervision can be tested without the
+// native library:
+//
+//	ok          answers every request; verify echoes the requests as
+//	            certificates and the message bytes
+//	hang        never answers a verify request
+//	crash       exits on a verify request
+//	crash-once  like crash for the first worker, after that like ok
+//	alloc       allocates 2 GiB on a verify request, then answers like ok
+const (
+	sandboxTestWorkerEnv = "LIBVERITAS_TEST_SANDBOX_WORKER"
+	sandboxTestMarkerEnv = "LIBVERITAS_TEST_SANDBOX_MARKER"
+)
+
+// sandboxTestAlloc is the allocation of the alloc worker, a variable so
+// that the test builds where int has 32 bits.
+var sandboxTestAlloc int64 = 2 << 30
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(sandboxTestWorkerEnv); mode != "" {
+		os.Exit(runFakeSandboxWorker(mode))
+	}
+	os.Exit(m.Run())
+}
+
+func runFakeSandboxWorker(mode string) int {
+	if mode == "crash-once" {
+		marker := os.Getenv(sandboxTestMarkerEnv)
+		if _, err := os.Stat(marker); err == nil {
+			mode = "ok"
+		} else {
+			os.WriteFile(marker, nil, 0o600)
+			mode = "crash"
+		}
+	}
+	for {
+		var request sandboxRequest
+		if err := readSandboxFrame(os.Stdin, &request); err != nil {
+			return 0
+		}
+		var response sandboxResponse
+		if request.Op == sandboxOpVerify {
+			switch mode {
+			case "hang":
+				time.Sleep(time.Hour)
+				return 0
+			case "crash":
+				return 3
+			case "alloc":
+				runtime.KeepAlive(make([]byte, sandboxTestAlloc))
+			}
+			for _, handle := range request.Requests {
+				response.Certificates = append(response.Certificates, []byte(handle))
+			}
+			response.Message = request.Message
+		}
+		if err := writeSandboxFrame(os.Stdout, &response); err != nil {
+			return 1
+		}
+	}
+}
+
+func newTestSandbox(t *testing.T, mode string, timeout time.Duration) *SandboxedVeritas {
+	t.Helper()
+	return newTestSandboxWith(t, mode, SandboxConfig{Timeout: timeout})
+}
+
+// newTestSandboxWith is newTestSandbox with further settings in config.
+func newTestSandboxWith(t *testing.T, mode string, config SandboxConfig) *SandboxedVeritas {
+	t.Helper()
+	config.Env = append(config.Env,
+		sandboxTestWorkerEnv+"="+mode,
+		sandboxTestMarkerEnv+"="+t.TempDir()+"/crashed",
+	)
+	sandbox, err := NewSandboxedVeritas("[]", config)
+	if err != nil {
+		t.Fatalf("NewSandboxedVeritas: %v", err)
+	}
+	t.Cleanup(sandbox.Destroy)
+	return sandbox
+}
+
+func TestSandboxVerifyBytes(t *testing.T) {
+	sandbox := newTestSandbox(t, "ok", 0)
+	qctx := sandbox.NewQueryContext()
+	defer qctx.Destroy()
+	if err := qctx.AddRequest("alice@bitcoin"); err != nil {
+		t.Fatalf("AddRequest: %v", err)
+	}
+	verified, err := sandbox.VerifyBytes(qctx, []byte{1, 2, 3}, VerifyOptionsDefault)
+	if err != nil {
+		t.Fatalf("VerifyBytes: %v", err)
+	}
+	if certs := verified.Certificates(); len(certs) != 1 || string(certs[0]) != "alice@bitcoin" {
+		t.Errorf("worker saw requests %q, want [alice@bitcoin]", certs)
+	}
+	if !bytes.Equal(verified.MessageBytes(), []byte{1, 2, 3}) {
+		t.Errorf("MessageBytes = %x", verified.MessageBytes())
+	}
+}
+
+func TestSandboxRejectsUnrecordedQueryContext(t *testing.T) {
+	sandbox := newTestSandbox(t, "ok", 0)
+	if _, err := sandbox.VerifyBytes(&QueryContext{}, nil, VerifyOptionsDefault); err == nil {
+		t.Error("VerifyBytes accepted a QueryContext that records nothing")
+	}
+}
+
+func TestSandboxTimeout(t *testing.T) {
+	sandbox := newTestSandbox(t, "hang", 200*time.Millisecond)
+	for i := 0; i < 2; i++ {
+		// The second call runs on a restarted worker.
+		if _, err := sandbox.VerifyBytes(nil, nil, VerifyOptionsDefault); !errors.Is(err, ErrSandboxTimeout) {
+			t.Fatalf("call %d: error = %v, want ErrSandboxTimeout", i, err)
+		}
+	}
+}
+
+func TestSandboxContextCancel(t *testing.T) {
+	sandbox := newTestSandbox(t, "hang", time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := sandbox.VerifyBytesContext(ctx, nil, nil, VerifyOptionsDefault); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestSandboxCrash(t *testing.T) {
+	sandbox := newTestSandbox(t, "crash", 0)
+	if _, err := sandbox.VerifyBytes(nil, nil, VerifyOptionsDefault); !errors.Is(err, ErrSandboxCrashed) {
+		t.Errorf("error = %v, want ErrSandboxCrashed", err)
+	}
+}
+
+func TestSandboxRestartsAfterCrash(t *testing.T) {
+	sandbox := newTestSandbox(t, "crash-once", 0)
+	if _, err := sandbox.VerifyBytes(nil, []byte{1}, VerifyOptionsDefault); !errors.Is(err, ErrSandboxCrashed) {
+		t.Fatalf("first call: error = %v, want ErrSandboxCrashed", err)
+	}
+	verified, err := sandbox.VerifyBytes(nil, []byte{1}, VerifyOptionsDefault)
+	if err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+	if !bytes.Equal(verified.MessageBytes(), []byte{1}) {
+		t.Errorf("MessageBytes = %x", verified.MessageBytes())
+	}
+}
+
+func TestSandboxClosed(t *testing.T) {
+	sandbox := newTestSandbox(t, "ok", 0)
+	sandbox.Destroy()
+	if _, err := sandbox.VerifyBytes(nil, nil, VerifyOptionsDefault); !errors.Is(err, ErrSandboxClosed) {
+		t.Errorf("error = %v, want ErrSandboxClosed", err)
+	}
+}
+
+func TestSandboxMemoryLimitSupport(t *testing.T) {
+	if runtime.GOOS == "linux" {
+		t.Skip("memory limits are supported on linux")
+	}
+	_, err := NewSandboxedVeritas("[]", SandboxConfig{MemoryLimit: 1 << 30})
+	if err == nil {
+		t.Error("NewSandboxedVeritas accepted a memory limit")
+	}
+}
+
+func TestSandboxFrames(t *testing.T) {
+	var buf bytes.Buffer
+	request := sandboxRequest{Op: sandboxOpVerify, Requests: []string{"alice@bitcoin"}, Message: []byte{0, 1}}
+	if err := writeSandboxFrame(&buf, &request); err != nil {
+		t.Fatalf("writeSandboxFrame: %v", err)
+	}
+	frame := append([]byte{}, buf.Bytes()...)
+
+	var got sandboxRequest
+	if err := readSandboxFrame(&buf, &got); err != nil {
+		t.Fatalf("readSandboxFrame: %v", err)
+	}
+	if got.Op != request.Op || got.Requests[0] != "alice@bitcoin" || !bytes.Equal(got.Message, request.Message) {
+		t.Errorf("frame round trip = %+v, want %+v", got, request)
+	}
+	if err := readSandboxFrame(&buf, &got); err != io.EOF {
+		t.Errorf("read at end = %v, want io.EOF", err)
+	}
+	if err := readSandboxFrame(bytes.NewReader(frame[:len(frame)-1]), &got); err != io.ErrUnexpectedEOF {
+		t.Errorf("read of truncated frame = %v, want io.ErrUnexpectedEOF", err)
+	}
+	if err := readSandboxFrame(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff}), &got); err == nil {
+		t.Error("read of oversized frame succeeded")
+	}
+}
+
+func TestSandboxErrorRoundTrip(t *testing.T) {
+	tests := []struct {
+		err    error
+		target error
+		code   ErrorCode
+	}{
+		{newInvalidInputError(CodeInvalidHandle, "bad handle"), ErrInvalidHandle, CodeInvalidHandle},
+		{newVerificationFailedError(CodeBadSignature, "bad sig"), ErrVeritasErrorVerificationFailed, CodeBadSignature},
+		{&VeritasError{err: &InternalError{Msg: "rust panicked"}}, ErrInternal, CodeInternal},
+		{ErrNativeUnavailable, ErrNativeUnavailable, CodeNativeUnavailable},
+	}
+	for _, tt := range tests {
+		got := newSandboxError(tt.err).err()
+		if !errors.Is(got, tt.target) {
+			t.Errorf("round trip of %v = %v, want %v", tt.err, got, tt.target)
+		}
+		if code := ErrorCodeOf(got); code != tt.code {
+			t.Errorf("round trip of %v has code %q, want %q", tt.err, code, tt.code)
+		}
+	}
+	if newSandboxError(nil).err() != nil {
+		t.Error("round trip of nil is not nil")
+	}
+}
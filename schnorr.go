@@ -0,0 +1,62 @@
+package libveritas
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// Pure-Go BIP-340 signature checks. These replace the FFI versions so that
+// signature verification neither crosses cgo nor requires the native library.
+
+// Prefix committed to by Spaces signed messages, including its length byte.
+const spacesSignedMessagePrefix = "\x17Spaces Signed Message:\n"
+
+// Hash a message with the Spaces signed-message prefix (SHA256).
+// Returns the 32-byte digest suitable for Schnorr signing/verification.
+func HashSignableMessage(msg []byte) []byte {
+	hasher := sha256.New()
+	hasher.Write([]byte(spacesSignedMessagePrefix))
+	hasher.Write(sip7AppendCompactSize(nil, uint64(len(msg))))
+	hasher.Write(msg)
+	return hasher.Sum(nil)
+}
+
+// Verify a raw Schnorr signature (no prefix, caller provides the 32-byte message hash).
+//
+// - `msg_hash`: 32-byte SHA256 hash
+// - `signature`: 64-byte Schnorr signature
+// - `pubkey`: 32-byte x-only public key
+func VerifySchnorr(msgHash []byte, signature []byte, pubkey []byte) error {
+	if len(msgHash) != 32 {
+		return newInvalidInputError(CodeInvalidInput, fmt.Sprintf("message hash must be 32 bytes, got %d", len(msgHash)))
+	}
+	if len(signature) != schnorr.SignatureSize {
+		return newInvalidInputError(CodeInvalidInput, fmt.Sprintf("signature must be %d bytes, got %d", schnorr.SignatureSize, len(signature)))
+	}
+	if len(pubkey) != schnorr.PubKeyBytesLen {
+		return newInvalidInputError(CodeInvalidInput, fmt.Sprintf("public key must be %d bytes, got %d", schnorr.PubKeyBytesLen, len(pubkey)))
+	}
+	key, err := schnorr.ParsePubKey(pubkey)
+	if err != nil {
+		return newInvalidInputError(CodeInvalidInput, fmt.Sprintf("invalid public key: %s", err))
+	}
+	sig, err := schnorr.ParseSignature(signature)
+	if err != nil {
+		return newVerificationFailedError(CodeBadSignature, fmt.Sprintf("invalid signature: %s", err))
+	}
+	if !sig.Verify(msgHash, key) {
+		return newVerificationFailedError(CodeBadSignature, "invalid signature")
+	}
+	return nil
+}
+
+// Verify a Schnorr signature over a message using the Spaces signed-message prefix.
+//
+// - `msg`: raw message bytes (prefixed and hashed internally)
+// - `signature`: 64-byte Schnorr signature
+// - `pubkey`: 32-byte x-only public key
+func VerifySpacesMessage(msg []byte, signature []byte, pubkey []byte) error {
+	return VerifySchnorr(HashSignableMessage(msg), signature, pubkey)
+}
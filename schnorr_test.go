@@ -0,0 +1,147 @@
+package libveritas
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// hashSignableVectors were computed independently as
+// sha256("\x17Spaces Signed Message:\n" || compact_size(len(msg)) || msg).
+var hashSignableVectors = []struct {
+	msg  string
+	hash string
+}{
+	{"", "7a65c00f22e3057469ec30839e90582844fe28dc8c04ba10cfc1f87e8105c2bb"},
+	{"hello", "6379f78412ee60a9b026581077bc473544705208ccac826d4aab1156a4ccb83d"},
+	{strings.Repeat("a", 253), "617bce8edbc54b2a252991c17b16d90546dd12b4abcfe83a67cccef3bf5f6a23"},
+}
+
+// schnorrVectors are BIP-340 test vectors 0 and 1 plus failing cases. A
+// case with a variant must fail with that VeritasError variant, in Go and
+// natively; one without must verify.
+var schnorrVectors = []struct {
+	name    string
+	pubkey  string
+	msgHash string
+	sig     string
+	variant error
+}{
+	{
+		name:    "bip340 vector 0",
+		pubkey:  "f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9",
+		msgHash: "0000000000000000000000000000000000000000000000000000000000000000",
+		sig:     "e907831f80848d1069a5371b402410364bdf1c5f8307b0084c55f1ce2dca821525f66a4a85ea8b71e482a74f382d2ce5ebeee8fdb2172f477df4900d310536c0",
+	},
+	{
+		name:    "bip340 vector 1",
+		pubkey:  "dff1d77f2a671c5f36183726db2341be58feae1da2deced843240f7b502ba659",
+		msgHash: "243f6a8885a308d313198a2e03707344a4093822299f31d0082efa98ec4e6c89",
+		sig:     "6896bd60eeae296db48a229ff71dfe071bde413e6d43f917dc8dcf8c78de33418906d11ac976abccb20b091292bff4ea897efcb639ea871cfa95f6de339e4b0a",
+	},
+	{
+		name:    "wrong message",
+		pubkey:  "f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9",
+		msgHash: "0100000000000000000000000000000000000000000000000000000000000000",
+		sig:     "e907831f80848d1069a5371b402410364bdf1c5f8307b0084c55f1ce2dca821525f66a4a85ea8b71e482a74f382d2ce5ebeee8fdb2172f477df4900d310536c0",
+		variant: ErrVeritasErrorVerificationFailed,
+	},
+	{
+		name:    "short message hash",
+		pubkey:  "f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9",
+		msgHash: "00",
+		sig:     "e907831f80848d1069a5371b402410364bdf1c5f8307b0084c55f1ce2dca821525f66a4a85ea8b71e482a74f382d2ce5ebeee8fdb2172f477df4900d310536c0",
+		variant: ErrVeritasErrorInvalidInput,
+	},
+	{
+		name:    "short signature",
+		pubkey:  "f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9",
+		msgHash: "0000000000000000000000000000000000000000000000000000000000000000",
+		sig:     "e907831f",
+		variant: ErrVeritasErrorInvalidInput,
+	},
+	{
+		name:    "pubkey not on curve",
+		pubkey:  "eefdea4cdb677750a420fee807eacf21eb9898ae79b9768766e4faa04a2d4a34",
+		msgHash: "0000000000000000000000000000000000000000000000000000000000000000",
+		sig:     "e907831f80848d1069a5371b402410364bdf1c5f8307b0084c55f1ce2dca821525f66a4a85ea8b71e482a74f382d2ce5ebeee8fdb2172f477df4900d310536c0",
+		variant: ErrVeritasErrorInvalidInput,
+	},
+}
+
+// spacesMessageVector is signed with the secret key 3 over
+// HashSignableMessage("hello").
+var spacesMessageVector = struct {
+	msg, pubkey, sig string
+}{
+	msg:    "hello",
+	pubkey: "f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9",
+	sig:    "767fd596a864d7a0794fe6c4778a1d4e27fa9f6eeabf8e49a7bd3b1dab7c9e569931fd705f5c36c449595a4eeaa01b9e5b186fbc0272e67ccbe8ee95b9171b1c",
+}
+
+func TestHashSignableMessage(t *testing.T) {
+	for _, v := range hashSignableVectors {
+		if got := hex.EncodeToString(HashSignableMessage([]byte(v.msg))); got != v.hash {
+			t.Errorf("HashSignableMessage(%d bytes) = %s, want %s", len(v.msg), got, v.hash)
+		}
+	}
+}
+
+func TestVerifySchnorr(t *testing.T) {
+	for _, v := range schnorrVectors {
+		t.Run(v.name, func(t *testing.T) {
+			err := VerifySchnorr(mustDecodeHex(t, v.msgHash), mustDecodeHex(t, v.sig), mustDecodeHex(t, v.pubkey))
+			checkSchnorrResult(t, err, v.variant)
+		})
+	}
+}
+
+func TestVerifySpacesMessage(t *testing.T) {
+	v := spacesMessageVector
+	sig, pubkey := mustDecodeHex(t, v.sig), mustDecodeHex(t, v.pubkey)
+	if err := VerifySpacesMessage([]byte(v.msg), sig, pubkey); err != nil {
+		t.Fatalf("VerifySpacesMessage: %v", err)
+	}
+	err := VerifySpacesMessage([]byte(v.msg+"!"), sig, pubkey)
+	if !errors.Is(err, ErrBadSignature) {
+		t.Errorf("VerifySpacesMessage of altered message = %v, want ErrBadSignature", err)
+	}
+}
+
+// TestNativeSchnorrVectors holds the native implementation to the same
+// vectors.
+func TestNativeSchnorrVectors(t *testing.T) {
+	if !NativeAvailable() {
+		t.Skip("native library not available")
+	}
+	for _, v := range hashSignableVectors {
+		got, err := nativeHashSignableMessage([]byte(v.msg))
+		if err != nil {
+			t.Fatalf("native HashSignableMessage: %v", err)
+		}
+		if hex.EncodeToString(got) != v.hash {
+			t.Errorf("native HashSignableMessage(%d bytes) = %x, want %s", len(v.msg), got, v.hash)
+		}
+	}
+	for _, v := range schnorrVectors {
+		t.Run(v.name, func(t *testing.T) {
+			err := nativeVerifySchnorr(mustDecodeHex(t, v.msgHash), mustDecodeHex(t, v.sig), mustDecodeHex(t, v.pubkey))
+			checkSchnorrResult(t, err, v.variant)
+		})
+	}
+	v := spacesMessageVector
+	if err := nativeVerifySpacesMessage([]byte(v.msg), mustDecodeHex(t, v.sig), mustDecodeHex(t, v.pubkey)); err != nil {
+		t.Errorf("native VerifySpacesMessage: %v", err)
+	}
+}
+
+func checkSchnorrResult(t *testing.T, err error, variant error) {
+	t.Helper()
+	switch {
+	case variant == nil && err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case variant != nil && !errors.Is(err, variant):
+		t.Errorf("error = %v, want %v", err, variant)
+	}
+}
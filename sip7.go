@@ -0,0 +1,342 @@
+package libveritas
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+// Pure-Go SIP-7 record set codec.
+//
+// A record set is a concatenation of records, each laid out as
+//
+//	rtype:u8 | rdlen:compact_size | rdata[rdlen]
+//
+// where compact_size is the Bitcoin variable length integer. The rdata of
+// the known record types is:
+//
+//	SEQ   version:compact_size
+//	TXT   key:str8 | value:str*
+//	ADDR  key:str8 | value:str*
+//	BLOB  key:str8 | data (rest of rdata)
+//	SIG   flags:u8 | canonical:str8 | handle:str8 | sig[64]
+//
+// str8 is a UTF-8 string with a u8 length prefix and str is a UTF-8 string
+// with a compact_size length prefix. Known records whose rdata does not parse
+// unpack as ParsedRecordMalformed, any other rtype as ParsedRecordUnknown.
+
+// SIP-7 record types.
+const (
+	RecordTypeSeq  uint8 = 0x00
+	RecordTypeTxt  uint8 = 0x01
+	RecordTypeAddr uint8 = 0x02
+	RecordTypeBlob uint8 = 0x03
+	RecordTypeSig  uint8 = 0xff
+)
+
+// Length of the Schnorr signature carried by a SIG record.
+const sip7SigLen = 64
+
+// PackRecords encodes records into SIP-7 wire bytes.
+//
+// The output is meant to equal RecordSetPack(records).ToBytes(). That is
+// checked only where the native library is linked, by
+// TestNativeRecordSetVectors; the shared vectors were derived from the
+// layout above rather than captured from the native codec.
+func PackRecords(records []Record) ([]byte, error) {
+	var out []byte
+	for i, record := range records {
+		rtype, rdata, err := sip7EncodeRdata(record)
+		if err != nil {
+			return nil, newInvalidInputError(CodeMalformedRecords, fmt.Sprintf("record %d: %s", i, err))
+		}
+		out = append(out, rtype)
+		out = sip7AppendCompactSize(out, uint64(len(rdata)))
+		out = append(out, rdata...)
+	}
+	return out, nil
+}
+
+// UnpackRecords parses SIP-7 wire bytes.
+// The result is identical to NewRecordSet(data).Unpack().
+func UnpackRecords(data []byte) ([]ParsedRecord, error) {
+	var records []ParsedRecord
+	for offset := 0; offset < len(data); {
+		rtype := data[offset]
+		rdlen, n, err := sip7ReadCompactSize(data[offset+1:])
+		if err != nil {
+			return nil, newInvalidInputError(CodeMalformedRecords, fmt.Sprintf("record at offset %d: %s", offset, err))
+		}
+		start := offset + 1 + n
+		if rdlen > uint64(len(data)-start) {
+			return nil, newInvalidInputError(CodeMalformedRecords, fmt.Sprintf("record at offset %d: rdata truncated", offset))
+		}
+		end := start + int(rdlen)
+		records = append(records, sip7DecodeRdata(rtype, data[start:end]))
+		offset = end
+	}
+	return records, nil
+}
+
+func sip7EncodeRdata(record Record) (uint8, []byte, error) {
+	switch r := record.(type) {
+	case RecordSeq:
+		return RecordTypeSeq, sip7AppendCompactSize(nil, r.Version), nil
+	case RecordTxt:
+		rdata, err := sip7EncodeStrings(r.Key, r.Value)
+		return RecordTypeTxt, rdata, err
+	case RecordAddr:
+		rdata, err := sip7EncodeStrings(r.Key, r.Value)
+		return RecordTypeAddr, rdata, err
+	case RecordBlob:
+		rdata, err := sip7AppendKey(nil, r.Key)
+		if err != nil {
+			return 0, nil, err
+		}
+		return RecordTypeBlob, append(rdata, r.Value...), nil
+	case RecordSig:
+		if len(r.Sig) != sip7SigLen {
+			return 0, nil, fmt.Errorf("signature must be %d bytes, got %d", sip7SigLen, len(r.Sig))
+		}
+		rdata := []byte{r.Flags}
+		rdata, err := sip7AppendStr8(rdata, "canonical", r.Canonical)
+		if err != nil {
+			return 0, nil, err
+		}
+		rdata, err = sip7AppendStr8(rdata, "handle", r.Handle)
+		if err != nil {
+			return 0, nil, err
+		}
+		return RecordTypeSig, append(rdata, r.Sig...), nil
+	case RecordUnknown:
+		if sip7IsKnownType(r.Rtype) {
+			return 0, nil, fmt.Errorf("rtype %#02x is reserved for a known record type", r.Rtype)
+		}
+		return r.Rtype, append([]byte{}, r.Rdata...), nil
+	default:
+		return 0, nil, fmt.Errorf("unsupported record %T", record)
+	}
+}
+
+func sip7DecodeRdata(rtype uint8, rdata []byte) ParsedRecord {
+	var (
+		parsed ParsedRecord
+		ok     bool
+	)
+	switch rtype {
+	case RecordTypeSeq:
+		parsed, ok = sip7DecodeSeq(rdata)
+	case RecordTypeTxt:
+		var key string
+		var value []string
+		key, value, ok = sip7DecodeStrings(rdata)
+		parsed = ParsedRecordTxt{Key: key, Value: value}
+	case RecordTypeAddr:
+		var key string
+		var value []string
+		key, value, ok = sip7DecodeStrings(rdata)
+		parsed = ParsedRecordAddr{Key: key, Value: value}
+	case RecordTypeBlob:
+		parsed, ok = sip7DecodeBlob(rdata)
+	case RecordTypeSig:
+		parsed, ok = sip7DecodeSig(rdata)
+	default:
+		return ParsedRecordUnknown{Rtype: rtype, Rdata: append([]byte{}, rdata...)}
+	}
+	if !ok {
+		return ParsedRecordMalformed{Rtype: rtype, Rdata: append([]byte{}, rdata...)}
+	}
+	return parsed
+}
+
+func sip7DecodeSeq(rdata []byte) (ParsedRecord, bool) {
+	version, n, err := sip7ReadCompactSize(rdata)
+	if err != nil || n != len(rdata) {
+		return nil, false
+	}
+	return ParsedRecordSeq{Version: version}, true
+}
+
+func sip7DecodeBlob(rdata []byte) (ParsedRecord, bool) {
+	key, n, ok := sip7ReadStr8(rdata)
+	if !ok || key == "" {
+		return nil, false
+	}
+	return ParsedRecordBlob{Key: key, Value: append([]byte{}, rdata[n:]...)}, true
+}
+
+func sip7DecodeSig(rdata []byte) (ParsedRecord, bool) {
+	if len(rdata) < 1 {
+		return nil, false
+	}
+	flags := rdata[0]
+	offset := 1
+	canonical, n, ok := sip7ReadStr8(rdata[offset:])
+	if !ok {
+		return nil, false
+	}
+	offset += n
+	handle, n, ok := sip7ReadStr8(rdata[offset:])
+	if !ok {
+		return nil, false
+	}
+	offset += n
+	if len(rdata)-offset != sip7SigLen {
+		return nil, false
+	}
+	return ParsedRecordSig{
+		Flags:     flags,
+		Canonical: canonical,
+		Handle:    handle,
+		Sig:       append([]byte{}, rdata[offset:]...),
+	}, true
+}
+
+func sip7EncodeStrings(key string, values []string) ([]byte, error) {
+	rdata, err := sip7AppendKey(nil, key)
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		if !utf8.ValidString(value) {
+			return nil, fmt.Errorf("value %d is not valid UTF-8", i)
+		}
+		rdata = sip7AppendCompactSize(rdata, uint64(len(value)))
+		rdata = append(rdata, value...)
+	}
+	return rdata, nil
+}
+
+func sip7DecodeStrings(rdata []byte) (string, []string, bool) {
+	key, offset, ok := sip7ReadStr8(rdata)
+	if !ok || key == "" {
+		return "", nil, false
+	}
+	var values []string
+	for offset < len(rdata) {
+		length, n, err := sip7ReadCompactSize(rdata[offset:])
+		if err != nil {
+			return "", nil, false
+		}
+		offset += n
+		if length > uint64(len(rdata)-offset) {
+			return "", nil, false
+		}
+		value := rdata[offset : offset+int(length)]
+		if !utf8.Valid(value) {
+			return "", nil, false
+		}
+		values = append(values, string(value))
+		offset += int(length)
+	}
+	return key, values, true
+}
+
+func sip7AppendKey(dst []byte, key string) ([]byte, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key must not be empty")
+	}
+	return sip7AppendStr8(dst, "key", key)
+}
+
+func sip7AppendStr8(dst []byte, field string, value string) ([]byte, error) {
+	if len(value) > math.MaxUint8 {
+		return nil, fmt.Errorf("%s is %d bytes, at most %d allowed", field, len(value), math.MaxUint8)
+	}
+	if !utf8.ValidString(value) {
+		return nil, fmt.Errorf("%s is not valid UTF-8", field)
+	}
+	dst = append(dst, uint8(len(value)))
+	return append(dst, value...), nil
+}
+
+func sip7ReadStr8(data []byte) (string, int, bool) {
+	if len(data) < 1 {
+		return "", 0, false
+	}
+	length := int(data[0])
+	if len(data)-1 < length {
+		return "", 0, false
+	}
+	value := data[1 : 1+length]
+	if !utf8.Valid(value) {
+		return "", 0, false
+	}
+	return string(value), 1 + length, true
+}
+
+func sip7IsKnownType(rtype uint8) bool {
+	switch rtype {
+	case RecordTypeSeq, RecordTypeTxt, RecordTypeAddr, RecordTypeBlob, RecordTypeSig:
+		return true
+	default:
+		return false
+	}
+}
+
+func sip7AppendCompactSize(dst []byte, value uint64) []byte {
+	switch {
+	case value < 0xfd:
+		return append(dst, uint8(value))
+	case value <= math.MaxUint16:
+		return binary.LittleEndian.AppendUint16(append(dst, 0xfd), uint16(value))
+	case value <= math.MaxUint32:
+		return binary.LittleEndian.AppendUint32(append(dst, 0xfe), uint32(value))
+	default:
+		return binary.LittleEndian.AppendUint64(append(dst, 0xff), value)
+	}
+}
+
+// sip7CompactSizeLen returns the encoded length of a compact_size.
+func sip7CompactSizeLen(value uint64) int {
+	switch {
+	case value < 0xfd:
+		return 1
+	case value <= math.MaxUint16:
+		return 3
+	case value <= math.MaxUint32:
+		return 5
+	default:
+		return 9
+	}
+}
+
+// sip7ReadCompactSize decodes a minimally encoded compact_size and returns
+// the value and the number of bytes consumed.
+func sip7ReadCompactSize(data []byte) (uint64, int, error) {
+	if len(data) < 1 {
+		return 0, 0, fmt.Errorf("compact size truncated")
+	}
+	var (
+		value uint64
+		n     int
+		min   uint64
+	)
+	switch data[0] {
+	case 0xfd:
+		n, min = 3, 0xfd
+		if len(data) >= n {
+			value = uint64(binary.LittleEndian.Uint16(data[1:]))
+		}
+	case 0xfe:
+		n, min = 5, math.MaxUint16+1
+		if len(data) >= n {
+			value = uint64(binary.LittleEndian.Uint32(data[1:]))
+		}
+	case 0xff:
+		n, min = 9, math.MaxUint32+1
+		if len(data) >= n {
+			value = binary.LittleEndian.Uint64(data[1:])
+		}
+	default:
+		return uint64(data[0]), 1, nil
+	}
+	if len(data) < n {
+		return 0, 0, fmt.Errorf("compact size truncated")
+	}
+	if value < min {
+		return 0, 0, fmt.Errorf("non-canonical compact size")
+	}
+	return value, n, nil
+}
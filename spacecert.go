@@ -0,0 +1,219 @@
+package libveritas
+
+import (
+	"bytes"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// .spacecert files.
+//
+// A chain is stored either as the binary output of CreateCertificateChain or
+// armored as text for pasting into tickets and chat:
+//
+//	-----BEGIN SPACECERT-----
+//	Subject: alice@bitcoin
+//
+//	<base64>
+//	-----END SPACECERT-----
+//
+// ReadSpacecert accepts both forms.
+
+// SpacecertArmorType is the PEM block type of an armored .spacecert.
+const SpacecertArmorType = "SPACECERT"
+
+// MaxSpacecertSize bounds the input read by ReadSpacecert.
+const MaxSpacecertSize = 1 << 20
+
+// ReadSpacecert reads a binary or armored .spacecert, parses it and checks it
+// with Validate. Parsing decodes the certificates natively, so without the
+// native library ReadSpacecert fails with ErrNativeUnavailable.
+func ReadSpacecert(r io.Reader) (*CertificateChain, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxSpacecertSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxSpacecertSize {
+		return nil, newInvalidInputError(CodeMalformedCertificate, fmt.Sprintf(".spacecert larger than %d bytes", MaxSpacecertSize))
+	}
+	if isArmoredSpacecert(data) {
+		if data, err = DearmorSpacecert(data); err != nil {
+			return nil, err
+		}
+	}
+	chain, err := ParseCertificateChain(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := chain.Validate(); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// WriteSpacecert writes the binary form of chain.
+func WriteSpacecert(w io.Writer, chain *CertificateChain) error {
+	if chain == nil {
+		return errNilCertificateChain
+	}
+	_, err := w.Write(chain.Raw)
+	return err
+}
+
+// WriteSpacecertArmored writes the armored text form of chain.
+func WriteSpacecertArmored(w io.Writer, chain *CertificateChain) error {
+	if chain == nil {
+		return errNilCertificateChain
+	}
+	return pem.Encode(w, spacecertBlock(chain.Subject, chain.Raw))
+}
+
+var errNilCertificateChain = newInvalidInputError(CodeMalformedCertificate, "certificate chain is nil")
+
+// ArmorSpacecert returns the armored text form of binary .spacecert bytes.
+// The subject is recorded as a header for readers; pass "" to omit it.
+func ArmorSpacecert(subject string, data []byte) []byte {
+	return pem.EncodeToMemory(spacecertBlock(subject, data))
+}
+
+// DearmorSpacecert returns the binary .spacecert in armored text. Text
+// around the block, such as a quoted email, is ignored.
+func DearmorSpacecert(text []byte) ([]byte, error) {
+	rest := text
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, newInvalidInputError(CodeMalformedCertificate, "no "+SpacecertArmorType+" block found")
+		}
+		if block.Type == SpacecertArmorType {
+			return block.Bytes, nil
+		}
+	}
+}
+
+func spacecertBlock(subject string, data []byte) *pem.Block {
+	block := &pem.Block{Type: SpacecertArmorType, Bytes: data}
+	if subject != "" {
+		block.Headers = map[string]string{"Subject": subject}
+	}
+	return block
+}
+
+func isArmoredSpacecert(data []byte) bool {
+	return bytes.Contains(data, []byte("-----BEGIN "+SpacecertArmorType+"-----"))
+}
+
+// Validate checks the structure of the chain:
+//
+//   - the subject is a valid handle and the chain has certificates,
+//   - each certificate has a subject, which is the chain's subject or a
+//     handle it is nested under,
+//   - certificates go from the space down to the subject, which comes last,
+//   - no certificate or subject appears twice.
+//
+// The error has code CodeMalformedCertificate and lists every problem found.
+func (c *CertificateChain) Validate() error {
+	if c == nil {
+		return errNilCertificateChain
+	}
+	var problems []string
+	subject, err := ParseHandle(c.Subject)
+	switch {
+	case c.Subject == "":
+		problems = append(problems, "no subject")
+	case err != nil:
+		problems = append(problems, fmt.Sprintf("subject %q is not a valid handle", c.Subject))
+	}
+	if len(c.Certificates) == 0 {
+		problems = append(problems, "no certificates")
+	}
+	seenRaw := make(map[string]int)
+	seenSubject := make(map[string]int)
+	var last Handle
+	for i, cert := range c.Certificates {
+		if j, ok := seenRaw[string(cert.Raw)]; ok {
+			problems = append(problems, fmt.Sprintf("certificate %d duplicates certificate %d", i, j))
+			continue
+		}
+		seenRaw[string(cert.Raw)] = i
+		if cert.Subject == "" {
+			problems = append(problems, fmt.Sprintf("certificate %d has no subject", i))
+			continue
+		}
+		handle, err := ParseHandle(cert.Subject)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("certificate %d: subject %q is not a valid handle", i, cert.Subject))
+			continue
+		}
+		if j, ok := seenSubject[handle.String()]; ok {
+			problems = append(problems, fmt.Sprintf("certificate %d: subject %s already certified by certificate %d", i, handle, j))
+			continue
+		}
+		seenSubject[handle.String()] = i
+		if subject.IsZero() {
+			continue
+		}
+		if !handleCovers(handle, subject) {
+			problems = append(problems, fmt.Sprintf("certificate %d: %s is not %s or a parent of it", i, handle, subject))
+			continue
+		}
+		if !last.IsZero() && !handleCovers(last, handle) {
+			problems = append(problems, fmt.Sprintf("certificate %d: %s comes after %s", i, handle, last))
+		}
+		last = handle
+	}
+	if !last.IsZero() && !last.Equal(subject) {
+		problems = append(problems, fmt.Sprintf("last certificate is for %s, not the subject %s", last, subject))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return withErrorDetails(
+		NewVeritasErrorInvalidInput("invalid certificate chain: "+strings.Join(problems, "; ")),
+		ErrorDetails{Code: CodeMalformedCertificate, Handle: c.Subject},
+	)
+}
+
+// handleCovers reports whether ancestor is handle or a handle it is nested
+// under.
+func handleCovers(ancestor, handle Handle) bool {
+	for {
+		if handle.Equal(ancestor) {
+			return true
+		}
+		parent, ok := handle.Parent()
+		if !ok {
+			return false
+		}
+		handle = parent
+	}
+}
+
+// checkSpacecert parses a .spacecert and checks it with Validate.
+func checkSpacecert(data []byte) error {
+	chain, err := ParseCertificateChain(data)
+	if err != nil {
+		return err
+	}
+	return chain.Validate()
+}
+
+// AddCertificateChain adds chain like AddChain, which validates chain.Raw.
+func (_self *MessageBuilder) AddCertificateChain(chain *CertificateChain) error {
+	if chain == nil {
+		return errNilCertificateChain
+	}
+	return _self.AddChain(chain.Raw)
+}
+
+// AddHandleChain adds chain with records like AddHandle, which validates
+// chain.Raw.
+func (_self *MessageBuilder) AddHandleChain(chain *CertificateChain, recordsBytes []byte) error {
+	if chain == nil {
+		return errNilCertificateChain
+	}
+	return _self.AddHandle(chain.Raw, recordsBytes)
+}
@@ -0,0 +1,196 @@
+package libveritas
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testChain(subject string, certSubjects ...string) *CertificateChain {
+	chain := &CertificateChain{Subject: subject, Raw: []byte("chain of " + subject)}
+	for _, s := range certSubjects {
+		chain.Certificates = append(chain.Certificates, Certificate{Subject: s, Raw: []byte("cert of " + s)})
+	}
+	return chain
+}
+
+func TestValidate(t *testing.T) {
+	valid := []*CertificateChain{
+		testChain("@bitcoin", "@bitcoin"),
+		testChain("alice@bitcoin", "@bitcoin", "alice@bitcoin"),
+		testChain("alice@bitcoin", "alice@bitcoin"),
+		testChain("bob.alice@bitcoin", "@bitcoin", "alice@bitcoin", "bob.alice@bitcoin"),
+	}
+	for _, chain := range valid {
+		if err := chain.Validate(); err != nil {
+			t.Errorf("Validate(%s): %v", chain.Subject, err)
+		}
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	duplicate := testChain("alice@bitcoin", "@bitcoin", "alice@bitcoin")
+	duplicate.Certificates = append(duplicate.Certificates, duplicate.Certificates[1])
+	recertified := testChain("@bitcoin", "@bitcoin", "@bitcoin")
+	recertified.Certificates[1].Raw = []byte("another cert of @bitcoin")
+	tests := []struct {
+		name  string
+		chain *CertificateChain
+		want  string
+	}{
+		{"nil", nil, "nil"},
+		{"no subject", testChain("", "@bitcoin"), "no subject"},
+		{"bad subject", testChain("alice", "@bitcoin"), "not a valid handle"},
+		{"no certificates", testChain("alice@bitcoin"), "no certificates"},
+		{"certificate without subject", testChain("alice@bitcoin", "@bitcoin", ""), "certificate 1 has no subject"},
+		{"bad certificate subject", testChain("alice@bitcoin", "alice"), "not a valid handle"},
+		{"foreign certificate", testChain("alice@bitcoin", "@other", "alice@bitcoin"), "not alice@bitcoin or a parent"},
+		{"wrong order", testChain("alice@bitcoin", "alice@bitcoin", "@bitcoin"), "comes after"},
+		{"missing leaf", testChain("alice@bitcoin", "@bitcoin"), "not the subject"},
+		{"duplicate", duplicate, "duplicates certificate 1"},
+		{"same subject", recertified, "already certified"},
+	}
+	for _, tt := range tests {
+		err := tt.chain.Validate()
+		if !errors.Is(err, ErrMalformedCertificate) {
+			t.Errorf("%s: error = %v, want ErrMalformedCertificate", tt.name, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error %q does not mention %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestWriteSpacecert(t *testing.T) {
+	chain := testChain("alice@bitcoin", "@bitcoin", "alice@bitcoin")
+	var buf bytes.Buffer
+	if err := WriteSpacecert(&buf, chain); err != nil {
+		t.Fatalf("WriteSpacecert: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), chain.Raw) {
+		t.Errorf("WriteSpacecert wrote %q, want %q", buf.Bytes(), chain.Raw)
+	}
+}
+
+func TestSpacecertArmorRoundTrip(t *testing.T) {
+	chain := testChain("alice@bitcoin", "@bitcoin", "alice@bitcoin")
+	var buf bytes.Buffer
+	if err := WriteSpacecertArmored(&buf, chain); err != nil {
+		t.Fatalf("WriteSpacecertArmored: %v", err)
+	}
+	text := buf.String()
+	if !strings.HasPrefix(text, "-----BEGIN SPACECERT-----\nSubject: alice@bitcoin\n") {
+		t.Errorf("armored form is\n%s", text)
+	}
+	if !isArmoredSpacecert(buf.Bytes()) {
+		t.Error("isArmoredSpacecert = false for armored output")
+	}
+	// Text around the block is ignored.
+	quoted := "> see the chain below\n" + text + "-- \nalice\n"
+	data, err := DearmorSpacecert([]byte(quoted))
+	if err != nil {
+		t.Fatalf("DearmorSpacecert: %v", err)
+	}
+	if !bytes.Equal(data, chain.Raw) {
+		t.Errorf("DearmorSpacecert = %q, want %q", data, chain.Raw)
+	}
+	if !bytes.Equal(ArmorSpacecert(chain.Subject, chain.Raw), buf.Bytes()) {
+		t.Error("ArmorSpacecert differs from WriteSpacecertArmored")
+	}
+	if armored := ArmorSpacecert("", chain.Raw); bytes.Contains(armored, []byte("Subject:")) {
+		t.Errorf("ArmorSpacecert without subject wrote a header:\n%s", armored)
+	}
+}
+
+func TestDearmorSpacecertErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"no armor here",
+		"-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n",
+		"-----BEGIN SPACECERT-----\n!!!\n-----END SPACECERT-----\n",
+	} {
+		if _, err := DearmorSpacecert([]byte(text)); !errors.Is(err, ErrMalformedCertificate) {
+			t.Errorf("DearmorSpacecert(%q) = %v, want ErrMalformedCertificate", text, err)
+		}
+	}
+	// Other blocks before the chain are skipped.
+	text := "-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n" + string(ArmorSpacecert("", []byte{1, 2}))
+	if data, err := DearmorSpacecert([]byte(text)); err != nil || !bytes.Equal(data, []byte{1, 2}) {
+		t.Errorf("DearmorSpacecert = %x, %v", data, err)
+	}
+}
+
+func TestWriteSpacecertNilChain(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSpacecert(&buf, nil); !errors.Is(err, ErrMalformedCertificate) {
+		t.Errorf("WriteSpacecert(nil) = %v, want ErrMalformedCertificate", err)
+	}
+	if err := WriteSpacecertArmored(&buf, nil); !errors.Is(err, ErrMalformedCertificate) {
+		t.Errorf("WriteSpacecertArmored(nil) = %v, want ErrMalformedCertificate", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wrote %q for a nil chain", buf.Bytes())
+	}
+}
+
+func TestReadSpacecertLimits(t *testing.T) {
+	big := bytes.Repeat([]byte{0}, MaxSpacecertSize+1)
+	if _, err := ReadSpacecert(bytes.NewReader(big)); !errors.Is(err, ErrMalformedCertificate) {
+		t.Errorf("ReadSpacecert of oversized input = %v, want ErrMalformedCertificate", err)
+	}
+	if _, err := ReadSpacecert(strings.NewReader("-----BEGIN SPACECERT-----\n!!!\n-----END SPACECERT-----\n")); !errors.Is(err, ErrMalformedCertificate) {
+		t.Errorf("ReadSpacecert of broken armor = %v, want ErrMalformedCertificate", err)
+	}
+}
+
+func TestNativeReadSpacecert(t *testing.T) {
+	data := certificateFixture(t)
+	cert, err := ParseCertificate(data)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	chain, err := NewCertificateChain(cert.Subject, [][]byte{data})
+	if err != nil {
+		t.Fatalf("NewCertificateChain: %v", err)
+	}
+	var binary, armored bytes.Buffer
+	if err := WriteSpacecert(&binary, chain); err != nil {
+		t.Fatalf("WriteSpacecert: %v", err)
+	}
+	if err := WriteSpacecertArmored(&armored, chain); err != nil {
+		t.Fatalf("WriteSpacecertArmored: %v", err)
+	}
+	for name, r := range map[string]*bytes.Buffer{"binary": &binary, "armored": &armored} {
+		read, err := ReadSpacecert(r)
+		if err != nil {
+			t.Fatalf("ReadSpacecert %s: %v", name, err)
+		}
+		if read.Subject != chain.Subject || !bytes.Equal(read.Raw, chain.Raw) || len(read.Certificates) != 1 {
+			t.Errorf("ReadSpacecert %s = %+v, want %+v", name, read, chain)
+		}
+	}
+
+	builder := NewMessageBuilder()
+	defer builder.Destroy()
+	if err := builder.AddCertificateChain(chain); err != nil {
+		t.Errorf("AddCertificateChain: %v", err)
+	}
+}
+
+func TestNativeMessageBuilderValidatesChains(t *testing.T) {
+	if !NativeAvailable() {
+		t.Skip("native library not available")
+	}
+	builder := NewMessageBuilder()
+	defer builder.Destroy()
+	for name, add := range map[string]func([]byte) error{
+		"AddChain":  builder.AddChain,
+		"AddHandle": func(chain []byte) error { return builder.AddHandle(chain, nil) },
+	} {
+		if err := add([]byte{1, 2, 3}); !errors.Is(err, ErrMalformedCertificate) {
+			t.Errorf("%s of a malformed chain = %v, want ErrMalformedCertificate", name, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package libveritas
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// VerifyOptions selects optional verification behaviour. Options add to the
+// library defaults; the zero value verifies with the defaults only.
+//
+// The bits are defined by this package and translated to the native flags
+// (VerifyDevMode, VerifyEnableSnark) when a verification runs, so values can
+// be stored in configuration and passed between processes.
+type VerifyOptions uint32
+
+const (
+	// Accept development-mode proofs.
+	VerifyOptionDevMode VerifyOptions = 1 << iota
+	// Verify SNARK proofs.
+	VerifyOptionEnableSnark
+
+	// VerifyOptionsDefault verifies with the library defaults.
+	VerifyOptionsDefault VerifyOptions = 0
+
+	verifyOptionsAll = VerifyOptionDevMode | VerifyOptionEnableSnark
+)
+
+// Option names, as used by String and ParseVerifyOptions.
+var verifyOptionNames = []struct {
+	option VerifyOptions
+	name   string
+}{
+	{VerifyOptionDevMode, "dev-mode"},
+	{VerifyOptionEnableSnark, "snark"},
+}
+
+const verifyOptionsDefaultName = "default"
+
+// ParseVerifyOptions parses a comma-separated list of option names, such as
+// "dev-mode,snark". An empty string or "default" yields VerifyOptionsDefault.
+func ParseVerifyOptions(s string) (VerifyOptions, error) {
+	var options VerifyOptions
+	for _, name := range strings.Split(s, ",") {
+		option, err := parseVerifyOption(strings.TrimSpace(name))
+		if err != nil {
+			return 0, err
+		}
+		options |= option
+	}
+	return options, nil
+}
+
+func parseVerifyOption(name string) (VerifyOptions, error) {
+	if name == "" || name == verifyOptionsDefaultName {
+		return 0, nil
+	}
+	for _, known := range verifyOptionNames {
+		if name == known.name {
+			return known.option, nil
+		}
+	}
+	return 0, newInvalidInputError(CodeInvalidInput, fmt.Sprintf("unknown verify option %q", name))
+}
+
+// Validate rejects bits that do not name a known option.
+func (o VerifyOptions) Validate() error {
+	if unknown := o &^ verifyOptionsAll; unknown != 0 {
+		return newInvalidInputError(CodeInvalidInput, fmt.Sprintf("unknown verify option bits %#x", uint32(unknown)))
+	}
+	return nil
+}
+
+// Has reports whether all options in other are set.
+func (o VerifyOptions) Has(other VerifyOptions) bool {
+	return o&other == other
+}
+
+// Names returns the names of the set options. Unknown bits are rendered in
+// hex.
+func (o VerifyOptions) Names() []string {
+	var names []string
+	for _, known := range verifyOptionNames {
+		if o.Has(known.option) {
+			names = append(names, known.name)
+		}
+	}
+	if unknown := o &^ verifyOptionsAll; unknown != 0 {
+		names = append(names, fmt.Sprintf("%#x", uint32(unknown)))
+	}
+	return names
+}
+
+// String returns the comma-separated option names, or "default".
+func (o VerifyOptions) String() string {
+	if o == 0 {
+		return verifyOptionsDefaultName
+	}
+	return strings.Join(o.Names(), ",")
+}
+
+// Set implements flag.Value, so options can be given as -verify=dev-mode,snark.
+func (o *VerifyOptions) Set(s string) error {
+	options, err := ParseVerifyOptions(s)
+	if err != nil {
+		return err
+	}
+	*o = options
+	return nil
+}
+
+func (o VerifyOptions) MarshalText() ([]byte, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	return []byte(o.String()), nil
+}
+
+func (o *VerifyOptions) UnmarshalText(text []byte) error {
+	return o.Set(string(text))
+}
+
+// MarshalJSON encodes the options as an array of names, e.g. ["snark"].
+func (o VerifyOptions) MarshalJSON() ([]byte, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	names := o.Names()
+	if names == nil {
+		names = []string{}
+	}
+	return json.Marshal(names)
+}
+
+// UnmarshalJSON accepts an array of names or a comma-separated string.
+func (o *VerifyOptions) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		var s string
+		if json.Unmarshal(data, &s) != nil {
+			return newInvalidInputError(CodeInvalidInput, "verify options must be an array of names or a string")
+		}
+		return o.Set(s)
+	}
+	return o.setNames(names)
+}
+
+// UnmarshalYAML accepts a sequence of names or a comma-separated string. It
+// implements the unmarshaler interface understood by the common YAML
+// packages, so that configs can say `verify: [snark]`.
+func (o *VerifyOptions) UnmarshalYAML(unmarshal func(any) error) error {
+	var names []string
+	if err := unmarshal(&names); err != nil {
+		var s string
+		if unmarshal(&s) != nil {
+			return newInvalidInputError(CodeInvalidInput, "verify options must be a sequence of names or a string")
+		}
+		return o.Set(s)
+	}
+	return o.setNames(names)
+}
+
+func (o *VerifyOptions) setNames(names []string) error {
+	var options VerifyOptions
+	for _, name := range names {
+		option, err := parseVerifyOption(name)
+		if err != nil {
+			return err
+		}
+		options |= option
+	}
+	*o = options
+	return nil
+}
+
+// native translates the options to the flags of the native library.
+func (o VerifyOptions) native() uint32 {
+	flags := VerifyDefault()
+	if o.Has(VerifyOptionDevMode) {
+		flags |= VerifyDevMode()
+	}
+	if o.Has(VerifyOptionEnableSnark) {
+		flags |= VerifyEnableSnark()
+	}
+	return flags
+}
@@ -0,0 +1,77 @@
+package libveritas
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseVerifyOptions(t *testing.T) {
+	tests := []struct {
+		in   string
+		want VerifyOptions
+	}{
+		{"", VerifyOptionsDefault},
+		{"default", VerifyOptionsDefault},
+		{"dev-mode", VerifyOptionDevMode},
+		{"snark, dev-mode", VerifyOptionDevMode | VerifyOptionEnableSnark},
+	}
+	for _, tt := range tests {
+		got, err := ParseVerifyOptions(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseVerifyOptions(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := ParseVerifyOptions("fast"); !errors.Is(err, ErrVeritasErrorInvalidInput) {
+		t.Errorf("ParseVerifyOptions(fast) = %v, want ErrVeritasErrorInvalidInput", err)
+	}
+}
+
+func TestVerifyOptionsString(t *testing.T) {
+	tests := []struct {
+		options VerifyOptions
+		want    string
+	}{
+		{VerifyOptionsDefault, "default"},
+		{VerifyOptionEnableSnark, "snark"},
+		{VerifyOptionDevMode | VerifyOptionEnableSnark, "dev-mode,snark"},
+		{VerifyOptionDevMode | 0x10, "dev-mode,0x10"},
+	}
+	for _, tt := range tests {
+		if got := tt.options.String(); got != tt.want {
+			t.Errorf("String(%#x) = %q, want %q", uint32(tt.options), got, tt.want)
+		}
+	}
+}
+
+func TestVerifyOptionsValidate(t *testing.T) {
+	if err := (VerifyOptionDevMode | VerifyOptionEnableSnark).Validate(); err != nil {
+		t.Errorf("Validate of known options: %v", err)
+	}
+	if err := VerifyOptions(0x10).Validate(); !errors.Is(err, ErrVeritasErrorInvalidInput) {
+		t.Errorf("Validate of unknown bits = %v, want ErrVeritasErrorInvalidInput", err)
+	}
+}
+
+func TestVerifyOptionsJSON(t *testing.T) {
+	data, err := json.Marshal(VerifyOptionDevMode | VerifyOptionEnableSnark)
+	if err != nil || string(data) != `["dev-mode","snark"]` {
+		t.Errorf("Marshal = %s, %v", data, err)
+	}
+	if data, err := json.Marshal(VerifyOptionsDefault); err != nil || string(data) != `[]` {
+		t.Errorf("Marshal of default = %s, %v", data, err)
+	}
+	if _, err := json.Marshal(VerifyOptions(0x10)); err == nil {
+		t.Error("Marshal of unknown bits succeeded")
+	}
+	for _, in := range []string{`["snark"]`, `"snark"`} {
+		var options VerifyOptions
+		if err := json.Unmarshal([]byte(in), &options); err != nil || options != VerifyOptionEnableSnark {
+			t.Errorf("Unmarshal(%s) = %v, %v", in, options, err)
+		}
+	}
+	var options VerifyOptions
+	if err := json.Unmarshal([]byte(`3`), &options); err == nil {
+		t.Error("Unmarshal of a number succeeded")
+	}
+}
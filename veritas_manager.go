@@ -0,0 +1,330 @@
+package libveritas
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AtomicVeritas holds a Veritas that can be replaced while calls are in
+// flight. Calls run against the Veritas current when they start; a replaced
+// Veritas is destroyed once its last call returns.
+type AtomicVeritas struct {
+	current atomic.Pointer[veritasRef]
+}
+
+// veritasRef guards a Veritas against being destroyed while in use: calls
+// hold the read lock, retire takes the write lock.
+type veritasRef struct {
+	veritas *Veritas
+	mu      sync.RWMutex
+	retired bool
+}
+
+// NewAtomicVeritas returns an AtomicVeritas holding veritas, which it now
+// owns.
+func NewAtomicVeritas(veritas *Veritas) *AtomicVeritas {
+	a := &AtomicVeritas{}
+	a.Swap(veritas)
+	return a
+}
+
+// Swap installs veritas and returns without waiting for calls on the
+// previous one, which is destroyed in the background when they are done.
+// Swapping in nil has the effect of Close, without waiting.
+func (a *AtomicVeritas) Swap(veritas *Veritas) {
+	var next *veritasRef
+	if veritas != nil {
+		next = &veritasRef{veritas: veritas}
+	}
+	if prev := a.current.Swap(next); prev != nil {
+		go prev.retire()
+	}
+}
+
+func (r *veritasRef) retire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retired = true
+	r.veritas.Destroy()
+}
+
+// acquire returns the current Veritas read-locked, retrying when it was
+// retired between loading and locking it.
+func (a *AtomicVeritas) acquire() (*veritasRef, error) {
+	for {
+		ref := a.current.Load()
+		if ref == nil {
+			return nil, ErrVeritasClosed
+		}
+		ref.mu.RLock()
+		if !ref.retired {
+			return ref, nil
+		}
+		ref.mu.RUnlock()
+	}
+}
+
+// ErrVeritasClosed is returned by calls on a closed AtomicVeritas or
+// VeritasManager.
+var ErrVeritasClosed = fmt.Errorf("libveritas: veritas closed")
+
+// Do calls fn with the current Veritas, which stays valid until fn returns.
+// fn must not keep it, and must not call a again: a pending Swap blocks
+// nested calls.
+func (a *AtomicVeritas) Do(fn func(*Veritas) error) error {
+	ref, err := a.acquire()
+	if err != nil {
+		return err
+	}
+	defer ref.mu.RUnlock()
+	return fn(ref.veritas)
+}
+
+// Verify is Veritas.Verify on the current Veritas.
+func (a *AtomicVeritas) Verify(ctx *QueryContext, msg *Message) (*VerifiedMessage, error) {
+	var verified *VerifiedMessage
+	err := a.Do(func(veritas *Veritas) (err error) {
+		verified, err = veritas.Verify(ctx, msg)
+		return err
+	})
+	return verified, err
+}
+
+// VerifyWith is Veritas.VerifyWith on the current Veritas.
+func (a *AtomicVeritas) VerifyWith(ctx *QueryContext, msg *Message, options VerifyOptions) (*VerifiedMessage, error) {
+	var verified *VerifiedMessage
+	err := a.Do(func(veritas *Veritas) (err error) {
+		verified, err = veritas.VerifyWith(ctx, msg, options)
+		return err
+	})
+	return verified, err
+}
+
+// VerifyContext is VerifyWith bounded by ctx.
+func (a *AtomicVeritas) VerifyContext(ctx context.Context, qctx *QueryContext, msg *Message, options VerifyOptions) (*VerifiedMessage, error) {
+	return runContext(ctx, func() (*VerifiedMessage, error) {
+		return a.VerifyWith(qctx, msg, options)
+	}, (*VerifiedMessage).Destroy)
+}
+
+// NewestAnchor is Veritas.NewestAnchor on the current Veritas, or 0 once
+// closed.
+func (a *AtomicVeritas) NewestAnchor() uint32 {
+	var height uint32
+	a.Do(func(veritas *Veritas) error {
+		height = veritas.NewestAnchor()
+		return nil
+	})
+	return height
+}
+
+// OldestAnchor is Veritas.OldestAnchor on the current Veritas, or 0 once
+// closed.
+func (a *AtomicVeritas) OldestAnchor() uint32 {
+	var height uint32
+	a.Do(func(veritas *Veritas) error {
+		height = veritas.OldestAnchor()
+		return nil
+	})
+	return height
+}
+
+// Close destroys the current Veritas after its calls return. Later calls
+// fail with ErrVeritasClosed.
+func (a *AtomicVeritas) Close() error {
+	if prev := a.current.Swap(nil); prev != nil {
+		prev.retire()
+	}
+	return nil
+}
+
+// AnchorSource delivers anchor sets to a VeritasManager.
+type AnchorSource interface {
+	// Next blocks until a set newer than the one returned last is
+	// available, and returns it. The first call returns the current set.
+	Next(ctx context.Context) (AnchorSet, error)
+}
+
+// VeritasEvent reports a refresh attempt of a VeritasManager.
+type VeritasEvent struct {
+	// NewestAnchor of the Veritas before and after the refresh. They are
+	// equal when the refresh failed.
+	OldNewestAnchor uint32
+	NewNewestAnchor uint32
+	// Anchors is the set the refresh was attempted with, nil if the source
+	// failed.
+	Anchors AnchorSet
+	// Err is the error of the source or of building the Veritas.
+	Err error
+}
+
+// DefaultVeritasRetryDelay is the pause after a failed refresh.
+const DefaultVeritasRetryDelay = 5 * time.Second
+
+// VeritasManagerConfig configures a VeritasManager.
+type VeritasManagerConfig struct {
+	// OnEvent is called after every refresh attempt, from the manager's
+	// goroutine. Optional.
+	OnEvent func(VeritasEvent)
+	// RetryDelay is the pause after a failed refresh. Zero selects
+	// DefaultVeritasRetryDelay.
+	RetryDelay time.Duration
+}
+
+// VeritasManager keeps a Veritas up to date with an AnchorSource, building
+// each new Veritas in the background. Its calls behave like those of an
+// AtomicVeritas, which only the manager swaps.
+type VeritasManager struct {
+	veritas *AtomicVeritas
+	source  AnchorSource
+	config  VeritasManagerConfig
+	anchors string
+	// newest is the NewestAnchor of the current Veritas.
+	newest uint32
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// build and newestAnchor are newVeritasFromJson and
+	// (*Veritas).NewestAnchor, replaced in tests.
+	build        func(anchorsJson string) (*Veritas, error)
+	newestAnchor func(*Veritas) uint32
+}
+
+// StartVeritasManager waits for the first anchor set from source, builds a
+// Veritas from it and starts refreshing it. ctx bounds the first set only;
+// the refresh runs until Close.
+func StartVeritasManager(ctx context.Context, source AnchorSource, config VeritasManagerConfig) (*VeritasManager, error) {
+	m := &VeritasManager{
+		source:       source,
+		config:       config,
+		build:        newVeritasFromJson,
+		newestAnchor: (*Veritas).NewestAnchor,
+	}
+	if err := m.start(ctx); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *VeritasManager) start(ctx context.Context) error {
+	if m.config.RetryDelay <= 0 {
+		m.config.RetryDelay = DefaultVeritasRetryDelay
+	}
+	set, err := m.source.Next(ctx)
+	if err != nil {
+		return err
+	}
+	anchorsJson, err := set.Json()
+	if err != nil {
+		return err
+	}
+	veritas, err := m.build(anchorsJson)
+	if err != nil {
+		return err
+	}
+	m.veritas = NewAtomicVeritas(veritas)
+	m.anchors = anchorsJson
+	m.newest = m.newestAnchor(veritas)
+	runCtx, cancel := context.WithCancel(context.Background())
+	m.cancel, m.done = cancel, make(chan struct{})
+	go m.run(runCtx)
+	return nil
+}
+
+func (m *VeritasManager) run(ctx context.Context) {
+	defer close(m.done)
+	for {
+		set, err := m.source.Next(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = m.refresh(set)
+		} else {
+			m.emit(VeritasEvent{OldNewestAnchor: m.newest, NewNewestAnchor: m.newest, Err: err})
+		}
+		if err != nil && sleepContext(ctx, m.config.RetryDelay) != nil {
+			return
+		}
+	}
+}
+
+// refresh swaps in a Veritas built from set, unless set is unchanged.
+func (m *VeritasManager) refresh(set AnchorSet) error {
+	old := m.newest
+	anchorsJson, err := set.Json()
+	if err == nil && anchorsJson == m.anchors {
+		return nil
+	}
+	var veritas *Veritas
+	if err == nil {
+		veritas, err = m.build(anchorsJson)
+	}
+	if err != nil {
+		m.emit(VeritasEvent{OldNewestAnchor: old, NewNewestAnchor: old, Anchors: set, Err: err})
+		return err
+	}
+	newest := m.newestAnchor(veritas)
+	m.veritas.Swap(veritas)
+	m.anchors, m.newest = anchorsJson, newest
+	m.emit(VeritasEvent{OldNewestAnchor: old, NewNewestAnchor: newest, Anchors: set})
+	return nil
+}
+
+func (m *VeritasManager) emit(event VeritasEvent) {
+	if m.config.OnEvent != nil {
+		m.config.OnEvent(event)
+	}
+}
+
+// Do is AtomicVeritas.Do on the current Veritas.
+func (m *VeritasManager) Do(fn func(*Veritas) error) error {
+	return m.veritas.Do(fn)
+}
+
+// Verify is Veritas.Verify on the current Veritas.
+func (m *VeritasManager) Verify(ctx *QueryContext, msg *Message) (*VerifiedMessage, error) {
+	return m.veritas.Verify(ctx, msg)
+}
+
+// VerifyWith is Veritas.VerifyWith on the current Veritas.
+func (m *VeritasManager) VerifyWith(ctx *QueryContext, msg *Message, options VerifyOptions) (*VerifiedMessage, error) {
+	return m.veritas.VerifyWith(ctx, msg, options)
+}
+
+// VerifyContext is VerifyWith bounded by ctx.
+func (m *VeritasManager) VerifyContext(ctx context.Context, qctx *QueryContext, msg *Message, options VerifyOptions) (*VerifiedMessage, error) {
+	return m.veritas.VerifyContext(ctx, qctx, msg, options)
+}
+
+// NewestAnchor is Veritas.NewestAnchor on the current Veritas, or 0 once
+// closed.
+func (m *VeritasManager) NewestAnchor() uint32 {
+	return m.veritas.NewestAnchor()
+}
+
+// OldestAnchor is Veritas.OldestAnchor on the current Veritas, or 0 once
+// closed.
+func (m *VeritasManager) OldestAnchor() uint32 {
+	return m.veritas.OldestAnchor()
+}
+
+// Close stops the refresh and destroys the current Veritas after its calls
+// return. Later calls fail with ErrVeritasClosed.
+func (m *VeritasManager) Close() error {
+	m.cancel()
+	<-m.done
+	return m.veritas.Close()
+}
+
+func newVeritasFromJson(anchorsJson string) (*Veritas, error) {
+	anchors, err := AnchorsFromJson(anchorsJson)
+	if err != nil {
+		return nil, err
+	}
+	defer anchors.Destroy()
+	return NewVeritas(anchors)
+}
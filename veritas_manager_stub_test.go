@@ -0,0 +1,247 @@
+//go:build !cgo || libveritas_nocgo || !(libveritas_dynamic || libveritas_pkgconfig || (linux && amd64) || (darwin && arm64) || (windows && amd64))
+
+package libveritas
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The tests below swap and destroy &Veritas{} values, which only the stub
+// build can do without native objects.
+
+func isRetiredNow(ref *veritasRef) bool {
+	ref.mu.RLock()
+	defer ref.mu.RUnlock()
+	return ref.retired
+}
+
+// isRetired reports whether ref was retired, waiting for up to a second.
+func isRetired(ref *veritasRef) bool {
+	for deadline := time.Now().Add(time.Second); ; {
+		retired := isRetiredNow(ref)
+		if retired || time.Now().After(deadline) {
+			return retired
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAtomicVeritasSwapDuringCall(t *testing.T) {
+	a := NewAtomicVeritas(&Veritas{})
+	first := a.current.Load()
+
+	entered, release := make(chan struct{}), make(chan struct{})
+	retiredInCall := make(chan bool, 1)
+	done := make(chan error)
+	go func() {
+		done <- a.Do(func(*Veritas) error {
+			close(entered)
+			<-release
+			// Do holds the read lock, so retire cannot have run.
+			retiredInCall <- first.retired
+			return nil
+		})
+	}()
+	<-entered
+
+	// Swap returns without waiting for the call.
+	a.Swap(&Veritas{})
+	second := a.current.Load()
+	if second == first {
+		t.Fatal("Swap did not install a new Veritas")
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("in-flight Do: %v", err)
+	}
+	if <-retiredInCall {
+		t.Error("Veritas retired while a call was in flight")
+	}
+	if !isRetired(first) {
+		t.Error("replaced Veritas not retired after its last call")
+	}
+	if err := a.Do(func(*Veritas) error { return nil }); err != nil {
+		t.Errorf("Do after Swap: %v", err)
+	}
+	if isRetiredNow(second) {
+		t.Error("current Veritas retired")
+	}
+}
+
+func TestAtomicVeritasAcquireRetries(t *testing.T) {
+	a := NewAtomicVeritas(&Veritas{})
+	stale := a.current.Load()
+	stale.retire() // Retired, but still current until the store below.
+
+	fresh := &veritasRef{veritas: &Veritas{}}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		a.current.Store(fresh)
+	}()
+	ref, err := a.acquire()
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	ref.mu.RUnlock()
+	if ref != fresh {
+		t.Error("acquire returned a retired Veritas")
+	}
+}
+
+func TestAtomicVeritasClose(t *testing.T) {
+	a := NewAtomicVeritas(&Veritas{})
+	ref := a.current.Load()
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !isRetiredNow(ref) {
+		t.Error("Close did not retire the Veritas")
+	}
+	if err := a.Do(func(*Veritas) error { return nil }); !errors.Is(err, ErrVeritasClosed) {
+		t.Errorf("Do after Close = %v, want ErrVeritasClosed", err)
+	}
+}
+
+// chanAnchorSource returns the sets, or errors, sent on its channel.
+type chanAnchorSource chan any
+
+func (s chanAnchorSource) Next(ctx context.Context) (AnchorSet, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case v := <-s:
+		if err, ok := v.(error); ok {
+			return nil, err
+		}
+		return v.(AnchorSet), nil
+	}
+}
+
+var errTestBuild = errors.New("cannot build veritas")
+
+// startTestManager starts a manager whose Veritas values report the newest
+// height of the set they are built from. Sets with an entry at height 0
+// fail to build.
+func startTestManager(t *testing.T, source chanAnchorSource) (*VeritasManager, chan VeritasEvent) {
+	t.Helper()
+	events := make(chan VeritasEvent, 16)
+	var mu sync.Mutex
+	var height uint32
+	m := &VeritasManager{
+		source: source,
+		config: VeritasManagerConfig{
+			OnEvent:    func(event VeritasEvent) { events <- event },
+			RetryDelay: time.Millisecond,
+		},
+		build: func(anchorsJson string) (*Veritas, error) {
+			set, err := ParseAnchorSet(anchorsJson)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := set.At(0); ok {
+				return nil, errTestBuild
+			}
+			newest, _ := set.Newest()
+			mu.Lock()
+			height = newest.Height
+			mu.Unlock()
+			return &Veritas{}, nil
+		},
+		newestAnchor: func(*Veritas) uint32 {
+			mu.Lock()
+			defer mu.Unlock()
+			return height
+		},
+	}
+	if err := m.start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m, events
+}
+
+func nextEvent(t *testing.T, events chan VeritasEvent) VeritasEvent {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+		return VeritasEvent{}
+	}
+}
+
+func TestVeritasManagerRefresh(t *testing.T) {
+	source := make(chanAnchorSource, 1)
+	source <- AnchorSet{testAnchor(10, 1)}
+	m, events := startTestManager(t, source)
+	initial := m.veritas.current.Load()
+
+	source <- AnchorSet{testAnchor(10, 1), testAnchor(20, 2)}
+	event := nextEvent(t, events)
+	if event.Err != nil || event.OldNewestAnchor != 10 || event.NewNewestAnchor != 20 || len(event.Anchors) != 2 {
+		t.Errorf("event = %+v, want 10 -> 20", event)
+	}
+	if m.veritas.current.Load() == initial || !isRetired(initial) {
+		t.Error("refresh did not swap and retire the Veritas")
+	}
+
+	// An unchanged set is not rebuilt and reports nothing; the next event
+	// is the one of the set after it.
+	source <- AnchorSet{testAnchor(10, 1), testAnchor(20, 2)}
+	source <- AnchorSet{testAnchor(30, 3)}
+	event = nextEvent(t, events)
+	if event.Err != nil || event.OldNewestAnchor != 20 || event.NewNewestAnchor != 30 {
+		t.Errorf("event = %+v, want 20 -> 30", event)
+	}
+}
+
+func TestVeritasManagerRefreshErrors(t *testing.T) {
+	source := make(chanAnchorSource, 1)
+	source <- AnchorSet{testAnchor(10, 1)}
+	m, events := startTestManager(t, source)
+	current := m.veritas.current.Load()
+
+	source <- AnchorSet{testAnchor(0, 9)}
+	event := nextEvent(t, events)
+	if !errors.Is(event.Err, errTestBuild) || event.OldNewestAnchor != 10 || event.NewNewestAnchor != 10 || event.Anchors == nil {
+		t.Errorf("build failure event = %+v", event)
+	}
+
+	sourceErr := errors.New("source down")
+	source <- sourceErr
+	event = nextEvent(t, events)
+	if !errors.Is(event.Err, sourceErr) || event.OldNewestAnchor != 10 || event.NewNewestAnchor != 10 || event.Anchors != nil {
+		t.Errorf("source failure event = %+v", event)
+	}
+
+	if m.veritas.current.Load() != current {
+		t.Error("a failed refresh replaced the Veritas")
+	}
+	// The manager keeps running after failures.
+	source <- AnchorSet{testAnchor(15, 5)}
+	if event := nextEvent(t, events); event.Err != nil || event.NewNewestAnchor != 15 {
+		t.Errorf("event after failures = %+v", event)
+	}
+}
+
+func TestVeritasManagerClose(t *testing.T) {
+	source := make(chanAnchorSource, 1)
+	source <- AnchorSet{testAnchor(10, 1)}
+	m, _ := startTestManager(t, source)
+	ref := m.veritas.current.Load()
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !isRetiredNow(ref) {
+		t.Error("Close did not retire the Veritas")
+	}
+	if _, err := m.Verify(nil, nil); !errors.Is(err, ErrVeritasClosed) {
+		t.Errorf("Verify after Close = %v, want ErrVeritasClosed", err)
+	}
+}
@@ -0,0 +1,45 @@
+package libveritas
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type failingAnchorSource struct{ err error }
+
+func (s failingAnchorSource) Next(context.Context) (AnchorSet, error) {
+	return nil, s.err
+}
+
+func TestAtomicVeritasClosed(t *testing.T) {
+	a := NewAtomicVeritas(nil)
+	if err := a.Do(func(*Veritas) error { return nil }); !errors.Is(err, ErrVeritasClosed) {
+		t.Errorf("Do = %v, want ErrVeritasClosed", err)
+	}
+	if _, err := a.Verify(nil, nil); !errors.Is(err, ErrVeritasClosed) {
+		t.Errorf("Verify = %v, want ErrVeritasClosed", err)
+	}
+	if a.NewestAnchor() != 0 || a.OldestAnchor() != 0 {
+		t.Error("anchors of a closed AtomicVeritas are not 0")
+	}
+	if err := a.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+func TestStartVeritasManagerSourceError(t *testing.T) {
+	sourceErr := errors.New("no anchors")
+	m, err := StartVeritasManager(context.Background(), failingAnchorSource{sourceErr}, VeritasManagerConfig{})
+	if !errors.Is(err, sourceErr) || m != nil {
+		t.Errorf("StartVeritasManager = %v, %v, want the source error", m, err)
+	}
+}
+
+func TestVeritasManagerHidesSwap(t *testing.T) {
+	// Only the manager may replace its Veritas.
+	if _, ok := reflect.TypeOf(&VeritasManager{}).MethodByName("Swap"); ok {
+		t.Error("VeritasManager exposes Swap")
+	}
+}
@@ -0,0 +1,177 @@
+package libveritas
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+// Stored zone encoding.
+//
+// MarshalBinary wraps the bytes of ZoneToBytes in an envelope
+//
+//	magic "LVZ" | version:u8 | payload | crc32:u32be
+//
+// where the CRC-32 (IEEE) covers everything before it. The version names the
+// payload format, so that blobs written today can still be read after the
+// native encoding changes. Bare ZoneToBytes output, as stored before the
+// envelope existed, is recognized by the missing magic and read as version 0.
+// A legacy blob can start with the magic by chance, so data that cannot be
+// an envelope, being too short or of an unknown version, is still tried as
+// a legacy blob before it is rejected. An envelope whose checksum does not
+// match is rejected as corrupt.
+
+var (
+	_ encoding.BinaryMarshaler   = Zone{}
+	_ encoding.BinaryUnmarshaler = (*Zone)(nil)
+	_ sql.Scanner                = (*Zone)(nil)
+	_ driver.Valuer              = Zone{}
+)
+
+const (
+	// Payload format of bare ZoneToBytes output without an envelope.
+	zoneBlobVersionLegacy uint8 = 0
+	// Payload format 1: ZoneToBytes output.
+	zoneBlobVersion1 uint8 = 1
+
+	// ZoneBlobVersion is the envelope version written by MarshalBinary.
+	ZoneBlobVersion = zoneBlobVersion1
+)
+
+var zoneBlobMagic = []byte("LVZ")
+
+const zoneBlobOverhead = 3 + 1 + 4
+
+func (r Zone) MarshalBinary() ([]byte, error) {
+	payload, err := ZoneToBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	return sealZoneBlob(ZoneBlobVersion, payload), nil
+}
+
+// UnmarshalBinary reads an enveloped zone or a bare ZoneToBytes blob. Use
+// ReadZoneBlob to learn whether the blob should be rewritten.
+func (r *Zone) UnmarshalBinary(data []byte) error {
+	zone, _, err := readZoneBlob(data, DecodeZone)
+	if err != nil {
+		return err
+	}
+	*r = zone
+	return nil
+}
+
+// ReadZoneBlob reads a stored zone like UnmarshalBinary. outdated reports
+// that data is not in the current envelope version, so that the caller can
+// store it again with MarshalBinary or UpgradeZoneBlob.
+func ReadZoneBlob(data []byte) (zone Zone, outdated bool, err error) {
+	return readZoneBlob(data, DecodeZone)
+}
+
+// Scan implements sql.Scanner for BYTEA/BLOB columns. NULL is an error; to
+// allow it, scan into a *Zone variable, which database/sql sets to nil.
+func (r *Zone) Scan(src any) error {
+	switch src := src.(type) {
+	case []byte:
+		return r.UnmarshalBinary(src)
+	case string:
+		return r.UnmarshalBinary([]byte(src))
+	case nil:
+		return newInvalidInputError(CodeInvalidInput, "cannot scan NULL into Zone")
+	default:
+		return newInvalidInputError(CodeInvalidInput, fmt.Sprintf("cannot scan %T into Zone", src))
+	}
+}
+
+// Value implements driver.Valuer, storing the MarshalBinary encoding.
+func (r Zone) Value() (driver.Value, error) {
+	return r.MarshalBinary()
+}
+
+// UpgradeZoneBlob rewrites a stored zone in the current envelope version.
+// It reports whether the blob changed; current blobs are returned as is.
+func UpgradeZoneBlob(data []byte) ([]byte, bool, error) {
+	return upgradeZoneBlob(data, DecodeZone, ZoneToBytes)
+}
+
+func upgradeZoneBlob(data []byte, decode func([]byte) (Zone, error), encode func(Zone) ([]byte, error)) ([]byte, bool, error) {
+	if version, _, _, err := openZoneBlob(data); err == nil && version == ZoneBlobVersion {
+		return data, false, nil
+	}
+	zone, _, err := readZoneBlob(data, decode)
+	if err != nil {
+		return nil, false, err
+	}
+	payload, err := encode(zone)
+	if err != nil {
+		return nil, false, err
+	}
+	return sealZoneBlob(ZoneBlobVersion, payload), true, nil
+}
+
+func sealZoneBlob(version uint8, payload []byte) []byte {
+	out := make([]byte, 0, zoneBlobOverhead+len(payload))
+	out = append(out, zoneBlobMagic...)
+	out = append(out, version)
+	out = append(out, payload...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out))
+}
+
+// openZoneBlob checks the envelope and returns the payload version and
+// bytes. Data without the magic is a legacy blob. maybeLegacy reports an
+// error for data that cannot be an envelope, being too short or of an
+// unknown version, and so may be a legacy blob that starts with the magic
+// by chance. A checksum mismatch is corruption and not such an error.
+func openZoneBlob(data []byte) (version uint8, payload []byte, maybeLegacy bool, err error) {
+	if !bytes.HasPrefix(data, zoneBlobMagic) {
+		return zoneBlobVersionLegacy, data, false, nil
+	}
+	if len(data) < zoneBlobOverhead {
+		return 0, nil, true, newInvalidInputError(CodeInvalidInput, "zone blob truncated")
+	}
+	version = data[len(zoneBlobMagic)]
+	if version == zoneBlobVersionLegacy || version > ZoneBlobVersion {
+		return 0, nil, true, newInvalidInputError(CodeInvalidInput, fmt.Sprintf("unsupported zone blob version %d", version))
+	}
+	body, sum := data[:len(data)-4], binary.BigEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return 0, nil, false, newInvalidInputError(CodeInvalidInput, "zone blob checksum mismatch")
+	}
+	return version, body[len(zoneBlobMagic)+1:], false, nil
+}
+
+// readZoneBlob decodes an enveloped or legacy blob with decode, the decoder
+// of ZoneToBytes output, and reports whether it is outdated. Data that
+// cannot be an envelope is decoded whole as a legacy blob; if that fails
+// too, the envelope error is returned.
+func readZoneBlob(data []byte, decode func([]byte) (Zone, error)) (Zone, bool, error) {
+	version, payload, maybeLegacy, err := openZoneBlob(data)
+	if err != nil {
+		if !maybeLegacy {
+			return Zone{}, false, err
+		}
+		zone, legacyErr := decode(data)
+		if legacyErr != nil {
+			return Zone{}, false, err
+		}
+		return zone, true, nil
+	}
+	zone, err := decodeZonePayload(version, payload, decode)
+	if err != nil {
+		return Zone{}, false, err
+	}
+	return zone, version != ZoneBlobVersion, nil
+}
+
+func decodeZonePayload(version uint8, payload []byte, decode func([]byte) (Zone, error)) (Zone, error) {
+	switch version {
+	case zoneBlobVersionLegacy, zoneBlobVersion1:
+		return decode(payload)
+	default:
+		return Zone{}, newInvalidInputError(CodeInvalidInput, fmt.Sprintf("unsupported zone blob version %d", version))
+	}
+}
@@ -0,0 +1,161 @@
+package libveritas
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeDecodeZone stands in for DecodeZone: it rejects payloads containing
+// "bad" and returns any other payload as the zone's handle.
+func fakeDecodeZone(payload []byte) (Zone, error) {
+	if bytes.Contains(payload, []byte("bad")) {
+		return Zone{}, newInvalidInputError(CodeInvalidInput, "not a zone")
+	}
+	return Zone{Handle: string(payload)}, nil
+}
+
+// fakeZoneToBytes is the encoder matching fakeDecodeZone.
+func fakeZoneToBytes(zone Zone) ([]byte, error) {
+	return []byte(zone.Handle), nil
+}
+
+func TestZoneBlobEnvelope(t *testing.T) {
+	blob := sealZoneBlob(ZoneBlobVersion, []byte("zone"))
+	if !bytes.HasPrefix(blob, zoneBlobMagic) || len(blob) != zoneBlobOverhead+len("zone") {
+		t.Fatalf("sealZoneBlob = %q", blob)
+	}
+	version, payload, _, err := openZoneBlob(blob)
+	if err != nil || version != ZoneBlobVersion || string(payload) != "zone" {
+		t.Errorf("openZoneBlob = %d, %q, %v", version, payload, err)
+	}
+	zone, outdated, err := readZoneBlob(blob, fakeDecodeZone)
+	if err != nil || zone.Handle != "zone" || outdated {
+		t.Errorf("readZoneBlob = %q, %v, %v", zone.Handle, outdated, err)
+	}
+}
+
+func TestZoneBlobLegacy(t *testing.T) {
+	tests := [][]byte{
+		[]byte("zone"),
+		// Legacy blobs that start with the magic by chance but cannot be
+		// envelopes are decoded whole.
+		[]byte("LVZ"),
+		[]byte("LVZ\x01zon"),
+		append([]byte("LVZ\x00zone"), 0, 0, 0, 0),
+		append([]byte("LVZ\x07zone"), 0, 0, 0, 0),
+	}
+	for _, data := range tests {
+		zone, outdated, err := readZoneBlob(data, fakeDecodeZone)
+		if err != nil || zone.Handle != string(data) || !outdated {
+			t.Errorf("readZoneBlob(%q) = %q, %v, %v", data, zone.Handle, outdated, err)
+		}
+	}
+}
+
+func TestZoneBlobErrors(t *testing.T) {
+	// A corrupt envelope is not decoded as a legacy blob, even when the
+	// legacy decoder would accept it.
+	corrupt := sealZoneBlob(ZoneBlobVersion, []byte("zone"))
+	corrupt[4] ^= 0xff
+	tests := map[string][]byte{
+		"checksum":  corrupt,
+		"truncated": []byte("LVZbad"),
+		"version 0": sealZoneBlob(zoneBlobVersionLegacy, []byte("bad")),
+		"future":    sealZoneBlob(ZoneBlobVersion+1, []byte("bad")),
+		"payload":   sealZoneBlob(ZoneBlobVersion, []byte("bad")),
+		"legacy":    []byte("bad"),
+	}
+	for name, data := range tests {
+		if _, _, err := readZoneBlob(data, fakeDecodeZone); !errors.Is(err, ErrVeritasErrorInvalidInput) {
+			t.Errorf("%s: error = %v, want ErrVeritasErrorInvalidInput", name, err)
+		}
+	}
+	// The envelope error is reported, not the one of the legacy decoder.
+	_, _, err := readZoneBlob(sealZoneBlob(ZoneBlobVersion+1, []byte("bad")), fakeDecodeZone)
+	if err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("error = %v, want the envelope error", err)
+	}
+	_, _, err = readZoneBlob(corrupt, fakeDecodeZone)
+	if err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("error = %v, want the checksum error", err)
+	}
+}
+
+func TestUpgradeZoneBlob(t *testing.T) {
+	current := sealZoneBlob(ZoneBlobVersion, []byte("zone"))
+	out, changed, err := upgradeZoneBlob(current, fakeDecodeZone, fakeZoneToBytes)
+	if err != nil || changed || !bytes.Equal(out, current) {
+		t.Errorf("upgrade of current blob = %q, %v, %v", out, changed, err)
+	}
+	for _, legacy := range [][]byte{[]byte("zone"), []byte("LVZ\x07zone\x00\x00\x00\x00")} {
+		out, changed, err = upgradeZoneBlob(legacy, fakeDecodeZone, fakeZoneToBytes)
+		if err != nil || !changed || !bytes.Equal(out, sealZoneBlob(ZoneBlobVersion, legacy)) {
+			t.Errorf("upgrade of %q = %q, %v, %v", legacy, out, changed, err)
+		}
+	}
+	if _, _, err := upgradeZoneBlob([]byte("bad"), fakeDecodeZone, fakeZoneToBytes); err == nil {
+		t.Error("upgrade of an undecodable blob succeeded")
+	}
+}
+
+func TestZoneScanErrors(t *testing.T) {
+	var zone Zone
+	for _, src := range []any{nil, 42} {
+		if err := zone.Scan(src); !errors.Is(err, ErrVeritasErrorInvalidInput) {
+			t.Errorf("Scan(%v) = %v, want ErrVeritasErrorInvalidInput", src, err)
+		}
+	}
+}
+
+// TestNativeZoneBlobRoundTrip runs the envelope over the native codec.
+func TestNativeZoneBlobRoundTrip(t *testing.T) {
+	if !NativeAvailable() {
+		t.Skip("native library not available")
+	}
+	zone := Zone{
+		Anchor:          1,
+		Sovereignty:     "sovereign",
+		Handle:          "alice@bitcoin",
+		Canonical:       "alice@bitcoin",
+		AnchorHash:      make([]byte, 32),
+		ScriptPubkey:    make([]byte, 34),
+		Records:         []byte{},
+		FallbackRecords: []byte{},
+		Delegate:        DelegateStateEmpty{},
+		Commitment:      CommitmentStateEmpty{},
+	}
+	legacy, err := ZoneToBytes(zone)
+	if err != nil {
+		t.Skipf("ZoneToBytes: %v", err)
+	}
+	want, err := DecodeZone(legacy)
+	if err != nil {
+		t.Fatalf("DecodeZone: %v", err)
+	}
+
+	blob, err := zone.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	var got Zone
+	if err := got.UnmarshalBinary(blob); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalBinary = %+v, %v, want %+v", got, err, want)
+	}
+	if err := got.Scan(legacy); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan of legacy blob = %+v, %v, want %+v", got, err, want)
+	}
+	if _, outdated, err := ReadZoneBlob(legacy); err != nil || !outdated {
+		t.Errorf("ReadZoneBlob of legacy blob = %v, %v, want outdated", outdated, err)
+	}
+
+	upgraded, changed, err := UpgradeZoneBlob(legacy)
+	if err != nil || !changed || !bytes.Equal(upgraded, blob) {
+		t.Errorf("UpgradeZoneBlob = %x, %v, %v, want %x", upgraded, changed, err, blob)
+	}
+	if _, changed, err := UpgradeZoneBlob(blob); err != nil || changed {
+		t.Errorf("UpgradeZoneBlob of current blob = %v, %v", changed, err)
+	}
+}
@@ -0,0 +1,517 @@
+package libveritas
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// JSON encoding of zones and records.
+//
+// Variants of the sealed interfaces Record, ParsedRecord, DelegateState and
+// CommitmentState encode as objects with a "type" tag:
+//
+//	{"type": "txt", "key": "website", "value": ["https://example.com"]}
+//	{"type": "blob", "key": "avatar", "value": "89504e47"}
+//	{"type": "exists", "script_pubkey": "5120...", ...}
+//
+// Byte fields are lower-case hex; optional fields are null when absent.
+// Empty and nil byte slices both encode as "" and decode as nil.
+//
+// The variants implement json.Marshaler. Interface values are decoded with
+// UnmarshalRecordJSON and friends, or as part of a Zone, RecordList or
+// ParsedRecordList. Decoding the encoding of a Zone yields an equal Zone.
+
+// Tags of the record variants.
+const (
+	recordTagSeq       = "seq"
+	recordTagTxt       = "txt"
+	recordTagAddr      = "addr"
+	recordTagBlob      = "blob"
+	recordTagSig       = "sig"
+	recordTagMalformed = "malformed"
+	recordTagUnknown   = "unknown"
+)
+
+// Tags of the delegate and commitment state variants.
+const (
+	stateTagExists  = "exists"
+	stateTagEmpty   = "empty"
+	stateTagUnknown = "unknown"
+)
+
+// hexBytes is []byte encoded as a hex string.
+type hexBytes []byte
+
+func (b hexBytes) MarshalText() ([]byte, error) {
+	out := make([]byte, hex.EncodedLen(len(b)))
+	hex.Encode(out, b)
+	return out, nil
+}
+
+func (b *hexBytes) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*b = nil
+		return nil
+	}
+	out := make([]byte, hex.DecodedLen(len(text)))
+	if _, err := hex.Decode(out, text); err != nil {
+		return err
+	}
+	*b = out
+	return nil
+}
+
+type taggedJSON struct {
+	Type string `json:"type"`
+}
+
+type seqJSON struct {
+	Type    string `json:"type"`
+	Version uint64 `json:"version"`
+}
+
+type stringsJSON struct {
+	Type  string   `json:"type"`
+	Key   string   `json:"key"`
+	Value []string `json:"value"`
+}
+
+type blobJSON struct {
+	Type  string   `json:"type"`
+	Key   string   `json:"key"`
+	Value hexBytes `json:"value"`
+}
+
+type sigJSON struct {
+	Type      string   `json:"type"`
+	Flags     uint8    `json:"flags"`
+	Canonical string   `json:"canonical"`
+	Handle    string   `json:"handle"`
+	Sig       hexBytes `json:"sig"`
+}
+
+type rawRecordJSON struct {
+	Type  string   `json:"type"`
+	Rtype uint8    `json:"rtype"`
+	Rdata hexBytes `json:"rdata"`
+}
+
+type delegateJSON struct {
+	Type            string   `json:"type"`
+	ScriptPubkey    hexBytes `json:"script_pubkey"`
+	FallbackRecords hexBytes `json:"fallback_records"`
+	Records         hexBytes `json:"records"`
+}
+
+type commitmentJSON struct {
+	Type        string    `json:"type"`
+	StateRoot   hexBytes  `json:"state_root"`
+	PrevRoot    *hexBytes `json:"prev_root"`
+	RollingHash hexBytes  `json:"rolling_hash"`
+	BlockHeight uint32    `json:"block_height"`
+	ReceiptHash *hexBytes `json:"receipt_hash"`
+}
+
+type zoneJSON struct {
+	Anchor          uint32          `json:"anchor"`
+	AnchorHash      hexBytes        `json:"anchor_hash"`
+	Sovereignty     string          `json:"sovereignty"`
+	Handle          string          `json:"handle"`
+	Canonical       string          `json:"canonical"`
+	Alias           *string         `json:"alias"`
+	ScriptPubkey    hexBytes        `json:"script_pubkey"`
+	NumId           *string         `json:"num_id"`
+	Records         hexBytes        `json:"records"`
+	FallbackRecords hexBytes        `json:"fallback_records"`
+	Delegate        json.RawMessage `json:"delegate"`
+	Commitment      json.RawMessage `json:"commitment"`
+}
+
+func (e RecordSeq) MarshalJSON() ([]byte, error) {
+	return json.Marshal(seqJSON{recordTagSeq, e.Version})
+}
+
+func (e RecordTxt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(stringsJSON{recordTagTxt, e.Key, e.Value})
+}
+
+func (e RecordAddr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(stringsJSON{recordTagAddr, e.Key, e.Value})
+}
+
+func (e RecordBlob) MarshalJSON() ([]byte, error) {
+	return json.Marshal(blobJSON{recordTagBlob, e.Key, e.Value})
+}
+
+func (e RecordSig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sigJSON{recordTagSig, e.Flags, e.Canonical, e.Handle, e.Sig})
+}
+
+func (e RecordUnknown) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rawRecordJSON{recordTagUnknown, e.Rtype, e.Rdata})
+}
+
+func (e ParsedRecordSeq) MarshalJSON() ([]byte, error) {
+	return json.Marshal(seqJSON{recordTagSeq, e.Version})
+}
+
+func (e ParsedRecordTxt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(stringsJSON{recordTagTxt, e.Key, e.Value})
+}
+
+func (e ParsedRecordAddr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(stringsJSON{recordTagAddr, e.Key, e.Value})
+}
+
+func (e ParsedRecordBlob) MarshalJSON() ([]byte, error) {
+	return json.Marshal(blobJSON{recordTagBlob, e.Key, e.Value})
+}
+
+func (e ParsedRecordSig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sigJSON{recordTagSig, e.Flags, e.Canonical, e.Handle, e.Sig})
+}
+
+func (e ParsedRecordMalformed) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rawRecordJSON{recordTagMalformed, e.Rtype, e.Rdata})
+}
+
+func (e ParsedRecordUnknown) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rawRecordJSON{recordTagUnknown, e.Rtype, e.Rdata})
+}
+
+func (e DelegateStateExists) MarshalJSON() ([]byte, error) {
+	return json.Marshal(delegateJSON{stateTagExists, e.ScriptPubkey, e.FallbackRecords, e.Records})
+}
+
+func (e DelegateStateEmpty) MarshalJSON() ([]byte, error) {
+	return json.Marshal(taggedJSON{stateTagEmpty})
+}
+
+func (e DelegateStateUnknown) MarshalJSON() ([]byte, error) {
+	return json.Marshal(taggedJSON{stateTagUnknown})
+}
+
+func (e CommitmentStateExists) MarshalJSON() ([]byte, error) {
+	return json.Marshal(commitmentJSON{
+		Type:        stateTagExists,
+		StateRoot:   e.StateRoot,
+		PrevRoot:    (*hexBytes)(e.PrevRoot),
+		RollingHash: e.RollingHash,
+		BlockHeight: e.BlockHeight,
+		ReceiptHash: (*hexBytes)(e.ReceiptHash),
+	})
+}
+
+func (e CommitmentStateEmpty) MarshalJSON() ([]byte, error) {
+	return json.Marshal(taggedJSON{stateTagEmpty})
+}
+
+func (e CommitmentStateUnknown) MarshalJSON() ([]byte, error) {
+	return json.Marshal(taggedJSON{stateTagUnknown})
+}
+
+// UnmarshalRecordJSON decodes a tagged Record.
+func UnmarshalRecordJSON(data []byte) (Record, error) {
+	record, err := decodeRecordJSON(data)
+	if err != nil {
+		return nil, newInvalidInputError(CodeMalformedRecords, "record: "+err.Error())
+	}
+	return record, nil
+}
+
+// UnmarshalParsedRecordJSON decodes a tagged ParsedRecord.
+func UnmarshalParsedRecordJSON(data []byte) (ParsedRecord, error) {
+	record, err := decodeParsedRecordJSON(data)
+	if err != nil {
+		return nil, newInvalidInputError(CodeMalformedRecords, "record: "+err.Error())
+	}
+	return record, nil
+}
+
+// UnmarshalDelegateStateJSON decodes a tagged DelegateState. JSON null
+// decodes as a nil DelegateState.
+func UnmarshalDelegateStateJSON(data []byte) (DelegateState, error) {
+	state, err := decodeDelegateStateJSON(data)
+	if err != nil {
+		return nil, newInvalidInputError(CodeInvalidInput, "delegate state: "+err.Error())
+	}
+	return state, nil
+}
+
+// UnmarshalCommitmentStateJSON decodes a tagged CommitmentState. JSON null
+// decodes as a nil CommitmentState.
+func UnmarshalCommitmentStateJSON(data []byte) (CommitmentState, error) {
+	state, err := decodeCommitmentStateJSON(data)
+	if err != nil {
+		return nil, newInvalidInputError(CodeInvalidInput, "commitment state: "+err.Error())
+	}
+	return state, nil
+}
+
+// RecordList is a []Record that can be decoded from JSON, for use in
+// request bodies and config structs.
+type RecordList []Record
+
+func (l *RecordList) UnmarshalJSON(data []byte) error {
+	records, err := decodeListJSON(data, decodeRecordJSON)
+	if err != nil {
+		return newInvalidInputError(CodeMalformedRecords, err.Error())
+	}
+	*l = records
+	return nil
+}
+
+// ParsedRecordList is a []ParsedRecord that can be decoded from JSON.
+type ParsedRecordList []ParsedRecord
+
+func (l *ParsedRecordList) UnmarshalJSON(data []byte) error {
+	records, err := decodeListJSON(data, decodeParsedRecordJSON)
+	if err != nil {
+		return newInvalidInputError(CodeMalformedRecords, err.Error())
+	}
+	*l = records
+	return nil
+}
+
+func (r Zone) MarshalJSON() ([]byte, error) {
+	delegate, err := json.Marshal(r.Delegate)
+	if err != nil {
+		return nil, err
+	}
+	commitment, err := json.Marshal(r.Commitment)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(zoneJSON{
+		Anchor:          r.Anchor,
+		AnchorHash:      r.AnchorHash,
+		Sovereignty:     r.Sovereignty,
+		Handle:          r.Handle,
+		Canonical:       r.Canonical,
+		Alias:           r.Alias,
+		ScriptPubkey:    r.ScriptPubkey,
+		NumId:           r.NumId,
+		Records:         r.Records,
+		FallbackRecords: r.FallbackRecords,
+		Delegate:        delegate,
+		Commitment:      commitment,
+	})
+}
+
+func (r *Zone) UnmarshalJSON(data []byte) error {
+	var v zoneJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return newInvalidInputError(CodeInvalidInput, "zone: "+err.Error())
+	}
+	delegate, err := decodeDelegateStateJSON(v.Delegate)
+	if err != nil {
+		return newInvalidInputError(CodeInvalidInput, "zone: delegate: "+err.Error())
+	}
+	commitment, err := decodeCommitmentStateJSON(v.Commitment)
+	if err != nil {
+		return newInvalidInputError(CodeInvalidInput, "zone: commitment: "+err.Error())
+	}
+	*r = Zone{
+		Anchor:          v.Anchor,
+		AnchorHash:      v.AnchorHash,
+		Sovereignty:     v.Sovereignty,
+		Handle:          v.Handle,
+		Canonical:       v.Canonical,
+		Alias:           v.Alias,
+		ScriptPubkey:    v.ScriptPubkey,
+		NumId:           v.NumId,
+		Records:         v.Records,
+		FallbackRecords: v.FallbackRecords,
+		Delegate:        delegate,
+		Commitment:      commitment,
+	}
+	return nil
+}
+
+// ZoneFromTaggedJson decodes a zone encoded with json.Marshal, in the tagged
+// format described above. It is the inverse of Zone.MarshalJSON, not of
+// the native ZoneToJson, whose output is meant for display and is not
+// accepted.
+func ZoneFromTaggedJson(data string) (Zone, error) {
+	var zone Zone
+	if err := zone.UnmarshalJSON([]byte(data)); err != nil {
+		return Zone{}, err
+	}
+	return zone, nil
+}
+
+func decodeRecordJSON(data []byte) (Record, error) {
+	tag, err := readJSONTag(data)
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case recordTagSeq:
+		return decodeTaggedJSON(data, func(v seqJSON) Record {
+			return RecordSeq{Version: v.Version}
+		})
+	case recordTagTxt:
+		return decodeTaggedJSON(data, func(v stringsJSON) Record {
+			return RecordTxt{Key: v.Key, Value: v.Value}
+		})
+	case recordTagAddr:
+		return decodeTaggedJSON(data, func(v stringsJSON) Record {
+			return RecordAddr{Key: v.Key, Value: v.Value}
+		})
+	case recordTagBlob:
+		return decodeTaggedJSON(data, func(v blobJSON) Record {
+			return RecordBlob{Key: v.Key, Value: v.Value}
+		})
+	case recordTagSig:
+		return decodeTaggedJSON(data, func(v sigJSON) Record {
+			return RecordSig{Flags: v.Flags, Canonical: v.Canonical, Handle: v.Handle, Sig: v.Sig}
+		})
+	case recordTagUnknown:
+		return decodeTaggedJSON(data, func(v rawRecordJSON) Record {
+			return RecordUnknown{Rtype: v.Rtype, Rdata: v.Rdata}
+		})
+	default:
+		return nil, fmt.Errorf("unknown type %q", tag)
+	}
+}
+
+func decodeParsedRecordJSON(data []byte) (ParsedRecord, error) {
+	tag, err := readJSONTag(data)
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case recordTagSeq:
+		return decodeTaggedJSON(data, func(v seqJSON) ParsedRecord {
+			return ParsedRecordSeq{Version: v.Version}
+		})
+	case recordTagTxt:
+		return decodeTaggedJSON(data, func(v stringsJSON) ParsedRecord {
+			return ParsedRecordTxt{Key: v.Key, Value: v.Value}
+		})
+	case recordTagAddr:
+		return decodeTaggedJSON(data, func(v stringsJSON) ParsedRecord {
+			return ParsedRecordAddr{Key: v.Key, Value: v.Value}
+		})
+	case recordTagBlob:
+		return decodeTaggedJSON(data, func(v blobJSON) ParsedRecord {
+			return ParsedRecordBlob{Key: v.Key, Value: v.Value}
+		})
+	case recordTagSig:
+		return decodeTaggedJSON(data, func(v sigJSON) ParsedRecord {
+			return ParsedRecordSig{Flags: v.Flags, Canonical: v.Canonical, Handle: v.Handle, Sig: v.Sig}
+		})
+	case recordTagMalformed:
+		return decodeTaggedJSON(data, func(v rawRecordJSON) ParsedRecord {
+			return ParsedRecordMalformed{Rtype: v.Rtype, Rdata: v.Rdata}
+		})
+	case recordTagUnknown:
+		return decodeTaggedJSON(data, func(v rawRecordJSON) ParsedRecord {
+			return ParsedRecordUnknown{Rtype: v.Rtype, Rdata: v.Rdata}
+		})
+	default:
+		return nil, fmt.Errorf("unknown type %q", tag)
+	}
+}
+
+func decodeDelegateStateJSON(data []byte) (DelegateState, error) {
+	if isJSONNull(data) {
+		return nil, nil
+	}
+	tag, err := readJSONTag(data)
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case stateTagExists:
+		return decodeTaggedJSON(data, func(v delegateJSON) DelegateState {
+			return DelegateStateExists{
+				ScriptPubkey:    v.ScriptPubkey,
+				FallbackRecords: v.FallbackRecords,
+				Records:         v.Records,
+			}
+		})
+	case stateTagEmpty:
+		return DelegateStateEmpty{}, nil
+	case stateTagUnknown:
+		return DelegateStateUnknown{}, nil
+	default:
+		return nil, fmt.Errorf("unknown type %q", tag)
+	}
+}
+
+func decodeCommitmentStateJSON(data []byte) (CommitmentState, error) {
+	if isJSONNull(data) {
+		return nil, nil
+	}
+	tag, err := readJSONTag(data)
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case stateTagExists:
+		return decodeTaggedJSON(data, func(v commitmentJSON) CommitmentState {
+			return CommitmentStateExists{
+				StateRoot:   v.StateRoot,
+				PrevRoot:    (*[]byte)(v.PrevRoot),
+				RollingHash: v.RollingHash,
+				BlockHeight: v.BlockHeight,
+				ReceiptHash: (*[]byte)(v.ReceiptHash),
+			}
+		})
+	case stateTagEmpty:
+		return CommitmentStateEmpty{}, nil
+	case stateTagUnknown:
+		return CommitmentStateUnknown{}, nil
+	default:
+		return nil, fmt.Errorf("unknown type %q", tag)
+	}
+}
+
+// decodeTaggedJSON decodes data into the JSON form J of a variant and
+// converts it with build.
+func decodeTaggedJSON[J any, V any](data []byte, build func(J) V) (V, error) {
+	var v J
+	if err := json.Unmarshal(data, &v); err != nil {
+		var zero V
+		return zero, err
+	}
+	return build(v), nil
+}
+
+// decodeListJSON decodes a JSON array with decode, reporting the index of a
+// failing item.
+func decodeListJSON[V any](data []byte, decode func([]byte) (V, error)) ([]V, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	if items == nil {
+		return nil, nil
+	}
+	values := make([]V, len(items))
+	for i, item := range items {
+		value, err := decode(item)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i, err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func readJSONTag(data []byte) (string, error) {
+	var tag taggedJSON
+	if err := json.Unmarshal(data, &tag); err != nil {
+		return "", err
+	}
+	if tag.Type == "" {
+		return "", fmt.Errorf("missing \"type\"")
+	}
+	return tag.Type, nil
+}
+
+func isJSONNull(data []byte) bool {
+	return len(data) == 0 || string(data) == "null"
+}
@@ -0,0 +1,126 @@
+package libveritas
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func testZone() Zone {
+	alias := "al@bitcoin"
+	prevRoot := []byte{0x02}
+	return Zone{
+		Anchor:          100,
+		AnchorHash:      []byte{0xaa, 0xbb},
+		Sovereignty:     "sovereign",
+		Handle:          "alice@bitcoin",
+		Canonical:       "alice@bitcoin",
+		Alias:           &alias,
+		ScriptPubkey:    []byte{0x51, 0x20},
+		Records:         []byte{0x00, 0x01, 0x01},
+		FallbackRecords: nil,
+		Delegate: DelegateStateExists{
+			ScriptPubkey: []byte{0x51},
+			Records:      []byte{0x01},
+		},
+		Commitment: CommitmentStateExists{
+			StateRoot:   []byte{0x01},
+			PrevRoot:    &prevRoot,
+			RollingHash: []byte{0x03},
+			BlockHeight: 900000,
+		},
+	}
+}
+
+func TestZoneTaggedJSONRoundTrip(t *testing.T) {
+	zones := []Zone{
+		testZone(),
+		{Handle: "@bitcoin", Delegate: DelegateStateEmpty{}, Commitment: CommitmentStateUnknown{}},
+		{Handle: "bob@bitcoin", Delegate: DelegateStateUnknown{}, Commitment: CommitmentStateEmpty{}},
+	}
+	for _, zone := range zones {
+		data, err := json.Marshal(zone)
+		if err != nil {
+			t.Fatalf("Marshal(%s): %v", zone.Handle, err)
+		}
+		decoded, err := ZoneFromTaggedJson(string(data))
+		if err != nil {
+			t.Fatalf("ZoneFromTaggedJson(%s): %v", data, err)
+		}
+		if !reflect.DeepEqual(decoded, zone) {
+			t.Errorf("round trip of %s = %+v, want %+v", data, decoded, zone)
+		}
+	}
+}
+
+func TestZoneTaggedJSONFormat(t *testing.T) {
+	data, err := json.Marshal(Zone{Handle: "@bitcoin", Delegate: DelegateStateEmpty{}, Commitment: CommitmentStateEmpty{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if string(fields["delegate"]) != `{"type":"empty"}` || string(fields["alias"]) != "null" || string(fields["records"]) != `""` {
+		t.Errorf("Marshal = %s", data)
+	}
+}
+
+func TestZoneFromTaggedJsonErrors(t *testing.T) {
+	for _, data := range []string{
+		`not json`,
+		`{"handle":"a@b","delegate":{"type":"bogus"},"commitment":{"type":"empty"}}`,
+		`{"handle":"a@b","delegate":{"type":"empty"},"commitment":{"type":"exists","state_root":"zz"}}`,
+		`{"handle":"a@b","records":"0"}`,
+	} {
+		if _, err := ZoneFromTaggedJson(data); !errors.Is(err, ErrVeritasErrorInvalidInput) {
+			t.Errorf("ZoneFromTaggedJson(%s) = %v, want ErrVeritasErrorInvalidInput", data, err)
+		}
+	}
+}
+
+func TestRecordListJSON(t *testing.T) {
+	records := []Record{
+		RecordSeq{Version: 1},
+		RecordTxt{Key: "website", Value: []string{"https://example.com"}},
+		RecordAddr{Key: "btc", Value: []string{"bc1q"}},
+		RecordBlob{Key: "avatar", Value: []byte{0x89, 0x50}},
+		RecordSig{Flags: 1, Canonical: "alice@bitcoin", Handle: "alice@bitcoin", Sig: []byte{0x01}},
+		RecordUnknown{Rtype: 200, Rdata: []byte{0x02}},
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var decoded RecordList
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal(%s): %v", data, err)
+	}
+	if !reflect.DeepEqual([]Record(decoded), records) {
+		t.Errorf("round trip = %+v, want %+v", decoded, records)
+	}
+	if err := json.Unmarshal([]byte(`[{"type":"nope"}]`), &decoded); !errors.Is(err, ErrMalformedRecords) {
+		t.Errorf("Unmarshal of unknown tag = %v, want ErrMalformedRecords", err)
+	}
+}
+
+func TestParsedRecordListJSON(t *testing.T) {
+	records := []ParsedRecord{
+		ParsedRecordSeq{Version: 2},
+		ParsedRecordTxt{Key: "name", Value: []string{"alice"}},
+		ParsedRecordMalformed{Rtype: 3, Rdata: []byte{0xff}},
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var decoded ParsedRecordList
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal(%s): %v", data, err)
+	}
+	if !reflect.DeepEqual([]ParsedRecord(decoded), records) {
+		t.Errorf("round trip = %+v, want %+v", decoded, records)
+	}
+}